@@ -0,0 +1,248 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/influxdb/influxdb/cluster/internal"
+	"github.com/influxdb/influxdb/tsdb"
+)
+
+// Value types in binary chunks.
+const (
+	valueNil = iota
+	valueFloat
+	valueInteger
+	valueBoolean
+	valueString
+	valueFloats // []float64, used by the stddev and median partial aggregates
+	valueFields // map[string]interface{}, used by raw queries with several fields
+	valueJSON   // partial aggregates without a native encoding
+)
+
+// marshalMapperOutput encodes a mapper output as a snappy-compressed
+// protobuf chunk. Field values keep their types so integers don't lose
+// precision as they do when encoded as JSON.
+func marshalMapperOutput(mo *tsdb.MapperOutput) ([]byte, error) {
+	pb := &internal.MapperChunk{
+		Name:      proto.String(mo.Name),
+		Tags:      marshalTags(mo.Tags),
+		Fields:    mo.Fields,
+		CursorKey: proto.String(mo.CursorKey),
+		Values:    make([]*internal.MapperValue, len(mo.Values)),
+	}
+
+	for i, mv := range mo.Values {
+		v := &internal.MapperValue{
+			Time: proto.Int64(mv.Time),
+			Tags: marshalTags(mv.Tags),
+		}
+
+		if a, ok := mv.Value.([]interface{}); ok {
+			// Aggregate output contains one partial aggregate per function call.
+			v.AggValues = make([]*internal.Value, len(a))
+			for j := range a {
+				pv, err := marshalValue(a[j])
+				if err != nil {
+					return nil, err
+				}
+				v.AggValues[j] = pv
+			}
+		} else {
+			pv, err := marshalValue(mv.Value)
+			if err != nil {
+				return nil, err
+			}
+			v.Value = pv
+		}
+		pb.Values[i] = v
+	}
+
+	buf, err := proto.Marshal(pb)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, buf), nil
+}
+
+// unmarshalMapperOutput decodes a chunk encoded by marshalMapperOutput.
+// Partial aggregates encoded as JSON are decoded with the unmarshaller of
+// their function call.
+func unmarshalMapperOutput(buf []byte, unmarshallers []tsdb.UnmarshalFunc) (*tsdb.MapperOutput, error) {
+	b, err := snappy.Decode(nil, buf)
+	if err != nil {
+		return nil, fmt.Errorf("decompress chunk: %s", err)
+	}
+
+	var pb internal.MapperChunk
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		return nil, err
+	}
+
+	mo := &tsdb.MapperOutput{
+		Name:      pb.GetName(),
+		Tags:      unmarshalTags(pb.GetTags()),
+		Fields:    pb.GetFields(),
+		CursorKey: pb.GetCursorKey(),
+	}
+
+	for _, v := range pb.GetValues() {
+		mv := &tsdb.MapperValue{
+			Time: v.GetTime(),
+			Tags: unmarshalTags(v.GetTags()),
+		}
+
+		if pvs := v.GetAggValues(); len(pvs) > 0 {
+			if len(pvs) > len(unmarshallers) {
+				return nil, fmt.Errorf("unexpected aggregate count: %d", len(pvs))
+			}
+
+			a := make([]interface{}, len(pvs))
+			for i, pv := range pvs {
+				if a[i], err = unmarshalValue(pv, unmarshallers[i]); err != nil {
+					return nil, err
+				}
+			}
+			mv.Value = a
+		} else {
+			if mv.Value, err = unmarshalValue(v.GetValue(), unmarshalJSON); err != nil {
+				return nil, err
+			}
+		}
+		mo.Values = append(mo.Values, mv)
+	}
+
+	return mo, nil
+}
+
+// marshalValue encodes a field value or partial aggregate.
+func marshalValue(v interface{}) (*internal.Value, error) {
+	pv := &internal.Value{}
+	switch v := v.(type) {
+	case nil:
+		pv.Type = proto.Int32(valueNil)
+	case float64:
+		pv.Type = proto.Int32(valueFloat)
+		pv.FloatValue = proto.Float64(v)
+	case int64:
+		pv.Type = proto.Int32(valueInteger)
+		pv.IntegerValue = proto.Int64(v)
+	case bool:
+		pv.Type = proto.Int32(valueBoolean)
+		pv.BooleanValue = proto.Bool(v)
+	case string:
+		pv.Type = proto.Int32(valueString)
+		pv.StringValue = proto.String(v)
+	case []float64:
+		pv.Type = proto.Int32(valueFloats)
+		pv.FloatValues = v
+	case map[string]interface{}:
+		pv.Type = proto.Int32(valueFields)
+		for k := range v {
+			pv.Keys = append(pv.Keys, k)
+		}
+		sort.Strings(pv.Keys)
+
+		pv.Values = make([]*internal.Value, len(pv.Keys))
+		for i, k := range pv.Keys {
+			fv, err := marshalValue(v[k])
+			if err != nil {
+				return nil, err
+			}
+			pv.Values[i] = fv
+		}
+	default:
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		pv.Type = proto.Int32(valueJSON)
+		pv.JSON = buf
+	}
+	return pv, nil
+}
+
+// unmarshalValue decodes a value encoded by marshalValue. Values encoded as
+// JSON are decoded with fn.
+func unmarshalValue(pv *internal.Value, fn tsdb.UnmarshalFunc) (interface{}, error) {
+	switch pv.GetType() {
+	case valueNil:
+		return nil, nil
+	case valueFloat:
+		return pv.GetFloatValue(), nil
+	case valueInteger:
+		return pv.GetIntegerValue(), nil
+	case valueBoolean:
+		return pv.GetBooleanValue(), nil
+	case valueString:
+		return pv.GetStringValue(), nil
+	case valueFloats:
+		if pv.FloatValues == nil {
+			return []float64{}, nil
+		}
+		return pv.GetFloatValues(), nil
+	case valueFields:
+		keys, values := pv.GetKeys(), pv.GetValues()
+		if len(keys) != len(values) {
+			return nil, fmt.Errorf("field count mismatch: %d keys, %d values", len(keys), len(values))
+		}
+
+		m := make(map[string]interface{}, len(keys))
+		for i, k := range keys {
+			v, err := unmarshalValue(values[i], unmarshalJSON)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case valueJSON:
+		return fn(pv.GetJSON())
+	default:
+		return nil, fmt.Errorf("unknown value type: %d", pv.GetType())
+	}
+}
+
+// unmarshalJSON decodes a raw value encoded as JSON.
+func unmarshalJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// marshalTags encodes a tag set sorted by key.
+func marshalTags(tags map[string]string) []*internal.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	a := make([]*internal.Tag, len(keys))
+	for i, k := range keys {
+		a[i] = &internal.Tag{Key: proto.String(k), Value: proto.String(tags[k])}
+	}
+	return a
+}
+
+// unmarshalTags decodes a tag set encoded by marshalTags.
+func unmarshalTags(a []*internal.Tag) map[string]string {
+	if len(a) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, len(a))
+	for _, t := range a {
+		tags[t.GetKey()] = t.GetValue()
+	}
+	return tags
+}
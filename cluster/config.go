@@ -15,6 +15,22 @@ const (
 
 	// DefaultShardMapperTimeout is the default timeout set on shard mappers.
 	DefaultShardMapperTimeout = 5 * time.Second
+
+	// DefaultWriteBatchSize is the default maximum number of points combined
+	// into one write request to a remote shard.
+	DefaultWriteBatchSize = 5000
+
+	// DefaultWriteBatchDelay is the default time to wait for more writes to
+	// a remote shard before sending a request.
+	DefaultWriteBatchDelay = 0
+
+	// DefaultMaxPendingWritePoints is the default maximum number of points
+	// waiting to be sent to a remote shard.
+	DefaultMaxPendingWritePoints = 100000
+
+	// DefaultMaxPipelinedWrites is the default maximum number of write
+	// requests sent to a node before their responses are read.
+	DefaultMaxPipelinedWrites = 8
 )
 
 // Config represents the configuration for the clustering service.
@@ -23,6 +39,25 @@ type Config struct {
 	WriteTimeout            toml.Duration `toml:"write-timeout"`
 	ShardWriterTimeout      toml.Duration `toml:"shard-writer-timeout"`
 	ShardMapperTimeout      toml.Duration `toml:"shard-mapper-timeout"`
+
+	// Writes to the same remote shard are combined into requests of up to
+	// WriteBatchSize points. Requests wait up to WriteBatchDelay for more
+	// writes. Writes fail once MaxPendingWritePoints are waiting and are
+	// queued for hinted handoff instead.
+	WriteBatchSize        int           `toml:"write-batch-size"`
+	WriteBatchDelay       toml.Duration `toml:"write-batch-delay"`
+	MaxPendingWritePoints int           `toml:"max-pending-write-points"`
+	MaxPipelinedWrites    int           `toml:"max-pipelined-writes"`
+
+	// The probability that a read of a shard is compared with a second owner
+	// of the shard. Shards that differ are queued for anti-entropy repair.
+	ReadRepairChance float64 `toml:"read-repair-chance"`
+
+	// TLS for all connections between nodes. Each node presents the
+	// certificate in TLSCertificate and verifies its peers against TLSCA.
+	TLSEnabled     bool   `toml:"tls-enabled"`
+	TLSCertificate string `toml:"tls-certificate"`
+	TLSCA          string `toml:"tls-ca"`
 }
 
 // NewConfig returns an instance of Config with defaults.
@@ -31,5 +66,10 @@ func NewConfig() Config {
 		WriteTimeout:       toml.Duration(DefaultWriteTimeout),
 		ShardWriterTimeout: toml.Duration(DefaultShardWriterTimeout),
 		ShardMapperTimeout: toml.Duration(DefaultShardMapperTimeout),
+
+		WriteBatchSize:        DefaultWriteBatchSize,
+		WriteBatchDelay:       toml.Duration(DefaultWriteBatchDelay),
+		MaxPendingWritePoints: DefaultMaxPendingWritePoints,
+		MaxPipelinedWrites:    DefaultMaxPipelinedWrites,
 	}
 }
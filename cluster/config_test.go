@@ -14,6 +14,11 @@ func TestConfig_Parse(t *testing.T) {
 	if _, err := toml.Decode(`
 shard-writer-timeout = "10s"
 write-timeout = "20s"
+read-repair-chance = 0.25
+write-batch-size = 1000
+write-batch-delay = "10ms"
+max-pending-write-points = 20000
+max-pipelined-writes = 4
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -23,5 +28,15 @@ write-timeout = "20s"
 		t.Fatalf("unexpected shard-writer timeout: %s", c.ShardWriterTimeout)
 	} else if time.Duration(c.WriteTimeout) != 20*time.Second {
 		t.Fatalf("unexpected write timeout s: %s", c.WriteTimeout)
+	} else if c.ReadRepairChance != 0.25 {
+		t.Fatalf("unexpected read repair chance: %v", c.ReadRepairChance)
+	} else if c.WriteBatchSize != 1000 {
+		t.Fatalf("unexpected write batch size: %d", c.WriteBatchSize)
+	} else if time.Duration(c.WriteBatchDelay) != 10*time.Millisecond {
+		t.Fatalf("unexpected write batch delay: %s", c.WriteBatchDelay)
+	} else if c.MaxPendingWritePoints != 20000 {
+		t.Fatalf("unexpected max pending write points: %d", c.MaxPendingWritePoints)
+	} else if c.MaxPipelinedWrites != 4 {
+		t.Fatalf("unexpected max pipelined writes: %d", c.MaxPipelinedWrites)
 	}
 }
@@ -11,8 +11,13 @@ It is generated from these files:
 It has these top-level messages:
 	WriteShardRequest
 	WriteShardResponse
+	PointError
 	MapShardRequest
 	MapShardResponse
+	MapperChunk
+	MapperValue
+	Value
+	Tag
 */
 package internal
 
@@ -50,9 +55,10 @@ func (m *WriteShardRequest) GetPoints() [][]byte {
 }
 
 type WriteShardResponse struct {
-	Code             *int32  `protobuf:"varint,1,req,name=Code" json:"Code,omitempty"`
-	Message          *string `protobuf:"bytes,2,opt,name=Message" json:"Message,omitempty"`
-	XXX_unrecognized []byte  `json:"-"`
+	Code             *int32        `protobuf:"varint,1,req,name=Code" json:"Code,omitempty"`
+	Message          *string       `protobuf:"bytes,2,opt,name=Message" json:"Message,omitempty"`
+	Errors           []*PointError `protobuf:"bytes,3,rep,name=Errors" json:"Errors,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
 }
 
 func (m *WriteShardResponse) Reset()         { *m = WriteShardResponse{} }
@@ -73,10 +79,53 @@ func (m *WriteShardResponse) GetMessage() string {
 	return ""
 }
 
+func (m *WriteShardResponse) GetErrors() []*PointError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type PointError struct {
+	Index            *int32  `protobuf:"varint,1,req,name=Index" json:"Index,omitempty"`
+	Kind             *string `protobuf:"bytes,2,opt,name=Kind" json:"Kind,omitempty"`
+	Message          *string `protobuf:"bytes,3,opt,name=Message" json:"Message,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *PointError) Reset()         { *m = PointError{} }
+func (m *PointError) String() string { return proto.CompactTextString(m) }
+func (*PointError) ProtoMessage()    {}
+
+func (m *PointError) GetIndex() int32 {
+	if m != nil && m.Index != nil {
+		return *m.Index
+	}
+	return 0
+}
+
+func (m *PointError) GetKind() string {
+	if m != nil && m.Kind != nil {
+		return *m.Kind
+	}
+	return ""
+}
+
+func (m *PointError) GetMessage() string {
+	if m != nil && m.Message != nil {
+		return *m.Message
+	}
+	return ""
+}
+
 type MapShardRequest struct {
 	ShardID          *uint64 `protobuf:"varint,1,req,name=ShardID" json:"ShardID,omitempty"`
 	Query            *string `protobuf:"bytes,2,req,name=Query" json:"Query,omitempty"`
 	ChunkSize        *int32  `protobuf:"varint,3,req,name=ChunkSize" json:"ChunkSize,omitempty"`
+	MaxSelectPointN  *int64  `protobuf:"varint,4,opt,name=MaxSelectPointN" json:"MaxSelectPointN,omitempty"`
+	MaxSelectSeriesN *int64  `protobuf:"varint,5,opt,name=MaxSelectSeriesN" json:"MaxSelectSeriesN,omitempty"`
+	Timeout          *int64  `protobuf:"varint,6,opt,name=Timeout" json:"Timeout,omitempty"`
+	BinaryChunks     *bool   `protobuf:"varint,7,opt,name=BinaryChunks" json:"BinaryChunks,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -105,12 +154,43 @@ func (m *MapShardRequest) GetChunkSize() int32 {
 	return 0
 }
 
+func (m *MapShardRequest) GetMaxSelectPointN() int64 {
+	if m != nil && m.MaxSelectPointN != nil {
+		return *m.MaxSelectPointN
+	}
+	return 0
+}
+
+func (m *MapShardRequest) GetMaxSelectSeriesN() int64 {
+	if m != nil && m.MaxSelectSeriesN != nil {
+		return *m.MaxSelectSeriesN
+	}
+	return 0
+}
+
+func (m *MapShardRequest) GetTimeout() int64 {
+	if m != nil && m.Timeout != nil {
+		return *m.Timeout
+	}
+	return 0
+}
+
+func (m *MapShardRequest) GetBinaryChunks() bool {
+	if m != nil && m.BinaryChunks != nil {
+		return *m.BinaryChunks
+	}
+	return false
+}
+
 type MapShardResponse struct {
 	Code             *int32   `protobuf:"varint,1,req,name=Code" json:"Code,omitempty"`
 	Message          *string  `protobuf:"bytes,2,opt,name=Message" json:"Message,omitempty"`
 	Data             []byte   `protobuf:"bytes,3,opt,name=Data" json:"Data,omitempty"`
 	TagSets          []string `protobuf:"bytes,4,rep,name=TagSets" json:"TagSets,omitempty"`
 	Fields           []string `protobuf:"bytes,5,rep,name=Fields" json:"Fields,omitempty"`
+	Chunk            []byte   `protobuf:"bytes,6,opt,name=Chunk" json:"Chunk,omitempty"`
+	PointN           *int64   `protobuf:"varint,7,opt,name=PointN" json:"PointN,omitempty"`
+	SeriesN          *int64   `protobuf:"varint,8,opt,name=SeriesN" json:"SeriesN,omitempty"`
 	XXX_unrecognized []byte   `json:"-"`
 }
 
@@ -152,3 +232,216 @@ func (m *MapShardResponse) GetFields() []string {
 	}
 	return nil
 }
+
+func (m *MapShardResponse) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+func (m *MapShardResponse) GetPointN() int64 {
+	if m != nil && m.PointN != nil {
+		return *m.PointN
+	}
+	return 0
+}
+
+func (m *MapShardResponse) GetSeriesN() int64 {
+	if m != nil && m.SeriesN != nil {
+		return *m.SeriesN
+	}
+	return 0
+}
+
+type MapperChunk struct {
+	Name             *string        `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Tags             []*Tag         `protobuf:"bytes,2,rep,name=Tags" json:"Tags,omitempty"`
+	Fields           []string       `protobuf:"bytes,3,rep,name=Fields" json:"Fields,omitempty"`
+	CursorKey        *string        `protobuf:"bytes,4,opt,name=CursorKey" json:"CursorKey,omitempty"`
+	Values           []*MapperValue `protobuf:"bytes,5,rep,name=Values" json:"Values,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
+}
+
+func (m *MapperChunk) Reset()         { *m = MapperChunk{} }
+func (m *MapperChunk) String() string { return proto.CompactTextString(m) }
+func (*MapperChunk) ProtoMessage()    {}
+
+func (m *MapperChunk) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *MapperChunk) GetTags() []*Tag {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *MapperChunk) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func (m *MapperChunk) GetCursorKey() string {
+	if m != nil && m.CursorKey != nil {
+		return *m.CursorKey
+	}
+	return ""
+}
+
+func (m *MapperChunk) GetValues() []*MapperValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type MapperValue struct {
+	Time             *int64   `protobuf:"varint,1,opt,name=Time" json:"Time,omitempty"`
+	Value            *Value   `protobuf:"bytes,2,opt,name=Value" json:"Value,omitempty"`
+	AggValues        []*Value `protobuf:"bytes,3,rep,name=AggValues" json:"AggValues,omitempty"`
+	Tags             []*Tag   `protobuf:"bytes,4,rep,name=Tags" json:"Tags,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *MapperValue) Reset()         { *m = MapperValue{} }
+func (m *MapperValue) String() string { return proto.CompactTextString(m) }
+func (*MapperValue) ProtoMessage()    {}
+
+func (m *MapperValue) GetTime() int64 {
+	if m != nil && m.Time != nil {
+		return *m.Time
+	}
+	return 0
+}
+
+func (m *MapperValue) GetValue() *Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *MapperValue) GetAggValues() []*Value {
+	if m != nil {
+		return m.AggValues
+	}
+	return nil
+}
+
+func (m *MapperValue) GetTags() []*Tag {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+type Value struct {
+	Type             *int32    `protobuf:"varint,1,req,name=Type" json:"Type,omitempty"`
+	FloatValue       *float64  `protobuf:"fixed64,2,opt,name=FloatValue" json:"FloatValue,omitempty"`
+	IntegerValue     *int64    `protobuf:"varint,3,opt,name=IntegerValue" json:"IntegerValue,omitempty"`
+	BooleanValue     *bool     `protobuf:"varint,4,opt,name=BooleanValue" json:"BooleanValue,omitempty"`
+	StringValue      *string   `protobuf:"bytes,5,opt,name=StringValue" json:"StringValue,omitempty"`
+	FloatValues      []float64 `protobuf:"fixed64,6,rep,name=FloatValues" json:"FloatValues,omitempty"`
+	Keys             []string  `protobuf:"bytes,7,rep,name=Keys" json:"Keys,omitempty"`
+	Values           []*Value  `protobuf:"bytes,8,rep,name=Values" json:"Values,omitempty"`
+	JSON             []byte    `protobuf:"bytes,9,opt,name=JSON" json:"JSON,omitempty"`
+	XXX_unrecognized []byte    `json:"-"`
+}
+
+func (m *Value) Reset()         { *m = Value{} }
+func (m *Value) String() string { return proto.CompactTextString(m) }
+func (*Value) ProtoMessage()    {}
+
+func (m *Value) GetType() int32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
+
+func (m *Value) GetFloatValue() float64 {
+	if m != nil && m.FloatValue != nil {
+		return *m.FloatValue
+	}
+	return 0
+}
+
+func (m *Value) GetIntegerValue() int64 {
+	if m != nil && m.IntegerValue != nil {
+		return *m.IntegerValue
+	}
+	return 0
+}
+
+func (m *Value) GetBooleanValue() bool {
+	if m != nil && m.BooleanValue != nil {
+		return *m.BooleanValue
+	}
+	return false
+}
+
+func (m *Value) GetStringValue() string {
+	if m != nil && m.StringValue != nil {
+		return *m.StringValue
+	}
+	return ""
+}
+
+func (m *Value) GetFloatValues() []float64 {
+	if m != nil {
+		return m.FloatValues
+	}
+	return nil
+}
+
+func (m *Value) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *Value) GetValues() []*Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *Value) GetJSON() []byte {
+	if m != nil {
+		return m.JSON
+	}
+	return nil
+}
+
+type Tag struct {
+	Key              *string `protobuf:"bytes,1,req,name=Key" json:"Key,omitempty"`
+	Value            *string `protobuf:"bytes,2,req,name=Value" json:"Value,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Tag) Reset()         { *m = Tag{} }
+func (m *Tag) String() string { return proto.CompactTextString(m) }
+func (*Tag) ProtoMessage()    {}
+
+func (m *Tag) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *Tag) GetValue() string {
+	if m != nil && m.Value != nil {
+		return *m.Value
+	}
+	return ""
+}
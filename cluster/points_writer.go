@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ const (
 	statPointWriteReq       = "pointReq"
 	statPointWriteReqLocal  = "pointReqLocal"
 	statPointWriteReqRemote = "pointReqRemote"
+	statPointWriteDropped   = "pointDropped"
 	statWriteOK             = "writeOk"
 	statWritePartial        = "writePartial"
 	statWriteTimeout        = "writeTimeout"
@@ -251,10 +253,15 @@ func (w *PointsWriter) WritePoints(p *WritePointsRequest) error {
 
 	// Write each shard in it's own goroutine and return as soon
 	// as one fails.
-	ch := make(chan error, len(shardMappings.Points))
+	type shardWriteResult struct {
+		points []models.Point
+		err    error
+	}
+	ch := make(chan shardWriteResult, len(shardMappings.Points))
 	for shardID, points := range shardMappings.Points {
 		go func(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) {
-			ch <- w.writeToShard(shard, p.Database, p.RetentionPolicy, p.ConsistencyLevel, points)
+			err := w.writeToShard(shard, p.Database, p.RetentionPolicy, p.ConsistencyLevel, points)
+			ch <- shardWriteResult{points, err}
 		}(shardMappings.Shards[shardID], p.Database, p.RetentionPolicy, points)
 	}
 
@@ -274,19 +281,52 @@ func (w *PointsWriter) WritePoints(p *WritePointsRequest) error {
 		w.statMap.Add(statSubWriteDrop, 1)
 	}
 
+	var dropped []influxdb.PointError
 	for range shardMappings.Points {
 		select {
 		case <-w.closing:
 			return ErrWriteFailed
-		case err := <-ch:
-			if err != nil {
-				return err
+		case result := <-ch:
+			if perr, ok := result.err.(*influxdb.PartialWriteError); ok {
+				dropped = append(dropped, requestPointErrors(p.Points, result.points, perr.Errors)...)
+				continue
+			} else if result.err != nil {
+				return result.err
 			}
 		}
 	}
+
+	if len(dropped) > 0 {
+		w.statMap.Add(statPointWriteDropped, int64(len(dropped)))
+		sort.Sort(pointErrors(dropped))
+		return &influxdb.PartialWriteError{Errors: dropped}
+	}
 	return nil
 }
 
+// requestPointErrors converts errors indexed by position in a shard's points
+// to errors indexed by position in the original write request.
+func requestPointErrors(requestPoints, shardPoints []models.Point, errs []influxdb.PointError) []influxdb.PointError {
+	index := make(map[models.Point]int, len(requestPoints))
+	for i, p := range requestPoints {
+		index[p] = i
+	}
+
+	a := make([]influxdb.PointError, len(errs))
+	for i, e := range errs {
+		e.Index = index[shardPoints[e.Index]]
+		a[i] = e
+	}
+	return a
+}
+
+// pointErrors sorts point errors by their index.
+type pointErrors []influxdb.PointError
+
+func (a pointErrors) Len() int           { return len(a) }
+func (a pointErrors) Less(i, j int) bool { return a[i].Index < a[j].Index }
+func (a pointErrors) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
 // writeToShards writes points to a shard and ensures a write consistency level has been met.  If the write
 // partially succeeds, ErrPartialWrite is returned.
 func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPolicy string,
@@ -350,6 +390,7 @@ func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPo
 	var wrote int
 	timeout := time.After(w.WriteTimeout)
 	var writeError error
+	var partialError *influxdb.PartialWriteError
 	for range shard.Owners {
 		select {
 		case <-w.closing:
@@ -359,6 +400,13 @@ func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPo
 			// return timeout error to caller
 			return ErrTimeout
 		case result := <-ch:
+			// Points rejected by the shard are reported back to the client but
+			// the remaining points were written.
+			if perr, ok := result.Err.(*influxdb.PartialWriteError); ok {
+				partialError = perr
+				result.Err = nil
+			}
+
 			// If the write returned an error, continue to the next response
 			if result.Err != nil {
 				w.statMap.Add(statWriteErr, 1)
@@ -376,6 +424,9 @@ func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPo
 			// We wrote the required consistency level
 			if wrote >= required {
 				w.statMap.Add(statWriteOK, 1)
+				if partialError != nil {
+					return partialError
+				}
 				return nil
 			}
 		}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/cluster"
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/models"
@@ -349,6 +350,51 @@ func TestPointsWriter_WritePoints(t *testing.T) {
 	}
 }
 
+// Ensure points rejected by a shard are reported by their index in the write request.
+func TestPointsWriter_WritePoints_PartialWriteError(t *testing.T) {
+	pr := &cluster.WritePointsRequest{
+		Database:         "mydb",
+		RetentionPolicy:  "myrp",
+		ConsistencyLevel: cluster.ConsistencyLevelAll,
+	}
+
+	// The last two points map to the same shard.
+	pr.AddPoint("cpu", 1.0, time.Unix(0, 0), nil)
+	pr.AddPoint("cpu", 2.0, time.Unix(0, 0).Add(time.Hour), nil)
+	pr.AddPoint("cpu", 3.0, time.Unix(0, 0).Add(time.Hour+time.Second), nil)
+
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			if len(points) != 2 {
+				return nil
+			}
+			return &influxdb.PartialWriteError{Errors: []influxdb.PointError{
+				{Index: 1, Kind: influxdb.WriteErrorFieldTypeConflict, Err: influxdb.ErrFieldTypeConflict},
+			}}
+		},
+	}
+
+	ms := NewMetaStore()
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	c := cluster.NewPointsWriter()
+	c.MetaStore = ms
+	c.ShardWriter = &fakeShardWriter{
+		ShardWriteFn: func(shardID, nodeID uint64, points []models.Point) error { return nil },
+	}
+	c.TSDBStore = store
+	c.Subscriber = Subscriber{PointsFn: func() chan<- *cluster.WritePointsRequest { return nil }}
+	c.Open()
+	defer c.Close()
+
+	err := c.WritePoints(pr)
+	if perr, ok := err.(*influxdb.PartialWriteError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(perr.Errors) != 1 || perr.Errors[0].Index != 2 || perr.Errors[0].Kind != influxdb.WriteErrorFieldTypeConflict {
+		t.Fatalf("unexpected point errors: %+v", perr.Errors)
+	}
+}
+
 var shardID uint64
 
 type fakeShardWriter struct {
@@ -0,0 +1,191 @@
+package cluster
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/tsdb"
+)
+
+// readRepairMapper reads a shard from two owners. The chunks of the primary
+// mapper are returned to the caller and their values are compared with the
+// values of the secondary mapper. Owners can split their data into chunks
+// differently so values are compared per series in the order they are read,
+// regardless of the chunk they were returned in. The shard is queued for
+// repair if they differ. Errors from the secondary mapper are ignored.
+type readRepairMapper struct {
+	tsdb.Mapper
+	secondary tsdb.Mapper
+	shardID   uint64
+	repairer  interface {
+		QueueRepair(shardID uint64)
+	}
+
+	ok     bool // true while the secondary mapper can be compared
+	queued bool
+
+	// Values read from one mapper but not yet from the other.
+	primaryValues   []seriesValue
+	secondaryValues []seriesValue
+	secondaryDone   bool
+}
+
+// seriesValue is a single value read from a mapper and the series it belongs to.
+type seriesValue struct {
+	series string
+	value  *tsdb.MapperValue
+}
+
+// newReadRepairMapper returns a mapper that compares primary with secondary.
+func newReadRepairMapper(primary, secondary tsdb.Mapper, shardID uint64, repairer interface {
+	QueueRepair(shardID uint64)
+}) *readRepairMapper {
+	return &readRepairMapper{
+		Mapper:    primary,
+		secondary: secondary,
+		shardID:   shardID,
+		repairer:  repairer,
+	}
+}
+
+// SetSelectLimiter sets the limiter on the primary mapper. The secondary
+// mapper reads the same data so it gets its own limiter with the same limits
+// to keep it from being counted against the statement twice.
+func (m *readRepairMapper) SetSelectLimiter(l *tsdb.SelectLimiter) {
+	if lm, ok := m.Mapper.(tsdb.LimitedMapper); ok {
+		lm.SetSelectLimiter(l)
+	}
+	if lm, ok := m.secondary.(tsdb.LimitedMapper); ok {
+		lm.SetSelectLimiter(tsdb.NewSelectLimiter(l.Limits()))
+	}
+}
+
+// Open opens both mappers.
+func (m *readRepairMapper) Open() error {
+	if err := m.Mapper.Open(); err != nil {
+		return err
+	}
+	m.ok = m.secondary.Open() == nil
+	return nil
+}
+
+// NextChunk returns the next chunk of the primary mapper.
+func (m *readRepairMapper) NextChunk() (interface{}, error) {
+	chunk, err := m.Mapper.NextChunk()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.ok {
+		m.compare(chunk)
+	}
+	return chunk, nil
+}
+
+// compare compares the values of a chunk of the primary mapper with the
+// values of the secondary mapper. The secondary mapper is read until it has
+// returned as many values, or until it is done if the primary mapper is.
+func (m *readRepairMapper) compare(chunk interface{}) {
+	values, ok := chunkValues(chunk)
+	if !ok {
+		m.ok = false
+		return
+	}
+	m.primaryValues = append(m.primaryValues, values...)
+	done := isEmptyChunk(chunk)
+
+	for !m.secondaryDone && (done || len(m.secondaryValues) < len(m.primaryValues)) {
+		other, err := m.secondary.NextChunk()
+		if err != nil {
+			m.ok = false
+			return
+		} else if isEmptyChunk(other) {
+			m.secondaryDone = true
+			break
+		}
+
+		values, ok := chunkValues(other)
+		if !ok {
+			m.ok = false
+			return
+		}
+		m.secondaryValues = append(m.secondaryValues, values...)
+	}
+
+	// Compare the values read from both mappers so far.
+	n := len(m.primaryValues)
+	if len(m.secondaryValues) < n {
+		n = len(m.secondaryValues)
+	}
+	for i := 0; i < n; i++ {
+		if !equalSeriesValues(m.primaryValues[i], m.secondaryValues[i]) {
+			m.ok = false
+			m.queueRepair()
+			return
+		}
+	}
+	m.primaryValues = m.primaryValues[n:]
+	m.secondaryValues = m.secondaryValues[n:]
+
+	// One mapper returned values the other didn't.
+	if (m.secondaryDone && len(m.primaryValues) > 0) || (done && len(m.secondaryValues) > 0) {
+		m.ok = false
+		m.queueRepair()
+	}
+}
+
+// Close closes both mappers.
+func (m *readRepairMapper) Close() {
+	m.Mapper.Close()
+	m.secondary.Close()
+}
+
+// queueRepair queues the shard for repair once.
+func (m *readRepairMapper) queueRepair() {
+	if !m.queued {
+		m.queued = true
+		m.repairer.QueueRepair(m.shardID)
+	}
+}
+
+// isEmptyChunk returns true if chunk marks the end of the data.
+func isEmptyChunk(chunk interface{}) bool {
+	if chunk == nil {
+		return true
+	}
+	mo, ok := chunk.(*tsdb.MapperOutput)
+	return ok && mo == nil
+}
+
+// chunkValues returns the values of a chunk with the series they belong to.
+// Returns false if the chunk can't be compared.
+func chunkValues(chunk interface{}) ([]seriesValue, bool) {
+	if isEmptyChunk(chunk) {
+		return nil, true
+	}
+	mo, ok := chunk.(*tsdb.MapperOutput)
+	if !ok {
+		return nil, false
+	}
+
+	series := seriesKey(mo)
+	values := make([]seriesValue, len(mo.Values))
+	for i, v := range mo.Values {
+		values[i] = seriesValue{series: series, value: v}
+	}
+	return values, true
+}
+
+// seriesKey returns a key identifying the series of a mapper output.
+func seriesKey(mo *tsdb.MapperOutput) string {
+	return mo.Name + "," + string(models.Tags(mo.Tags).HashKey())
+}
+
+// equalSeriesValues returns true if a and b are the same value of the same series.
+func equalSeriesValues(a, b seriesValue) bool {
+	return a.series == b.series &&
+		a.value.Time == b.value.Time &&
+		bytes.Equal(models.Tags(a.value.Tags).HashKey(), models.Tags(b.value.Tags).HashKey()) &&
+		reflect.DeepEqual(a.value.Value, b.value.Value)
+}
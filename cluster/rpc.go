@@ -1,12 +1,15 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/cluster/internal"
 	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/tsdb"
 )
 
 //go:generate protoc --gogo_out=. internal/data.proto
@@ -34,6 +37,34 @@ func (m *MapShardRequest) SetQuery(query string) { m.pb.Query = &query }
 // SetChunkSize sets the Shard map request's chunk size
 func (m *MapShardRequest) SetChunkSize(chunkSize int32) { m.pb.ChunkSize = &chunkSize }
 
+// Limits returns the query limits the remote mapper must enforce.
+func (m *MapShardRequest) Limits() tsdb.QueryLimits {
+	return tsdb.QueryLimits{
+		Timeout:          time.Duration(m.pb.GetTimeout()),
+		MaxSelectPointN:  int(m.pb.GetMaxSelectPointN()),
+		MaxSelectSeriesN: int(m.pb.GetMaxSelectSeriesN()),
+	}
+}
+
+// SetLimits sets the query limits the remote mapper must enforce.
+func (m *MapShardRequest) SetLimits(limits tsdb.QueryLimits) {
+	if limits.Timeout > 0 {
+		m.pb.Timeout = proto.Int64(int64(limits.Timeout))
+	}
+	if limits.MaxSelectPointN > 0 {
+		m.pb.MaxSelectPointN = proto.Int64(int64(limits.MaxSelectPointN))
+	}
+	if limits.MaxSelectSeriesN > 0 {
+		m.pb.MaxSelectSeriesN = proto.Int64(int64(limits.MaxSelectSeriesN))
+	}
+}
+
+// BinaryChunks returns true if the requester accepts binary chunks.
+func (m *MapShardRequest) BinaryChunks() bool { return m.pb.GetBinaryChunks() }
+
+// SetBinaryChunks sets whether the requester accepts binary chunks.
+func (m *MapShardRequest) SetBinaryChunks(v bool) { m.pb.BinaryChunks = &v }
+
 // MarshalBinary encodes the object to a binary format.
 func (m *MapShardRequest) MarshalBinary() ([]byte, error) {
 	return proto.Marshal(&m.pb)
@@ -75,6 +106,15 @@ func (r *MapShardResponse) Fields() []string { return r.pb.GetFields() }
 // Data returns the Shard map response's Data
 func (r *MapShardResponse) Data() []byte { return r.pb.GetData() }
 
+// Chunk returns the Shard map response's binary chunk
+func (r *MapShardResponse) Chunk() []byte { return r.pb.GetChunk() }
+
+// PointN returns the number of points read for the Shard map response
+func (r *MapShardResponse) PointN() int { return int(r.pb.GetPointN()) }
+
+// SeriesN returns the number of series opened for the Shard map response
+func (r *MapShardResponse) SeriesN() int { return int(r.pb.GetSeriesN()) }
+
 // SetCode sets the Shard map response's code
 func (r *MapShardResponse) SetCode(code int) { r.pb.Code = proto.Int32(int32(code)) }
 
@@ -90,6 +130,15 @@ func (r *MapShardResponse) SetFields(fields []string) { r.pb.Fields = fields }
 // SetData sets the Shard map response's Data
 func (r *MapShardResponse) SetData(data []byte) { r.pb.Data = data }
 
+// SetChunk sets the Shard map response's binary chunk
+func (r *MapShardResponse) SetChunk(chunk []byte) { r.pb.Chunk = chunk }
+
+// SetPointN sets the number of points read for the Shard map response
+func (r *MapShardResponse) SetPointN(n int) { r.pb.PointN = proto.Int64(int64(n)) }
+
+// SetSeriesN sets the number of series opened for the Shard map response
+func (r *MapShardResponse) SetSeriesN(n int) { r.pb.SeriesN = proto.Int64(int64(n)) }
+
 // MarshalBinary encodes the object to a binary format.
 func (r *MapShardResponse) MarshalBinary() ([]byte, error) {
 	return proto.Marshal(&r.pb)
@@ -199,6 +248,46 @@ func (w *WriteShardResponse) Code() int { return int(w.pb.GetCode()) }
 // Message returns the Message
 func (w *WriteShardResponse) Message() string { return w.pb.GetMessage() }
 
+// SetPointErrors sets the points rejected by the write.
+func (w *WriteShardResponse) SetPointErrors(errs []influxdb.PointError) {
+	w.pb.Errors = make([]*internal.PointError, len(errs))
+	for i, e := range errs {
+		w.pb.Errors[i] = &internal.PointError{
+			Index:   proto.Int32(int32(e.Index)),
+			Kind:    proto.String(string(e.Kind)),
+			Message: proto.String(e.Err.Error()),
+		}
+	}
+}
+
+// PointErrors returns the points rejected by the write.
+func (w *WriteShardResponse) PointErrors() []influxdb.PointError {
+	if len(w.pb.Errors) == 0 {
+		return nil
+	}
+
+	errs := make([]influxdb.PointError, len(w.pb.Errors))
+	for i, e := range w.pb.Errors {
+		errs[i] = influxdb.PointError{
+			Index: int(e.GetIndex()),
+			Kind:  influxdb.WriteErrorKind(e.GetKind()),
+			Err:   pointError(e.GetMessage()),
+		}
+	}
+	return errs
+}
+
+// pointError returns the error for a point error message from a remote node.
+// Known errors are returned as their variable so they can be compared.
+func pointError(msg string) error {
+	for _, err := range []error{influxdb.ErrFieldsRequired, influxdb.ErrFieldTypeConflict} {
+		if msg == err.Error() {
+			return err
+		}
+	}
+	return errors.New(msg)
+}
+
 // MarshalBinary encodes the object to a binary format.
 func (w *WriteShardResponse) MarshalBinary() ([]byte, error) {
 	return proto.Marshal(&w.pb)
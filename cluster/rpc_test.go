@@ -1,8 +1,12 @@
 package cluster
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/influxdb/influxdb"
 )
 
 func TestWriteShardRequestBinary(t *testing.T) {
@@ -108,3 +112,27 @@ func TestWriteShardResponseBinary(t *testing.T) {
 	}
 
 }
+
+func TestWriteShardResponseBinary_PointErrors(t *testing.T) {
+	errs := []influxdb.PointError{
+		{Index: 1, Kind: influxdb.WriteErrorFieldTypeConflict, Err: influxdb.ErrFieldTypeConflict},
+		{Index: 3, Kind: influxdb.WriteErrorParse, Err: errors.New("bad point")},
+	}
+
+	sr := &WriteShardResponse{}
+	sr.SetCode(1)
+	sr.SetPointErrors(errs)
+	b, err := sr.MarshalBinary()
+	if err != nil {
+		t.Fatalf("WriteShardResponse.MarshalBinary() failed: %v", err)
+	}
+
+	got := &WriteShardResponse{}
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatalf("WriteShardResponse.UnmarshalBinary() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.PointErrors(), errs) {
+		t.Fatalf("PointErrors mismatch: got %v, exp %v", got.PointErrors(), errs)
+	}
+}
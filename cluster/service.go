@@ -209,12 +209,18 @@ func (s *Service) processWriteShardRequest(buf []byte) error {
 
 		err = s.TSDBStore.CreateShard(database, retentionPolicy, req.ShardID())
 		if err != nil {
+			s.statMap.Add(writeShardFail, 1)
 			return err
 		}
-		return s.TSDBStore.WriteToShard(req.ShardID(), req.Points())
+		err = s.TSDBStore.WriteToShard(req.ShardID(), req.Points())
 	}
 
-	if err != nil {
+	// Points rejected individually are returned to the writer as they are so
+	// it knows the rest of the points were written.
+	if _, ok := err.(*influxdb.PartialWriteError); ok {
+		s.statMap.Add(writeShardFail, 1)
+		return err
+	} else if err != nil {
 		s.statMap.Add(writeShardFail, 1)
 		return fmt.Errorf("write shard %d: %s", req.ShardID(), err)
 	}
@@ -228,6 +234,9 @@ func (s *Service) writeShardResponse(w io.Writer, e error) {
 	if e != nil {
 		resp.SetCode(1)
 		resp.SetMessage(e.Error())
+		if perr, ok := e.(*influxdb.PartialWriteError); ok {
+			resp.SetPointErrors(perr.Errors)
+		}
 	} else {
 		resp.SetCode(0)
 	}
@@ -268,12 +277,21 @@ func (s *Service) processMapShardRequest(w io.Writer, buf []byte) error {
 		return writeMapShardResponseMessage(w, NewMapShardResponse(0, ""))
 	}
 
+	// Enforce the limits of the query on the local mapper. The points and
+	// series read are also returned so the limits can be enforced across
+	// all the shards of the statement by the requesting node.
+	limiter := tsdb.NewSelectLimiter(req.Limits())
+	if lm, ok := m.(tsdb.LimitedMapper); ok {
+		lm.SetSelectLimiter(limiter)
+	}
+
 	if err := m.Open(); err != nil {
 		return fmt.Errorf("mapper open: %s", err)
 	}
 	defer m.Close()
 
 	var metaSent bool
+	var pointN, seriesN int // counts already sent
 	for {
 		var resp MapShardResponse
 
@@ -291,7 +309,13 @@ func (s *Service) processMapShardRequest(w io.Writer, buf []byte) error {
 		// NOTE: Even if the chunk is nil, we still need to send one
 		// empty response to let the other side know we're out of data.
 
-		if chunk != nil {
+		if mo, ok := chunk.(*tsdb.MapperOutput); ok && mo != nil && req.BinaryChunks() {
+			b, err := marshalMapperOutput(mo)
+			if err != nil {
+				return fmt.Errorf("encoding: %s", err)
+			}
+			resp.SetChunk(b)
+		} else if chunk != nil {
 			b, err := json.Marshal(chunk)
 			if err != nil {
 				return fmt.Errorf("encoding: %s", err)
@@ -299,6 +323,13 @@ func (s *Service) processMapShardRequest(w io.Writer, buf []byte) error {
 			resp.SetData(b)
 		}
 
+		// Send the points and series read since the last response.
+		if p, s := limiter.Counts(); p > pointN || s > seriesN {
+			resp.SetPointN(p - pointN)
+			resp.SetSeriesN(s - seriesN)
+			pointN, seriesN = p, s
+		}
+
 		// Write to connection.
 		resp.SetCode(0)
 		if err := writeMapShardResponseMessage(w, &resp); err != nil {
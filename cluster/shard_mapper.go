@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/influxdb/influxdb/influxql"
 	"github.com/influxdb/influxdb/meta"
+	"github.com/influxdb/influxdb/tcp"
 	"github.com/influxdb/influxdb/tsdb"
 )
 
@@ -27,6 +29,17 @@ type ShardMapper struct {
 		CreateMapper(shardID uint64, stmt influxql.Statement, chunkSize int) (tsdb.Mapper, error)
 	}
 
+	// If set, connections to other nodes use TLS.
+	TLSConfig *tls.Config
+
+	// The probability that a shard is also read from a second owner. The
+	// shard is queued for repair when the results differ. Requires Repairer.
+	ReadRepairChance float64
+
+	Repairer interface {
+		QueueRepair(shardID uint64)
+	}
+
 	timeout time.Duration
 	pool    *clientPool
 }
@@ -41,40 +54,89 @@ func NewShardMapper(timeout time.Duration) *ShardMapper {
 
 // CreateMapper returns a Mapper for the given shard ID.
 func (s *ShardMapper) CreateMapper(sh meta.ShardInfo, stmt influxql.Statement, chunkSize int) (tsdb.Mapper, error) {
+	var m tsdb.Mapper
+	var nodeID uint64
+
 	// Create a remote mapper if the local node doesn't own the shard.
 	if !sh.OwnedBy(s.MetaStore.NodeID()) || s.ForceRemoteMapping {
-		// Pick a node in a pseudo-random manner.
-		conn, err := s.dial(sh.Owners[rand.Intn(len(sh.Owners))].NodeID)
+		// Try the owners in a pseudo-random order. The mapper fails over to
+		// the remaining owners if its connection fails.
+		nodeIDs := make([]uint64, len(sh.Owners))
+		for i, j := range rand.Perm(len(sh.Owners)) {
+			nodeIDs[i] = sh.Owners[j].NodeID
+		}
+
+		rm, err := s.remoteMapper(sh.ID, nodeIDs, stmt, chunkSize)
 		if err != nil {
 			return nil, err
+		} else if rm == nil {
+			return nil, nil
 		}
-		conn.SetDeadline(time.Now().Add(s.timeout))
-
-		return NewRemoteMapper(conn, sh.ID, stmt, chunkSize), nil
+		m, nodeID = rm, rm.nodeID
+	} else {
+		// If it is local then return the mapper from the store.
+		lm, err := s.TSDBStore.CreateMapper(sh.ID, stmt, chunkSize)
+		if err != nil {
+			return nil, err
+		} else if lm == nil {
+			return nil, nil
+		}
+		m, nodeID = lm, s.MetaStore.NodeID()
 	}
 
-	// If it is local then return the mapper from the store.
-	m, err := s.TSDBStore.CreateMapper(sh.ID, stmt, chunkSize)
-	if err != nil {
-		return nil, err
+	if s.Repairer != nil && rand.Float64() < s.ReadRepairChance {
+		// Compare with the first other owner that can be reached.
+		var nodeIDs []uint64
+		for _, o := range sh.Owners {
+			if o.NodeID != nodeID {
+				nodeIDs = append(nodeIDs, o.NodeID)
+			}
+		}
+		for _, id := range nodeIDs {
+			conn, err := s.dial(id)
+			if err != nil {
+				continue
+			}
+			return newReadRepairMapper(m, NewRemoteMapper(conn, sh.ID, stmt, chunkSize), sh.ID, s.Repairer), nil
+		}
 	}
 
 	return m, nil
 }
 
+// remoteMapper returns a mapper connected to the first node in nodeIDs that
+// can be reached. The mapper fails over to the remaining nodes. Returns the
+// last dial error if no node can be reached.
+func (s *ShardMapper) remoteMapper(shardID uint64, nodeIDs []uint64, stmt influxql.Statement, chunkSize int) (*RemoteMapper, error) {
+	var err error
+	for i, id := range nodeIDs {
+		var conn net.Conn
+		if conn, err = s.dial(id); err != nil {
+			continue
+		}
+
+		m := NewRemoteMapper(conn, shardID, stmt, chunkSize)
+		m.nodeID = id
+		m.nodeIDs = nodeIDs[i+1:]
+		m.dial = s.dial
+		return m, nil
+	}
+	return nil, err
+}
+
 func (s *ShardMapper) dial(nodeID uint64) (net.Conn, error) {
 	ni, err := s.MetaStore.Node(nodeID)
 	if err != nil {
 		return nil, err
+	} else if ni == nil {
+		return nil, meta.ErrNodeNotFound
 	}
-	conn, err := net.Dial("tcp", ni.Host)
+	// Connect and write the cluster multiplexing header byte
+	conn, err := tcp.DialTLS("tcp", ni.Host, MuxHeader, s.TLSConfig)
 	if err != nil {
 		return nil, err
 	}
-
-	// Write the cluster multiplexing header byte
-	conn.Write([]byte{MuxHeader})
-
+	conn.SetDeadline(time.Now().Add(s.timeout))
 	return conn, nil
 }
 
@@ -92,6 +154,29 @@ type RemoteMapper struct {
 	bufferedResponse *MapShardResponse
 
 	unmarshallers []tsdb.UnmarshalFunc // Mapping-specific unmarshal functions.
+
+	limiter *tsdb.SelectLimiter
+
+	// Used to fail over to the other owners of the shard. Optional.
+	nodeID  uint64   // node currently connected to
+	nodeIDs []uint64 // owners not yet tried
+	dial    func(nodeID uint64) (net.Conn, error)
+
+	// Values returned to the caller per series, and the values per series
+	// still to be skipped after failing over to another owner.
+	returned map[string]int
+	skip     map[string]int
+}
+
+// mapShardError is an error returned by the remote node while mapping a shard.
+// These errors are returned to the caller instead of failing over.
+type mapShardError struct {
+	code    int
+	message string
+}
+
+func (e *mapShardError) Error() string {
+	return fmt.Sprintf("error code %d: %s", e.code, e.message)
 }
 
 // NewRemoteMapper returns a new remote mapper using the given connection.
@@ -104,6 +189,10 @@ func NewRemoteMapper(c net.Conn, shardID uint64, stmt influxql.Statement, chunkS
 	}
 }
 
+// SetSelectLimiter sets the limiter whose limits are sent to the remote node.
+// The points and series read by the remote node are added to the limiter.
+func (r *RemoteMapper) SetSelectLimiter(l *tsdb.SelectLimiter) { r.limiter = l }
+
 // Open connects to the remote node and starts receiving data.
 func (r *RemoteMapper) Open() (err error) {
 	defer func() {
@@ -112,11 +201,35 @@ func (r *RemoteMapper) Open() (err error) {
 		}
 	}()
 
+	if err := r.open(); err != nil {
+		if err := r.reconnect(err); err != nil {
+			return err
+		}
+	}
+
+	// Set up each mapping function for this statement.
+	if stmt, ok := r.stmt.(*influxql.SelectStatement); ok {
+		for _, c := range stmt.FunctionCalls() {
+			fn, err := tsdb.InitializeUnmarshaller(c)
+			if err != nil {
+				return err
+			}
+			r.unmarshallers = append(r.unmarshallers, fn)
+		}
+	}
+
+	return nil
+}
+
+// open sends the map request and reads the first response.
+func (r *RemoteMapper) open() error {
 	// Build Map request.
 	var request MapShardRequest
 	request.SetShardID(r.shardID)
 	request.SetQuery(r.stmt.String())
 	request.SetChunkSize(int32(r.chunkSize))
+	request.SetLimits(r.limiter.Limits())
+	request.SetBinaryChunks(true)
 
 	// Marshal into protocol buffers.
 	buf, err := request.MarshalBinary()
@@ -130,37 +243,81 @@ func (r *RemoteMapper) Open() (err error) {
 	}
 
 	// Read the response.
-	_, buf, err = ReadTLV(r.conn)
+	resp, err := r.readResponse()
 	if err != nil {
 		return err
 	}
+	r.bufferedResponse = resp
 
-	// Unmarshal response.
-	r.bufferedResponse = &MapShardResponse{}
-	if err := r.bufferedResponse.UnmarshalBinary(buf); err != nil {
-		return err
+	// Decode the first response to get the TagSets.
+	r.tagsets = resp.TagSets()
+	r.fields = resp.Fields()
+
+	return nil
+}
+
+// reconnect connects to the next owner of the shard after the connection
+// fails with cause and sends the map request again. The values already
+// returned to the caller are skipped when they are read again. Another owner
+// can split its chunks differently, but returns the values of each series in
+// the same order. Returns cause if it was returned by the remote node or if
+// there are no more owners to try.
+func (r *RemoteMapper) reconnect(cause error) error {
+	for {
+		if _, ok := cause.(*mapShardError); ok || r.dial == nil || len(r.nodeIDs) == 0 {
+			return cause
+		}
+
+		// Connect to the next owner.
+		r.conn.Close()
+		id := r.nodeIDs[0]
+		r.nodeIDs = r.nodeIDs[1:]
+		conn, err := r.dial(id)
+		if err != nil {
+			cause = err
+			continue
+		}
+		r.conn, r.nodeID = conn, id
+
+		// Restart the query and skip the values already returned.
+		if err := r.open(); err != nil {
+			cause = err
+			continue
+		}
+		r.skip = make(map[string]int, len(r.returned))
+		for k, n := range r.returned {
+			r.skip[k] = n
+		}
+		return nil
 	}
+}
 
-	if r.bufferedResponse.Code() != 0 {
-		return fmt.Errorf("error code %d: %s", r.bufferedResponse.Code(), r.bufferedResponse.Message())
+// nextResponse returns the buffered response or reads the next response.
+func (r *RemoteMapper) nextResponse() (*MapShardResponse, error) {
+	if r.bufferedResponse != nil {
+		resp := r.bufferedResponse
+		r.bufferedResponse = nil
+		return resp, nil
 	}
+	return r.readResponse()
+}
 
-	// Decode the first response to get the TagSets.
-	r.tagsets = r.bufferedResponse.TagSets()
-	r.fields = r.bufferedResponse.Fields()
+// readResponse reads and unmarshals the next response from the connection.
+func (r *RemoteMapper) readResponse() (*MapShardResponse, error) {
+	_, buf, err := ReadTLV(r.conn)
+	if err != nil {
+		return nil, err
+	}
 
-	// Set up each mapping function for this statement.
-	if stmt, ok := r.stmt.(*influxql.SelectStatement); ok {
-		for _, c := range stmt.FunctionCalls() {
-			fn, err := tsdb.InitializeUnmarshaller(c)
-			if err != nil {
-				return err
-			}
-			r.unmarshallers = append(r.unmarshallers, fn)
-		}
+	resp := &MapShardResponse{}
+	if err := resp.UnmarshalBinary(buf); err != nil {
+		return nil, err
 	}
 
-	return nil
+	if resp.Code() != 0 {
+		return nil, &mapShardError{code: resp.Code(), message: resp.Message()}
+	}
+	return resp, nil
 }
 
 // TagSets returns the TagSets
@@ -174,31 +331,62 @@ func (r *RemoteMapper) Fields() []string {
 }
 
 // NextChunk returns the next chunk read from the remote node to the client.
-func (r *RemoteMapper) NextChunk() (chunk interface{}, err error) {
-	var response *MapShardResponse
-	if r.bufferedResponse != nil {
-		response = r.bufferedResponse
-		r.bufferedResponse = nil
-	} else {
-		response = &MapShardResponse{}
-
-		// Read the response.
-		_, buf, err := ReadTLV(r.conn)
+// If the connection fails, the query continues on another owner of the shard.
+func (r *RemoteMapper) NextChunk() (interface{}, error) {
+	for {
+		response, err := r.nextResponse()
 		if err != nil {
+			if err := r.reconnect(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Count what the remote node read against the limits of the statement.
+		if err := r.limiter.AddSeries(response.SeriesN()); err != nil {
+			return nil, err
+		} else if err := r.limiter.AddPoints(response.PointN()); err != nil {
 			return nil, err
 		}
 
-		// Unmarshal response.
-		if err := response.UnmarshalBinary(buf); err != nil {
+		mo, err := r.decodeResponse(response)
+		if err != nil {
 			return nil, err
+		} else if mo == nil {
+			return nil, nil
 		}
 
-		if response.Code() != 0 {
-			return nil, fmt.Errorf("error code %d: %s", response.Code(), response.Message())
+		// Drop values returned before failing over and count the rest.
+		key := seriesKey(mo)
+		if n := r.skip[key]; n > 0 {
+			if n > len(mo.Values) {
+				n = len(mo.Values)
+			}
+			mo.Values = mo.Values[n:]
+			r.skip[key] -= n
+			if len(mo.Values) == 0 {
+				continue
+			}
+		}
+		if r.returned == nil {
+			r.returned = make(map[string]int)
 		}
+		r.returned[key] += len(mo.Values)
+		return mo, nil
 	}
+}
 
-	if response.Data() == nil {
+// decodeResponse returns the mapper output in a response. Returns nil at the
+// end of the data.
+func (r *RemoteMapper) decodeResponse(response *MapShardResponse) (*tsdb.MapperOutput, error) {
+	// Nodes that support binary chunks return them instead of JSON.
+	if buf := response.Chunk(); buf != nil {
+		mo, err := unmarshalMapperOutput(buf, r.unmarshallers)
+		if err != nil {
+			return nil, err
+		}
+		return mo, nil
+	} else if response.Data() == nil {
 		return nil, nil
 	}
 
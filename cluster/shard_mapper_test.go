@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"reflect"
 	"testing"
 
 	"github.com/influxdb/influxdb/influxql"
@@ -98,6 +99,305 @@ func TestShardWriter_RemoteMapper_Success(t *testing.T) {
 	}
 }
 
+// Ensure a RemoteMapper decodes binary chunks.
+func TestShardWriter_RemoteMapper_BinaryChunks(t *testing.T) {
+	exp := &tsdb.MapperOutput{
+		Name:   "cpu",
+		Tags:   map[string]string{"host": "serverA"},
+		Fields: []string{"value"},
+		Values: []*tsdb.MapperValue{{Time: 10, Value: int64(1 << 62)}},
+	}
+	buf, err := marshalMapperOutput(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newRemoteShardResponder(nil, nil)
+	for _, b := range [][]byte{buf, nil} {
+		resp := &MapShardResponse{}
+		resp.SetCode(0)
+		resp.SetChunk(b)
+		g, _ := resp.MarshalBinary()
+		WriteTLV(c.buffer, mapShardResponseMessage, g)
+	}
+
+	r := NewRemoteMapper(c, 1234, mustParseStmt("SELECT value FROM cpu"), 10)
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if chunk, err := r.NextChunk(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(chunk, exp) {
+		t.Fatalf("unexpected chunk:\nexp=%#v\ngot=%#v", exp, chunk)
+	}
+	if chunk, err := r.NextChunk(); err != nil {
+		t.Fatal(err)
+	} else if chunk != nil {
+		t.Fatalf("unexpected chunk: %v", chunk)
+	}
+}
+
+// Ensure a RemoteMapper counts the points and series read by the remote node
+// against the limiter of the statement.
+func TestShardWriter_RemoteMapper_SelectLimiter(t *testing.T) {
+	buf, err := marshalMapperOutput(&tsdb.MapperOutput{Name: "cpu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newRemoteShardResponder(nil, nil)
+	for _, n := range []int{3, 6} {
+		resp := &MapShardResponse{}
+		resp.SetCode(0)
+		resp.SetPointN(n)
+		resp.SetSeriesN(1)
+		resp.SetChunk(buf)
+		g, _ := resp.MarshalBinary()
+		WriteTLV(c.buffer, mapShardResponseMessage, g)
+	}
+
+	// The limiter is shared with the other mappers of the statement.
+	l := tsdb.NewSelectLimiter(tsdb.QueryLimits{MaxSelectPointN: 10})
+	if err := l.AddPoints(2); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRemoteMapper(c, 1234, mustParseStmt("SELECT value FROM cpu"), 10)
+	r.SetSelectLimiter(l)
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.NextChunk(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.NextChunk(); err == nil || err.Error() != "max-select-point limit exceeded: (11/10)" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pointN, seriesN := l.Counts(); pointN != 11 || seriesN != 2 {
+		t.Fatalf("unexpected counts: %d points, %d series", pointN, seriesN)
+	}
+}
+
+// Ensure mapper outputs keep their value types in binary chunks.
+func TestMapperOutput_Binary(t *testing.T) {
+	stmt := mustParseStmt("SELECT count(value), stddev(value), min(value), top(value, 1) FROM cpu").(*influxql.SelectStatement)
+	var unmarshallers []tsdb.UnmarshalFunc
+	for _, c := range stmt.FunctionCalls() {
+		fn, err := tsdb.InitializeUnmarshaller(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unmarshallers = append(unmarshallers, fn)
+	}
+
+	for i, mo := range []*tsdb.MapperOutput{
+		// Raw values.
+		{
+			Name:      "cpu",
+			Tags:      map[string]string{"host": "serverA", "region": "west"},
+			Fields:    []string{"value", "idle"},
+			CursorKey: "cpu|host|serverA",
+			Values: []*tsdb.MapperValue{
+				{Time: 1, Value: 1.5},
+				{Time: 2, Value: int64(9007199254740993)},
+				{Time: 3, Value: true},
+				{Time: 4, Value: "foo", Tags: map[string]string{"host": "serverB"}},
+				{Time: 5, Value: map[string]interface{}{"value": int64(1), "idle": 2.5}},
+				{Time: 6},
+			},
+		},
+
+		// Partial aggregates.
+		{
+			Name:   "cpu",
+			Fields: []string{"value"},
+			Values: []*tsdb.MapperValue{{
+				Time: 10,
+				Value: []interface{}{
+					float64(3),
+					[]float64{1, 2, 3},
+					nil,
+					tsdb.PositionPoints{{Time: 1, Value: 3.0, Fields: map[string]interface{}{"value": 3.0}}},
+				},
+			}},
+		},
+	} {
+		buf, err := marshalMapperOutput(mo)
+		if err != nil {
+			t.Fatalf("%d. marshal: %s", i, err)
+		}
+
+		other, err := unmarshalMapperOutput(buf, unmarshallers)
+		if err != nil {
+			t.Fatalf("%d. unmarshal: %s", i, err)
+		} else if !reflect.DeepEqual(mo, other) {
+			t.Fatalf("%d. mapper output mismatch:\nexp=%#v\ngot=%#v", i, mo, other)
+		}
+	}
+}
+
+// Ensure a RemoteMapper restarts on another owner when its connection fails
+// before any chunks are returned.
+func TestShardWriter_RemoteMapper_Failover(t *testing.T) {
+	outputs := []*tsdb.MapperOutput{{Name: "cpu"}, {Name: "mem"}, nil}
+
+	// The first connection fails before the first response.
+	r := NewRemoteMapper(newRemoteShardResponder(nil, nil), 1234, mustParseStmt("SELECT * FROM CPU"), 10)
+	r.nodeIDs = []uint64{2, 3}
+	r.dial = func(nodeID uint64) (net.Conn, error) {
+		if nodeID == 2 {
+			return nil, fmt.Errorf("marker")
+		}
+		return newRemoteShardResponder(outputs, nil), nil
+	}
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure every chunk is returned once.
+	for i, exp := range []string{"cpu", "mem"} {
+		chunk, err := r.NextChunk()
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		} else if name := chunk.(*tsdb.MapperOutput).Name; name != exp {
+			t.Fatalf("%d. unexpected chunk: %s", i, name)
+		}
+	}
+	if chunk, err := r.NextChunk(); err != nil {
+		t.Fatal(err)
+	} else if chunk != nil {
+		t.Fatalf("unexpected chunk: %v", chunk)
+	} else if r.nodeID != 3 {
+		t.Fatalf("unexpected node: %d", r.nodeID)
+	}
+
+	// Ensure the error is returned when there are no owners left.
+	r = NewRemoteMapper(newRemoteShardResponder(nil, nil), 1234, mustParseStmt("SELECT * FROM CPU"), 10)
+	r.nodeIDs = []uint64{2}
+	r.dial = func(nodeID uint64) (net.Conn, error) { return nil, fmt.Errorf("marker") }
+	if err := r.Open(); err == nil || err.Error() != "marker" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a RemoteMapper resumes on another owner when its connection fails
+// after chunks were returned, skipping the values already returned.
+func TestShardWriter_RemoteMapper_FailoverAfterChunk(t *testing.T) {
+	value := func(t int64) *tsdb.MapperValue { return &tsdb.MapperValue{Time: t, Value: float64(t)} }
+
+	// The first connection fails after returning two cpu values.
+	first := []*tsdb.MapperOutput{{Name: "cpu", Values: []*tsdb.MapperValue{value(1), value(2)}}}
+	// The next owner splits its chunks differently.
+	second := []*tsdb.MapperOutput{
+		{Name: "cpu", Values: []*tsdb.MapperValue{value(1)}},
+		{Name: "cpu", Values: []*tsdb.MapperValue{value(2), value(3)}},
+		{Name: "mem", Values: []*tsdb.MapperValue{value(1)}},
+		nil,
+	}
+
+	r := NewRemoteMapper(newRemoteShardResponder(first, nil), 1234, mustParseStmt("SELECT * FROM CPU"), 10)
+	r.nodeIDs = []uint64{2}
+	r.dial = func(nodeID uint64) (net.Conn, error) {
+		return newRemoteShardResponder(second, nil), nil
+	}
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure every value is returned once.
+	var got []string
+	for {
+		chunk, err := r.NextChunk()
+		if err != nil {
+			t.Fatal(err)
+		} else if chunk == nil {
+			break
+		}
+		mo := chunk.(*tsdb.MapperOutput)
+		for _, v := range mo.Values {
+			got = append(got, fmt.Sprintf("%s:%d", mo.Name, v.Time))
+		}
+	}
+	if exp := []string{"cpu:1", "cpu:2", "cpu:3", "mem:1"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected values: %v", got)
+	} else if r.nodeID != 2 {
+		t.Fatalf("unexpected node: %d", r.nodeID)
+	}
+}
+
+// Ensure a shard is queued for repair when its owners return different values.
+func TestReadRepairMapper(t *testing.T) {
+	// cpu returns the values of the cpu series split into chunks of size n.
+	cpu := func(n int, values ...float64) []*tsdb.MapperOutput {
+		var outputs []*tsdb.MapperOutput
+		for i, v := range values {
+			if i%n == 0 {
+				outputs = append(outputs, &tsdb.MapperOutput{Name: "cpu", Tags: map[string]string{"host": "a"}})
+			}
+			mo := outputs[len(outputs)-1]
+			mo.Values = append(mo.Values, &tsdb.MapperValue{Time: int64(i + 1), Value: v})
+		}
+		return append(outputs, nil)
+	}
+
+	for i, tt := range []struct {
+		primary   []*tsdb.MapperOutput
+		secondary []*tsdb.MapperOutput
+		repair    bool
+	}{
+		{primary: cpu(2, 1, 2, 3), secondary: cpu(2, 1, 2, 3)},
+		{primary: cpu(1, 1, 2, 3), secondary: cpu(3, 1, 2, 3)},
+		{primary: cpu(3, 1, 2, 3), secondary: cpu(1, 1, 2, 3)},
+		{primary: cpu(2, 1, 2, 3), secondary: cpu(2, 1, 5, 3), repair: true},
+		{primary: cpu(2, 1, 2, 3), secondary: cpu(2, 1, 2), repair: true},
+		{primary: cpu(2, 1, 2), secondary: cpu(2, 1, 2, 3), repair: true},
+		{primary: cpu(2, 1, 2, 3), secondary: []*tsdb.MapperOutput{nil}, repair: true},
+		{
+			primary:   cpu(2, 1, 2, 3),
+			secondary: []*tsdb.MapperOutput{{Name: "mem", Values: []*tsdb.MapperValue{{Time: 1, Value: float64(1)}}}, nil},
+			repair:    true,
+		},
+	} {
+		var repairs []uint64
+		repairer := &shardRepairer{fn: func(id uint64) { repairs = append(repairs, id) }}
+
+		m := newReadRepairMapper(
+			NewRemoteMapper(newRemoteShardResponder(tt.primary, nil), 1234, mustParseStmt("SELECT * FROM CPU"), 10),
+			NewRemoteMapper(newRemoteShardResponder(tt.secondary, nil), 1234, mustParseStmt("SELECT * FROM CPU"), 10),
+			1234, repairer,
+		)
+		if err := m.Open(); err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		// Ensure the primary chunks are returned.
+		for j := range tt.primary {
+			chunk, err := m.NextChunk()
+			if err != nil {
+				t.Fatalf("%d.%d. unexpected error: %s", i, j, err)
+			} else if tt.primary[j] == nil && chunk != nil {
+				t.Fatalf("%d.%d. unexpected chunk: %v", i, j, chunk)
+			} else if tt.primary[j] != nil && len(chunk.(*tsdb.MapperOutput).Values) != len(tt.primary[j].Values) {
+				t.Fatalf("%d.%d. unexpected chunk: %v", i, j, chunk)
+			}
+		}
+		m.Close()
+
+		if tt.repair && (len(repairs) != 1 || repairs[0] != 1234) {
+			t.Fatalf("%d. unexpected repairs: %v", i, repairs)
+		} else if !tt.repair && len(repairs) != 0 {
+			t.Fatalf("%d. unexpected repairs: %v", i, repairs)
+		}
+	}
+}
+
+// shardRepairer is a mock that implements ShardMapper.Repairer.
+type shardRepairer struct {
+	fn func(shardID uint64)
+}
+
+func (r *shardRepairer) QueueRepair(shardID uint64) { r.fn(shardID) }
+
 // mustParseStmt parses a single statement or panics.
 func mustParseStmt(stmt string) influxql.Statement {
 	q, err := influxql.ParseQuery(stmt)
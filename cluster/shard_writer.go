@@ -1,13 +1,19 @@
 package cluster
 
 import (
+	"crypto/tls"
+	"errors"
+	"expvar"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
+	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/models"
-	"gopkg.in/fatih/pool.v2"
+	"github.com/influxdb/influxdb/tcp"
+	"github.com/influxdb/influxdb/tsdb"
 )
 
 const (
@@ -17,38 +23,190 @@ const (
 	mapShardResponseMessage
 )
 
+// The statistics generated by the shard writer.
+const (
+	statWriteShardReq       = "writeShardReq"
+	statWriteShardPointReq  = "writeShardPointReq"
+	statWriteShardCoalesced = "writeShardCoalesced"
+	statWriteShardQueueFull = "writeShardQueueFull"
+	statWriteShardResend    = "writeShardResend"
+)
+
+var (
+	// ErrWriteQueueFull is returned when too many points are waiting to be
+	// sent to a shard on a node. The error is retryable so the points
+	// writer queues the write for hinted handoff.
+	ErrWriteQueueFull = errors.New("write queue full")
+
+	// ErrShardWriterClosed is returned when writing to a closed shard writer.
+	ErrShardWriterClosed = errors.New("shard writer closed")
+)
+
 // ShardWriter writes a set of points to a shard.
+//
+// Writes for the same shard and node are queued and combined into a single
+// request while a previous request is in flight or for up to MaxBatchDelay.
+// Requests to a node are pipelined over one connection. Each call to
+// WriteShard still returns the result of its own points.
 type ShardWriter struct {
-	pool    *clientPool
-	timeout time.Duration
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	closing  chan struct{}
+	batchers map[shardOwner]*shardBatcher
+	nodes    map[uint64]*nodeConn
+	timeout  time.Duration
+
+	// Maximum number of points combined into one request. A single write
+	// larger than this is sent on its own.
+	MaxBatchSize int
+
+	// Time to wait for more writes before sending a request. If zero, only
+	// writes queued while a request is in flight are combined.
+	MaxBatchDelay time.Duration
+
+	// Maximum number of points queued for a shard on a node. Writes beyond
+	// this fail with ErrWriteQueueFull.
+	MaxPendingPoints int
+
+	// Maximum number of requests sent to a node before their responses
+	// are read.
+	MaxPipelinedRequests int
 
 	MetaStore interface {
 		Node(id uint64) (ni *meta.NodeInfo, err error)
 	}
+
+	// If set, connections to other nodes use TLS.
+	TLSConfig *tls.Config
+
+	statMap *expvar.Map
 }
 
 // NewShardWriter returns a new instance of ShardWriter.
 func NewShardWriter(timeout time.Duration) *ShardWriter {
 	return &ShardWriter{
-		pool:    newClientPool(),
-		timeout: timeout,
+		closing:              make(chan struct{}),
+		batchers:             make(map[shardOwner]*shardBatcher),
+		nodes:                make(map[uint64]*nodeConn),
+		timeout:              timeout,
+		MaxBatchSize:         DefaultWriteBatchSize,
+		MaxBatchDelay:        DefaultWriteBatchDelay,
+		MaxPendingPoints:     DefaultMaxPendingWritePoints,
+		MaxPipelinedRequests: DefaultMaxPipelinedWrites,
+		statMap:              influxdb.NewStatistics("shardWriter", "shardWriter", nil),
 	}
 }
 
 // WriteShard writes time series points to a shard
 func (w *ShardWriter) WriteShard(shardID, ownerID uint64, points []models.Point) error {
-	c, err := w.dial(ownerID)
-	if err != nil {
-		return err
+	pw := &pendingWrite{points: points, err: make(chan error, 1)}
+
+	w.mu.Lock()
+	if w.batchers == nil {
+		w.mu.Unlock()
+		return ErrShardWriterClosed
+	}
+
+	// Start sending if nothing is queued for the shard on this node.
+	key := shardOwner{shardID: shardID, nodeID: ownerID}
+	b := w.batchers[key]
+	if b == nil {
+		b = &shardBatcher{shardOwner: key}
+		w.batchers[key] = b
+		w.wg.Add(1)
+		go w.processBatches(b)
+	}
+
+	// Apply backpressure once too many points are waiting. A single write
+	// is always accepted into an empty queue.
+	if b.n > 0 && b.n+len(points) > w.MaxPendingPoints {
+		w.mu.Unlock()
+		w.statMap.Add(statWriteShardQueueFull, 1)
+		return ErrWriteQueueFull
+	}
+	b.writes = append(b.writes, pw)
+	b.n += len(points)
+	w.mu.Unlock()
+
+	return <-pw.err
+}
+
+// processBatches sends the writes queued on b until the queue is empty.
+func (w *ShardWriter) processBatches(b *shardBatcher) {
+	defer w.wg.Done()
+
+	// Give other writes a chance to join the first request.
+	if w.MaxBatchDelay > 0 {
+		t := time.NewTimer(w.MaxBatchDelay)
+		select {
+		case <-t.C:
+		case <-w.closing:
+			t.Stop()
+		}
+	}
+
+	for {
+		w.mu.Lock()
+		writes := b.next(w.MaxBatchSize)
+		if len(writes) == 0 {
+			if w.batchers != nil {
+				delete(w.batchers, b.shardOwner)
+			}
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		w.writeBatch(b.shardID, b.nodeID, writes)
+	}
+}
+
+// writeBatch sends writes as one request and returns the result to each write.
+func (w *ShardWriter) writeBatch(shardID, nodeID uint64, writes []*pendingWrite) {
+	var points []models.Point
+	if len(writes) == 1 {
+		points = writes[0].points
+	} else {
+		for _, pw := range writes {
+			points = append(points, pw.points...)
+		}
+		w.statMap.Add(statWriteShardCoalesced, int64(len(writes)-1))
+	}
+
+	err := w.send(shardID, nodeID, points)
+
+	// Return the points rejected individually to the writes they came from.
+	if perr, ok := err.(*influxdb.PartialWriteError); ok && len(writes) > 1 {
+		var offset int
+		for _, pw := range writes {
+			pw.err <- perr.Slice(offset, offset+len(pw.points))
+			offset += len(pw.points)
+		}
+		return
+	}
+
+	// Other errors from the remote node fail the whole request so resend
+	// each write on its own. This way a write isn't failed by the points
+	// of another write. Points already written are simply overwritten.
+	if err != nil && !tsdb.IsRetryable(err) && len(writes) > 1 {
+		w.statMap.Add(statWriteShardResend, int64(len(writes)))
+		for _, pw := range writes {
+			pw.err <- w.send(shardID, nodeID, pw.points)
+		}
+		return
+	}
+
+	for _, pw := range writes {
+		pw.err <- err
 	}
+}
 
-	conn, ok := c.(*pool.PoolConn)
-	if !ok {
-		panic("wrong connection type")
+// send writes points to a shard on a node.
+func (w *ShardWriter) send(shardID, nodeID uint64, points []models.Point) error {
+	n, err := w.node(nodeID)
+	if err != nil {
+		return err
 	}
-	defer func(conn net.Conn) {
-		conn.Close() // return to pool
-	}(conn)
 
 	// Build write request.
 	var request WriteShardRequest
@@ -61,58 +219,261 @@ func (w *ShardWriter) WriteShard(shardID, ownerID uint64, points []models.Point)
 		return err
 	}
 
-	// Write request.
-	conn.SetWriteDeadline(time.Now().Add(w.timeout))
-	if err := WriteTLV(conn, writeShardRequestMessage, buf); err != nil {
-		conn.MarkUnusable()
-		return err
+	w.statMap.Add(statWriteShardReq, 1)
+	w.statMap.Add(statWriteShardPointReq, int64(len(points)))
+	return n.write(buf)
+}
+
+// node returns the connection to a node.
+func (w *ShardWriter) node(nodeID uint64) (*nodeConn, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.nodes == nil {
+		return nil, ErrShardWriterClosed
 	}
 
-	// Read the response.
-	conn.SetReadDeadline(time.Now().Add(w.timeout))
-	_, buf, err = ReadTLV(conn)
+	n := w.nodes[nodeID]
+	if n == nil {
+		factory := &connFactory{nodeID: nodeID, clientPool: w, timeout: w.timeout, tlsConfig: w.TLSConfig}
+		factory.metaStore = w.MetaStore
+
+		// At least one request must be allowed in flight.
+		depth := w.MaxPipelinedRequests
+		if depth < 1 {
+			depth = 1
+		}
+
+		n = &nodeConn{
+			dial:    factory.dial,
+			timeout: w.timeout,
+			slots:   make(chan struct{}, depth),
+		}
+		w.nodes[nodeID] = n
+	}
+	return n, nil
+}
+
+// size returns the number of nodes with a connection.
+func (w *ShardWriter) size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.nodes)
+}
+
+// Close closes the connections of the shard writer. Queued writes fail.
+func (w *ShardWriter) Close() error {
+	w.mu.Lock()
+	if w.nodes == nil {
+		w.mu.Unlock()
+		return fmt.Errorf("client already closed")
+	}
+	close(w.closing)
+	for _, n := range w.nodes {
+		n.close()
+	}
+	w.batchers, w.nodes = nil, nil
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return nil
+}
+
+// shardOwner identifies a shard on a node.
+type shardOwner struct {
+	shardID uint64
+	nodeID  uint64
+}
+
+// shardBatcher queues the writes for a shard on a node. It is protected by
+// the mutex of the shard writer.
+type shardBatcher struct {
+	shardOwner
+	writes []*pendingWrite
+	n      int // number of points queued
+}
+
+// next removes writes from the queue up to max points. At least one write
+// is returned if any are queued.
+func (b *shardBatcher) next(max int) []*pendingWrite {
+	var i, n int
+	for ; i < len(b.writes); i++ {
+		if i > 0 && n+len(b.writes[i].points) > max {
+			break
+		}
+		n += len(b.writes[i].points)
+	}
+
+	writes := b.writes[:i:i]
+	b.writes = b.writes[i:]
+	b.n -= n
+	return writes
+}
+
+// pendingWrite is a write waiting to be sent.
+type pendingWrite struct {
+	points []models.Point
+	err    chan error
+}
+
+// nodeConn sends write requests to a node over a single connection. Requests
+// are written without waiting for the previous response. The cluster service
+// responds to requests in order so responses are matched by position.
+type nodeConn struct {
+	dial    func() (net.Conn, error)
+	timeout time.Duration
+	slots   chan struct{} // limits requests waiting for a response
+
+	mu     sync.Mutex
+	conn   *pipelinedConn
+	closed bool
+}
+
+// write sends a request and waits for its response.
+func (n *nodeConn) write(buf []byte) error {
+	n.slots <- struct{}{}
+
+	n.mu.Lock()
+	c, err := n.connect()
 	if err != nil {
-		conn.MarkUnusable()
+		n.mu.Unlock()
+		<-n.slots
 		return err
 	}
 
-	// Unmarshal response.
-	var response WriteShardResponse
-	if err := response.UnmarshalBinary(buf); err != nil {
+	// Write request.
+	c.SetWriteDeadline(time.Now().Add(n.timeout))
+	if err := WriteTLV(c, writeShardRequestMessage, buf); err != nil {
+		n.closeConn(c)
+		n.mu.Unlock()
+		<-n.slots
 		return err
 	}
 
-	if response.Code() != 0 {
-		return fmt.Errorf("error code %d: %s", response.Code(), response.Message())
+	// The pending queue holds as many requests as there are slots so this
+	// never blocks.
+	errc := make(chan error, 1)
+	c.pending <- errc
+	n.mu.Unlock()
+
+	return <-errc
+}
+
+// connect returns the current connection or dials a new one. Must be called
+// with the lock held.
+func (n *nodeConn) connect() (*pipelinedConn, error) {
+	if n.closed {
+		return nil, ErrShardWriterClosed
+	} else if n.conn != nil {
+		return n.conn, nil
 	}
 
-	return nil
+	conn, err := n.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	n.conn = &pipelinedConn{
+		Conn:    conn,
+		pending: make(chan chan error, cap(n.slots)),
+		closing: make(chan struct{}),
+	}
+	go n.readResponses(n.conn)
+	return n.conn, nil
 }
 
-func (w *ShardWriter) dial(nodeID uint64) (net.Conn, error) {
-	// If we don't have a connection pool for that addr yet, create one
-	_, ok := w.pool.getPool(nodeID)
-	if !ok {
-		factory := &connFactory{nodeID: nodeID, clientPool: w.pool, timeout: w.timeout}
-		factory.metaStore = w.MetaStore
+// readResponses reads the responses on c and returns them to the waiting
+// requests in order.
+func (n *nodeConn) readResponses(c *pipelinedConn) {
+	for {
+		select {
+		case errc := <-c.pending:
+			resp, err := readWriteShardResponse(c, n.timeout)
+			if err != nil {
+				// The connection is no longer usable. Fail the request and
+				// every request written after it.
+				n.mu.Lock()
+				n.closeConn(c)
+				n.mu.Unlock()
 
-		p, err := pool.NewChannelPool(1, 3, factory.dial)
-		if err != nil {
-			return nil, err
+				errc <- err
+				<-n.slots
+				n.fail(c, err)
+				return
+			}
+			if errs := resp.PointErrors(); resp.Code() != 0 && errs != nil {
+				errc <- &influxdb.PartialWriteError{Errors: errs}
+			} else if resp.Code() != 0 {
+				errc <- fmt.Errorf("error code %d: %s", resp.Code(), resp.Message())
+			} else {
+				errc <- nil
+			}
+			<-n.slots
+		case <-c.closing:
+			n.fail(c, errors.New("connection closed"))
+			return
 		}
-		w.pool.setPool(nodeID, p)
 	}
-	return w.pool.conn(nodeID)
 }
 
-// Close closes ShardWriter's pool
-func (w *ShardWriter) Close() error {
-	if w.pool == nil {
-		return fmt.Errorf("client already closed")
+// fail returns err to all requests waiting on the closed connection c.
+func (n *nodeConn) fail(c *pipelinedConn, err error) {
+	for {
+		select {
+		case errc := <-c.pending:
+			errc <- err
+			<-n.slots
+		default:
+			return
+		}
 	}
-	w.pool.close()
-	w.pool = nil
-	return nil
+}
+
+// closeConn closes c and removes it as the current connection. Must be
+// called with the lock held.
+func (n *nodeConn) closeConn(c *pipelinedConn) {
+	if n.conn == c {
+		n.conn = nil
+	}
+	c.once.Do(func() {
+		close(c.closing)
+		c.Close()
+	})
+}
+
+// close closes the current connection and prevents new ones.
+func (n *nodeConn) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closed = true
+	if n.conn != nil {
+		n.closeConn(n.conn)
+	}
+}
+
+// pipelinedConn is a connection with the requests waiting for a response.
+type pipelinedConn struct {
+	net.Conn
+	pending chan chan error
+	closing chan struct{}
+	once    sync.Once
+}
+
+// readWriteShardResponse reads a write response from conn.
+func readWriteShardResponse(conn net.Conn, timeout time.Duration) (*WriteShardResponse, error) {
+	// Read the response.
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	_, buf, err := ReadTLV(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal response.
+	var response WriteShardResponse
+	if err := response.UnmarshalBinary(buf); err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
 const (
@@ -123,8 +484,9 @@ const (
 var errMaxConnectionsExceeded = fmt.Errorf("can not exceed max connections of %d", maxConnections)
 
 type connFactory struct {
-	nodeID  uint64
-	timeout time.Duration
+	nodeID    uint64
+	timeout   time.Duration
+	tlsConfig *tls.Config
 
 	clientPool interface {
 		size() int
@@ -149,7 +511,7 @@ func (c *connFactory) dial() (net.Conn, error) {
 		return nil, fmt.Errorf("node %d does not exist", c.nodeID)
 	}
 
-	conn, err := net.DialTimeout("tcp", ni.Host, c.timeout)
+	conn, err := tcp.DialConn("tcp", ni.Host, c.timeout, c.tlsConfig)
 	if err != nil {
 		return nil, err
 	}
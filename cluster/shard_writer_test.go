@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/cluster"
 	"github.com/influxdb/influxdb/models"
 )
@@ -184,3 +185,118 @@ func TestShardWriter_Write_ErrReadTimeout(t *testing.T) {
 		t.Fatalf("unexpected error: %s", err)
 	}
 }
+
+// Ensure the shard writer combines concurrent writes to a shard into batches.
+func TestShardWriter_WriteShard_Coalesce(t *testing.T) {
+	requests := make(chan int, 10)
+	ts := newTestWriteService(func(shardID uint64, points []models.Point) error {
+		requests <- len(points)
+		return nil
+	})
+	s := cluster.NewService(cluster.Config{})
+	s.Listener = ts.muxln
+	s.TSDBStore = ts
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	defer ts.Close()
+
+	w := cluster.NewShardWriter(time.Minute)
+	w.MetaStore = &metaStore{host: ts.ln.Addr().String()}
+	w.MaxBatchSize = 4
+	w.MaxBatchDelay = 100 * time.Millisecond
+	defer w.Close()
+
+	// Write 10 single points concurrently.
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			points := []models.Point{models.MustNewPoint("cpu", models.Tags{"host": "server01"}, map[string]interface{}{"value": int64(i)}, time.Unix(int64(i), 0))}
+			errs <- w.WriteShard(1, 2, points)
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Validate the writes were sent as three batches.
+	if len(requests) != 3 {
+		t.Fatalf("unexpected request count: %d", len(requests))
+	}
+	for i, exp := range []int{4, 4, 2} {
+		if n := <-requests; n != exp {
+			t.Fatalf("unexpected point count in request %d: %d", i, n)
+		}
+	}
+}
+
+// Ensure the shard writer returns the result of each write when a combined
+// request is rejected.
+func TestShardWriter_WriteShard_CoalesceRejected(t *testing.T) {
+	ts := newTestWriteService(func(shardID uint64, points []models.Point) error {
+		for _, p := range points {
+			if p.Name() == "mem" {
+				return influxdb.ErrFieldTypeConflict
+			}
+		}
+		return nil
+	})
+	s := cluster.NewService(cluster.Config{})
+	s.Listener = ts.muxln
+	s.TSDBStore = ts
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	defer ts.Close()
+
+	w := cluster.NewShardWriter(time.Minute)
+	w.MetaStore = &metaStore{host: ts.ln.Addr().String()}
+	w.MaxBatchDelay = 100 * time.Millisecond
+	defer w.Close()
+
+	cpu := make(chan error, 1)
+	mem := make(chan error, 1)
+	go func() {
+		cpu <- w.WriteShard(1, 2, []models.Point{models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))})
+	}()
+	go func() {
+		mem <- w.WriteShard(1, 2, []models.Point{models.MustNewPoint("mem", nil, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))})
+	}()
+
+	if err := <-cpu; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := <-mem; err == nil || !strings.Contains(err.Error(), influxdb.ErrFieldTypeConflict.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the shard writer rejects writes when too many points are queued.
+func TestShardWriter_WriteShard_ErrWriteQueueFull(t *testing.T) {
+	w := cluster.NewShardWriter(time.Minute)
+	w.MetaStore = &metaStore{}
+	w.MaxBatchDelay = time.Hour
+	w.MaxPendingPoints = 1
+
+	points := []models.Point{models.MustNewPoint("cpu", nil, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))}
+
+	// The first write waits in the queue and the second write is rejected.
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { errs <- w.WriteShard(1, 2, points) }()
+	}
+	if err := <-errs; err != cluster.ErrWriteQueueFull {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Closing the writer fails the queued write.
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	} else if err := <-errs; err != cluster.ErrShardWriterClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
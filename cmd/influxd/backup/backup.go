@@ -1,17 +1,19 @@
 package backup
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"os"
 
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
 	"github.com/influxdb/influxdb/services/snapshotter"
 	"github.com/influxdb/influxdb/snapshot"
+	"github.com/influxdb/influxdb/tcp"
 )
 
 // Suffix is a suffix added to the backup while it's in-process.
@@ -40,7 +42,7 @@ func (cmd *Command) Run(args ...string) error {
 	cmd.Logger.Printf("influxdb backup")
 
 	// Parse command line arguments.
-	host, path, err := cmd.parseFlags(args)
+	host, path, config, err := cmd.parseFlags(args)
 	if err != nil {
 		return err
 	}
@@ -63,7 +65,7 @@ func (cmd *Command) Run(args ...string) error {
 	}
 
 	// Retrieve snapshot.
-	if err := cmd.download(host, m, tmppath); err != nil {
+	if err := cmd.download(host, config, m, tmppath); err != nil {
 		return fmt.Errorf("download: %s", err)
 	}
 
@@ -80,25 +82,39 @@ func (cmd *Command) Run(args ...string) error {
 	return nil
 }
 
-// parseFlags parses and validates the command line arguments.
-func (cmd *Command) parseFlags(args []string) (host string, path string, err error) {
+// parseFlags parses and validates the command line arguments. The returned
+// TLS config is nil unless a certificate is given.
+func (cmd *Command) parseFlags(args []string) (host string, path string, config *tls.Config, err error) {
+	var certFile, caFile string
 	fs := flag.NewFlagSet("", flag.ContinueOnError)
 	fs.StringVar(&host, "host", "localhost:8088", "")
+	fs.StringVar(&certFile, "tls-certificate", "", "")
+	fs.StringVar(&caFile, "tls-ca", "", "")
 	fs.SetOutput(cmd.Stderr)
 	fs.Usage = cmd.printUsage
 	if err := fs.Parse(args); err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
 	// Ensure that only one arg is specified.
 	if fs.NArg() == 0 {
-		return "", "", errors.New("snapshot path required")
+		return "", "", nil, errors.New("snapshot path required")
 	} else if fs.NArg() != 1 {
-		return "", "", errors.New("only one snapshot path allowed")
+		return "", "", nil, errors.New("only one snapshot path allowed")
 	}
 	path = fs.Arg(0)
 
-	return host, path, nil
+	// Connect the same way as the cluster nodes when they use TLS.
+	if certFile != "" {
+		config, err = tlsconfig.NewCluster(certFile, caFile)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("tls: %s", err)
+		}
+	} else if caFile != "" {
+		return "", "", nil, errors.New("tls-ca requires tls-certificate")
+	}
+
+	return host, path, config, nil
 }
 
 // nextPath returns the next file to write to.
@@ -121,8 +137,9 @@ func (cmd *Command) nextPath(path string) (string, error) {
 	}
 }
 
-// download downloads a snapshot from a host to a given path.
-func (cmd *Command) download(host string, m *snapshot.Manifest, path string) error {
+// download downloads a snapshot from a host to a given path. The connection
+// uses TLS if config is not nil.
+func (cmd *Command) download(host string, config *tls.Config, m *snapshot.Manifest, path string) error {
 	// Create local file to write to.
 	f, err := os.Create(path)
 	if err != nil {
@@ -131,7 +148,7 @@ func (cmd *Command) download(host string, m *snapshot.Manifest, path string) err
 	defer f.Close()
 
 	// Connect to snapshotter service.
-	conn, err := net.Dial("tcp", host)
+	conn, err := tcp.DialConn("tcp", host, 0, config)
 	if err != nil {
 		return err
 	}
@@ -166,5 +183,13 @@ backup downloads a snapshot of a data node and saves it to disk.
         -host <host:port>
                           The host to connect to snapshot.
                           Defaults to 127.0.0.1:8088.
+
+        -tls-certificate <path>
+                          PEM file with the certificate and key presented to
+                          the host when the cluster uses TLS.
+
+        -tls-ca <path>
+                          PEM file with the CA certificates used to verify
+                          the host. Required with -tls-certificate.
 `)
 }
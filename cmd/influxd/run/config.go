@@ -15,6 +15,8 @@ import (
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/monitor"
 	"github.com/influxdb/influxdb/services/admin"
+	"github.com/influxdb/influxdb/services/antientropy"
+	"github.com/influxdb/influxdb/services/balancer"
 	"github.com/influxdb/influxdb/services/collectd"
 	"github.com/influxdb/influxdb/services/continuous_querier"
 	"github.com/influxdb/influxdb/services/graphite"
@@ -50,7 +52,9 @@ type Config struct {
 	// Snapshot SnapshotConfig `toml:"snapshot"`
 	ContinuousQuery continuous_querier.Config `toml:"continuous_queries"`
 
-	HintedHandoff hh.Config `toml:"hinted-handoff"`
+	HintedHandoff hh.Config          `toml:"hinted-handoff"`
+	AntiEntropy   antientropy.Config `toml:"anti-entropy"`
+	Balancer      balancer.Config    `toml:"balancer"`
 
 	// Server reporting
 	ReportingDisabled bool `toml:"reporting-disabled"`
@@ -75,6 +79,8 @@ func NewConfig() *Config {
 	c.ContinuousQuery = continuous_querier.NewConfig()
 	c.Retention = retention.NewConfig()
 	c.HintedHandoff = hh.NewConfig()
+	c.AntiEntropy = antientropy.NewConfig()
+	c.Balancer = balancer.NewConfig()
 
 	return c
 }
@@ -117,6 +123,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.HTTPD.Validate(); err != nil {
+		return fmt.Errorf("invalid http config: %v", err)
+	}
+
 	for _, g := range c.Graphites {
 		if err := g.Validate(); err != nil {
 			return fmt.Errorf("invalid graphite config: %v", err)
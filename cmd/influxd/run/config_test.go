@@ -3,6 +3,7 @@ package run_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/influxdb/influxdb/cmd/influxd/run"
@@ -18,8 +19,12 @@ dir = "/tmp/meta"
 
 [data]
 dir = "/tmp/data"
+query-timeout = "10s"
+max-select-point = 1000
 
 [cluster]
+tls-enabled = true
+tls-ca = "/etc/ssl/ca.pem"
 
 [admin]
 bind-address = ":8083"
@@ -50,6 +55,13 @@ enabled = true
 
 [continuous_queries]
 enabled = true
+
+[anti-entropy]
+enabled = true
+
+[balancer]
+enabled = true
+copy-rate-limit = 1024
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -59,6 +71,16 @@ enabled = true
 		t.Fatalf("unexpected meta dir: %s", c.Meta.Dir)
 	} else if c.Data.Dir != "/tmp/data" {
 		t.Fatalf("unexpected data dir: %s", c.Data.Dir)
+	} else if !c.AntiEntropy.Enabled {
+		t.Fatalf("unexpected anti-entropy enabled: %v", c.AntiEntropy.Enabled)
+	} else if !c.Balancer.Enabled || c.Balancer.CopyRateLimit != 1024 {
+		t.Fatalf("unexpected balancer: %v, %d", c.Balancer.Enabled, c.Balancer.CopyRateLimit)
+	} else if time.Duration(c.Data.QueryTimeout) != 10*time.Second {
+		t.Fatalf("unexpected query timeout: %s", c.Data.QueryTimeout)
+	} else if c.Data.MaxSelectPointN != 1000 {
+		t.Fatalf("unexpected max select point: %d", c.Data.MaxSelectPointN)
+	} else if !c.Cluster.TLSEnabled || c.Cluster.TLSCA != "/etc/ssl/ca.pem" {
+		t.Fatalf("unexpected cluster tls: %v, %s", c.Cluster.TLSEnabled, c.Cluster.TLSCA)
 	} else if c.Admin.BindAddress != ":8083" {
 		t.Fatalf("unexpected admin bind address: %s", c.Admin.BindAddress)
 	} else if c.HTTPD.BindAddress != ":8087" {
@@ -1,6 +1,7 @@
 package run
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -14,7 +15,10 @@ import (
 	"github.com/influxdb/influxdb/cluster"
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/monitor"
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
 	"github.com/influxdb/influxdb/services/admin"
+	"github.com/influxdb/influxdb/services/antientropy"
+	"github.com/influxdb/influxdb/services/balancer"
 	"github.com/influxdb/influxdb/services/collectd"
 	"github.com/influxdb/influxdb/services/continuous_querier"
 	"github.com/influxdb/influxdb/services/copier"
@@ -70,9 +74,14 @@ type Server struct {
 	ClusterService     *cluster.Service
 	SnapshotterService *snapshotter.Service
 	CopierService      *copier.Service
+	AntiEntropyService *antientropy.Service
+	BalancerService    *balancer.Service
 
 	Monitor *monitor.Monitor
 
+	// TLS configuration for connections between nodes.
+	tlsConfig *tls.Config
+
 	// Server reporting and registration
 	reportingDisabled bool
 
@@ -109,11 +118,23 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	s.TSDBStore.EngineOptions.WALFlushInterval = time.Duration(c.Data.WALFlushInterval)
 	s.TSDBStore.EngineOptions.WALPartitionFlushDelay = time.Duration(c.Data.WALPartitionFlushDelay)
 
+	// Load the certificates used between nodes.
+	if c.Cluster.TLSEnabled {
+		tlsConfig, err := tlsconfig.NewCluster(c.Cluster.TLSCertificate, c.Cluster.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("cluster tls: %s", err)
+		}
+		s.tlsConfig = tlsConfig
+		s.MetaStore.TLSConfig = tlsConfig
+	}
+
 	// Set the shard mapper
 	s.ShardMapper = cluster.NewShardMapper(time.Duration(c.Cluster.ShardMapperTimeout))
 	s.ShardMapper.ForceRemoteMapping = c.Cluster.ForceRemoteShardMapping
 	s.ShardMapper.MetaStore = s.MetaStore
 	s.ShardMapper.TSDBStore = s.TSDBStore
+	s.ShardMapper.TLSConfig = s.tlsConfig
+	s.ShardMapper.ReadRepairChance = c.Cluster.ReadRepairChance
 
 	// Initialize query executor.
 	s.QueryExecutor = tsdb.NewQueryExecutor(s.TSDBStore)
@@ -122,15 +143,31 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	s.QueryExecutor.MonitorStatementExecutor = &monitor.StatementExecutor{Monitor: s.Monitor}
 	s.QueryExecutor.ShardMapper = s.ShardMapper
 	s.QueryExecutor.QueryLogEnabled = c.Data.QueryLogEnabled
+	s.QueryExecutor.Limits = tsdb.QueryLimits{
+		Timeout:           time.Duration(c.Data.QueryTimeout),
+		MaxSelectPointN:   c.Data.MaxSelectPointN,
+		MaxSelectSeriesN:  c.Data.MaxSelectSeriesN,
+		MaxSelectBucketsN: c.Data.MaxSelectBuckets,
+	}
 
 	// Set the shard writer
 	s.ShardWriter = cluster.NewShardWriter(time.Duration(c.Cluster.ShardWriterTimeout))
 	s.ShardWriter.MetaStore = s.MetaStore
+	s.ShardWriter.TLSConfig = s.tlsConfig
+	s.ShardWriter.MaxBatchSize = c.Cluster.WriteBatchSize
+	s.ShardWriter.MaxBatchDelay = time.Duration(c.Cluster.WriteBatchDelay)
+	s.ShardWriter.MaxPendingPoints = c.Cluster.MaxPendingWritePoints
+	s.ShardWriter.MaxPipelinedRequests = c.Cluster.MaxPipelinedWrites
 
 	// Create the hinted handoff service
 	s.HintedHandoff = hh.NewService(c.HintedHandoff, s.ShardWriter, s.MetaStore)
 	s.HintedHandoff.Monitor = s.Monitor
 
+	// Manage queues with SHOW, PURGE and REPLAY HINTED HANDOFF.
+	if e, ok := s.QueryExecutor.MetaStatementExecutor.(*meta.StatementExecutor); ok {
+		e.HintedHandoff = s.HintedHandoff
+	}
+
 	// Create the Subscriber service
 	s.Subscriber = subscriber.NewService(c.Subscriber)
 	s.Subscriber.MetaStore = s.MetaStore
@@ -161,6 +198,8 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	s.appendRegistrationService(c.Registration)
 	s.appendSnapshotterService()
 	s.appendCopierService()
+	s.appendAntiEntropyService(c.AntiEntropy)
+	s.appendBalancerService(c.Balancer)
 	s.appendAdminService(c.Admin)
 	s.appendContinuousQueryService(c.ContinuousQuery)
 	s.appendHTTPDService(c.HTTPD)
@@ -199,11 +238,54 @@ func (s *Server) appendSnapshotterService() {
 
 func (s *Server) appendCopierService() {
 	srv := copier.NewService()
+	srv.MetaStore = s.MetaStore
 	srv.TSDBStore = s.TSDBStore
+	srv.TLSConfig = s.tlsConfig
+
+	// Rebalance shards with COPY SHARD and MOVE SHARD.
+	if e, ok := s.QueryExecutor.MetaStatementExecutor.(*meta.StatementExecutor); ok {
+		e.ShardCopier = srv
+	}
+
 	s.Services = append(s.Services, srv)
 	s.CopierService = srv
 }
 
+func (s *Server) appendAntiEntropyService(c antientropy.Config) {
+	srv := antientropy.NewService(c)
+	srv.MetaStore = s.MetaStore
+	srv.TSDBStore = s.TSDBStore
+	srv.ShardWriter = s.ShardWriter
+	srv.TLSConfig = s.tlsConfig
+
+	// Compare and repair shards with SHOW SHARD DIFFERENCES and REPAIR SHARD.
+	if e, ok := s.QueryExecutor.MetaStatementExecutor.(*meta.StatementExecutor); ok {
+		e.AntiEntropy = srv
+	}
+
+	// Repair shards whose owners returned different results to a query.
+	s.ShardMapper.Repairer = srv
+
+	// Verify shard copies before they are used.
+	s.CopierService.ShardComparer = srv
+
+	s.Services = append(s.Services, srv)
+	s.AntiEntropyService = srv
+}
+
+func (s *Server) appendBalancerService(c balancer.Config) {
+	srv := balancer.NewService(c)
+	srv.MetaStore = s.MetaStore
+	srv.ShardCopier = s.CopierService
+
+	// Limit the rate that shards are streamed to other nodes. This applies to
+	// all copies, not just the balancer's, and even when it is disabled.
+	s.CopierService.RateLimit = c.CopyRateLimit
+
+	s.Services = append(s.Services, srv)
+	s.BalancerService = srv
+}
+
 func (s *Server) appendRetentionPolicyService(c retention.Config) {
 	if !c.Enabled {
 		return
@@ -230,6 +312,7 @@ func (s *Server) appendHTTPDService(c httpd.Config) {
 	srv.Handler.MetaStore = s.MetaStore
 	srv.Handler.QueryExecutor = s.QueryExecutor
 	srv.Handler.PointsWriter = s.PointsWriter
+	srv.Handler.Monitor = s.Monitor
 	srv.Handler.Version = s.buildInfo.Version
 
 	// If a ContinuousQuerier service has been started, attach it.
@@ -328,6 +411,12 @@ func (s *Server) appendContinuousQueryService(c continuous_querier.Config) {
 	srv := continuous_querier.NewService(c)
 	srv.MetaStore = s.MetaStore
 	srv.QueryExecutor = s.QueryExecutor
+
+	// Report the run status of CQs in SHOW CONTINUOUS QUERIES.
+	if e, ok := s.QueryExecutor.MetaStatementExecutor.(*meta.StatementExecutor); ok {
+		e.ContinuousQuerier = srv
+	}
+
 	s.Services = append(s.Services, srv)
 }
 
@@ -368,6 +457,7 @@ func (s *Server) Open() error {
 
 		// Multiplex listener.
 		mux := tcp.NewMux()
+		mux.TLSConfig = s.tlsConfig
 		s.MetaStore.RaftListener = mux.Listen(meta.MuxRaftHeader)
 		s.MetaStore.ExecListener = mux.Listen(meta.MuxExecHeader)
 		s.MetaStore.RPCListener = mux.Listen(meta.MuxRPCHeader)
@@ -375,6 +465,7 @@ func (s *Server) Open() error {
 		s.ClusterService.Listener = mux.Listen(cluster.MuxHeader)
 		s.SnapshotterService.Listener = mux.Listen(snapshotter.MuxHeader)
 		s.CopierService.Listener = mux.Listen(copier.MuxHeader)
+		s.AntiEntropyService.Listener = mux.Listen(antientropy.MuxHeader)
 		go mux.Serve(ln)
 
 		// Open meta store.
@@ -36,6 +36,9 @@ func IsClientError(err error) bool {
 	if err == ErrFieldTypeConflict {
 		return true
 	}
+	if _, ok := err.(*PartialWriteError); ok {
+		return true
+	}
 
 	if strings.Contains(err.Error(), ErrFieldTypeConflict.Error()) {
 		return true
@@ -43,3 +46,70 @@ func IsClientError(err error) bool {
 
 	return false
 }
+
+// WriteErrorKind classifies the reason a point was rejected by a write.
+type WriteErrorKind string
+
+const (
+	WriteErrorParse                   WriteErrorKind = "parse"
+	WriteErrorFieldTypeConflict       WriteErrorKind = "field_type_conflict"
+	WriteErrorDatabaseNotFound        WriteErrorKind = "database_not_found"
+	WriteErrorRetentionPolicyNotFound WriteErrorKind = "retention_policy_not_found"
+	WriteErrorInternal                WriteErrorKind = "internal"
+)
+
+// WriteErrorKindOf returns the kind of a write error. Errors from remote
+// nodes only carry their message so the kind is determined from the text.
+func WriteErrorKindOf(err error) WriteErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, ErrFieldTypeConflict.Error()):
+		return WriteErrorFieldTypeConflict
+	case strings.HasPrefix(msg, "database not found"):
+		return WriteErrorDatabaseNotFound
+	case strings.HasPrefix(msg, "retention policy not found"):
+		return WriteErrorRetentionPolicyNotFound
+	}
+	return WriteErrorInternal
+}
+
+// PointError describes a single point that was rejected by a write.
+type PointError struct {
+	Index int // position of the point within the write
+	Kind  WriteErrorKind
+	Err   error
+}
+
+// PartialWriteError is returned when some of the points in a write were
+// rejected. All points not listed in Errors were written.
+type PartialWriteError struct {
+	Errors []PointError
+}
+
+// Error returns the string representation of the error.
+func (e *PartialWriteError) Error() string {
+	if len(e.Errors) == 0 {
+		return "partial write"
+	}
+	return fmt.Sprintf("partial write: %d points dropped: %v", len(e.Errors), e.Errors[0].Err)
+}
+
+// Slice returns the errors for the points from start up to end, indexed from
+// start. Returns nil if none of those points were rejected.
+func (e *PartialWriteError) Slice(start, end int) error {
+	var errs []PointError
+	for _, pe := range e.Errors {
+		if pe.Index >= start && pe.Index < end {
+			pe.Index -= start
+			errs = append(errs, pe)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &PartialWriteError{Errors: errs}
+}
@@ -80,44 +80,57 @@ type Node interface {
 func (*Query) node()     {}
 func (Statements) node() {}
 
-func (*AlterRetentionPolicyStatement) node()  {}
-func (*CreateContinuousQueryStatement) node() {}
-func (*CreateDatabaseStatement) node()        {}
-func (*CreateRetentionPolicyStatement) node() {}
-func (*CreateSubscriptionStatement) node()    {}
-func (*CreateUserStatement) node()            {}
-func (*Distinct) node()                       {}
-func (*DeleteStatement) node()                {}
-func (*DropContinuousQueryStatement) node()   {}
-func (*DropDatabaseStatement) node()          {}
-func (*DropMeasurementStatement) node()       {}
-func (*DropRetentionPolicyStatement) node()   {}
-func (*DropSeriesStatement) node()            {}
-func (*DropServerStatement) node()            {}
-func (*DropSubscriptionStatement) node()      {}
-func (*DropUserStatement) node()              {}
-func (*GrantStatement) node()                 {}
-func (*GrantAdminStatement) node()            {}
-func (*RevokeStatement) node()                {}
-func (*RevokeAdminStatement) node()           {}
-func (*SelectStatement) node()                {}
-func (*SetPasswordUserStatement) node()       {}
-func (*ShowContinuousQueriesStatement) node() {}
-func (*ShowGrantsForUserStatement) node()     {}
-func (*ShowServersStatement) node()           {}
-func (*ShowDatabasesStatement) node()         {}
-func (*ShowFieldKeysStatement) node()         {}
-func (*ShowRetentionPoliciesStatement) node() {}
-func (*ShowMeasurementsStatement) node()      {}
-func (*ShowSeriesStatement) node()            {}
-func (*ShowShardGroupsStatement) node()       {}
-func (*ShowShardsStatement) node()            {}
-func (*ShowStatsStatement) node()             {}
-func (*ShowSubscriptionsStatement) node()     {}
-func (*ShowDiagnosticsStatement) node()       {}
-func (*ShowTagKeysStatement) node()           {}
-func (*ShowTagValuesStatement) node()         {}
-func (*ShowUsersStatement) node()             {}
+func (*AlterContinuousQueryStatement) node()    {}
+func (*AlterRetentionPolicyStatement) node()    {}
+func (*AlterServerStatement) node()             {}
+func (*BackfillContinuousQueryStatement) node() {}
+func (*CreateContinuousQueryStatement) node()   {}
+func (*CreateDatabaseStatement) node()          {}
+func (*CreateRetentionPolicyStatement) node()   {}
+func (*CreateSubscriptionStatement) node()      {}
+func (*CreateTokenStatement) node()             {}
+func (*CreateUserStatement) node()              {}
+func (*Distinct) node()                         {}
+func (*DeleteStatement) node()                  {}
+func (*DropContinuousQueryStatement) node()     {}
+func (*DropDatabaseStatement) node()            {}
+func (*DropMeasurementStatement) node()         {}
+func (*DropRetentionPolicyStatement) node()     {}
+func (*DropSeriesStatement) node()              {}
+func (*DropServerStatement) node()              {}
+func (*DropSubscriptionStatement) node()        {}
+func (*DropTokenStatement) node()               {}
+func (*DropUserStatement) node()                {}
+func (*GrantStatement) node()                   {}
+func (*GrantAdminStatement) node()              {}
+func (*RevokeStatement) node()                  {}
+func (*RepairShardStatement) node()             {}
+func (*CopyShardStatement) node()               {}
+func (*MoveShardStatement) node()               {}
+func (*ShowHintedHandoffStatement) node()       {}
+func (*PurgeHintedHandoffStatement) node()      {}
+func (*ReplayHintedHandoffStatement) node()     {}
+func (*RevokeAdminStatement) node()             {}
+func (*SelectStatement) node()                  {}
+func (*SetPasswordUserStatement) node()         {}
+func (*ShowContinuousQueriesStatement) node()   {}
+func (*ShowGrantsForUserStatement) node()       {}
+func (*ShowServersStatement) node()             {}
+func (*ShowDatabasesStatement) node()           {}
+func (*ShowFieldKeysStatement) node()           {}
+func (*ShowRetentionPoliciesStatement) node()   {}
+func (*ShowMeasurementsStatement) node()        {}
+func (*ShowSeriesStatement) node()              {}
+func (*ShowShardDifferencesStatement) node()    {}
+func (*ShowShardGroupsStatement) node()         {}
+func (*ShowShardsStatement) node()              {}
+func (*ShowStatsStatement) node()               {}
+func (*ShowSubscriptionsStatement) node()       {}
+func (*ShowDiagnosticsStatement) node()         {}
+func (*ShowTagKeysStatement) node()             {}
+func (*ShowTagValuesStatement) node()           {}
+func (*ShowTokensStatement) node()              {}
+func (*ShowUsersStatement) node()               {}
 
 func (*BinaryExpr) node()      {}
 func (*BooleanLiteral) node()  {}
@@ -192,43 +205,56 @@ type ExecutionPrivilege struct {
 // ExecutionPrivileges is a list of privileges required to execute a statement.
 type ExecutionPrivileges []ExecutionPrivilege
 
-func (*AlterRetentionPolicyStatement) stmt()  {}
-func (*CreateContinuousQueryStatement) stmt() {}
-func (*CreateDatabaseStatement) stmt()        {}
-func (*CreateRetentionPolicyStatement) stmt() {}
-func (*CreateSubscriptionStatement) stmt()    {}
-func (*CreateUserStatement) stmt()            {}
-func (*DeleteStatement) stmt()                {}
-func (*DropContinuousQueryStatement) stmt()   {}
-func (*DropDatabaseStatement) stmt()          {}
-func (*DropMeasurementStatement) stmt()       {}
-func (*DropRetentionPolicyStatement) stmt()   {}
-func (*DropSeriesStatement) stmt()            {}
-func (*DropServerStatement) stmt()            {}
-func (*DropSubscriptionStatement) stmt()      {}
-func (*DropUserStatement) stmt()              {}
-func (*GrantStatement) stmt()                 {}
-func (*GrantAdminStatement) stmt()            {}
-func (*ShowContinuousQueriesStatement) stmt() {}
-func (*ShowGrantsForUserStatement) stmt()     {}
-func (*ShowServersStatement) stmt()           {}
-func (*ShowDatabasesStatement) stmt()         {}
-func (*ShowFieldKeysStatement) stmt()         {}
-func (*ShowMeasurementsStatement) stmt()      {}
-func (*ShowRetentionPoliciesStatement) stmt() {}
-func (*ShowSeriesStatement) stmt()            {}
-func (*ShowShardGroupsStatement) stmt()       {}
-func (*ShowShardsStatement) stmt()            {}
-func (*ShowStatsStatement) stmt()             {}
-func (*ShowSubscriptionsStatement) stmt()     {}
-func (*ShowDiagnosticsStatement) stmt()       {}
-func (*ShowTagKeysStatement) stmt()           {}
-func (*ShowTagValuesStatement) stmt()         {}
-func (*ShowUsersStatement) stmt()             {}
-func (*RevokeStatement) stmt()                {}
-func (*RevokeAdminStatement) stmt()           {}
-func (*SelectStatement) stmt()                {}
-func (*SetPasswordUserStatement) stmt()       {}
+func (*AlterContinuousQueryStatement) stmt()    {}
+func (*AlterRetentionPolicyStatement) stmt()    {}
+func (*AlterServerStatement) stmt()             {}
+func (*BackfillContinuousQueryStatement) stmt() {}
+func (*CreateContinuousQueryStatement) stmt()   {}
+func (*CreateDatabaseStatement) stmt()          {}
+func (*CreateRetentionPolicyStatement) stmt()   {}
+func (*CreateSubscriptionStatement) stmt()      {}
+func (*CreateTokenStatement) stmt()             {}
+func (*CreateUserStatement) stmt()              {}
+func (*DeleteStatement) stmt()                  {}
+func (*DropContinuousQueryStatement) stmt()     {}
+func (*DropDatabaseStatement) stmt()            {}
+func (*DropMeasurementStatement) stmt()         {}
+func (*DropRetentionPolicyStatement) stmt()     {}
+func (*DropSeriesStatement) stmt()              {}
+func (*DropServerStatement) stmt()              {}
+func (*DropSubscriptionStatement) stmt()        {}
+func (*DropTokenStatement) stmt()               {}
+func (*DropUserStatement) stmt()                {}
+func (*GrantStatement) stmt()                   {}
+func (*GrantAdminStatement) stmt()              {}
+func (*RepairShardStatement) stmt()             {}
+func (*CopyShardStatement) stmt()               {}
+func (*MoveShardStatement) stmt()               {}
+func (*ShowHintedHandoffStatement) stmt()       {}
+func (*PurgeHintedHandoffStatement) stmt()      {}
+func (*ReplayHintedHandoffStatement) stmt()     {}
+func (*ShowContinuousQueriesStatement) stmt()   {}
+func (*ShowGrantsForUserStatement) stmt()       {}
+func (*ShowServersStatement) stmt()             {}
+func (*ShowDatabasesStatement) stmt()           {}
+func (*ShowFieldKeysStatement) stmt()           {}
+func (*ShowMeasurementsStatement) stmt()        {}
+func (*ShowRetentionPoliciesStatement) stmt()   {}
+func (*ShowSeriesStatement) stmt()              {}
+func (*ShowShardDifferencesStatement) stmt()    {}
+func (*ShowShardGroupsStatement) stmt()         {}
+func (*ShowShardsStatement) stmt()              {}
+func (*ShowStatsStatement) stmt()               {}
+func (*ShowSubscriptionsStatement) stmt()       {}
+func (*ShowDiagnosticsStatement) stmt()         {}
+func (*ShowTagKeysStatement) stmt()             {}
+func (*ShowTagValuesStatement) stmt()           {}
+func (*ShowTokensStatement) stmt()              {}
+func (*ShowUsersStatement) stmt()               {}
+func (*RevokeStatement) stmt()                  {}
+func (*RevokeAdminStatement) stmt()             {}
+func (*SelectStatement) stmt()                  {}
+func (*SetPasswordUserStatement) stmt()         {}
 
 // Expr represents an expression that can be evaluated to a value.
 type Expr interface {
@@ -458,6 +484,56 @@ func (s *DropUserStatement) RequiredPrivileges() ExecutionPrivileges {
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
 }
 
+// CreateTokenStatement represents a command for creating an API token for a user.
+type CreateTokenStatement struct {
+	// Name of the token to be created.
+	Name string
+
+	// User the token authenticates as.
+	Username string
+
+	// Duration the token is valid for. Zero if the token never expires.
+	Duration time.Duration
+}
+
+// String returns a string representation of the create token statement.
+func (s *CreateTokenStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("CREATE TOKEN ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	_, _ = buf.WriteString(" FOR ")
+	_, _ = buf.WriteString(QuoteIdent(s.Username))
+	if s.Duration > 0 {
+		_, _ = buf.WriteString(" DURATION ")
+		_, _ = buf.WriteString(FormatDuration(s.Duration))
+	}
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a CreateTokenStatement.
+func (s *CreateTokenStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// DropTokenStatement represents a command for dropping an API token.
+type DropTokenStatement struct {
+	// Name of the token to drop.
+	Name string
+}
+
+// String returns a string representation of the drop token statement.
+func (s *DropTokenStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("DROP TOKEN ")
+	_, _ = buf.WriteString(QuoteIdent(s.Name))
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a DropTokenStatement.
+func (s *DropTokenStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
 // Privilege is a type of action a user can be granted the right to use.
 type Privilege int
 
@@ -624,6 +700,9 @@ type CreateRetentionPolicyStatement struct {
 	// Replication factor for data written to this policy.
 	Replication int
 
+	// Strategy used to assign shards to nodes. Empty for the default.
+	Placement string
+
 	// Should this policy be set as default for the database?
 	Default bool
 }
@@ -639,6 +718,10 @@ func (s *CreateRetentionPolicyStatement) String() string {
 	_, _ = buf.WriteString(FormatDuration(s.Duration))
 	_, _ = buf.WriteString(" REPLICATION ")
 	_, _ = buf.WriteString(strconv.Itoa(s.Replication))
+	if s.Placement != "" {
+		_, _ = buf.WriteString(" PLACEMENT ")
+		_, _ = buf.WriteString(QuoteString(s.Placement))
+	}
 	if s.Default {
 		_, _ = buf.WriteString(" DEFAULT")
 	}
@@ -664,6 +747,9 @@ type AlterRetentionPolicyStatement struct {
 	// Replication factor for data written to this policy.
 	Replication *int
 
+	// Strategy used to assign shards to nodes in new shard groups.
+	Placement *string
+
 	// Should this policy be set as defalut for the database?
 	Default bool
 }
@@ -686,6 +772,11 @@ func (s *AlterRetentionPolicyStatement) String() string {
 		_, _ = buf.WriteString(strconv.Itoa(*s.Replication))
 	}
 
+	if s.Placement != nil {
+		_, _ = buf.WriteString(" PLACEMENT ")
+		_, _ = buf.WriteString(QuoteString(*s.Placement))
+	}
+
 	if s.Default {
 		_, _ = buf.WriteString(" DEFAULT")
 	}
@@ -698,6 +789,57 @@ func (s *AlterRetentionPolicyStatement) RequiredPrivileges() ExecutionPrivileges
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
 }
 
+// AlterServerStatement represents a command for changing the state or the
+// labels of a server.
+type AlterServerStatement struct {
+	// ID of the node to be altered.
+	NodeID uint64
+
+	// New state of the node, if not empty.
+	State string
+
+	// Labels to merge into the labels of the node. An empty value removes
+	// the label.
+	Labels map[string]string
+}
+
+// String returns a string representation of the alter server statement.
+func (s *AlterServerStatement) String() string {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("ALTER SERVER ")
+	_, _ = buf.WriteString(strconv.FormatUint(s.NodeID, 10))
+
+	if s.State != "" {
+		_, _ = buf.WriteString(" STATE ")
+		_, _ = buf.WriteString(QuoteString(s.State))
+	}
+
+	if len(s.Labels) > 0 {
+		keys := make([]string, 0, len(s.Labels))
+		for k := range s.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		_, _ = buf.WriteString(" LABELS ")
+		for i, k := range keys {
+			if i > 0 {
+				_, _ = buf.WriteString(", ")
+			}
+			_, _ = buf.WriteString(QuoteIdent(k))
+			_, _ = buf.WriteString(" = ")
+			_, _ = buf.WriteString(QuoteString(s.Labels[k]))
+		}
+	}
+
+	return buf.String()
+}
+
+// RequiredPrivileges returns the privilege required to execute an AlterServerStatement.
+func (s *AlterServerStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
 type FillOption int
 
 const (
@@ -1967,11 +2109,37 @@ type CreateContinuousQueryStatement struct {
 
 	// Source of data (SELECT statement).
 	Source *SelectStatement
+
+	// Interval to run the query. Zero means the interval is derived from the GROUP BY.
+	ResampleEvery time.Duration
+
+	// Window of time to recompute on each run. Zero means the service defaults are used.
+	ResampleFor time.Duration
 }
 
 // String returns a string representation of the statement.
 func (s *CreateContinuousQueryStatement) String() string {
-	return fmt.Sprintf("CREATE CONTINUOUS QUERY %s ON %s BEGIN %s END", QuoteIdent(s.Name), QuoteIdent(s.Database), s.Source.String())
+	return fmt.Sprintf("CREATE CONTINUOUS QUERY %s ON %s%s BEGIN %s END", QuoteIdent(s.Name), QuoteIdent(s.Database), resampleString(s.ResampleEvery, s.ResampleFor), s.Source.String())
+}
+
+// resampleString returns the RESAMPLE clause of a continuous query statement.
+// Returns a blank string if neither interval is set.
+func resampleString(every, duration time.Duration) string {
+	if every == 0 && duration == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	_, _ = buf.WriteString(" RESAMPLE")
+	if every != 0 {
+		_, _ = buf.WriteString(" EVERY ")
+		_, _ = buf.WriteString(FormatDuration(every))
+	}
+	if duration != 0 {
+		_, _ = buf.WriteString(" FOR ")
+		_, _ = buf.WriteString(FormatDuration(duration))
+	}
+	return buf.String()
 }
 
 // DefaultDatabase returns the default database from the statement.
@@ -2000,6 +2168,82 @@ func (s *CreateContinuousQueryStatement) RequiredPrivileges() ExecutionPrivilege
 	return ep
 }
 
+// AlterContinuousQueryStatement represents a command for replacing the query of an existing continuous query.
+type AlterContinuousQueryStatement struct {
+	// Name of the continuous query to be altered.
+	Name string
+
+	// Name of the database the continuous query belongs to.
+	Database string
+
+	// Source of data (SELECT statement).
+	Source *SelectStatement
+
+	// Interval to run the query. Zero means the interval is derived from the GROUP BY.
+	ResampleEvery time.Duration
+
+	// Window of time to recompute on each run. Zero means the service defaults are used.
+	ResampleFor time.Duration
+}
+
+// String returns a string representation of the statement.
+func (s *AlterContinuousQueryStatement) String() string {
+	return fmt.Sprintf("ALTER CONTINUOUS QUERY %s ON %s%s BEGIN %s END", QuoteIdent(s.Name), QuoteIdent(s.Database), resampleString(s.ResampleEvery, s.ResampleFor), s.Source.String())
+}
+
+// DefaultDatabase returns the default database from the statement.
+func (s *AlterContinuousQueryStatement) DefaultDatabase() string {
+	return s.Database
+}
+
+// RequiredPrivileges returns the privilege required to execute an AlterContinuousQueryStatement.
+func (s *AlterContinuousQueryStatement) RequiredPrivileges() ExecutionPrivileges {
+	return s.CreateStatement().RequiredPrivileges()
+}
+
+// CreateStatement returns the statement that would create the continuous query
+// in its altered form. This is the form stored in the meta store.
+func (s *AlterContinuousQueryStatement) CreateStatement() *CreateContinuousQueryStatement {
+	return &CreateContinuousQueryStatement{
+		Name:          s.Name,
+		Database:      s.Database,
+		Source:        s.Source,
+		ResampleEvery: s.ResampleEvery,
+		ResampleFor:   s.ResampleFor,
+	}
+}
+
+// BackfillContinuousQueryStatement represents a command for running a continuous
+// query over a range of historical data.
+type BackfillContinuousQueryStatement struct {
+	// Name of the continuous query to be backfilled.
+	Name string
+
+	// Name of the database the continuous query belongs to.
+	Database string
+
+	// Time range to backfill.
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// String returns a string representation of the statement.
+func (s *BackfillContinuousQueryStatement) String() string {
+	return fmt.Sprintf("BACKFILL CONTINUOUS QUERY %s ON %s FROM %s TO %s",
+		QuoteIdent(s.Name), QuoteIdent(s.Database),
+		(&TimeLiteral{Val: s.StartTime}).String(), (&TimeLiteral{Val: s.EndTime}).String())
+}
+
+// DefaultDatabase returns the default database from the statement.
+func (s *BackfillContinuousQueryStatement) DefaultDatabase() string {
+	return s.Database
+}
+
+// RequiredPrivileges returns the privilege required to execute a BackfillContinuousQueryStatement.
+func (s *BackfillContinuousQueryStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: false, Name: s.Database, Privilege: WritePrivilege}}
+}
+
 // DropContinuousQueryStatement represents a command for removing a continuous query.
 type DropContinuousQueryStatement struct {
 	Name     string
@@ -2155,6 +2399,123 @@ func (s *ShowShardsStatement) RequiredPrivileges() ExecutionPrivileges {
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
 }
 
+// ShowShardDifferencesStatement represents a command for comparing the
+// replicas of the shards owned by this node.
+type ShowShardDifferencesStatement struct{}
+
+// String returns a string representation.
+func (s *ShowShardDifferencesStatement) String() string { return "SHOW SHARD DIFFERENCES" }
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *ShowShardDifferencesStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// RepairShardStatement represents a command for repairing the differences
+// between the replicas of a shard.
+type RepairShardStatement struct {
+	// ID of the shard to repair.
+	ID uint64
+}
+
+// String returns a string representation.
+func (s *RepairShardStatement) String() string {
+	return fmt.Sprintf("REPAIR SHARD %d", s.ID)
+}
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *RepairShardStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// CopyShardStatement represents a command for copying a shard from one
+// node to another. Both nodes own the shard once the copy is complete.
+type CopyShardStatement struct {
+	// ID of the shard to copy.
+	ID uint64
+
+	// Nodes to copy the shard from and to.
+	From uint64
+	To   uint64
+}
+
+// String returns a string representation.
+func (s *CopyShardStatement) String() string {
+	return fmt.Sprintf("COPY SHARD %d FROM %d TO %d", s.ID, s.From, s.To)
+}
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *CopyShardStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// MoveShardStatement represents a command for moving a shard from one node
+// to another. The shard is removed from the source node once it is copied.
+type MoveShardStatement struct {
+	// ID of the shard to move.
+	ID uint64
+
+	// Nodes to move the shard from and to.
+	From uint64
+	To   uint64
+}
+
+// String returns a string representation.
+func (s *MoveShardStatement) String() string {
+	return fmt.Sprintf("MOVE SHARD %d FROM %d TO %d", s.ID, s.From, s.To)
+}
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *MoveShardStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// ShowHintedHandoffStatement represents a command for listing the hinted
+// handoff queues on this node.
+type ShowHintedHandoffStatement struct{}
+
+// String returns a string representation.
+func (s *ShowHintedHandoffStatement) String() string { return "SHOW HINTED HANDOFF" }
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *ShowHintedHandoffStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// PurgeHintedHandoffStatement represents a command for deleting the hinted
+// handoff queue for a node.
+type PurgeHintedHandoffStatement struct {
+	// ID of the node whose queue is deleted.
+	NodeID uint64
+}
+
+// String returns a string representation.
+func (s *PurgeHintedHandoffStatement) String() string {
+	return fmt.Sprintf("PURGE HINTED HANDOFF FOR %d", s.NodeID)
+}
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *PurgeHintedHandoffStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
+// ReplayHintedHandoffStatement represents a command for sending the hinted
+// handoff queue for a node without waiting for the next retry.
+type ReplayHintedHandoffStatement struct {
+	// ID of the node whose queue is sent.
+	NodeID uint64
+}
+
+// String returns a string representation.
+func (s *ReplayHintedHandoffStatement) String() string {
+	return fmt.Sprintf("REPLAY HINTED HANDOFF FOR %d", s.NodeID)
+}
+
+// RequiredPrivileges returns the privileges required to execute the statement.
+func (s *ReplayHintedHandoffStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
 // ShowDiagnosticsStatement represents a command for show node diagnostics.
 type ShowDiagnosticsStatement struct {
 	// Module
@@ -2385,6 +2746,25 @@ func (s *ShowUsersStatement) RequiredPrivileges() ExecutionPrivileges {
 	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
 }
 
+// ShowTokensStatement represents a command for listing API tokens.
+type ShowTokensStatement struct {
+	// Only list tokens for this user if set.
+	Username string
+}
+
+// String returns a string representation of the ShowTokensStatement.
+func (s *ShowTokensStatement) String() string {
+	if s.Username != "" {
+		return "SHOW TOKENS FOR " + QuoteIdent(s.Username)
+	}
+	return "SHOW TOKENS"
+}
+
+// RequiredPrivileges returns the privilege(s) required to execute a ShowTokensStatement
+func (s *ShowTokensStatement) RequiredPrivileges() ExecutionPrivileges {
+	return ExecutionPrivileges{{Admin: true, Name: "", Privilege: AllPrivileges}}
+}
+
 // ShowFieldKeysStatement represents a command for listing field keys.
 type ShowFieldKeysStatement struct {
 	// Data sources that fields are extracted from.
@@ -3001,6 +3381,9 @@ func Walk(v Visitor, node Node) {
 			Walk(v, expr)
 		}
 
+	case *AlterContinuousQueryStatement:
+		Walk(v, n.Source)
+
 	case *CreateContinuousQueryStatement:
 		Walk(v, n.Source)
 
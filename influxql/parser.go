@@ -2,6 +2,7 @@ package influxql
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -23,7 +24,8 @@ const (
 
 // Parser represents an InfluxQL parser.
 type Parser struct {
-	s *bufScanner
+	s      *bufScanner
+	params map[string]interface{}
 }
 
 // NewParser returns a new instance of Parser.
@@ -34,6 +36,17 @@ func NewParser(r io.Reader) *Parser {
 // ParseQuery parses a query string and returns its AST representation.
 func ParseQuery(s string) (*Query, error) { return NewParser(strings.NewReader(s)).ParseQuery() }
 
+// SetParams sets the values bound to $name placeholders in the query.
+//
+// A string, number, or bool value is bound as a literal of that type. A map
+// with a single key binds a value of a specific kind: "identifier", "string",
+// "number", "duration", "time", or "regex". For example:
+//
+//	{"host": "server01", "field": {"identifier": "value"}, "interval": {"duration": "10m"}}
+func (p *Parser) SetParams(params map[string]interface{}) {
+	p.params = params
+}
+
 // ParseStatement parses a statement string and returns its AST representation.
 func ParseStatement(s string) (Statement, error) {
 	return NewParser(strings.NewReader(s)).ParseStatement()
@@ -96,8 +109,20 @@ func (p *Parser) ParseStatement() (Statement, error) {
 		return p.parseAlterStatement()
 	case SET:
 		return p.parseSetPasswordUserStatement()
+	case BACKFILL:
+		return p.parseBackfillContinuousQueryStatement()
+	case REPAIR:
+		return p.parseRepairShardStatement()
+	case COPY:
+		return p.parseCopyShardStatement()
+	case MOVE:
+		return p.parseMoveShardStatement()
+	case PURGE:
+		return p.parsePurgeHintedHandoffStatement()
+	case REPLAY:
+		return p.parseReplayHintedHandoffStatement()
 	default:
-		return nil, newParseError(tokstr(tok, lit), []string{"SELECT", "DELETE", "SHOW", "CREATE", "DROP", "GRANT", "REVOKE", "ALTER", "SET"}, pos)
+		return nil, newParseError(tokstr(tok, lit), []string{"SELECT", "DELETE", "SHOW", "CREATE", "DROP", "GRANT", "REVOKE", "ALTER", "SET", "BACKFILL", "REPAIR", "COPY", "MOVE", "PURGE", "REPLAY"}, pos)
 	}
 }
 
@@ -110,6 +135,11 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		return p.parseShowContinuousQueriesStatement()
 	case GRANTS:
 		return p.parseGrantsForUserStatement()
+	case HINTED:
+		if err := p.parseTokens([]Token{HANDOFF}); err != nil {
+			return nil, err
+		}
+		return &ShowHintedHandoffStatement{}, nil
 	case DATABASES:
 		return p.parseShowDatabasesStatement()
 	case SERVERS:
@@ -134,8 +164,10 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		tok, pos, lit := p.scanIgnoreWhitespace()
 		if tok == GROUPS {
 			return p.parseShowShardGroupsStatement()
+		} else if tok == DIFFERENCES {
+			return p.parseShowShardDifferencesStatement()
 		}
-		return nil, newParseError(tokstr(tok, lit), []string{"GROUPS"}, pos)
+		return nil, newParseError(tokstr(tok, lit), []string{"GROUPS", "DIFFERENCES"}, pos)
 	case SHARDS:
 		return p.parseShowShardsStatement()
 	case STATS:
@@ -152,6 +184,8 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		return nil, newParseError(tokstr(tok, lit), []string{"KEYS", "VALUES"}, pos)
 	case USERS:
 		return p.parseShowUsersStatement()
+	case TOKENS:
+		return p.parseShowTokensStatement()
 	case SUBSCRIPTIONS:
 		return p.parseShowSubscriptionsStatement()
 	}
@@ -161,6 +195,7 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		"DATABASES",
 		"FIELD",
 		"GRANTS",
+		"HINTED",
 		"MEASUREMENTS",
 		"RETENTION",
 		"SERIES",
@@ -172,6 +207,7 @@ func (p *Parser) parseShowStatement() (Statement, error) {
 		"SHARD",
 		"SHARDS",
 		"SUBSCRIPTIONS",
+		"TOKENS",
 	}
 	sort.Strings(showQueryKeywords)
 
@@ -196,9 +232,11 @@ func (p *Parser) parseCreateStatement() (Statement, error) {
 		return p.parseCreateRetentionPolicyStatement()
 	} else if tok == SUBSCRIPTION {
 		return p.parseCreateSubscriptionStatement()
+	} else if tok == TOKEN {
+		return p.parseCreateTokenStatement()
 	}
 
-	return nil, newParseError(tokstr(tok, lit), []string{"CONTINUOUS", "DATABASE", "USER", "RETENTION", "SUBSCRIPTION"}, pos)
+	return nil, newParseError(tokstr(tok, lit), []string{"CONTINUOUS", "DATABASE", "USER", "RETENTION", "SUBSCRIPTION", "TOKEN"}, pos)
 }
 
 // parseDropStatement parses a string and returns a drop statement.
@@ -224,9 +262,11 @@ func (p *Parser) parseDropStatement() (Statement, error) {
 		return p.parseDropServerStatement()
 	} else if tok == SUBSCRIPTION {
 		return p.parseDropSubscriptionStatement()
+	} else if tok == TOKEN {
+		return p.parseDropTokenStatement()
 	}
 
-	return nil, newParseError(tokstr(tok, lit), []string{"SERIES", "CONTINUOUS", "MEASUREMENT", "SERVER", "SUBSCRIPTION"}, pos)
+	return nil, newParseError(tokstr(tok, lit), []string{"SERIES", "CONTINUOUS", "MEASUREMENT", "SERVER", "SUBSCRIPTION", "TOKEN"}, pos)
 }
 
 // parseAlterStatement parses a string and returns an alter statement.
@@ -238,9 +278,13 @@ func (p *Parser) parseAlterStatement() (Statement, error) {
 			return nil, newParseError(tokstr(tok, lit), []string{"POLICY"}, pos)
 		}
 		return p.parseAlterRetentionPolicyStatement()
+	} else if tok == CONTINUOUS {
+		return p.parseAlterContinuousQueryStatement()
+	} else if tok == SERVER {
+		return p.parseAlterServerStatement()
 	}
 
-	return nil, newParseError(tokstr(tok, lit), []string{"RETENTION"}, pos)
+	return nil, newParseError(tokstr(tok, lit), []string{"RETENTION", "CONTINUOUS", "SERVER"}, pos)
 }
 
 // parseSetPasswordUserStatement parses a string and returns a set statement.
@@ -379,11 +423,20 @@ func (p *Parser) parseCreateRetentionPolicyStatement() (*CreateRetentionPolicySt
 	}
 	stmt.Replication = n
 
+	// Parse optional PLACEMENT token.
+	tok, pos, lit = p.scanIgnoreWhitespace()
+	if tok == PLACEMENT {
+		if stmt.Placement, err = p.parseString(); err != nil {
+			return nil, err
+		}
+		tok, pos, lit = p.scanIgnoreWhitespace()
+	}
+
 	// Parse optional DEFAULT token.
-	if tok, pos, lit = p.scanIgnoreWhitespace(); tok == DEFAULT {
+	if tok == DEFAULT {
 		stmt.Default = true
 	} else if tok != EOF && tok != SEMICOLON {
-		return nil, newParseError(tokstr(tok, lit), []string{"DEFAULT"}, pos)
+		return nil, newParseError(tokstr(tok, lit), []string{"PLACEMENT", "DEFAULT"}, pos)
 	}
 
 	return stmt, nil
@@ -417,7 +470,7 @@ func (p *Parser) parseAlterRetentionPolicyStatement() (*AlterRetentionPolicyStat
 	stmt.Database = ident
 
 	// Loop through option tokens (DURATION, REPLICATION, DEFAULT, etc.).
-	maxNumOptions := 3
+	maxNumOptions := 4
 Loop:
 	for i := 0; i < maxNumOptions; i++ {
 		tok, pos, lit := p.scanIgnoreWhitespace()
@@ -434,11 +487,17 @@ Loop:
 				return nil, err
 			}
 			stmt.Replication = &n
+		case PLACEMENT:
+			placement, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Placement = &placement
 		case DEFAULT:
 			stmt.Default = true
 		default:
 			if i < 1 {
-				return nil, newParseError(tokstr(tok, lit), []string{"DURATION", "RETENTION", "DEFAULT"}, pos)
+				return nil, newParseError(tokstr(tok, lit), []string{"DURATION", "RETENTION", "PLACEMENT", "DEFAULT"}, pos)
 			}
 			p.unscan()
 			break Loop
@@ -1328,6 +1387,70 @@ func (p *Parser) parseDropServerStatement() (*DropServerStatement, error) {
 	return s, nil
 }
 
+// parseAlterServerStatement parses a string and returns an AlterServerStatement.
+// This function assumes the "ALTER SERVER" tokens have already been consumed.
+func (p *Parser) parseAlterServerStatement() (*AlterServerStatement, error) {
+	s := &AlterServerStatement{}
+	var err error
+
+	// Parse the server's ID.
+	if s.NodeID, err = p.parseUInt64(); err != nil {
+		return nil, err
+	}
+
+	// Loop through option tokens (STATE, LABELS).
+	maxNumOptions := 2
+Loop:
+	for i := 0; i < maxNumOptions; i++ {
+		tok, pos, lit := p.scanIgnoreWhitespace()
+		switch tok {
+		case STATE:
+			if s.State, err = p.parseString(); err != nil {
+				return nil, err
+			}
+		case LABELS:
+			if s.Labels, err = p.parseLabels(); err != nil {
+				return nil, err
+			}
+		default:
+			if i < 1 {
+				return nil, newParseError(tokstr(tok, lit), []string{"STATE", "LABELS"}, pos)
+			}
+			p.unscan()
+			break Loop
+		}
+	}
+
+	return s, nil
+}
+
+// parseLabels parses a comma-separated list of key = 'value' pairs.
+func (p *Parser) parseLabels() (map[string]string, error) {
+	labels := make(map[string]string)
+	for {
+		key, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, pos, lit := p.scanIgnoreWhitespace(); tok != EQ {
+			return nil, newParseError(tokstr(tok, lit), []string{"="}, pos)
+		}
+
+		value, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		labels[key] = value
+
+		// If there's not a comma next then stop parsing labels.
+		if tok, _, _ := p.scanIgnoreWhitespace(); tok != COMMA {
+			p.unscan()
+			return labels, nil
+		}
+	}
+}
+
 // parseShowContinuousQueriesStatement parses a string and returns a ShowContinuousQueriesStatement.
 // This function assumes the "SHOW CONTINUOUS" tokens have already been consumed.
 func (p *Parser) parseShowContinuousQueriesStatement() (*ShowContinuousQueriesStatement, error) {
@@ -1403,6 +1526,15 @@ func (p *Parser) parseCreateContinuousQueryStatement() (*CreateContinuousQuerySt
 	}
 	stmt.Database = ident
 
+	// Parse the optional RESAMPLE clause.
+	if tok, _, _ := p.scanIgnoreWhitespace(); tok == RESAMPLE {
+		if stmt.ResampleEvery, stmt.ResampleFor, err = p.parseResample(); err != nil {
+			return nil, err
+		}
+	} else {
+		p.unscan()
+	}
+
 	// Expect a "BEGIN SELECT" tokens.
 	if err := p.parseTokens([]Token{BEGIN, SELECT}); err != nil {
 		return nil, err
@@ -1429,6 +1561,11 @@ func (p *Parser) parseCreateContinuousQueryStatement() (*CreateContinuousQuerySt
 			}
 			return nil, newParseError(tokstr(tok, lit), expected, pos)
 		}
+
+		// The recompute window must cover at least one interval.
+		if stmt.ResampleFor != 0 && stmt.ResampleFor < d {
+			return nil, fmt.Errorf("FOR duration must be >= GROUP BY time duration: must be a minimum of %s, got %s", FormatDuration(d), FormatDuration(stmt.ResampleFor))
+		}
 	}
 
 	// Expect a "END" keyword.
@@ -1439,6 +1576,52 @@ func (p *Parser) parseCreateContinuousQueryStatement() (*CreateContinuousQuerySt
 	return stmt, nil
 }
 
+// parseResample parses the EVERY and FOR arguments of a RESAMPLE clause.
+// This function assumes the RESAMPLE token has already been consumed.
+func (p *Parser) parseResample() (every, duration time.Duration, err error) {
+	var found bool
+	if tok, _, _ := p.scanIgnoreWhitespace(); tok == EVERY {
+		if every, err = p.parseResampleDuration(); err != nil {
+			return 0, 0, err
+		}
+		found = true
+	} else {
+		p.unscan()
+	}
+
+	if tok, _, _ := p.scanIgnoreWhitespace(); tok == FOR {
+		if duration, err = p.parseResampleDuration(); err != nil {
+			return 0, 0, err
+		}
+		found = true
+	} else {
+		p.unscan()
+	}
+
+	// At least one of EVERY or FOR is required.
+	if !found {
+		tok, pos, lit := p.scanIgnoreWhitespace()
+		return 0, 0, newParseError(tokstr(tok, lit), []string{"EVERY", "FOR"}, pos)
+	}
+	return every, duration, nil
+}
+
+// parseResampleDuration parses a non-zero duration for a RESAMPLE clause.
+func (p *Parser) parseResampleDuration() (time.Duration, error) {
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	if tok != DURATION_VAL {
+		return 0, newParseError(tokstr(tok, lit), []string{"duration"}, pos)
+	}
+
+	d, err := ParseDuration(lit)
+	if err != nil {
+		return 0, &ParseError{Message: err.Error(), Pos: pos}
+	} else if d == 0 {
+		return 0, &ParseError{Message: "RESAMPLE duration must be greater than zero", Pos: pos}
+	}
+	return d, nil
+}
+
 // parseCreateDatabaseStatement parses a string and returns a CreateDatabaseStatement.
 // This function assumes the "CREATE DATABASE" tokens have already been consumed.
 func (p *Parser) parseCreateDatabaseStatement() (*CreateDatabaseStatement, error) {
@@ -1654,6 +1837,82 @@ func (p *Parser) parseDropUserStatement() (*DropUserStatement, error) {
 	return stmt, nil
 }
 
+// parseCreateTokenStatement parses a string and returns a CreateTokenStatement.
+// This function assumes the "CREATE TOKEN" tokens have already been consumed.
+func (p *Parser) parseCreateTokenStatement() (*CreateTokenStatement, error) {
+	stmt := &CreateTokenStatement{}
+
+	// Parse name of the token to be created.
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = ident
+
+	// Parse the user the token authenticates as.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FOR {
+		return nil, newParseError(tokstr(tok, lit), []string{"FOR"}, pos)
+	}
+	if stmt.Username, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+
+	// Check for optional DURATION clause.
+	if tok, _, _ := p.scanIgnoreWhitespace(); tok != DURATION {
+		p.unscan()
+		return stmt, nil
+	}
+
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	if tok != DURATION_VAL {
+		return nil, newParseError(tokstr(tok, lit), []string{"duration"}, pos)
+	}
+	d, err := ParseDuration(lit)
+	if err != nil {
+		return nil, &ParseError{Message: err.Error(), Pos: pos}
+	} else if d == 0 {
+		return nil, &ParseError{Message: "token duration must be greater than zero", Pos: pos}
+	}
+	stmt.Duration = d
+
+	return stmt, nil
+}
+
+// parseDropTokenStatement parses a string and returns a DropTokenStatement.
+// This function assumes the "DROP TOKEN" tokens have already been consumed.
+func (p *Parser) parseDropTokenStatement() (*DropTokenStatement, error) {
+	stmt := &DropTokenStatement{}
+
+	// Parse the name of the token to be dropped.
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = lit
+
+	return stmt, nil
+}
+
+// parseShowTokensStatement parses a string and returns a ShowTokensStatement.
+// This function assumes the "SHOW TOKENS" tokens have already been consumed.
+func (p *Parser) parseShowTokensStatement() (*ShowTokensStatement, error) {
+	stmt := &ShowTokensStatement{}
+
+	// Check for optional FOR clause.
+	if tok, _, _ := p.scanIgnoreWhitespace(); tok != FOR {
+		p.unscan()
+		return stmt, nil
+	}
+
+	lit, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Username = lit
+
+	return stmt, nil
+}
+
 // parseRetentionPolicy parses a string and returns a retention policy name.
 // This function assumes the "WITH" token has already been consumed.
 func (p *Parser) parseRetentionPolicy() (name string, dfault bool, err error) {
@@ -1697,6 +1956,113 @@ func (p *Parser) parseShowShardsStatement() (*ShowShardsStatement, error) {
 	return &ShowShardsStatement{}, nil
 }
 
+// parseShowShardDifferencesStatement parses a string for "SHOW SHARD DIFFERENCES" statement.
+// This function assumes the "SHOW SHARD DIFFERENCES" tokens have already been consumed.
+func (p *Parser) parseShowShardDifferencesStatement() (*ShowShardDifferencesStatement, error) {
+	return &ShowShardDifferencesStatement{}, nil
+}
+
+// parseRepairShardStatement parses a string and returns a RepairShardStatement.
+// This function assumes the "REPAIR" token has already been consumed.
+func (p *Parser) parseRepairShardStatement() (*RepairShardStatement, error) {
+	stmt := &RepairShardStatement{}
+
+	// Expect a "SHARD" token.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != SHARD {
+		return nil, newParseError(tokstr(tok, lit), []string{"SHARD"}, pos)
+	}
+
+	// Parse the shard's ID.
+	id, err := p.parseUInt64()
+	if err != nil {
+		return nil, err
+	}
+	stmt.ID = id
+
+	return stmt, nil
+}
+
+// parseCopyShardStatement parses a string and returns a CopyShardStatement.
+// This function assumes the "COPY" token has already been consumed.
+func (p *Parser) parseCopyShardStatement() (*CopyShardStatement, error) {
+	id, from, to, err := p.parseShardTransfer()
+	if err != nil {
+		return nil, err
+	}
+	return &CopyShardStatement{ID: id, From: from, To: to}, nil
+}
+
+// parseMoveShardStatement parses a string and returns a MoveShardStatement.
+// This function assumes the "MOVE" token has already been consumed.
+func (p *Parser) parseMoveShardStatement() (*MoveShardStatement, error) {
+	id, from, to, err := p.parseShardTransfer()
+	if err != nil {
+		return nil, err
+	}
+	return &MoveShardStatement{ID: id, From: from, To: to}, nil
+}
+
+// parseShardTransfer parses the "SHARD <id> FROM <node> TO <node>" clause
+// shared by the COPY and MOVE statements.
+func (p *Parser) parseShardTransfer() (id, from, to uint64, err error) {
+	// Expect a "SHARD" token.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != SHARD {
+		return 0, 0, 0, newParseError(tokstr(tok, lit), []string{"SHARD"}, pos)
+	}
+
+	// Parse the shard's ID.
+	if id, err = p.parseUInt64(); err != nil {
+		return 0, 0, 0, err
+	}
+
+	// Parse the source node's ID.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FROM {
+		return 0, 0, 0, newParseError(tokstr(tok, lit), []string{"FROM"}, pos)
+	}
+	if from, err = p.parseUInt64(); err != nil {
+		return 0, 0, 0, err
+	}
+
+	// Parse the destination node's ID.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != TO {
+		return 0, 0, 0, newParseError(tokstr(tok, lit), []string{"TO"}, pos)
+	}
+	if to, err = p.parseUInt64(); err != nil {
+		return 0, 0, 0, err
+	}
+
+	return id, from, to, nil
+}
+
+// parsePurgeHintedHandoffStatement parses a string and returns a PurgeHintedHandoffStatement.
+// This function assumes the "PURGE" token has already been consumed.
+func (p *Parser) parsePurgeHintedHandoffStatement() (*PurgeHintedHandoffStatement, error) {
+	id, err := p.parseHintedHandoffNode()
+	if err != nil {
+		return nil, err
+	}
+	return &PurgeHintedHandoffStatement{NodeID: id}, nil
+}
+
+// parseReplayHintedHandoffStatement parses a string and returns a ReplayHintedHandoffStatement.
+// This function assumes the "REPLAY" token has already been consumed.
+func (p *Parser) parseReplayHintedHandoffStatement() (*ReplayHintedHandoffStatement, error) {
+	id, err := p.parseHintedHandoffNode()
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayHintedHandoffStatement{NodeID: id}, nil
+}
+
+// parseHintedHandoffNode parses the "HINTED HANDOFF FOR <node>" clause shared
+// by the PURGE and REPLAY statements.
+func (p *Parser) parseHintedHandoffNode() (uint64, error) {
+	if err := p.parseTokens([]Token{HINTED, HANDOFF, FOR}); err != nil {
+		return 0, err
+	}
+	return p.parseUInt64()
+}
+
 // parseShowStatsStatement parses a string and returns a ShowStatsStatement.
 // This function assumes the "SHOW STATS" tokens have already been consumed.
 func (p *Parser) parseShowStatsStatement() (*ShowStatsStatement, error) {
@@ -1726,6 +2092,90 @@ func (p *Parser) parseShowDiagnosticsStatement() (*ShowDiagnosticsStatement, err
 	return stmt, err
 }
 
+// parseAlterContinuousQueryStatement parses a string and returns an AlterContinuousQueryStatement.
+// This function assumes the "ALTER CONTINUOUS" tokens have already been consumed.
+func (p *Parser) parseAlterContinuousQueryStatement() (*AlterContinuousQueryStatement, error) {
+	// The remainder of the statement has the same form as CREATE CONTINUOUS QUERY.
+	cq, err := p.parseCreateContinuousQueryStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AlterContinuousQueryStatement{
+		Name:          cq.Name,
+		Database:      cq.Database,
+		Source:        cq.Source,
+		ResampleEvery: cq.ResampleEvery,
+		ResampleFor:   cq.ResampleFor,
+	}, nil
+}
+
+// parseBackfillContinuousQueryStatement parses a string and returns a BackfillContinuousQueryStatement.
+// This function assumes the "BACKFILL" token has already been consumed.
+func (p *Parser) parseBackfillContinuousQueryStatement() (*BackfillContinuousQueryStatement, error) {
+	stmt := &BackfillContinuousQueryStatement{}
+
+	// Expect "CONTINUOUS QUERY" tokens.
+	if err := p.parseTokens([]Token{CONTINUOUS, QUERY}); err != nil {
+		return nil, err
+	}
+
+	// Read the id of the query to backfill.
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = ident
+
+	// Expect an "ON" keyword.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != ON {
+		return nil, newParseError(tokstr(tok, lit), []string{"ON"}, pos)
+	}
+
+	// Read the name of the database the query belongs to.
+	if ident, err = p.parseIdent(); err != nil {
+		return nil, err
+	}
+	stmt.Database = ident
+
+	// Parse the required FROM <time> TO <time> range.
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != FROM {
+		return nil, newParseError(tokstr(tok, lit), []string{"FROM"}, pos)
+	}
+	if stmt.StartTime, err = p.parseTimeLiteral(); err != nil {
+		return nil, err
+	}
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != TO {
+		return nil, newParseError(tokstr(tok, lit), []string{"TO"}, pos)
+	}
+	_, pos, _ := p.scanIgnoreWhitespace()
+	p.unscan()
+	if stmt.EndTime, err = p.parseTimeLiteral(); err != nil {
+		return nil, err
+	} else if !stmt.EndTime.After(stmt.StartTime) {
+		return nil, &ParseError{Message: "backfill end time must be after start time", Pos: pos}
+	}
+
+	return stmt, nil
+}
+
+// parseTimeLiteral parses a date or date time string literal.
+func (p *Parser) parseTimeLiteral() (time.Time, error) {
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	p.unscan()
+	if tok != STRING {
+		return time.Time{}, newParseError(tokstr(tok, lit), []string{"time"}, pos)
+	}
+
+	expr, err := p.parseUnaryExpr()
+	if err != nil {
+		return time.Time{}, err
+	} else if t, ok := expr.(*TimeLiteral); ok {
+		return t.Val, nil
+	}
+	return time.Time{}, newParseError(tokstr(tok, lit), []string{"time"}, pos)
+}
+
 // parseDropContinuousQueriesStatement parses a string and returns a DropContinuousQueryStatement.
 // This function assumes the "DROP CONTINUOUS" tokens have already been consumed.
 func (p *Parser) parseDropContinuousQueryStatement() (*DropContinuousQueryStatement, error) {
@@ -2330,8 +2780,23 @@ func (p *Parser) parseRegex() (*RegexLiteral, error) {
 		p.consumeWhitespace()
 	}
 
-	// If the next character is not a '/', then return nils.
+	// A bound parameter may hold a regex.
 	nextRune = p.peekRune()
+	if nextRune == '$' {
+		tok, pos, lit := p.scan()
+		if tok != REGEX {
+			p.unscan()
+			return nil, nil
+		}
+
+		re, err := regexp.Compile(lit)
+		if err != nil {
+			return nil, &ParseError{Message: err.Error(), Pos: pos}
+		}
+		return &RegexLiteral{Val: re}, nil
+	}
+
+	// If the next character is not a '/', then return nils.
 	if nextRune != '/' {
 		return nil, nil
 	}
@@ -2392,7 +2857,85 @@ func (p *Parser) parseCall(name string) (*Call, error) {
 }
 
 // scan returns the next token from the underlying scanner.
-func (p *Parser) scan() (tok Token, pos Pos, lit string) { return p.s.Scan() }
+func (p *Parser) scan() (tok Token, pos Pos, lit string) {
+	tok, pos, lit = p.s.Scan()
+	if tok == BOUNDPARAM {
+		tok, lit = p.bind(lit)
+	}
+	return
+}
+
+// bind returns the token and literal for the value bound to a parameter.
+// The value always becomes a single token so it can't change the structure
+// of the query. Returns an ILLEGAL token if the parameter isn't set or its
+// value can't be bound.
+func (p *Parser) bind(name string) (Token, string) {
+	v, ok := p.params[name]
+	if !ok {
+		return ILLEGAL, "$" + name
+	}
+
+	// Values with an explicit kind are given as a map with a single key.
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 1 {
+		for kind, value := range m {
+			if kind == "number" {
+				if tok, lit := bindNumber(value); tok != ILLEGAL {
+					return tok, lit
+				}
+				break
+			}
+
+			str, ok := value.(string)
+			if !ok {
+				break
+			}
+			switch kind {
+			case "identifier":
+				return IDENT, str
+			case "string", "time":
+				return STRING, str
+			case "duration":
+				if _, err := ParseDuration(str); err == nil {
+					return DURATION_VAL, str
+				}
+			case "regex":
+				return REGEX, str
+			}
+		}
+		return ILLEGAL, "$" + name
+	}
+
+	switch v := v.(type) {
+	case string:
+		return STRING, v
+	case bool:
+		if v {
+			return TRUE, ""
+		}
+		return FALSE, ""
+	}
+	if tok, lit := bindNumber(v); tok != ILLEGAL {
+		return tok, lit
+	}
+	return ILLEGAL, "$" + name
+}
+
+// bindNumber returns the NUMBER token and literal for a numeric parameter value.
+func bindNumber(v interface{}) (Token, string) {
+	switch v := v.(type) {
+	case float64:
+		return NUMBER, strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return NUMBER, strconv.FormatInt(v, 10)
+	case int:
+		return NUMBER, strconv.Itoa(v)
+	case json.Number:
+		if _, err := v.Float64(); err == nil {
+			return NUMBER, v.String()
+		}
+	}
+	return ILLEGAL, ""
+}
 
 // scanIgnoreWhitespace scans the next non-whitespace token.
 func (p *Parser) scanIgnoreWhitespace() (tok Token, pos Pos, lit string) {
@@ -51,6 +51,58 @@ func TestParser_ParseQuery_ParseError(t *testing.T) {
 	}
 }
 
+// Ensure the parser binds parameters to $name placeholders.
+func TestParser_ParseQuery_BoundParams(t *testing.T) {
+	for i, tt := range []struct {
+		s      string
+		params map[string]interface{}
+		out    string
+		err    string
+	}{
+		{
+			s:      `SELECT value FROM cpu WHERE host = $host AND value > $value AND enabled = $enabled`,
+			params: map[string]interface{}{"host": "server01' OR 1=1", "value": float64(10.5), "enabled": true},
+			out:    `SELECT value FROM cpu WHERE host = 'server01\' OR 1=1' AND value > 10.500 AND enabled = true`,
+		},
+		{
+			s: `SELECT mean($field) FROM $db.$rp.$m WHERE time > $start GROUP BY time($interval) LIMIT $limit`,
+			params: map[string]interface{}{
+				"field":    map[string]interface{}{"identifier": "value"},
+				"db":       map[string]interface{}{"identifier": "db0"},
+				"rp":       map[string]interface{}{"identifier": "rp0"},
+				"m":        map[string]interface{}{"identifier": "cpu; DROP DATABASE db0"},
+				"start":    map[string]interface{}{"time": "2015-01-01T00:00:00Z"},
+				"interval": map[string]interface{}{"duration": "10m"},
+				"limit":    json.Number("5"),
+			},
+			out: `SELECT mean(value) FROM db0.rp0."cpu; DROP DATABASE db0" WHERE time > '2015-01-01T00:00:00Z' GROUP BY time(10m) LIMIT 5`,
+		},
+		{
+			s:      `SELECT value FROM cpu WHERE host =~ $re`,
+			params: map[string]interface{}{"re": map[string]interface{}{"regex": "^server0[12]$"}},
+			out:    `SELECT value FROM cpu WHERE host =~ /^server0[12]$/`,
+		},
+		{
+			s:   `SELECT value FROM cpu WHERE host = $host`,
+			err: `found $host, expected identifier, string, number, bool at line 1, char 36`,
+		},
+		{
+			s:      `SELECT value FROM cpu WHERE time > now() - $d`,
+			params: map[string]interface{}{"d": map[string]interface{}{"duration": "10x"}},
+			err:    `found $d, expected identifier, string, number, bool at line 1, char 44`,
+		},
+	} {
+		p := influxql.NewParser(strings.NewReader(tt.s))
+		p.SetParams(tt.params)
+		q, err := p.ParseQuery()
+		if errstring(err) != tt.err {
+			t.Errorf("%d. %q: error mismatch:\n  exp=%s\n  got=%s", i, tt.s, tt.err, err)
+		} else if err == nil && q.String() != tt.out {
+			t.Errorf("%d. %q: unexpected query:\n  exp=%s\n  got=%s", i, tt.s, tt.out, q.String())
+		}
+	}
+}
+
 // Ensure the parser can parse strings into Statement ASTs.
 func TestParser_ParseStatement(t *testing.T) {
 	// For use in various tests.
@@ -1043,6 +1095,24 @@ func TestParser_ParseStatement(t *testing.T) {
 			stmt: &influxql.DropServerStatement{NodeID: 123, Force: true},
 		},
 
+		// ALTER SERVER statement
+		{
+			s:    `ALTER SERVER 123 STATE 'draining'`,
+			stmt: &influxql.AlterServerStatement{NodeID: 123, State: "draining"},
+		},
+		{
+			s: `ALTER SERVER 123 LABELS zone = 'us-east-1a', rack='r12' STATE 'active'`,
+			stmt: &influxql.AlterServerStatement{
+				NodeID: 123,
+				State:  "active",
+				Labels: map[string]string{"zone": "us-east-1a", "rack": "r12"},
+			},
+		},
+		{
+			s:    `ALTER SERVER 123 LABELS role = ''`,
+			stmt: &influxql.AlterServerStatement{NodeID: 123, Labels: map[string]string{"role": ""}},
+		},
+
 		// SHOW CONTINUOUS QUERIES statement
 		{
 			s:    `SHOW CONTINUOUS QUERIES`,
@@ -1174,6 +1244,57 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		// CREATE CONTINUOUS QUERY with RESAMPLE clause
+		{
+			s: `CREATE CONTINUOUS QUERY myquery ON testdb RESAMPLE EVERY 1m FOR 1h BEGIN SELECT count(field1) INTO measure1 FROM myseries GROUP BY time(5m) END`,
+			stmt: &influxql.CreateContinuousQueryStatement{
+				Name:          "myquery",
+				Database:      "testdb",
+				ResampleEvery: time.Minute,
+				ResampleFor:   time.Hour,
+				Source: &influxql.SelectStatement{
+					Fields:  []*influxql.Field{{Expr: &influxql.Call{Name: "count", Args: []influxql.Expr{&influxql.VarRef{Val: "field1"}}}}},
+					Target:  &influxql.Target{Measurement: &influxql.Measurement{Name: "measure1", IsTarget: true}},
+					Sources: []influxql.Source{&influxql.Measurement{Name: "myseries"}},
+					Dimensions: []*influxql.Dimension{
+						{
+							Expr: &influxql.Call{
+								Name: "time",
+								Args: []influxql.Expr{
+									&influxql.DurationLiteral{Val: 5 * time.Minute},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// CREATE CONTINUOUS QUERY with only RESAMPLE FOR
+		{
+			s: `CREATE CONTINUOUS QUERY myquery ON testdb RESAMPLE FOR 10m BEGIN SELECT count(field1) INTO measure1 FROM myseries GROUP BY time(5m) END`,
+			stmt: &influxql.CreateContinuousQueryStatement{
+				Name:        "myquery",
+				Database:    "testdb",
+				ResampleFor: 10 * time.Minute,
+				Source: &influxql.SelectStatement{
+					Fields:  []*influxql.Field{{Expr: &influxql.Call{Name: "count", Args: []influxql.Expr{&influxql.VarRef{Val: "field1"}}}}},
+					Target:  &influxql.Target{Measurement: &influxql.Measurement{Name: "measure1", IsTarget: true}},
+					Sources: []influxql.Source{&influxql.Measurement{Name: "myseries"}},
+					Dimensions: []*influxql.Dimension{
+						{
+							Expr: &influxql.Call{
+								Name: "time",
+								Args: []influxql.Expr{
+									&influxql.DurationLiteral{Val: 5 * time.Minute},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+
 		// CREATE DATABASE statement
 		{
 			s: `CREATE DATABASE testdb`,
@@ -1308,6 +1429,41 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		// ALTER CONTINUOUS QUERY statement
+		{
+			s: `ALTER CONTINUOUS QUERY myquery ON testdb BEGIN SELECT mean(field1) INTO measure1 FROM myseries GROUP BY time(10m) END`,
+			stmt: &influxql.AlterContinuousQueryStatement{
+				Name:     "myquery",
+				Database: "testdb",
+				Source: &influxql.SelectStatement{
+					Fields:  []*influxql.Field{{Expr: &influxql.Call{Name: "mean", Args: []influxql.Expr{&influxql.VarRef{Val: "field1"}}}}},
+					Target:  &influxql.Target{Measurement: &influxql.Measurement{Name: "measure1", IsTarget: true}},
+					Sources: []influxql.Source{&influxql.Measurement{Name: "myseries"}},
+					Dimensions: []*influxql.Dimension{
+						{
+							Expr: &influxql.Call{
+								Name: "time",
+								Args: []influxql.Expr{
+									&influxql.DurationLiteral{Val: 10 * time.Minute},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// BACKFILL CONTINUOUS QUERY statement
+		{
+			s: `BACKFILL CONTINUOUS QUERY myquery ON testdb FROM '2015-01-01' TO '2015-01-02T12:00:00Z'`,
+			stmt: &influxql.BackfillContinuousQueryStatement{
+				Name:      "myquery",
+				Database:  "testdb",
+				StartTime: mustParseTime("2015-01-01T00:00:00Z"),
+				EndTime:   mustParseTime("2015-01-02T12:00:00Z"),
+			},
+		},
+
 		// DROP CONTINUOUS QUERY statement
 		{
 			s:    `DROP CONTINUOUS QUERY myquery ON foo`,
@@ -1351,6 +1507,36 @@ func TestParser_ParseStatement(t *testing.T) {
 			stmt: &influxql.DropUserStatement{Name: "jdoe"},
 		},
 
+		// CREATE TOKEN statement
+		{
+			s:    `CREATE TOKEN telegraf FOR jdoe`,
+			stmt: &influxql.CreateTokenStatement{Name: "telegraf", Username: "jdoe"},
+		},
+
+		// CREATE TOKEN statement with duration
+		{
+			s:    `CREATE TOKEN telegraf FOR jdoe DURATION 30d`,
+			stmt: &influxql.CreateTokenStatement{Name: "telegraf", Username: "jdoe", Duration: 30 * 24 * time.Hour},
+		},
+
+		// DROP TOKEN statement
+		{
+			s:    `DROP TOKEN telegraf`,
+			stmt: &influxql.DropTokenStatement{Name: "telegraf"},
+		},
+
+		// SHOW TOKENS statement
+		{
+			s:    `SHOW TOKENS`,
+			stmt: &influxql.ShowTokensStatement{},
+		},
+
+		// SHOW TOKENS FOR statement
+		{
+			s:    `SHOW TOKENS FOR jdoe`,
+			stmt: &influxql.ShowTokensStatement{Username: "jdoe"},
+		},
+
 		// GRANT READ
 		{
 			s: `GRANT READ ON testdb TO jdoe`,
@@ -1497,6 +1683,19 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		// CREATE RETENTION POLICY ... PLACEMENT
+		{
+			s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 2 PLACEMENT 'zone-aware' DEFAULT`,
+			stmt: &influxql.CreateRetentionPolicyStatement{
+				Name:        "policy1",
+				Database:    "testdb",
+				Duration:    time.Hour,
+				Replication: 2,
+				Placement:   "zone-aware",
+				Default:     true,
+			},
+		},
+
 		// ALTER RETENTION POLICY
 		{
 			s:    `ALTER RETENTION POLICY policy1 ON testdb DURATION 1m REPLICATION 4 DEFAULT`,
@@ -1538,6 +1737,16 @@ func TestParser_ParseStatement(t *testing.T) {
 			stmt: newAlterRetentionPolicyStatement("default", "testdb", -1, 4, false),
 		},
 
+		// ALTER RETENTION POLICY with PLACEMENT
+		{
+			s: `ALTER RETENTION POLICY policy1 ON testdb PLACEMENT 'consistent-hash'`,
+			stmt: &influxql.AlterRetentionPolicyStatement{
+				Name:      "policy1",
+				Database:  "testdb",
+				Placement: func(s string) *string { return &s }("consistent-hash"),
+			},
+		},
+
 		// SHOW STATS
 		{
 			s: `SHOW STATS`,
@@ -1558,6 +1767,48 @@ func TestParser_ParseStatement(t *testing.T) {
 			stmt: &influxql.ShowShardGroupsStatement{},
 		},
 
+		// SHOW SHARD DIFFERENCES
+		{
+			s:    `SHOW SHARD DIFFERENCES`,
+			stmt: &influxql.ShowShardDifferencesStatement{},
+		},
+
+		// REPAIR SHARD
+		{
+			s:    `REPAIR SHARD 2`,
+			stmt: &influxql.RepairShardStatement{ID: 2},
+		},
+
+		// COPY SHARD
+		{
+			s:    `COPY SHARD 2 FROM 1 TO 3`,
+			stmt: &influxql.CopyShardStatement{ID: 2, From: 1, To: 3},
+		},
+
+		// MOVE SHARD
+		{
+			s:    `MOVE SHARD 2 FROM 1 TO 3`,
+			stmt: &influxql.MoveShardStatement{ID: 2, From: 1, To: 3},
+		},
+
+		// SHOW HINTED HANDOFF
+		{
+			s:    `SHOW HINTED HANDOFF`,
+			stmt: &influxql.ShowHintedHandoffStatement{},
+		},
+
+		// PURGE HINTED HANDOFF
+		{
+			s:    `PURGE HINTED HANDOFF FOR 2`,
+			stmt: &influxql.PurgeHintedHandoffStatement{NodeID: 2},
+		},
+
+		// REPLAY HINTED HANDOFF
+		{
+			s:    `REPLAY HINTED HANDOFF FOR 2`,
+			stmt: &influxql.ReplayHintedHandoffStatement{NodeID: 2},
+		},
+
 		// SHOW SHARDS
 		{
 			s:    `SHOW SHARDS`,
@@ -1605,10 +1856,10 @@ func TestParser_ParseStatement(t *testing.T) {
 		},
 
 		// Errors
-		{s: ``, err: `found EOF, expected SELECT, DELETE, SHOW, CREATE, DROP, GRANT, REVOKE, ALTER, SET at line 1, char 1`},
+		{s: ``, err: `found EOF, expected SELECT, DELETE, SHOW, CREATE, DROP, GRANT, REVOKE, ALTER, SET, BACKFILL, REPAIR, COPY, MOVE, PURGE, REPLAY at line 1, char 1`},
 		{s: `SELECT`, err: `found EOF, expected identifier, string, number, bool at line 1, char 8`},
 		{s: `SELECT time FROM myseries`, err: `at least 1 non-time field must be queried`},
-		{s: `blah blah`, err: `found blah, expected SELECT, DELETE, SHOW, CREATE, DROP, GRANT, REVOKE, ALTER, SET at line 1, char 1`},
+		{s: `blah blah`, err: `found blah, expected SELECT, DELETE, SHOW, CREATE, DROP, GRANT, REVOKE, ALTER, SET, BACKFILL, REPAIR, COPY, MOVE, PURGE, REPLAY at line 1, char 1`},
 		{s: `SELECT field1 X`, err: `found X, expected FROM at line 1, char 15`},
 		{s: `SELECT field1 FROM "series" WHERE X +;`, err: `found ;, expected identifier, string, number, bool at line 1, char 38`},
 		{s: `SELECT field1 FROM myseries GROUP`, err: `found EOF, expected BY at line 1, char 35`},
@@ -1702,20 +1953,40 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `SHOW RETENTION POLICIES`, err: `found EOF, expected ON at line 1, char 25`},
 		{s: `SHOW RETENTION POLICIES mydb`, err: `found mydb, expected ON at line 1, char 25`},
 		{s: `SHOW RETENTION POLICIES ON`, err: `found EOF, expected identifier at line 1, char 28`},
-		{s: `SHOW SHARD`, err: `found EOF, expected GROUPS at line 1, char 12`},
-		{s: `SHOW FOO`, err: `found FOO, expected CONTINUOUS, DATABASES, DIAGNOSTICS, FIELD, GRANTS, MEASUREMENTS, RETENTION, SERIES, SERVERS, SHARD, SHARDS, STATS, SUBSCRIPTIONS, TAG, USERS at line 1, char 6`},
+		{s: `SHOW SHARD`, err: `found EOF, expected GROUPS, DIFFERENCES at line 1, char 12`},
+		{s: `REPAIR`, err: `found EOF, expected SHARD at line 1, char 8`},
+		{s: `REPAIR SHARD`, err: `found EOF, expected number at line 1, char 14`},
+		{s: `COPY`, err: `found EOF, expected SHARD at line 1, char 6`},
+		{s: `COPY SHARD 1`, err: `found EOF, expected FROM at line 1, char 13`},
+		{s: `COPY SHARD 1 FROM 2`, err: `found EOF, expected TO at line 1, char 20`},
+		{s: `MOVE SHARD 1 FROM 2 TO`, err: `found EOF, expected number at line 1, char 24`},
+		{s: `SHOW HINTED`, err: `found EOF, expected HANDOFF at line 1, char 13`},
+		{s: `PURGE HINTED HANDOFF`, err: `found EOF, expected FOR at line 1, char 22`},
+		{s: `REPLAY HINTED HANDOFF FOR`, err: `found EOF, expected number at line 1, char 27`},
+		{s: `REPLAY HANDOFF`, err: `found HANDOFF, expected HINTED at line 1, char 8`},
+		{s: `SHOW FOO`, err: `found FOO, expected CONTINUOUS, DATABASES, DIAGNOSTICS, FIELD, GRANTS, HINTED, MEASUREMENTS, RETENTION, SERIES, SERVERS, SHARD, SHARDS, STATS, SUBSCRIPTIONS, TAG, TOKENS, USERS at line 1, char 6`},
 		{s: `SHOW STATS FOR`, err: `found EOF, expected string at line 1, char 16`},
 		{s: `SHOW DIAGNOSTICS FOR`, err: `found EOF, expected string at line 1, char 22`},
 		{s: `SHOW GRANTS`, err: `found EOF, expected FOR at line 1, char 13`},
 		{s: `SHOW GRANTS FOR`, err: `found EOF, expected identifier at line 1, char 17`},
 		{s: `DROP CONTINUOUS`, err: `found EOF, expected QUERY at line 1, char 17`},
+		{s: `BACKFILL`, err: `found EOF, expected CONTINUOUS at line 1, char 10`},
+		{s: `BACKFILL CONTINUOUS QUERY myquery`, err: `found EOF, expected ON at line 1, char 35`},
+		{s: `BACKFILL CONTINUOUS QUERY myquery ON testdb`, err: `found EOF, expected FROM at line 1, char 45`},
+		{s: `BACKFILL CONTINUOUS QUERY myquery ON testdb FROM now()`, err: `found now, expected time at line 1, char 50`},
+		{s: `BACKFILL CONTINUOUS QUERY myquery ON testdb FROM '2015-01-01'`, err: `found EOF, expected TO at line 1, char 62`},
+		{s: `BACKFILL CONTINUOUS QUERY myquery ON testdb FROM '2015-01-02' TO '2015-01-01'`, err: `backfill end time must be after start time at line 1, char 65`},
 		{s: `DROP CONTINUOUS QUERY`, err: `found EOF, expected identifier at line 1, char 23`},
 		{s: `DROP CONTINUOUS QUERY myquery`, err: `found EOF, expected ON at line 1, char 31`},
 		{s: `DROP CONTINUOUS QUERY myquery ON`, err: `found EOF, expected identifier at line 1, char 34`},
 		{s: `CREATE CONTINUOUS`, err: `found EOF, expected QUERY at line 1, char 19`},
 		{s: `CREATE CONTINUOUS QUERY`, err: `found EOF, expected identifier at line 1, char 25`},
-		{s: `DROP FOO`, err: `found FOO, expected SERIES, CONTINUOUS, MEASUREMENT, SERVER, SUBSCRIPTION at line 1, char 6`},
-		{s: `CREATE FOO`, err: `found FOO, expected CONTINUOUS, DATABASE, USER, RETENTION, SUBSCRIPTION at line 1, char 8`},
+		{s: `CREATE CONTINUOUS QUERY myquery ON testdb RESAMPLE BEGIN`, err: `found BEGIN, expected EVERY, FOR at line 1, char 52`},
+		{s: `CREATE CONTINUOUS QUERY myquery ON testdb RESAMPLE EVERY BEGIN`, err: `found BEGIN, expected duration at line 1, char 58`},
+		{s: `CREATE CONTINUOUS QUERY myquery ON testdb RESAMPLE EVERY 0s BEGIN`, err: `RESAMPLE duration must be greater than zero at line 1, char 58`},
+		{s: `CREATE CONTINUOUS QUERY myquery ON testdb RESAMPLE FOR 1m BEGIN SELECT count(field1) INTO measure1 FROM myseries GROUP BY time(5m) END`, err: `FOR duration must be >= GROUP BY time duration: must be a minimum of 5m, got 1m`},
+		{s: `DROP FOO`, err: `found FOO, expected SERIES, CONTINUOUS, MEASUREMENT, SERVER, SUBSCRIPTION, TOKEN at line 1, char 6`},
+		{s: `CREATE FOO`, err: `found FOO, expected CONTINUOUS, DATABASE, USER, RETENTION, SUBSCRIPTION, TOKEN at line 1, char 8`},
 		{s: `CREATE DATABASE`, err: `found EOF, expected identifier at line 1, char 17`},
 		{s: `CREATE DATABASE "testdb" WITH`, err: `found EOF, expected DURATION, REPLICATION, NAME at line 1, char 31`},
 		{s: `CREATE DATABASE "testdb" WITH DURATION`, err: `found EOF, expected duration at line 1, char 40`},
@@ -1736,6 +2007,13 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `DROP RETENTION POLICY "1h.cpu"`, err: `found EOF, expected ON at line 1, char 31`},
 		{s: `DROP RETENTION POLICY "1h.cpu" ON`, err: `found EOF, expected identifier at line 1, char 35`},
 		{s: `DROP USER`, err: `found EOF, expected identifier at line 1, char 11`},
+		{s: `DROP TOKEN`, err: `found EOF, expected identifier at line 1, char 12`},
+		{s: `CREATE TOKEN`, err: `found EOF, expected identifier at line 1, char 14`},
+		{s: `CREATE TOKEN telegraf`, err: `found EOF, expected FOR at line 1, char 23`},
+		{s: `CREATE TOKEN telegraf FOR`, err: `found EOF, expected identifier at line 1, char 27`},
+		{s: `CREATE TOKEN telegraf FOR jdoe DURATION`, err: `found EOF, expected duration at line 1, char 41`},
+		{s: `CREATE TOKEN telegraf FOR jdoe DURATION 0s`, err: `token duration must be greater than zero at line 1, char 41`},
+		{s: `SHOW TOKENS FOR`, err: `found EOF, expected identifier at line 1, char 17`},
 		{s: `DROP SUBSCRIPTION`, err: `found EOF, expected identifier at line 1, char 19`},
 		{s: `DROP SUBSCRIPTION "name"`, err: `found EOF, expected ON at line 1, char 25`},
 		{s: `DROP SUBSCRIPTION "name" ON `, err: `found EOF, expected identifier at line 1, char 30`},
@@ -1830,12 +2108,21 @@ func TestParser_ParseStatement(t *testing.T) {
 		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 3.14`, err: `number must be an integer at line 1, char 67`},
 		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 0`, err: `invalid value 0: must be 1 <= n <= 2147483647 at line 1, char 67`},
 		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION bad`, err: `found bad, expected number at line 1, char 67`},
-		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 1 foo`, err: `found foo, expected DEFAULT at line 1, char 69`},
-		{s: `ALTER`, err: `found EOF, expected RETENTION at line 1, char 7`},
+		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 1 foo`, err: `found foo, expected PLACEMENT, DEFAULT at line 1, char 69`},
+		{s: `CREATE RETENTION POLICY policy1 ON testdb DURATION 1h REPLICATION 1 PLACEMENT`, err: `found EOF, expected string at line 1, char 79`},
+		{s: `ALTER`, err: `found EOF, expected RETENTION, CONTINUOUS, SERVER at line 1, char 7`},
+		{s: `ALTER SERVER`, err: `found EOF, expected number at line 1, char 14`},
+		{s: `ALTER SERVER 1`, err: `found EOF, expected STATE, LABELS at line 1, char 15`},
+		{s: `ALTER SERVER 1 STATE draining`, err: `found draining, expected string at line 1, char 22`},
+		{s: `ALTER SERVER 1 LABELS zone`, err: `found EOF, expected = at line 1, char 28`},
+		{s: `ALTER SERVER 1 LABELS zone = 'a',`, err: `found EOF, expected identifier at line 1, char 34`},
+		{s: `ALTER CONTINUOUS`, err: `found EOF, expected QUERY at line 1, char 18`},
+		{s: `ALTER CONTINUOUS QUERY myquery`, err: `found EOF, expected ON at line 1, char 32`},
+		{s: `ALTER CONTINUOUS QUERY myquery ON testdb`, err: `found EOF, expected BEGIN at line 1, char 42`},
 		{s: `ALTER RETENTION`, err: `found EOF, expected POLICY at line 1, char 17`},
 		{s: `ALTER RETENTION POLICY`, err: `found EOF, expected identifier at line 1, char 24`},
 		{s: `ALTER RETENTION POLICY policy1`, err: `found EOF, expected ON at line 1, char 32`}, {s: `ALTER RETENTION POLICY policy1 ON`, err: `found EOF, expected identifier at line 1, char 35`},
-		{s: `ALTER RETENTION POLICY policy1 ON testdb`, err: `found EOF, expected DURATION, RETENTION, DEFAULT at line 1, char 42`},
+		{s: `ALTER RETENTION POLICY policy1 ON testdb`, err: `found EOF, expected DURATION, RETENTION, PLACEMENT, DEFAULT at line 1, char 42`},
 		{s: `SET`, err: `found EOF, expected PASSWORD at line 1, char 5`},
 		{s: `SET PASSWORD`, err: `found EOF, expected FOR at line 1, char 14`},
 		{s: `SET PASSWORD something`, err: `found something, expected FOR at line 1, char 14`},
@@ -1859,6 +2146,10 @@ func TestParser_ParseStatement(t *testing.T) {
 			if st != nil && st.Source != nil {
 				tt.stmt.(*influxql.CreateContinuousQueryStatement).Source.GroupByInterval()
 			}
+		} else if st, ok := stmt.(*influxql.AlterContinuousQueryStatement); ok {
+			if st != nil && st.Source != nil {
+				tt.stmt.(*influxql.AlterContinuousQueryStatement).Source.GroupByInterval()
+			}
 		}
 
 		if !reflect.DeepEqual(tt.err, errstring(err)) {
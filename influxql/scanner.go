@@ -97,6 +97,12 @@ func (s *Scanner) Scan() (tok Token, pos Pos, lit string) {
 		return SEMICOLON, pos, ""
 	case ':':
 		return COLON, pos, ""
+	case '$':
+		if ch1, _ := s.r.read(); isIdentFirstChar(ch1) {
+			s.r.unread()
+			return BOUNDPARAM, pos, ScanBareIdent(s.r)
+		}
+		s.r.unread()
 	}
 
 	return ILLEGAL, pos, string(ch0)
@@ -29,6 +29,12 @@ func TestScanner_Scan(t *testing.T) {
 		{s: " \n\t \r\n\t", tok: influxql.WS, lit: " \n\t \n\t"},
 		{s: " foo", tok: influxql.WS, lit: " "},
 
+		// Bound parameters
+		{s: `$host`, tok: influxql.BOUNDPARAM, lit: `host`},
+		{s: `$host_1 `, tok: influxql.BOUNDPARAM, lit: `host_1`},
+		{s: `$`, tok: influxql.ILLEGAL, lit: `$`},
+		{s: `$1`, tok: influxql.ILLEGAL, lit: `$`},
+
 		// Numeric operators
 		{s: `+`, tok: influxql.ADD},
 		{s: `-`, tok: influxql.SUB},
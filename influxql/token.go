@@ -25,6 +25,7 @@ const (
 	FALSE        // false
 	REGEX        // Regular expressions
 	BADREGEX     // `.*
+	BOUNDPARAM   // $param
 	literal_end
 
 	operator_beg
@@ -61,10 +62,12 @@ const (
 	ANY
 	AS
 	ASC
+	BACKFILL
 	BEGIN
 	BY
 	CREATE
 	CONTINUOUS
+	COPY
 	DATABASE
 	DATABASES
 	DEFAULT
@@ -72,10 +75,12 @@ const (
 	DESC
 	DESTINATIONS
 	DIAGNOSTICS
+	DIFFERENCES
 	DISTINCT
 	DROP
 	DURATION
 	END
+	EVERY
 	EXISTS
 	EXPLAIN
 	FIELD
@@ -86,6 +91,8 @@ const (
 	GRANTS
 	GROUP
 	GROUPS
+	HANDOFF
+	HINTED
 	IF
 	IN
 	INF
@@ -94,22 +101,29 @@ const (
 	INTO
 	KEY
 	KEYS
+	LABELS
 	LIMIT
 	MEASUREMENT
 	MEASUREMENTS
+	MOVE
 	NAME
 	NOT
 	OFFSET
 	ON
 	ORDER
 	PASSWORD
+	PLACEMENT
 	POLICY
 	POLICIES
 	PRIVILEGES
+	PURGE
 	QUERIES
 	QUERY
 	READ
+	REPAIR
+	REPLAY
 	REPLICATION
+	RESAMPLE
 	RETENTION
 	REVOKE
 	SELECT
@@ -122,11 +136,14 @@ const (
 	SHARDS
 	SLIMIT
 	SOFFSET
+	STATE
 	STATS
 	SUBSCRIPTION
 	SUBSCRIPTIONS
 	TAG
 	TO
+	TOKEN
+	TOKENS
 	USER
 	USERS
 	VALUES
@@ -150,6 +167,7 @@ var tokens = [...]string{
 	TRUE:         "TRUE",
 	FALSE:        "FALSE",
 	REGEX:        "REGEX",
+	BOUNDPARAM:   "BOUNDPARAM",
 
 	ADD: "+",
 	SUB: "-",
@@ -180,10 +198,12 @@ var tokens = [...]string{
 	ANY:           "ANY",
 	AS:            "AS",
 	ASC:           "ASC",
+	BACKFILL:      "BACKFILL",
 	BEGIN:         "BEGIN",
 	BY:            "BY",
 	CREATE:        "CREATE",
 	CONTINUOUS:    "CONTINUOUS",
+	COPY:          "COPY",
 	DATABASE:      "DATABASE",
 	DATABASES:     "DATABASES",
 	DEFAULT:       "DEFAULT",
@@ -191,10 +211,12 @@ var tokens = [...]string{
 	DESC:          "DESC",
 	DESTINATIONS:  "DESTINATIONS",
 	DIAGNOSTICS:   "DIAGNOSTICS",
+	DIFFERENCES:   "DIFFERENCES",
 	DISTINCT:      "DISTINCT",
 	DROP:          "DROP",
 	DURATION:      "DURATION",
 	END:           "END",
+	EVERY:         "EVERY",
 	EXISTS:        "EXISTS",
 	EXPLAIN:       "EXPLAIN",
 	FIELD:         "FIELD",
@@ -205,6 +227,8 @@ var tokens = [...]string{
 	GRANTS:        "GRANTS",
 	GROUP:         "GROUP",
 	GROUPS:        "GROUPS",
+	HANDOFF:       "HANDOFF",
+	HINTED:        "HINTED",
 	IF:            "IF",
 	IN:            "IN",
 	INF:           "INF",
@@ -213,22 +237,29 @@ var tokens = [...]string{
 	INTO:          "INTO",
 	KEY:           "KEY",
 	KEYS:          "KEYS",
+	LABELS:        "LABELS",
 	LIMIT:         "LIMIT",
 	MEASUREMENT:   "MEASUREMENT",
 	MEASUREMENTS:  "MEASUREMENTS",
+	MOVE:          "MOVE",
 	NAME:          "NAME",
 	NOT:           "NOT",
 	OFFSET:        "OFFSET",
 	ON:            "ON",
 	ORDER:         "ORDER",
 	PASSWORD:      "PASSWORD",
+	PLACEMENT:     "PLACEMENT",
 	POLICY:        "POLICY",
 	POLICIES:      "POLICIES",
 	PRIVILEGES:    "PRIVILEGES",
+	PURGE:         "PURGE",
 	QUERIES:       "QUERIES",
 	QUERY:         "QUERY",
 	READ:          "READ",
+	REPAIR:        "REPAIR",
+	REPLAY:        "REPLAY",
 	REPLICATION:   "REPLICATION",
+	RESAMPLE:      "RESAMPLE",
 	RETENTION:     "RETENTION",
 	REVOKE:        "REVOKE",
 	SELECT:        "SELECT",
@@ -241,11 +272,14 @@ var tokens = [...]string{
 	SHARDS:        "SHARDS",
 	SLIMIT:        "SLIMIT",
 	SOFFSET:       "SOFFSET",
+	STATE:         "STATE",
 	STATS:         "STATS",
 	SUBSCRIPTION:  "SUBSCRIPTION",
 	SUBSCRIPTIONS: "SUBSCRIPTIONS",
 	TAG:           "TAG",
 	TO:            "TO",
+	TOKEN:         "TOKEN",
+	TOKENS:        "TOKENS",
 	USER:          "USER",
 	USERS:         "USERS",
 	VALUES:        "VALUES",
@@ -43,3 +43,34 @@ func NewStatistics(key, name string, tags map[string]string) *expvar.Map {
 
 	return statMap
 }
+
+var (
+	gaugesMu sync.RWMutex
+	gauges   = make(map[string]map[string]bool)
+)
+
+// RegisterGauges declares values of the statistics with the given name that
+// can go down, such as active connections. Exporters report every other value
+// as a counter so statistics providers should register their gauges, usually
+// from an init function.
+func RegisterGauges(name string, values ...string) {
+	gaugesMu.Lock()
+	defer gaugesMu.Unlock()
+
+	m := gauges[name]
+	if m == nil {
+		m = make(map[string]bool)
+		gauges[name] = m
+	}
+	for _, v := range values {
+		m[v] = true
+	}
+}
+
+// IsGauge returns true if the value of the named statistics was registered
+// as a gauge.
+func IsGauge(name, value string) bool {
+	gaugesMu.RLock()
+	defer gaugesMu.RUnlock()
+	return gauges[name][value]
+}
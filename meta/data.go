@@ -31,6 +31,7 @@ type Data struct {
 	Nodes     []NodeInfo
 	Databases []DatabaseInfo
 	Users     []UserInfo
+	Tokens    []TokenInfo
 
 	MaxNodeID       uint64
 	MaxShardGroupID uint64
@@ -57,6 +58,65 @@ func (data *Data) NodeByHost(host string) *NodeInfo {
 	return nil
 }
 
+// SetNodeLabels replaces the labels of a node.
+func (data *Data) SetNodeLabels(id uint64, labels map[string]string) error {
+	ni := data.Node(id)
+	if ni == nil {
+		return ErrNodeNotFound
+	}
+
+	ni.Labels = nil
+	if len(labels) > 0 {
+		ni.Labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			ni.Labels[k] = v
+		}
+	}
+	return nil
+}
+
+// SetNodeState sets the state of a node. A node can only be decommissioned
+// once it owns no shards.
+func (data *Data) SetNodeState(id uint64, state string) error {
+	ni := data.Node(id)
+	if ni == nil {
+		return ErrNodeNotFound
+	}
+
+	switch state {
+	case NodeStateActive, NodeStateDraining:
+	case NodeStateDecommissioned:
+		if data.ownsShards(id) {
+			return ErrNodeNotDrained
+		}
+	default:
+		return ErrInvalidNodeState
+	}
+
+	ni.State = state
+	return nil
+}
+
+// ownsShards returns true if the node owns a shard in a shard group that
+// hasn't been deleted.
+func (data *Data) ownsShards(id uint64) bool {
+	for _, d := range data.Databases {
+		for _, rp := range d.RetentionPolicies {
+			for _, sg := range rp.ShardGroups {
+				if sg.Deleted() {
+					continue
+				}
+				for _, s := range sg.Shards {
+					if s.OwnedBy(id) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
 // CreateNode adds a node to the metadata.
 func (data *Data) CreateNode(host string) error {
 	// Ensure a node with the same host doesn't already exist.
@@ -91,15 +151,16 @@ func (data *Data) DeleteNode(id uint64, force bool) error {
 		return ErrNodeUnableToDropFinalNode
 	}
 
-	// Determine if there are any any non-replicated nodes and force was not specified
+	// Determine if the node holds the only copy of a shard and force was not specified.
+	// Shards of replicated policies can lose their other owners to failed copies
+	// and drops so every shard is checked.
 	if !force {
 		for _, d := range data.Databases {
 			for _, rp := range d.RetentionPolicies {
-				// ignore replicated retention policies
-				if rp.ReplicaN > 1 {
-					continue
-				}
 				for _, sg := range rp.ShardGroups {
+					if sg.Deleted() {
+						continue
+					}
 					for _, s := range sg.Shards {
 						if s.OwnedBy(id) && len(s.Owners) == 1 {
 							return ErrShardNotReplicated
@@ -201,6 +262,8 @@ func (data *Data) CreateRetentionPolicy(database string, rpi *RetentionPolicyInf
 		return ErrRetentionPolicyNameRequired
 	} else if rpi.ReplicaN < 1 {
 		return ErrReplicationFactorTooLow
+	} else if _, err := shardPlacer(rpi.ShardPlacement); err != nil {
+		return err
 	}
 
 	// Find database.
@@ -217,6 +280,7 @@ func (data *Data) CreateRetentionPolicy(database string, rpi *RetentionPolicyInf
 		Duration:           rpi.Duration,
 		ShardGroupDuration: shardGroupDuration(rpi.Duration),
 		ReplicaN:           rpi.ReplicaN,
+		ShardPlacement:     rpi.ShardPlacement,
 	})
 
 	return nil
@@ -269,6 +333,13 @@ func (data *Data) UpdateRetentionPolicy(database, name string, rpu *RetentionPol
 		return ErrRetentionPolicyDurationTooLow
 	}
 
+	// Only registered placement strategies can be used.
+	if rpu.ShardPlacement != nil {
+		if _, err := shardPlacer(*rpu.ShardPlacement); err != nil {
+			return err
+		}
+	}
+
 	// Update fields.
 	if rpu.Name != nil {
 		rpi.Name = *rpu.Name
@@ -280,6 +351,9 @@ func (data *Data) UpdateRetentionPolicy(database, name string, rpu *RetentionPol
 	if rpu.ReplicaN != nil {
 		rpi.ReplicaN = *rpu.ReplicaN
 	}
+	if rpu.ShardPlacement != nil {
+		rpi.ShardPlacement = *rpu.ShardPlacement
+	}
 
 	return nil
 }
@@ -367,23 +441,41 @@ func (data *Data) CreateShardGroup(database, policy string, timestamp time.Time)
 		return influxdb.ErrRetentionPolicyNotFound(policy)
 	}
 
+	// Find the placement strategy of the policy.
+	placer, err := shardPlacer(rpi.ShardPlacement)
+	if err != nil {
+		return err
+	}
+
 	// Verify that shard group doesn't already exist for this timestamp.
 	if rpi.ShardGroupByTimestamp(timestamp) != nil {
 		return ErrShardGroupExists
 	}
 
+	// Only active nodes get new shards. Draining and decommissioned nodes
+	// are on their way out of the cluster.
+	var nodes []NodeInfo
+	for _, ni := range data.Nodes {
+		if ni.Active() {
+			nodes = append(nodes, ni)
+		}
+	}
+	if len(nodes) == 0 {
+		return ErrActiveNodesRequired
+	}
+
 	// Require at least one replica but no more replicas than nodes.
 	replicaN := rpi.ReplicaN
 	if replicaN == 0 {
 		replicaN = 1
-	} else if replicaN > len(data.Nodes) {
-		replicaN = len(data.Nodes)
+	} else if replicaN > len(nodes) {
+		replicaN = len(nodes)
 	}
 
 	// Determine shard count by node count divided by replication factor.
 	// This will ensure nodes will get distributed across nodes evenly and
 	// replicated the correct number of times.
-	shardN := len(data.Nodes) / replicaN
+	shardN := len(nodes) / replicaN
 
 	// Create the shard group.
 	data.MaxShardGroupID++
@@ -391,6 +483,7 @@ func (data *Data) CreateShardGroup(database, policy string, timestamp time.Time)
 	sgi.ID = data.MaxShardGroupID
 	sgi.StartTime = timestamp.Truncate(rpi.ShardGroupDuration).UTC()
 	sgi.EndTime = sgi.StartTime.Add(rpi.ShardGroupDuration).UTC()
+	sgi.ShardPlacement = rpi.ShardPlacement
 
 	// Create shards on the group.
 	sgi.Shards = make([]ShardInfo, shardN)
@@ -399,17 +492,15 @@ func (data *Data) CreateShardGroup(database, policy string, timestamp time.Time)
 		sgi.Shards[i] = ShardInfo{ID: data.MaxShardID}
 	}
 
-	// Assign data nodes to shards via round robin.
-	// Start from a repeatably "random" place in the node list.
-	nodeIndex := int(data.Index % uint64(len(data.Nodes)))
-	for i := range sgi.Shards {
-		si := &sgi.Shards[i]
-		for j := 0; j < replicaN; j++ {
-			nodeID := data.Nodes[nodeIndex%len(data.Nodes)].ID
-			si.Owners = append(si.Owners, ShardOwner{NodeID: nodeID})
-			nodeIndex++
-		}
-	}
+	// Assign data nodes to shards.
+	placer.PlaceShards(&ShardPlacementRequest{
+		Database:        database,
+		RetentionPolicy: policy,
+		ShardGroup:      &sgi,
+		Nodes:           nodes,
+		ReplicaN:        replicaN,
+		Index:           data.Index,
+	})
 
 	// Retention policy has a new shard group, so update the policy. Shard
 	// Groups must be stored in sorted order, as other parts of the system
@@ -441,6 +532,58 @@ func (data *Data) DeleteShardGroup(database, policy string, id uint64) error {
 	return ErrShardGroupNotFound
 }
 
+// AddShardOwner adds a node to the owners of a shard.
+func (data *Data) AddShardOwner(id, nodeID uint64) error {
+	if data.Node(nodeID) == nil {
+		return ErrNodeNotFound
+	}
+
+	sh := data.shard(id)
+	if sh == nil {
+		return ErrShardNotFound
+	} else if sh.OwnedBy(nodeID) {
+		return ErrShardOwnerExists
+	}
+	sh.Owners = append(sh.Owners, ShardOwner{NodeID: nodeID})
+	return nil
+}
+
+// RemoveShardOwner removes a node from the owners of a shard.
+func (data *Data) RemoveShardOwner(id, nodeID uint64) error {
+	sh := data.shard(id)
+	if sh == nil {
+		return ErrShardNotFound
+	} else if !sh.OwnedBy(nodeID) {
+		return ErrShardOwnerNotFound
+	}
+
+	var owners []ShardOwner
+	for _, o := range sh.Owners {
+		if o.NodeID != nodeID {
+			owners = append(owners, o)
+		}
+	}
+	sh.Owners = owners
+	return nil
+}
+
+// shard returns a reference to a shard by id. Returns nil if not found.
+func (data *Data) shard(id uint64) *ShardInfo {
+	for di := range data.Databases {
+		for ri := range data.Databases[di].RetentionPolicies {
+			rp := &data.Databases[di].RetentionPolicies[ri]
+			for gi := range rp.ShardGroups {
+				for si := range rp.ShardGroups[gi].Shards {
+					if rp.ShardGroups[gi].Shards[si].ID == id {
+						return &rp.ShardGroups[gi].Shards[si]
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // CreateContinuousQuery adds a named continuous query to a database.
 func (data *Data) CreateContinuousQuery(database, name, query string) error {
 	di := data.Database(database)
@@ -456,14 +599,56 @@ func (data *Data) CreateContinuousQuery(database, name, query string) error {
 	}
 
 	// Append new query.
-	di.ContinuousQueries = append(di.ContinuousQueries, ContinuousQueryInfo{
-		Name:  name,
-		Query: query,
-	})
+	di.ContinuousQueries = append(di.ContinuousQueries, newContinuousQueryInfo(name, query))
 
 	return nil
 }
 
+// UpdateContinuousQuery replaces the query of an existing continuous query.
+func (data *Data) UpdateContinuousQuery(database, name, query string) error {
+	di := data.Database(database)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	for i := range di.ContinuousQueries {
+		if di.ContinuousQueries[i].Name == name {
+			// Keep the lease so the owner doesn't change when a query is altered.
+			cqi := newContinuousQueryInfo(name, query)
+			cqi.LeaseNodeID = di.ContinuousQueries[i].LeaseNodeID
+			cqi.LeaseExpiration = di.ContinuousQueries[i].LeaseExpiration
+			di.ContinuousQueries[i] = cqi
+			return nil
+		}
+	}
+	return ErrContinuousQueryNotFound
+}
+
+// AcquireContinuousQueryLease grants nodeID the right to run a continuous query
+// until expiration. The lease can only be taken from another node once it has
+// expired as of now.
+func (data *Data) AcquireContinuousQueryLease(database, name string, nodeID uint64, now, expiration time.Time) error {
+	di := data.Database(database)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	for i := range di.ContinuousQueries {
+		cqi := &di.ContinuousQueries[i]
+		if cqi.Name != name {
+			continue
+		}
+
+		if cqi.LeaseNodeID != 0 && cqi.LeaseNodeID != nodeID && now.Before(cqi.LeaseExpiration) {
+			return ErrContinuousQueryLeaseHeld
+		}
+		cqi.LeaseNodeID = nodeID
+		cqi.LeaseExpiration = expiration
+		return nil
+	}
+	return ErrContinuousQueryNotFound
+}
+
 // DropContinuousQuery removes a continuous query.
 func (data *Data) DropContinuousQuery(database, name string) error {
 	di := data.Database(database)
@@ -552,11 +737,12 @@ func (data *Data) CreateUser(name, hash string, admin bool) error {
 	return nil
 }
 
-// DropUser removes an existing user by name.
+// DropUser removes an existing user by name along with the user's tokens.
 func (data *Data) DropUser(name string) error {
 	for i := range data.Users {
 		if data.Users[i].Name == name {
 			data.Users = append(data.Users[:i], data.Users[i+1:]...)
+			data.dropUserTokens(name)
 			return nil
 		}
 	}
@@ -627,6 +813,69 @@ func (data *Data) UserPrivilege(name, database string) (*influxql.Privilege, err
 	return influxql.NewPrivilege(influxql.NoPrivileges), nil
 }
 
+// Token returns a token by name.
+func (data *Data) Token(name string) *TokenInfo {
+	for i := range data.Tokens {
+		if data.Tokens[i].Name == name {
+			return &data.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// TokenByHash returns a token by the hash of its value.
+func (data *Data) TokenByHash(hash string) *TokenInfo {
+	for i := range data.Tokens {
+		if data.Tokens[i].Hash == hash {
+			return &data.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// CreateToken creates a new token bound to a user.
+// A zero expiration creates a token that never expires.
+func (data *Data) CreateToken(name, username, hash string, expiration time.Time) error {
+	if name == "" {
+		return ErrTokenNameRequired
+	} else if data.Token(name) != nil {
+		return ErrTokenExists
+	} else if data.User(username) == nil {
+		return ErrUserNotFound
+	}
+
+	data.Tokens = append(data.Tokens, TokenInfo{
+		Name:       name,
+		Username:   username,
+		Hash:       hash,
+		Expiration: expiration,
+	})
+
+	return nil
+}
+
+// DropToken removes an existing token by name.
+func (data *Data) DropToken(name string) error {
+	for i := range data.Tokens {
+		if data.Tokens[i].Name == name {
+			data.Tokens = append(data.Tokens[:i], data.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// dropUserTokens removes all tokens bound to a user.
+func (data *Data) dropUserTokens(username string) {
+	var tokens []TokenInfo
+	for _, ti := range data.Tokens {
+		if ti.Username != username {
+			tokens = append(tokens, ti)
+		}
+	}
+	data.Tokens = tokens
+}
+
 // Clone returns a copy of data with a new version.
 func (data *Data) Clone() *Data {
 	other := *data
@@ -655,6 +904,12 @@ func (data *Data) Clone() *Data {
 		}
 	}
 
+	// Copy tokens.
+	if data.Tokens != nil {
+		other.Tokens = make([]TokenInfo, len(data.Tokens))
+		copy(other.Tokens, data.Tokens)
+	}
+
 	return &other
 }
 
@@ -685,6 +940,11 @@ func (data *Data) marshal() *internal.Data {
 		pb.Users[i] = data.Users[i].marshal()
 	}
 
+	pb.Tokens = make([]*internal.TokenInfo, len(data.Tokens))
+	for i := range data.Tokens {
+		pb.Tokens[i] = data.Tokens[i].marshal()
+	}
+
 	return pb
 }
 
@@ -712,6 +972,11 @@ func (data *Data) unmarshal(pb *internal.Data) {
 	for i, x := range pb.GetUsers() {
 		data.Users[i].unmarshal(x)
 	}
+
+	data.Tokens = make([]TokenInfo, len(pb.GetTokens()))
+	for i, x := range pb.GetTokens() {
+		data.Tokens[i].unmarshal(x)
+	}
 }
 
 // MarshalBinary encodes the metadata to a binary format.
@@ -729,20 +994,57 @@ func (data *Data) UnmarshalBinary(buf []byte) error {
 	return nil
 }
 
+// Node states.
+const (
+	// NodeStateActive is the state of a node that can own new shards.
+	// A node without a state is active.
+	NodeStateActive = "active"
+
+	// NodeStateDraining is the state of a node that gets no new shards and
+	// whose shards are being moved to other nodes.
+	NodeStateDraining = "draining"
+
+	// NodeStateDecommissioned is the state of a drained node that owns no
+	// shards and can be dropped from the cluster.
+	NodeStateDecommissioned = "decommissioned"
+)
+
 // NodeInfo represents information about a single node in the cluster.
 type NodeInfo struct {
-	ID   uint64
-	Host string
+	ID     uint64
+	Host   string
+	Labels map[string]string
+	State  string
+}
+
+// Active returns true if the node can own new shards.
+func (ni NodeInfo) Active() bool {
+	return ni.State == "" || ni.State == NodeStateActive
 }
 
 // clone returns a deep copy of ni.
-func (ni NodeInfo) clone() NodeInfo { return ni }
+func (ni NodeInfo) clone() NodeInfo {
+	other := ni
+
+	if ni.Labels != nil {
+		other.Labels = make(map[string]string, len(ni.Labels))
+		for k, v := range ni.Labels {
+			other.Labels[k] = v
+		}
+	}
+
+	return other
+}
 
 // marshal serializes to a protobuf representation.
 func (ni NodeInfo) marshal() *internal.NodeInfo {
 	pb := &internal.NodeInfo{}
 	pb.ID = proto.Uint64(ni.ID)
 	pb.Host = proto.String(ni.Host)
+	pb.Labels = marshalNodeLabels(ni.Labels)
+	if ni.State != "" {
+		pb.State = proto.String(ni.State)
+	}
 	return pb
 }
 
@@ -750,6 +1052,36 @@ func (ni NodeInfo) marshal() *internal.NodeInfo {
 func (ni *NodeInfo) unmarshal(pb *internal.NodeInfo) {
 	ni.ID = pb.GetID()
 	ni.Host = pb.GetHost()
+	ni.Labels = unmarshalNodeLabels(pb.GetLabels())
+	ni.State = pb.GetState()
+}
+
+// marshalNodeLabels serializes labels sorted by key.
+func marshalNodeLabels(labels map[string]string) []*internal.NodeLabel {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var a []*internal.NodeLabel
+	for _, k := range keys {
+		a = append(a, &internal.NodeLabel{Key: proto.String(k), Value: proto.String(labels[k])})
+	}
+	return a
+}
+
+// unmarshalNodeLabels deserializes labels from a protobuf representation.
+func unmarshalNodeLabels(a []*internal.NodeLabel) map[string]string {
+	if len(a) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(a))
+	for _, l := range a {
+		labels[l.GetKey()] = l.GetValue()
+	}
+	return labels
 }
 
 // NodeInfos is a slice of NodeInfo used for sorting
@@ -870,6 +1202,10 @@ type RetentionPolicyInfo struct {
 	ShardGroupDuration time.Duration
 	ShardGroups        []ShardGroupInfo
 	Subscriptions      []SubscriptionInfo
+
+	// Strategy used to assign the shards of new groups to nodes. Empty
+	// for round robin.
+	ShardPlacement string
 }
 
 // NewRetentionPolicyInfo returns a new instance of RetentionPolicyInfo with defaults set.
@@ -924,6 +1260,9 @@ func (rpi *RetentionPolicyInfo) marshal() *internal.RetentionPolicyInfo {
 		Duration:           proto.Int64(int64(rpi.Duration)),
 		ShardGroupDuration: proto.Int64(int64(rpi.ShardGroupDuration)),
 	}
+	if rpi.ShardPlacement != "" {
+		pb.ShardPlacement = proto.String(rpi.ShardPlacement)
+	}
 
 	pb.ShardGroups = make([]*internal.ShardGroupInfo, len(rpi.ShardGroups))
 	for i, sgi := range rpi.ShardGroups {
@@ -939,6 +1278,7 @@ func (rpi *RetentionPolicyInfo) unmarshal(pb *internal.RetentionPolicyInfo) {
 	rpi.ReplicaN = int(pb.GetReplicaN())
 	rpi.Duration = time.Duration(pb.GetDuration())
 	rpi.ShardGroupDuration = time.Duration(pb.GetShardGroupDuration())
+	rpi.ShardPlacement = pb.GetShardPlacement()
 
 	if len(pb.GetShardGroups()) > 0 {
 		rpi.ShardGroups = make([]ShardGroupInfo, len(pb.GetShardGroups()))
@@ -988,6 +1328,10 @@ type ShardGroupInfo struct {
 	EndTime   time.Time
 	DeletedAt time.Time
 	Shards    []ShardInfo
+
+	// Strategy used to assign the shards to nodes. It also determines
+	// which shard stores a series.
+	ShardPlacement string
 }
 
 // ShardGroupInfos is a collection of ShardGroupInfo
@@ -1028,7 +1372,11 @@ func (sgi ShardGroupInfo) clone() ShardGroupInfo {
 
 // ShardFor returns the ShardInfo for a Point hash
 func (sgi *ShardGroupInfo) ShardFor(hash uint64) ShardInfo {
-	return sgi.Shards[hash%uint64(len(sgi.Shards))]
+	p, err := shardPlacer(sgi.ShardPlacement)
+	if err != nil {
+		p = roundRobinPlacer{}
+	}
+	return sgi.Shards[p.ShardIndex(hash, len(sgi.Shards))]
 }
 
 // marshal serializes to a protobuf representation.
@@ -1039,6 +1387,9 @@ func (sgi *ShardGroupInfo) marshal() *internal.ShardGroupInfo {
 		EndTime:   proto.Int64(MarshalTime(sgi.EndTime)),
 		DeletedAt: proto.Int64(MarshalTime(sgi.DeletedAt)),
 	}
+	if sgi.ShardPlacement != "" {
+		pb.ShardPlacement = proto.String(sgi.ShardPlacement)
+	}
 
 	pb.Shards = make([]*internal.ShardInfo, len(sgi.Shards))
 	for i := range sgi.Shards {
@@ -1054,6 +1405,7 @@ func (sgi *ShardGroupInfo) unmarshal(pb *internal.ShardGroupInfo) {
 	sgi.StartTime = UnmarshalTime(pb.GetStartTime())
 	sgi.EndTime = UnmarshalTime(pb.GetEndTime())
 	sgi.DeletedAt = UnmarshalTime(pb.GetDeletedAt())
+	sgi.ShardPlacement = pb.GetShardPlacement()
 
 	if len(pb.GetShards()) > 0 {
 		sgi.Shards = make([]ShardInfo, len(pb.GetShards()))
@@ -1197,6 +1549,29 @@ func (so *ShardOwner) unmarshal(pb *internal.ShardOwner) {
 type ContinuousQueryInfo struct {
 	Name  string
 	Query string
+
+	// ResampleEvery and ResampleFor are set from the RESAMPLE clause of the
+	// query. Zero values mean the continuous query service defaults are used.
+	ResampleEvery time.Duration
+	ResampleFor   time.Duration
+
+	// LeaseNodeID is the node that currently runs the continuous query.
+	// The lease is held until LeaseExpiration unless it is renewed.
+	LeaseNodeID     uint64
+	LeaseExpiration time.Time
+}
+
+// newContinuousQueryInfo returns a ContinuousQueryInfo for query with the
+// resample settings extracted from its RESAMPLE clause.
+func newContinuousQueryInfo(name, query string) ContinuousQueryInfo {
+	cqi := ContinuousQueryInfo{Name: name, Query: query}
+	if stmt, err := influxql.ParseStatement(query); err == nil {
+		if cq, ok := stmt.(*influxql.CreateContinuousQueryStatement); ok {
+			cqi.ResampleEvery = cq.ResampleEvery
+			cqi.ResampleFor = cq.ResampleFor
+		}
+	}
+	return cqi
 }
 
 // clone returns a deep copy of cqi.
@@ -1204,16 +1579,33 @@ func (cqi ContinuousQueryInfo) clone() ContinuousQueryInfo { return cqi }
 
 // marshal serializes to a protobuf representation.
 func (cqi ContinuousQueryInfo) marshal() *internal.ContinuousQueryInfo {
-	return &internal.ContinuousQueryInfo{
+	pb := &internal.ContinuousQueryInfo{
 		Name:  proto.String(cqi.Name),
 		Query: proto.String(cqi.Query),
 	}
+	if cqi.ResampleEvery != 0 {
+		pb.ResampleEvery = proto.Int64(int64(cqi.ResampleEvery))
+	}
+	if cqi.ResampleFor != 0 {
+		pb.ResampleFor = proto.Int64(int64(cqi.ResampleFor))
+	}
+	if cqi.LeaseNodeID != 0 {
+		pb.LeaseNodeID = proto.Uint64(cqi.LeaseNodeID)
+		pb.LeaseExpiration = proto.Int64(cqi.LeaseExpiration.UnixNano())
+	}
+	return pb
 }
 
 // unmarshal deserializes from a protobuf representation.
 func (cqi *ContinuousQueryInfo) unmarshal(pb *internal.ContinuousQueryInfo) {
 	cqi.Name = pb.GetName()
 	cqi.Query = pb.GetQuery()
+	cqi.ResampleEvery = time.Duration(pb.GetResampleEvery())
+	cqi.ResampleFor = time.Duration(pb.GetResampleFor())
+	if pb.LeaseNodeID != nil {
+		cqi.LeaseNodeID = pb.GetLeaseNodeID()
+		cqi.LeaseExpiration = time.Unix(0, pb.GetLeaseExpiration()).UTC()
+	}
 }
 
 // UserInfo represents metadata about a user in the system.
@@ -1277,6 +1669,38 @@ func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
 	}
 }
 
+// TokenInfo represents an API token bound to a user.
+// Only a hash of the token value is stored.
+type TokenInfo struct {
+	Name       string
+	Username   string
+	Hash       string
+	Expiration time.Time // zero if the token never expires
+}
+
+// Expired returns true if the token has expired as of now.
+func (ti TokenInfo) Expired(now time.Time) bool {
+	return !ti.Expiration.IsZero() && !now.Before(ti.Expiration)
+}
+
+// marshal serializes to a protobuf representation.
+func (ti TokenInfo) marshal() *internal.TokenInfo {
+	return &internal.TokenInfo{
+		Name:       proto.String(ti.Name),
+		Username:   proto.String(ti.Username),
+		Hash:       proto.String(ti.Hash),
+		Expiration: proto.Int64(MarshalTime(ti.Expiration)),
+	}
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ti *TokenInfo) unmarshal(pb *internal.TokenInfo) {
+	ti.Name = pb.GetName()
+	ti.Username = pb.GetUsername()
+	ti.Hash = pb.GetHash()
+	ti.Expiration = UnmarshalTime(pb.GetExpiration())
+}
+
 // MarshalTime converts t to nanoseconds since epoch. A zero time returns 0.
 func MarshalTime(t time.Time) int64 {
 	if t.IsZero() {
@@ -41,9 +41,9 @@ func TestData_DeleteNode_Basic(t *testing.T) {
 		t.Fatal(err)
 	} else if len(data.Nodes) != 2 {
 		t.Fatalf("unexpected node count: %d", len(data.Nodes))
-	} else if data.Nodes[0] != (meta.NodeInfo{ID: 2, Host: "host1"}) {
+	} else if !reflect.DeepEqual(data.Nodes[0], meta.NodeInfo{ID: 2, Host: "host1"}) {
 		t.Fatalf("unexpected node: %#v", data.Nodes[0])
-	} else if data.Nodes[1] != (meta.NodeInfo{ID: 3, Host: "host2"}) {
+	} else if !reflect.DeepEqual(data.Nodes[1], meta.NodeInfo{ID: 3, Host: "host2"}) {
 		t.Fatalf("unexpected node: %#v", data.Nodes[1])
 	}
 }
@@ -91,6 +91,36 @@ func TestData_DeleteNode_Shards(t *testing.T) {
 	}
 }
 
+// Ensure a node holding the only copy of a shard can't be removed without force.
+func TestData_DeleteNode_ErrShardNotReplicated(t *testing.T) {
+	var data meta.Data
+	for i := 0; i < 3; i++ {
+		if err := data.CreateNode(fmt.Sprintf("host%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err = data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: 2}); err != nil {
+		t.Fatal(err)
+	} else if err = data.CreateShardGroup("db0", "rp0", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove a replica so a single node holds the shard.
+	si := data.Databases[0].RetentionPolicies[0].ShardGroups[0].Shards[0]
+	id := si.Owners[0].NodeID
+	if err := data.RemoveShardOwner(si.ID, si.Owners[1].NodeID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.DeleteNode(id, false); err != meta.ErrShardNotReplicated {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.DeleteNode(id, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // Ensure a database can be created.
 func TestData_CreateDatabase(t *testing.T) {
 	var data meta.Data
@@ -365,6 +395,249 @@ func TestData_CreateShardGroup(t *testing.T) {
 	}
 }
 
+// Ensure consistent-hash placement spreads the shards of a group evenly.
+func TestData_CreateShardGroup_ConsistentHash(t *testing.T) {
+	data := newPlacementData(t, "consistent-hash", 2, nil)
+
+	sgi, _ := data.ShardGroupByTimestamp("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if sgi == nil {
+		t.Fatal("shard group not created")
+	} else if sgi.ShardPlacement != "consistent-hash" {
+		t.Fatalf("unexpected shard placement: %s", sgi.ShardPlacement)
+	} else if len(sgi.Shards) != 3 {
+		t.Fatalf("unexpected shard count: %d", len(sgi.Shards))
+	}
+
+	// Every node owns exactly one replica.
+	owners := make(map[uint64]int)
+	for _, si := range sgi.Shards {
+		if len(si.Owners) != 2 {
+			t.Fatalf("unexpected owners for shard %d: %v", si.ID, si.Owners)
+		} else if si.Owners[0].NodeID == si.Owners[1].NodeID {
+			t.Fatalf("shard %d owned twice by node %d", si.ID, si.Owners[0].NodeID)
+		}
+		for _, o := range si.Owners {
+			owners[o.NodeID]++
+		}
+	}
+	for id := uint64(1); id <= 6; id++ {
+		if owners[id] != 1 {
+			t.Fatalf("unexpected shard count for node %d: %d", id, owners[id])
+		}
+	}
+
+	// Placement is repeatable.
+	other := newPlacementData(t, "consistent-hash", 2, nil)
+	if sgi2, _ := other.ShardGroupByTimestamp("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)); !reflect.DeepEqual(sgi, sgi2) {
+		t.Fatalf("placement not repeatable: %#v != %#v", sgi, sgi2)
+	}
+}
+
+// Ensure zone-aware placement puts the replicas of a shard in different zones.
+func TestData_CreateShardGroup_ZoneAware(t *testing.T) {
+	data := newPlacementData(t, "zone-aware", 3, []map[string]string{
+		{"zone": "a", "rack": "1"},
+		{"zone": "a", "rack": "2"},
+		{"zone": "b", "rack": "1"},
+		{"zone": "b", "rack": "2"},
+		{"zone": "c", "rack": "1"},
+		{"zone": "c", "rack": "2"},
+	})
+
+	sgi, _ := data.ShardGroupByTimestamp("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if sgi == nil {
+		t.Fatal("shard group not created")
+	} else if len(sgi.Shards) != 2 {
+		t.Fatalf("unexpected shard count: %d", len(sgi.Shards))
+	}
+
+	for _, si := range sgi.Shards {
+		zones := make(map[string]bool)
+		for _, o := range si.Owners {
+			zones[data.Node(o.NodeID).Labels["zone"]] = true
+		}
+		if len(si.Owners) != 3 || len(zones) != 3 {
+			t.Fatalf("replicas of shard %d not spread across zones: %v", si.ID, si.Owners)
+		}
+	}
+}
+
+// Ensure zone-aware placement uses different racks when there are fewer zones than replicas.
+func TestData_CreateShardGroup_ZoneAware_Racks(t *testing.T) {
+	data := newPlacementData(t, "zone-aware", 2, []map[string]string{
+		{"zone": "a", "rack": "1"},
+		{"zone": "a", "rack": "1"},
+		{"zone": "a", "rack": "2"},
+		{"zone": "a", "rack": "2"},
+	})
+
+	sgi, _ := data.ShardGroupByTimestamp("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	for _, si := range sgi.Shards {
+		if len(si.Owners) != 2 {
+			t.Fatalf("unexpected owners for shard %d: %v", si.ID, si.Owners)
+		} else if data.Node(si.Owners[0].NodeID).Labels["rack"] == data.Node(si.Owners[1].NodeID).Labels["rack"] {
+			t.Fatalf("replicas of shard %d in the same rack: %v", si.ID, si.Owners)
+		}
+	}
+}
+
+// Ensure a retention policy cannot use an unknown shard placement.
+func TestData_CreateRetentionPolicy_ErrShardPlacementNotFound(t *testing.T) {
+	var data meta.Data
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: 1, ShardPlacement: "bad"}); err != meta.ErrShardPlacementNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: 1}); err != nil {
+		t.Fatal(err)
+	}
+	rpu := &meta.RetentionPolicyUpdate{}
+	rpu.SetShardPlacement("bad")
+	if err := data.UpdateRetentionPolicy("db0", "rp0", rpu); err != meta.ErrShardPlacementNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// Ensure series are mapped to shards with a consistent hash.
+func TestShardGroupInfo_ShardFor_ConsistentHash(t *testing.T) {
+	newGroup := func(n int) *meta.ShardGroupInfo {
+		sgi := &meta.ShardGroupInfo{ShardPlacement: "consistent-hash"}
+		for i := 0; i < n; i++ {
+			sgi.Shards = append(sgi.Shards, meta.ShardInfo{ID: uint64(i)})
+		}
+		return sgi
+	}
+
+	// Adding a shard only moves series to the new shard.
+	a, b := newGroup(4), newGroup(5)
+	var moved int
+	for i := uint64(0); i < 1000; i++ {
+		hash := i * 0x9E3779B97F4A7C15
+		if x, y := a.ShardFor(hash).ID, b.ShardFor(hash).ID; x != y {
+			if y != 4 {
+				t.Fatalf("series %d moved from shard %d to shard %d", i, x, y)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 400 {
+		t.Fatalf("unexpected moved series: %d", moved)
+	}
+}
+
+// Ensure node labels can be set.
+func TestData_SetNodeLabels(t *testing.T) {
+	var data meta.Data
+	if err := data.CreateNode("host0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.SetNodeLabels(1, map[string]string{"zone": "a"}); err != nil {
+		t.Fatal(err)
+	} else if ni := data.Node(1); !reflect.DeepEqual(ni.Labels, map[string]string{"zone": "a"}) {
+		t.Fatalf("unexpected labels: %v", ni.Labels)
+	}
+
+	if err := data.SetNodeLabels(2, nil); err != meta.ErrNodeNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// Ensure node states can be set and a node is only decommissioned once drained.
+func TestData_SetNodeState(t *testing.T) {
+	data := newPlacementData(t, "", 1, nil)
+	id := data.Databases[0].RetentionPolicies[0].ShardGroups[0].Shards[0].Owners[0].NodeID
+
+	if err := data.SetNodeState(id, meta.NodeStateDraining); err != nil {
+		t.Fatal(err)
+	} else if ni := data.Node(id); ni.State != meta.NodeStateDraining || ni.Active() {
+		t.Fatalf("unexpected node: %#v", ni)
+	}
+
+	// The node can't be decommissioned while it owns a shard.
+	if err := data.SetNodeState(id, meta.NodeStateDecommissioned); err != meta.ErrNodeNotDrained {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move the shard and decommission the node.
+	si := data.Databases[0].RetentionPolicies[0].ShardGroups[0].Shards[0]
+	if err := data.AddShardOwner(si.ID, id%6+1); err != nil {
+		t.Fatal(err)
+	} else if err := data.RemoveShardOwner(si.ID, id); err != nil {
+		t.Fatal(err)
+	} else if err := data.SetNodeState(id, meta.NodeStateDecommissioned); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.SetNodeState(id, "gone"); err != meta.ErrInvalidNodeState {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.SetNodeState(100, meta.NodeStateActive); err != meta.ErrNodeNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure draining nodes don't get new shards.
+func TestData_CreateShardGroup_Draining(t *testing.T) {
+	data := newPlacementData(t, "", 2, nil)
+	for _, id := range []uint64{2, 5} {
+		if err := data.SetNodeState(id, meta.NodeStateDraining); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := data.CreateShardGroup("db0", "rp0", time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	sgi := data.Databases[0].RetentionPolicies[0].ShardGroups[1]
+	if len(sgi.Shards) != 2 {
+		t.Fatalf("unexpected shard count: %d", len(sgi.Shards))
+	}
+	for _, si := range sgi.Shards {
+		if len(si.Owners) != 2 || si.OwnedBy(2) || si.OwnedBy(5) {
+			t.Fatalf("unexpected owners: %v", si.Owners)
+		}
+	}
+
+	// Shard groups can't be created without an active node.
+	for _, id := range []uint64{1, 3, 4, 6} {
+		if err := data.SetNodeState(id, meta.NodeStateDraining); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := data.CreateShardGroup("db0", "rp0", time.Date(2000, time.January, 1, 2, 0, 0, 0, time.UTC)); err != meta.ErrActiveNodesRequired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// newPlacementData returns data with six nodes and a shard group placed by
+// a retention policy using placement.
+func newPlacementData(t *testing.T, placement string, replicaN int, labels []map[string]string) *meta.Data {
+	var data meta.Data
+	for i := 0; i < 6; i++ {
+		if err := data.CreateNode(fmt.Sprintf("node%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range labels {
+		if err := data.SetNodeLabels(uint64(i+1), labels[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err = data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: replicaN, Duration: time.Hour, ShardPlacement: placement}); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateShardGroup("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	return &data
+}
+
 // Ensure that a shard group is correctly detected as expired.
 func TestData_ShardGroupExpiredDeleted(t *testing.T) {
 	var data meta.Data
@@ -484,6 +757,56 @@ func TestData_DeleteShardGroup(t *testing.T) {
 	}
 }
 
+// Ensure a node can be added to and removed from the owners of a shard.
+func TestData_ShardOwners(t *testing.T) {
+	var data meta.Data
+	if err := data.CreateNode("node0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateNode("node1"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err = data.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: 1}); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateShardGroup("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := &data.Databases[0].RetentionPolicies[0].ShardGroups[0].Shards[0]
+	owner := sh.Owners[0].NodeID
+	other := uint64(1)
+	if owner == 1 {
+		other = 2
+	}
+
+	// Add the other node and ensure both nodes own the shard.
+	if err := data.AddShardOwner(sh.ID, other); err != nil {
+		t.Fatal(err)
+	} else if !sh.OwnedBy(owner) || !sh.OwnedBy(other) {
+		t.Fatalf("unexpected owners: %v", sh.Owners)
+	} else if err := data.AddShardOwner(sh.ID, other); err != meta.ErrShardOwnerExists {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Remove the original owner.
+	if err := data.RemoveShardOwner(sh.ID, owner); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(sh.Owners, []meta.ShardOwner{{NodeID: other}}) {
+		t.Fatalf("unexpected owners: %v", sh.Owners)
+	} else if err := data.RemoveShardOwner(sh.ID, owner); err != meta.ErrShardOwnerNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Ensure unknown shards and nodes return an error.
+	if err := data.AddShardOwner(100, other); err != meta.ErrShardNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	} else if err := data.AddShardOwner(sh.ID, 100); err != meta.ErrNodeNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	} else if err := data.RemoveShardOwner(100, other); err != meta.ErrShardNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
 // Ensure a continuous query can be created.
 func TestData_CreateContinuousQuery(t *testing.T) {
 	var data meta.Data
@@ -498,6 +821,66 @@ func TestData_CreateContinuousQuery(t *testing.T) {
 	}
 }
 
+// Ensure a continuous query stores the settings from its RESAMPLE clause.
+func TestData_CreateContinuousQuery_Resample(t *testing.T) {
+	var data meta.Data
+	q := `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 1m FOR 1h BEGIN SELECT count(value) INTO bar FROM foo GROUP BY time(10m) END`
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateContinuousQuery("db0", "cq0", q); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(data.Databases[0].ContinuousQueries, []meta.ContinuousQueryInfo{
+		{Name: "cq0", Query: q, ResampleEvery: time.Minute, ResampleFor: time.Hour},
+	}) {
+		t.Fatalf("unexpected queries: %#v", data.Databases[0].ContinuousQueries)
+	}
+
+	// Altering the query without a RESAMPLE clause should reset the settings.
+	q = `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT count(value) INTO bar FROM foo GROUP BY time(10m) END`
+	if err := data.UpdateContinuousQuery("db0", "cq0", q); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(data.Databases[0].ContinuousQueries, []meta.ContinuousQueryInfo{
+		{Name: "cq0", Query: q},
+	}) {
+		t.Fatalf("unexpected queries: %#v", data.Databases[0].ContinuousQueries)
+	}
+}
+
+// Ensure a continuous query lease can only be taken from another node once it expires.
+func TestData_AcquireContinuousQueryLease(t *testing.T) {
+	var data meta.Data
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateContinuousQuery("db0", "cq0", "SELECT count() FROM foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(100, 0).UTC()
+	if err := data.AcquireContinuousQueryLease("db0", "cq0", 1, now, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if err := data.AcquireContinuousQueryLease("db0", "cq0", 2, now.Add(time.Second), now.Add(time.Minute)); err != meta.ErrContinuousQueryLeaseHeld {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Updating the query keeps the lease.
+	if err := data.UpdateContinuousQuery("db0", "cq0", "SELECT mean() FROM foo"); err != nil {
+		t.Fatal(err)
+	} else if cqi := data.Databases[0].ContinuousQueries[0]; cqi.LeaseNodeID != 1 || !cqi.LeaseExpiration.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected lease: %#v", cqi)
+	}
+
+	// Once the lease expires another node can take it.
+	if err := data.AcquireContinuousQueryLease("db0", "cq0", 2, now.Add(time.Minute), now.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if cqi := data.Databases[0].ContinuousQueries[0]; cqi.LeaseNodeID != 2 {
+		t.Fatalf("unexpected lease owner: %d", cqi.LeaseNodeID)
+	}
+
+	if err := data.AcquireContinuousQueryLease("db0", "no_such_cq", 1, now, now); err != meta.ErrContinuousQueryNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure a continuous query can be removed.
 func TestData_DropContinuousQuery(t *testing.T) {
 	var data meta.Data
@@ -518,6 +901,31 @@ func TestData_DropContinuousQuery(t *testing.T) {
 	}
 }
 
+// Ensure a continuous query can be updated.
+func TestData_UpdateContinuousQuery(t *testing.T) {
+	var data meta.Data
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateContinuousQuery("db0", "cq0", "SELECT count() FROM foo"); err != nil {
+		t.Fatal(err)
+	} else if err = data.CreateContinuousQuery("db0", "cq1", "SELECT count() FROM bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.UpdateContinuousQuery("db0", "cq1", "SELECT mean() FROM bar"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(data.Databases[0].ContinuousQueries, []meta.ContinuousQueryInfo{
+		{Name: "cq0", Query: "SELECT count() FROM foo"},
+		{Name: "cq1", Query: "SELECT mean() FROM bar"},
+	}) {
+		t.Fatalf("unexpected queries: %#v", data.Databases[0].ContinuousQueries)
+	}
+
+	if err := data.UpdateContinuousQuery("db0", "no_such_cq", "SELECT mean() FROM bar"); err != meta.ErrContinuousQueryNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
 // Ensure a subscription can be created.
 func TestData_CreateSubscription(t *testing.T) {
 	var data meta.Data
@@ -652,7 +1060,7 @@ func TestData_Clone(t *testing.T) {
 		Term:  10,
 		Index: 20,
 		Nodes: []meta.NodeInfo{
-			{ID: 1, Host: "host0"},
+			{ID: 1, Host: "host0", Labels: map[string]string{"zone": "us-east-1a"}, State: "draining"},
 			{ID: 2, Host: "host1"},
 		},
 		Databases: []meta.DatabaseInfo{
@@ -723,7 +1131,7 @@ func TestData_MarshalBinary(t *testing.T) {
 		Term:  10,
 		Index: 20,
 		Nodes: []meta.NodeInfo{
-			{ID: 1, Host: "host0"},
+			{ID: 1, Host: "host0", Labels: map[string]string{"zone": "us-east-1a"}, State: "draining"},
 			{ID: 2, Host: "host1"},
 		},
 		Databases: []meta.DatabaseInfo{
@@ -734,6 +1142,7 @@ func TestData_MarshalBinary(t *testing.T) {
 					{
 						Name:               "rp0",
 						ReplicaN:           3,
+						ShardPlacement:     "zone-aware",
 						Duration:           10 * time.Second,
 						ShardGroupDuration: 3 * time.Millisecond,
 						ShardGroups: []meta.ShardGroupInfo{
@@ -756,7 +1165,7 @@ func TestData_MarshalBinary(t *testing.T) {
 					},
 				},
 				ContinuousQueries: []meta.ContinuousQueryInfo{
-					{Query: "SELECT count() FROM foo"},
+					{Query: "SELECT count() FROM foo", ResampleEvery: time.Minute, ResampleFor: time.Hour, LeaseNodeID: 1, LeaseExpiration: time.Unix(0, 1000).UTC()},
 				},
 			},
 		},
@@ -30,6 +30,16 @@ var (
 	// ErrNodeIDRequired is returned when using a zero node id.
 	ErrNodeIDRequired = newError("node id must be greater than 0")
 
+	// ErrInvalidNodeState is returned when setting a node to an unknown state.
+	ErrInvalidNodeState = newError("invalid node state")
+
+	// ErrNodeNotDrained is returned when decommissioning a node that still owns shards.
+	ErrNodeNotDrained = newError("node still owns shards")
+
+	// ErrActiveNodesRequired is returned when creating a shard group while
+	// every node is draining or decommissioned.
+	ErrActiveNodesRequired = newError("at least one active node required")
+
 	// ErrNodeUnableToDropFinalNode is returned if the node being dropped is the last
 	// node in the cluster
 	ErrNodeUnableToDropFinalNode = newError("unable to drop the final node in a cluster")
@@ -66,6 +76,10 @@ var (
 	// ErrReplicationFactorTooLow is returned when the replication factor is not in an
 	// acceptable range.
 	ErrReplicationFactorTooLow = newError("replication factor must be greater than 0")
+
+	// ErrShardPlacementNotFound is returned when a retention policy uses a
+	// shard placement strategy that is not registered.
+	ErrShardPlacementNotFound = newError("shard placement not found")
 )
 
 var (
@@ -78,6 +92,27 @@ var (
 	// ErrShardNotReplicated is returned if the node requested to be dropped has
 	// the last copy of a shard present and the force keyword was not used
 	ErrShardNotReplicated = newError("shard not replicated")
+
+	// ErrShardNotFound is returned when mutating a shard that doesn't exist.
+	ErrShardNotFound = newError("shard not found")
+
+	// ErrShardOwnerExists is returned when adding a node that already owns a shard.
+	ErrShardOwnerExists = newError("shard already owned by node")
+
+	// ErrShardOwnerNotFound is returned when removing a node that doesn't own a shard.
+	ErrShardOwnerNotFound = newError("shard not owned by node")
+
+	// ErrAntiEntropyDisabled is returned when comparing or repairing shards
+	// on a node that isn't running the anti-entropy service.
+	ErrAntiEntropyDisabled = newError("anti-entropy service is not enabled")
+
+	// ErrShardCopierUnavailable is returned when copying or moving shards
+	// on a node that isn't running the copier service.
+	ErrShardCopierUnavailable = newError("shard copier is not available")
+
+	// ErrHintedHandoffUnavailable is returned when managing hinted handoff
+	// queues on a node that isn't running the hinted handoff service.
+	ErrHintedHandoffUnavailable = newError("hinted handoff is not available")
 )
 
 var (
@@ -86,6 +121,14 @@ var (
 
 	// ErrContinuousQueryNotFound is returned when removing a continuous query that doesn't exist.
 	ErrContinuousQueryNotFound = newError("continuous query not found")
+
+	// ErrContinuousQueriesDisabled is returned when backfilling a continuous query
+	// on a node that isn't running the continuous query service.
+	ErrContinuousQueriesDisabled = newError("continuous query service is not enabled")
+
+	// ErrContinuousQueryLeaseHeld is returned when acquiring the lease on a
+	// continuous query that is held by another node.
+	ErrContinuousQueryLeaseHeld = newError("continuous query lease held by another node")
 )
 
 var (
@@ -107,6 +150,20 @@ var (
 	ErrUsernameRequired = newError("username required")
 )
 
+var (
+	// ErrTokenExists is returned when creating an already existing token.
+	ErrTokenExists = newError("token already exists")
+
+	// ErrTokenNotFound is returned when dropping a token that doesn't exist.
+	ErrTokenNotFound = newError("token not found")
+
+	// ErrTokenNameRequired is returned when creating a token without a name.
+	ErrTokenNameRequired = newError("token name required")
+
+	// ErrTokenExpired is returned when authenticating with an expired token.
+	ErrTokenExpired = newError("token expired")
+)
+
 // errLookup stores a mapping of error strings to well defined error types.
 var errLookup = make(map[string]error)
 
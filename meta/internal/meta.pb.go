@@ -11,6 +11,7 @@ It is generated from these files:
 It has these top-level messages:
 	Data
 	NodeInfo
+	NodeLabel
 	DatabaseInfo
 	RetentionPolicyInfo
 	ShardGroupInfo
@@ -20,6 +21,7 @@ It has these top-level messages:
 	ContinuousQueryInfo
 	UserInfo
 	UserPrivilege
+	TokenInfo
 	Command
 	CreateNodeCommand
 	DeleteNodeCommand
@@ -43,6 +45,14 @@ It has these top-level messages:
 	CreateSubscriptionCommand
 	DropSubscriptionCommand
 	RemovePeerCommand
+	UpdateContinuousQueryCommand
+	AcquireContinuousQueryLeaseCommand
+	CreateTokenCommand
+	DropTokenCommand
+	AddShardOwnerCommand
+	RemoveShardOwnerCommand
+	SetNodeLabelsCommand
+	SetNodeStateCommand
 	Response
 	ResponseHeader
 	ErrorResponse
@@ -106,28 +116,36 @@ func (x *RPCType) UnmarshalJSON(data []byte) error {
 type Command_Type int32
 
 const (
-	Command_CreateNodeCommand                Command_Type = 1
-	Command_DeleteNodeCommand                Command_Type = 2
-	Command_CreateDatabaseCommand            Command_Type = 3
-	Command_DropDatabaseCommand              Command_Type = 4
-	Command_CreateRetentionPolicyCommand     Command_Type = 5
-	Command_DropRetentionPolicyCommand       Command_Type = 6
-	Command_SetDefaultRetentionPolicyCommand Command_Type = 7
-	Command_UpdateRetentionPolicyCommand     Command_Type = 8
-	Command_CreateShardGroupCommand          Command_Type = 9
-	Command_DeleteShardGroupCommand          Command_Type = 10
-	Command_CreateContinuousQueryCommand     Command_Type = 11
-	Command_DropContinuousQueryCommand       Command_Type = 12
-	Command_CreateUserCommand                Command_Type = 13
-	Command_DropUserCommand                  Command_Type = 14
-	Command_UpdateUserCommand                Command_Type = 15
-	Command_SetPrivilegeCommand              Command_Type = 16
-	Command_SetDataCommand                   Command_Type = 17
-	Command_SetAdminPrivilegeCommand         Command_Type = 18
-	Command_UpdateNodeCommand                Command_Type = 19
-	Command_CreateSubscriptionCommand        Command_Type = 21
-	Command_DropSubscriptionCommand          Command_Type = 22
-	Command_RemovePeerCommand                Command_Type = 23
+	Command_CreateNodeCommand                  Command_Type = 1
+	Command_DeleteNodeCommand                  Command_Type = 2
+	Command_CreateDatabaseCommand              Command_Type = 3
+	Command_DropDatabaseCommand                Command_Type = 4
+	Command_CreateRetentionPolicyCommand       Command_Type = 5
+	Command_DropRetentionPolicyCommand         Command_Type = 6
+	Command_SetDefaultRetentionPolicyCommand   Command_Type = 7
+	Command_UpdateRetentionPolicyCommand       Command_Type = 8
+	Command_CreateShardGroupCommand            Command_Type = 9
+	Command_DeleteShardGroupCommand            Command_Type = 10
+	Command_CreateContinuousQueryCommand       Command_Type = 11
+	Command_DropContinuousQueryCommand         Command_Type = 12
+	Command_CreateUserCommand                  Command_Type = 13
+	Command_DropUserCommand                    Command_Type = 14
+	Command_UpdateUserCommand                  Command_Type = 15
+	Command_SetPrivilegeCommand                Command_Type = 16
+	Command_SetDataCommand                     Command_Type = 17
+	Command_SetAdminPrivilegeCommand           Command_Type = 18
+	Command_UpdateNodeCommand                  Command_Type = 19
+	Command_CreateSubscriptionCommand          Command_Type = 21
+	Command_DropSubscriptionCommand            Command_Type = 22
+	Command_RemovePeerCommand                  Command_Type = 23
+	Command_UpdateContinuousQueryCommand       Command_Type = 24
+	Command_AcquireContinuousQueryLeaseCommand Command_Type = 25
+	Command_CreateTokenCommand                 Command_Type = 26
+	Command_DropTokenCommand                   Command_Type = 27
+	Command_AddShardOwnerCommand               Command_Type = 28
+	Command_RemoveShardOwnerCommand            Command_Type = 29
+	Command_SetNodeLabelsCommand               Command_Type = 30
+	Command_SetNodeStateCommand                Command_Type = 31
 )
 
 var Command_Type_name = map[int32]string{
@@ -153,30 +171,46 @@ var Command_Type_name = map[int32]string{
 	21: "CreateSubscriptionCommand",
 	22: "DropSubscriptionCommand",
 	23: "RemovePeerCommand",
+	24: "UpdateContinuousQueryCommand",
+	25: "AcquireContinuousQueryLeaseCommand",
+	26: "CreateTokenCommand",
+	27: "DropTokenCommand",
+	28: "AddShardOwnerCommand",
+	29: "RemoveShardOwnerCommand",
+	30: "SetNodeLabelsCommand",
+	31: "SetNodeStateCommand",
 }
 var Command_Type_value = map[string]int32{
-	"CreateNodeCommand":                1,
-	"DeleteNodeCommand":                2,
-	"CreateDatabaseCommand":            3,
-	"DropDatabaseCommand":              4,
-	"CreateRetentionPolicyCommand":     5,
-	"DropRetentionPolicyCommand":       6,
-	"SetDefaultRetentionPolicyCommand": 7,
-	"UpdateRetentionPolicyCommand":     8,
-	"CreateShardGroupCommand":          9,
-	"DeleteShardGroupCommand":          10,
-	"CreateContinuousQueryCommand":     11,
-	"DropContinuousQueryCommand":       12,
-	"CreateUserCommand":                13,
-	"DropUserCommand":                  14,
-	"UpdateUserCommand":                15,
-	"SetPrivilegeCommand":              16,
-	"SetDataCommand":                   17,
-	"SetAdminPrivilegeCommand":         18,
-	"UpdateNodeCommand":                19,
-	"CreateSubscriptionCommand":        21,
-	"DropSubscriptionCommand":          22,
-	"RemovePeerCommand":                23,
+	"CreateNodeCommand":                  1,
+	"DeleteNodeCommand":                  2,
+	"CreateDatabaseCommand":              3,
+	"DropDatabaseCommand":                4,
+	"CreateRetentionPolicyCommand":       5,
+	"DropRetentionPolicyCommand":         6,
+	"SetDefaultRetentionPolicyCommand":   7,
+	"UpdateRetentionPolicyCommand":       8,
+	"CreateShardGroupCommand":            9,
+	"DeleteShardGroupCommand":            10,
+	"CreateContinuousQueryCommand":       11,
+	"DropContinuousQueryCommand":         12,
+	"CreateUserCommand":                  13,
+	"DropUserCommand":                    14,
+	"UpdateUserCommand":                  15,
+	"SetPrivilegeCommand":                16,
+	"SetDataCommand":                     17,
+	"SetAdminPrivilegeCommand":           18,
+	"UpdateNodeCommand":                  19,
+	"CreateSubscriptionCommand":          21,
+	"DropSubscriptionCommand":            22,
+	"RemovePeerCommand":                  23,
+	"UpdateContinuousQueryCommand":       24,
+	"AcquireContinuousQueryLeaseCommand": 25,
+	"CreateTokenCommand":                 26,
+	"DropTokenCommand":                   27,
+	"AddShardOwnerCommand":               28,
+	"RemoveShardOwnerCommand":            29,
+	"SetNodeLabelsCommand":               30,
+	"SetNodeStateCommand":                31,
 }
 
 func (x Command_Type) Enum() *Command_Type {
@@ -206,6 +240,7 @@ type Data struct {
 	MaxNodeID        *uint64         `protobuf:"varint,7,req,name=MaxNodeID" json:"MaxNodeID,omitempty"`
 	MaxShardGroupID  *uint64         `protobuf:"varint,8,req,name=MaxShardGroupID" json:"MaxShardGroupID,omitempty"`
 	MaxShardID       *uint64         `protobuf:"varint,9,req,name=MaxShardID" json:"MaxShardID,omitempty"`
+	Tokens           []*TokenInfo    `protobuf:"bytes,10,rep,name=Tokens" json:"Tokens,omitempty"`
 	XXX_unrecognized []byte          `json:"-"`
 }
 
@@ -276,10 +311,19 @@ func (m *Data) GetMaxShardID() uint64 {
 	return 0
 }
 
+func (m *Data) GetTokens() []*TokenInfo {
+	if m != nil {
+		return m.Tokens
+	}
+	return nil
+}
+
 type NodeInfo struct {
-	ID               *uint64 `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
-	Host             *string `protobuf:"bytes,2,req,name=Host" json:"Host,omitempty"`
-	XXX_unrecognized []byte  `json:"-"`
+	ID               *uint64      `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
+	Host             *string      `protobuf:"bytes,2,req,name=Host" json:"Host,omitempty"`
+	Labels           []*NodeLabel `protobuf:"bytes,3,rep,name=Labels" json:"Labels,omitempty"`
+	State            *string      `protobuf:"bytes,4,opt,name=State" json:"State,omitempty"`
+	XXX_unrecognized []byte       `json:"-"`
 }
 
 func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
@@ -300,6 +344,44 @@ func (m *NodeInfo) GetHost() string {
 	return ""
 }
 
+func (m *NodeInfo) GetLabels() []*NodeLabel {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *NodeInfo) GetState() string {
+	if m != nil && m.State != nil {
+		return *m.State
+	}
+	return ""
+}
+
+type NodeLabel struct {
+	Key              *string `protobuf:"bytes,1,req,name=Key" json:"Key,omitempty"`
+	Value            *string `protobuf:"bytes,2,req,name=Value" json:"Value,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *NodeLabel) Reset()         { *m = NodeLabel{} }
+func (m *NodeLabel) String() string { return proto.CompactTextString(m) }
+func (*NodeLabel) ProtoMessage()    {}
+
+func (m *NodeLabel) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *NodeLabel) GetValue() string {
+	if m != nil && m.Value != nil {
+		return *m.Value
+	}
+	return ""
+}
+
 type DatabaseInfo struct {
 	Name                   *string                `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
 	DefaultRetentionPolicy *string                `protobuf:"bytes,2,req,name=DefaultRetentionPolicy" json:"DefaultRetentionPolicy,omitempty"`
@@ -347,6 +429,7 @@ type RetentionPolicyInfo struct {
 	ReplicaN           *uint32             `protobuf:"varint,4,req,name=ReplicaN" json:"ReplicaN,omitempty"`
 	ShardGroups        []*ShardGroupInfo   `protobuf:"bytes,5,rep,name=ShardGroups" json:"ShardGroups,omitempty"`
 	Subscriptions      []*SubscriptionInfo `protobuf:"bytes,6,rep,name=Subscriptions" json:"Subscriptions,omitempty"`
+	ShardPlacement     *string             `protobuf:"bytes,7,opt,name=ShardPlacement" json:"ShardPlacement,omitempty"`
 	XXX_unrecognized   []byte              `json:"-"`
 }
 
@@ -396,12 +479,20 @@ func (m *RetentionPolicyInfo) GetSubscriptions() []*SubscriptionInfo {
 	return nil
 }
 
+func (m *RetentionPolicyInfo) GetShardPlacement() string {
+	if m != nil && m.ShardPlacement != nil {
+		return *m.ShardPlacement
+	}
+	return ""
+}
+
 type ShardGroupInfo struct {
 	ID               *uint64      `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
 	StartTime        *int64       `protobuf:"varint,2,req,name=StartTime" json:"StartTime,omitempty"`
 	EndTime          *int64       `protobuf:"varint,3,req,name=EndTime" json:"EndTime,omitempty"`
 	DeletedAt        *int64       `protobuf:"varint,4,req,name=DeletedAt" json:"DeletedAt,omitempty"`
 	Shards           []*ShardInfo `protobuf:"bytes,5,rep,name=Shards" json:"Shards,omitempty"`
+	ShardPlacement   *string      `protobuf:"bytes,6,opt,name=ShardPlacement" json:"ShardPlacement,omitempty"`
 	XXX_unrecognized []byte       `json:"-"`
 }
 
@@ -444,6 +535,13 @@ func (m *ShardGroupInfo) GetShards() []*ShardInfo {
 	return nil
 }
 
+func (m *ShardGroupInfo) GetShardPlacement() string {
+	if m != nil && m.ShardPlacement != nil {
+		return *m.ShardPlacement
+	}
+	return ""
+}
+
 type ShardInfo struct {
 	ID               *uint64       `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
 	OwnerIDs         []uint64      `protobuf:"varint,2,rep,name=OwnerIDs" json:"OwnerIDs,omitempty"`
@@ -527,6 +625,10 @@ func (m *ShardOwner) GetNodeID() uint64 {
 type ContinuousQueryInfo struct {
 	Name             *string `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
 	Query            *string `protobuf:"bytes,2,req,name=Query" json:"Query,omitempty"`
+	ResampleEvery    *int64  `protobuf:"varint,3,opt,name=ResampleEvery" json:"ResampleEvery,omitempty"`
+	ResampleFor      *int64  `protobuf:"varint,4,opt,name=ResampleFor" json:"ResampleFor,omitempty"`
+	LeaseNodeID      *uint64 `protobuf:"varint,5,opt,name=LeaseNodeID" json:"LeaseNodeID,omitempty"`
+	LeaseExpiration  *int64  `protobuf:"varint,6,opt,name=LeaseExpiration" json:"LeaseExpiration,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -548,6 +650,34 @@ func (m *ContinuousQueryInfo) GetQuery() string {
 	return ""
 }
 
+func (m *ContinuousQueryInfo) GetResampleEvery() int64 {
+	if m != nil && m.ResampleEvery != nil {
+		return *m.ResampleEvery
+	}
+	return 0
+}
+
+func (m *ContinuousQueryInfo) GetResampleFor() int64 {
+	if m != nil && m.ResampleFor != nil {
+		return *m.ResampleFor
+	}
+	return 0
+}
+
+func (m *ContinuousQueryInfo) GetLeaseNodeID() uint64 {
+	if m != nil && m.LeaseNodeID != nil {
+		return *m.LeaseNodeID
+	}
+	return 0
+}
+
+func (m *ContinuousQueryInfo) GetLeaseExpiration() int64 {
+	if m != nil && m.LeaseExpiration != nil {
+		return *m.LeaseExpiration
+	}
+	return 0
+}
+
 type UserInfo struct {
 	Name             *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
 	Hash             *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
@@ -612,6 +742,46 @@ func (m *UserPrivilege) GetPrivilege() int32 {
 	return 0
 }
 
+type TokenInfo struct {
+	Name             *string `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Username         *string `protobuf:"bytes,2,req,name=Username" json:"Username,omitempty"`
+	Hash             *string `protobuf:"bytes,3,req,name=Hash" json:"Hash,omitempty"`
+	Expiration       *int64  `protobuf:"varint,4,opt,name=Expiration" json:"Expiration,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *TokenInfo) Reset()         { *m = TokenInfo{} }
+func (m *TokenInfo) String() string { return proto.CompactTextString(m) }
+func (*TokenInfo) ProtoMessage()    {}
+
+func (m *TokenInfo) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetUsername() string {
+	if m != nil && m.Username != nil {
+		return *m.Username
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetHash() string {
+	if m != nil && m.Hash != nil {
+		return *m.Hash
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetExpiration() int64 {
+	if m != nil && m.Expiration != nil {
+		return *m.Expiration
+	}
+	return 0
+}
+
 type Command struct {
 	Type             *Command_Type             `protobuf:"varint,1,req,name=type,enum=internal.Command_Type" json:"type,omitempty"`
 	XXX_extensions   map[int32]proto.Extension `json:"-"`
@@ -857,6 +1027,7 @@ type UpdateRetentionPolicyCommand struct {
 	NewName          *string `protobuf:"bytes,3,opt,name=NewName" json:"NewName,omitempty"`
 	Duration         *int64  `protobuf:"varint,4,opt,name=Duration" json:"Duration,omitempty"`
 	ReplicaN         *uint32 `protobuf:"varint,5,opt,name=ReplicaN" json:"ReplicaN,omitempty"`
+	ShardPlacement   *string `protobuf:"bytes,6,opt,name=ShardPlacement" json:"ShardPlacement,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -899,6 +1070,13 @@ func (m *UpdateRetentionPolicyCommand) GetReplicaN() uint32 {
 	return 0
 }
 
+func (m *UpdateRetentionPolicyCommand) GetShardPlacement() string {
+	if m != nil && m.ShardPlacement != nil {
+		return *m.ShardPlacement
+	}
+	return ""
+}
+
 var E_UpdateRetentionPolicyCommand_Command = &proto.ExtensionDesc{
 	ExtendedType:  (*Command)(nil),
 	ExtensionType: (*UpdateRetentionPolicyCommand)(nil),
@@ -1411,6 +1589,310 @@ var E_RemovePeerCommand_Command = &proto.ExtensionDesc{
 	Tag:           "bytes,123,opt,name=command",
 }
 
+type UpdateContinuousQueryCommand struct {
+	Database         *string `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
+	Name             *string `protobuf:"bytes,2,req,name=Name" json:"Name,omitempty"`
+	Query            *string `protobuf:"bytes,3,req,name=Query" json:"Query,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *UpdateContinuousQueryCommand) Reset()         { *m = UpdateContinuousQueryCommand{} }
+func (m *UpdateContinuousQueryCommand) String() string { return proto.CompactTextString(m) }
+func (*UpdateContinuousQueryCommand) ProtoMessage()    {}
+
+func (m *UpdateContinuousQueryCommand) GetDatabase() string {
+	if m != nil && m.Database != nil {
+		return *m.Database
+	}
+	return ""
+}
+
+func (m *UpdateContinuousQueryCommand) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *UpdateContinuousQueryCommand) GetQuery() string {
+	if m != nil && m.Query != nil {
+		return *m.Query
+	}
+	return ""
+}
+
+var E_UpdateContinuousQueryCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*UpdateContinuousQueryCommand)(nil),
+	Field:         124,
+	Name:          "internal.UpdateContinuousQueryCommand.command",
+	Tag:           "bytes,124,opt,name=command",
+}
+
+type AcquireContinuousQueryLeaseCommand struct {
+	Database         *string `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
+	Name             *string `protobuf:"bytes,2,req,name=Name" json:"Name,omitempty"`
+	NodeID           *uint64 `protobuf:"varint,3,req,name=NodeID" json:"NodeID,omitempty"`
+	Time             *int64  `protobuf:"varint,4,req,name=Time" json:"Time,omitempty"`
+	Expiration       *int64  `protobuf:"varint,5,req,name=Expiration" json:"Expiration,omitempty"`
+	Duration         *int64  `protobuf:"varint,6,opt,name=Duration" json:"Duration,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AcquireContinuousQueryLeaseCommand) Reset()         { *m = AcquireContinuousQueryLeaseCommand{} }
+func (m *AcquireContinuousQueryLeaseCommand) String() string { return proto.CompactTextString(m) }
+func (*AcquireContinuousQueryLeaseCommand) ProtoMessage()    {}
+
+func (m *AcquireContinuousQueryLeaseCommand) GetDatabase() string {
+	if m != nil && m.Database != nil {
+		return *m.Database
+	}
+	return ""
+}
+
+func (m *AcquireContinuousQueryLeaseCommand) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *AcquireContinuousQueryLeaseCommand) GetNodeID() uint64 {
+	if m != nil && m.NodeID != nil {
+		return *m.NodeID
+	}
+	return 0
+}
+
+func (m *AcquireContinuousQueryLeaseCommand) GetTime() int64 {
+	if m != nil && m.Time != nil {
+		return *m.Time
+	}
+	return 0
+}
+
+func (m *AcquireContinuousQueryLeaseCommand) GetExpiration() int64 {
+	if m != nil && m.Expiration != nil {
+		return *m.Expiration
+	}
+	return 0
+}
+
+func (m *AcquireContinuousQueryLeaseCommand) GetDuration() int64 {
+	if m != nil && m.Duration != nil {
+		return *m.Duration
+	}
+	return 0
+}
+
+var E_AcquireContinuousQueryLeaseCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*AcquireContinuousQueryLeaseCommand)(nil),
+	Field:         125,
+	Name:          "internal.AcquireContinuousQueryLeaseCommand.command",
+	Tag:           "bytes,125,opt,name=command",
+}
+
+type CreateTokenCommand struct {
+	Name             *string `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Username         *string `protobuf:"bytes,2,req,name=Username" json:"Username,omitempty"`
+	Hash             *string `protobuf:"bytes,3,req,name=Hash" json:"Hash,omitempty"`
+	Expiration       *int64  `protobuf:"varint,4,opt,name=Expiration" json:"Expiration,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CreateTokenCommand) Reset()         { *m = CreateTokenCommand{} }
+func (m *CreateTokenCommand) String() string { return proto.CompactTextString(m) }
+func (*CreateTokenCommand) ProtoMessage()    {}
+
+func (m *CreateTokenCommand) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *CreateTokenCommand) GetUsername() string {
+	if m != nil && m.Username != nil {
+		return *m.Username
+	}
+	return ""
+}
+
+func (m *CreateTokenCommand) GetHash() string {
+	if m != nil && m.Hash != nil {
+		return *m.Hash
+	}
+	return ""
+}
+
+func (m *CreateTokenCommand) GetExpiration() int64 {
+	if m != nil && m.Expiration != nil {
+		return *m.Expiration
+	}
+	return 0
+}
+
+var E_CreateTokenCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*CreateTokenCommand)(nil),
+	Field:         126,
+	Name:          "internal.CreateTokenCommand.command",
+	Tag:           "bytes,126,opt,name=command",
+}
+
+type DropTokenCommand struct {
+	Name             *string `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *DropTokenCommand) Reset()         { *m = DropTokenCommand{} }
+func (m *DropTokenCommand) String() string { return proto.CompactTextString(m) }
+func (*DropTokenCommand) ProtoMessage()    {}
+
+func (m *DropTokenCommand) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+var E_DropTokenCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*DropTokenCommand)(nil),
+	Field:         127,
+	Name:          "internal.DropTokenCommand.command",
+	Tag:           "bytes,127,opt,name=command",
+}
+
+type AddShardOwnerCommand struct {
+	ID               *uint64 `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
+	NodeID           *uint64 `protobuf:"varint,2,req,name=NodeID" json:"NodeID,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AddShardOwnerCommand) Reset()         { *m = AddShardOwnerCommand{} }
+func (m *AddShardOwnerCommand) String() string { return proto.CompactTextString(m) }
+func (*AddShardOwnerCommand) ProtoMessage()    {}
+
+func (m *AddShardOwnerCommand) GetID() uint64 {
+	if m != nil && m.ID != nil {
+		return *m.ID
+	}
+	return 0
+}
+
+func (m *AddShardOwnerCommand) GetNodeID() uint64 {
+	if m != nil && m.NodeID != nil {
+		return *m.NodeID
+	}
+	return 0
+}
+
+var E_AddShardOwnerCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*AddShardOwnerCommand)(nil),
+	Field:         128,
+	Name:          "internal.AddShardOwnerCommand.command",
+	Tag:           "bytes,128,opt,name=command",
+}
+
+type RemoveShardOwnerCommand struct {
+	ID               *uint64 `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
+	NodeID           *uint64 `protobuf:"varint,2,req,name=NodeID" json:"NodeID,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *RemoveShardOwnerCommand) Reset()         { *m = RemoveShardOwnerCommand{} }
+func (m *RemoveShardOwnerCommand) String() string { return proto.CompactTextString(m) }
+func (*RemoveShardOwnerCommand) ProtoMessage()    {}
+
+func (m *RemoveShardOwnerCommand) GetID() uint64 {
+	if m != nil && m.ID != nil {
+		return *m.ID
+	}
+	return 0
+}
+
+func (m *RemoveShardOwnerCommand) GetNodeID() uint64 {
+	if m != nil && m.NodeID != nil {
+		return *m.NodeID
+	}
+	return 0
+}
+
+var E_RemoveShardOwnerCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*RemoveShardOwnerCommand)(nil),
+	Field:         129,
+	Name:          "internal.RemoveShardOwnerCommand.command",
+	Tag:           "bytes,129,opt,name=command",
+}
+
+type SetNodeLabelsCommand struct {
+	ID               *uint64      `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
+	Labels           []*NodeLabel `protobuf:"bytes,2,rep,name=Labels" json:"Labels,omitempty"`
+	XXX_unrecognized []byte       `json:"-"`
+}
+
+func (m *SetNodeLabelsCommand) Reset()         { *m = SetNodeLabelsCommand{} }
+func (m *SetNodeLabelsCommand) String() string { return proto.CompactTextString(m) }
+func (*SetNodeLabelsCommand) ProtoMessage()    {}
+
+func (m *SetNodeLabelsCommand) GetID() uint64 {
+	if m != nil && m.ID != nil {
+		return *m.ID
+	}
+	return 0
+}
+
+func (m *SetNodeLabelsCommand) GetLabels() []*NodeLabel {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+var E_SetNodeLabelsCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*SetNodeLabelsCommand)(nil),
+	Field:         130,
+	Name:          "internal.SetNodeLabelsCommand.command",
+	Tag:           "bytes,130,opt,name=command",
+}
+
+type SetNodeStateCommand struct {
+	ID               *uint64 `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
+	State            *string `protobuf:"bytes,2,req,name=State" json:"State,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *SetNodeStateCommand) Reset()         { *m = SetNodeStateCommand{} }
+func (m *SetNodeStateCommand) String() string { return proto.CompactTextString(m) }
+func (*SetNodeStateCommand) ProtoMessage()    {}
+
+func (m *SetNodeStateCommand) GetID() uint64 {
+	if m != nil && m.ID != nil {
+		return *m.ID
+	}
+	return 0
+}
+
+func (m *SetNodeStateCommand) GetState() string {
+	if m != nil && m.State != nil {
+		return *m.State
+	}
+	return ""
+}
+
+var E_SetNodeStateCommand_Command = &proto.ExtensionDesc{
+	ExtendedType:  (*Command)(nil),
+	ExtensionType: (*SetNodeStateCommand)(nil),
+	Field:         131,
+	Name:          "internal.SetNodeStateCommand.command",
+	Tag:           "bytes,131,opt,name=command",
+}
+
 type Response struct {
 	OK               *bool   `protobuf:"varint,1,req,name=OK" json:"OK,omitempty"`
 	Error            *string `protobuf:"bytes,2,opt,name=Error" json:"Error,omitempty"`
@@ -1686,4 +2168,12 @@ func init() {
 	proto.RegisterExtension(E_CreateSubscriptionCommand_Command)
 	proto.RegisterExtension(E_DropSubscriptionCommand_Command)
 	proto.RegisterExtension(E_RemovePeerCommand_Command)
+	proto.RegisterExtension(E_UpdateContinuousQueryCommand_Command)
+	proto.RegisterExtension(E_AcquireContinuousQueryLeaseCommand_Command)
+	proto.RegisterExtension(E_CreateTokenCommand_Command)
+	proto.RegisterExtension(E_DropTokenCommand_Command)
+	proto.RegisterExtension(E_AddShardOwnerCommand_Command)
+	proto.RegisterExtension(E_RemoveShardOwnerCommand_Command)
+	proto.RegisterExtension(E_SetNodeLabelsCommand_Command)
+	proto.RegisterExtension(E_SetNodeStateCommand_Command)
 }
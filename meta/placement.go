@@ -0,0 +1,270 @@
+package meta
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Shard placement strategies available to retention policies.
+const (
+	// ShardPlacementRoundRobin assigns owners by walking the node list from
+	// a repeatably "random" offset. It is used when a policy has no placement.
+	ShardPlacementRoundRobin = "round-robin"
+
+	// ShardPlacementConsistentHash assigns owners from a hash ring of the
+	// nodes so adding or removing a node only moves some of the shards.
+	ShardPlacementConsistentHash = "consistent-hash"
+
+	// ShardPlacementZoneAware assigns owners like ShardPlacementConsistentHash
+	// but places the replicas of a shard in different zones, and within a
+	// zone in different racks, when the nodes allow it.
+	ShardPlacementZoneAware = "zone-aware"
+)
+
+// Node labels used by zone-aware placement. A node without a label is
+// considered to be in a failure domain of its own.
+const (
+	NodeLabelZone = "zone"
+	NodeLabelRack = "rack"
+)
+
+// ringReplicas is the number of points each node has on the hash ring.
+const ringReplicas = 64
+
+// ShardPlacer assigns owners to the shards of a new shard group. The meta
+// store runs it on every meta node so the result must only depend on its
+// arguments.
+type ShardPlacer interface {
+	// PlaceShards sets the owners of every shard in the group.
+	PlaceShards(req *ShardPlacementRequest)
+
+	// ShardIndex returns the index of the shard that stores a series.
+	ShardIndex(hash uint64, shardN int) int
+}
+
+// ShardPlacementRequest describes a new shard group to place.
+type ShardPlacementRequest struct {
+	Database        string
+	RetentionPolicy string
+	ShardGroup      *ShardGroupInfo
+	Nodes           []NodeInfo // sorted by ID
+	ReplicaN        int        // no more than the number of nodes
+	Index           uint64     // a repeatably "random" value
+}
+
+var shardPlacers = map[string]ShardPlacer{
+	ShardPlacementRoundRobin:     roundRobinPlacer{},
+	ShardPlacementConsistentHash: &ringPlacer{},
+	ShardPlacementZoneAware:      &ringPlacer{zoneAware: true},
+}
+
+// RegisterShardPlacer makes a placement strategy available to retention
+// policies under name. It must be called on every node before the meta
+// store is opened.
+func RegisterShardPlacer(name string, p ShardPlacer) {
+	if _, ok := shardPlacers[name]; ok {
+		panic("shard placement already registered: " + name)
+	}
+	shardPlacers[name] = p
+}
+
+// shardPlacer returns the placement strategy registered under name. An
+// empty name returns the round-robin strategy.
+func shardPlacer(name string) (ShardPlacer, error) {
+	if name == "" {
+		name = ShardPlacementRoundRobin
+	}
+	p, ok := shardPlacers[name]
+	if !ok {
+		return nil, ErrShardPlacementNotFound
+	}
+	return p, nil
+}
+
+// roundRobinPlacer assigns owners by walking the node list.
+type roundRobinPlacer struct{}
+
+// PlaceShards assigns owners to the shards in round robin order.
+func (roundRobinPlacer) PlaceShards(req *ShardPlacementRequest) {
+	// Start from a repeatably "random" place in the node list.
+	nodeIndex := int(req.Index % uint64(len(req.Nodes)))
+	for i := range req.ShardGroup.Shards {
+		si := &req.ShardGroup.Shards[i]
+		for j := 0; j < req.ReplicaN; j++ {
+			nodeID := req.Nodes[nodeIndex%len(req.Nodes)].ID
+			si.Owners = append(si.Owners, ShardOwner{NodeID: nodeID})
+			nodeIndex++
+		}
+	}
+}
+
+// ShardIndex returns the series hash modulo the shard count.
+func (roundRobinPlacer) ShardIndex(hash uint64, shardN int) int {
+	return int(hash % uint64(shardN))
+}
+
+// ringPlacer assigns owners from a consistent hash ring. Each shard is
+// hashed onto the ring and owned by the next nodes clockwise. Nodes that
+// already own their even share of the group are skipped so the group stays
+// balanced.
+type ringPlacer struct {
+	zoneAware bool
+}
+
+// PlaceShards assigns owners to the shards from the hash ring.
+func (p *ringPlacer) PlaceShards(req *ShardPlacementRequest) {
+	ring := newHashRing(req.Nodes)
+
+	// The most owners any node takes in this group.
+	shards := req.ShardGroup.Shards
+	capacity := (len(shards)*req.ReplicaN + len(req.Nodes) - 1) / len(req.Nodes)
+	load := make(map[uint64]int, len(req.Nodes))
+
+	for i := range shards {
+		key := req.Database + "/" + req.RetentionPolicy + "/" + strconv.Itoa(i)
+		candidates := ring.walk(hashString(key))
+
+		var owners []NodeInfo
+		owned := func(ni NodeInfo) bool {
+			for _, o := range owners {
+				if o.ID == ni.ID {
+					return true
+				}
+			}
+			return false
+		}
+
+		// Each pass relaxes the rules for choosing the next owner. Zone-aware
+		// placement prefers nodes in unused zones, then nodes in unused racks,
+		// even if that unbalances the group. The final pass takes any node so
+		// every shard gets its replicas.
+		balanced := func(ni NodeInfo) bool { return load[ni.ID] < capacity }
+		passes := []func(NodeInfo) bool{
+			balanced,
+			func(ni NodeInfo) bool { return true },
+		}
+		if p.zoneAware {
+			newZone := func(ni NodeInfo) bool { return !sharesDomain(ni, owners, zoneDomain) }
+			newRack := func(ni NodeInfo) bool { return !sharesDomain(ni, owners, rackDomain) }
+			passes = append([]func(NodeInfo) bool{
+				func(ni NodeInfo) bool { return newZone(ni) && balanced(ni) },
+				newZone,
+				func(ni NodeInfo) bool { return newRack(ni) && balanced(ni) },
+				newRack,
+			}, passes...)
+		}
+
+		for _, accept := range passes {
+			for _, ni := range candidates {
+				if len(owners) == req.ReplicaN {
+					break
+				} else if !owned(ni) && accept(ni) {
+					owners = append(owners, ni)
+					load[ni.ID]++
+				}
+			}
+		}
+
+		for _, ni := range owners {
+			shards[i].Owners = append(shards[i].Owners, ShardOwner{NodeID: ni.ID})
+		}
+	}
+}
+
+// ShardIndex returns the shard for a series using jump consistent hashing.
+// Series keep their shard index when the shard count of a later group
+// changes, unless they move to one of the new shards.
+func (p *ringPlacer) ShardIndex(hash uint64, shardN int) int {
+	var b, j int64 = -1, 0
+	for j < int64(shardN) {
+		b = j
+		hash = hash*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((hash>>33)+1)))
+	}
+	return int(b)
+}
+
+// hashRing is a consistent hash ring of nodes.
+type hashRing struct {
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash uint64
+	node NodeInfo
+}
+
+// newHashRing returns a ring with ringReplicas points for each node.
+func newHashRing(nodes []NodeInfo) *hashRing {
+	r := &hashRing{points: make([]ringPoint, 0, len(nodes)*ringReplicas)}
+	for _, ni := range nodes {
+		for i := 0; i < ringReplicas; i++ {
+			key := strconv.FormatUint(ni.ID, 10) + "-" + strconv.Itoa(i)
+			r.points = append(r.points, ringPoint{hash: hashString(key), node: ni})
+		}
+	}
+	sort.Sort(ringPoints(r.points))
+	return r
+}
+
+// walk returns every node once, in ring order starting at hash.
+func (r *hashRing) walk(hash uint64) []NodeInfo {
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= hash })
+
+	var a []NodeInfo
+	seen := make(map[uint64]struct{})
+	for i := 0; i < len(r.points); i++ {
+		pt := r.points[(start+i)%len(r.points)]
+		if _, ok := seen[pt.node.ID]; !ok {
+			seen[pt.node.ID] = struct{}{}
+			a = append(a, pt.node)
+		}
+	}
+	return a
+}
+
+type ringPoints []ringPoint
+
+func (a ringPoints) Len() int      { return len(a) }
+func (a ringPoints) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ringPoints) Less(i, j int) bool {
+	if a[i].hash != a[j].hash {
+		return a[i].hash < a[j].hash
+	}
+	return a[i].node.ID < a[j].node.ID
+}
+
+// zoneDomain returns the zone of a node.
+func zoneDomain(ni NodeInfo) string {
+	if zone, ok := ni.Labels[NodeLabelZone]; ok {
+		return "zone:" + zone
+	}
+	return "node:" + strconv.FormatUint(ni.ID, 10)
+}
+
+// rackDomain returns the rack of a node within its zone.
+func rackDomain(ni NodeInfo) string {
+	if rack, ok := ni.Labels[NodeLabelRack]; ok {
+		return zoneDomain(ni) + "/rack:" + rack
+	}
+	return "node:" + strconv.FormatUint(ni.ID, 10)
+}
+
+// sharesDomain returns true if ni is in the same failure domain as any owner.
+func sharesDomain(ni NodeInfo, owners []NodeInfo, domain func(NodeInfo) string) bool {
+	d := domain(ni)
+	for _, o := range owners {
+		if domain(o) == d {
+			return true
+		}
+	}
+	return false
+}
+
+// hashString returns the 64-bit FNV-1a hash of s.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -1,12 +1,16 @@
 package meta
 
 import (
+	"crypto/tls"
 	"io"
 	"net"
+	"time"
+
+	"github.com/influxdb/influxdb/tcp"
 )
 
 // proxy brokers a connection from src to dst
-func proxy(dst, src *net.TCPConn) error {
+func proxy(dst, src net.Conn) error {
 	// channels to wait on the close event for each connection
 	serverClosed := make(chan struct{}, 1)
 	clientClosed := make(chan struct{}, 1)
@@ -25,7 +29,7 @@ func proxy(dst, src *net.TCPConn) error {
 		// the client closed first and any more packets from the server aren't
 		// useful, so we can optionally SetLinger(0) here to recycle the port
 		// faster.
-		dst.SetLinger(0)
+		setLinger(dst, 0)
 		dst.Close()
 		waitFor = serverClosed
 	case <-serverClosed:
@@ -33,7 +37,7 @@ func proxy(dst, src *net.TCPConn) error {
 		waitFor = clientClosed
 	case err := <-errors:
 		src.Close()
-		dst.SetLinger(0)
+		setLinger(dst, 0)
 		dst.Close()
 		return err
 	}
@@ -60,3 +64,25 @@ func broker(dst, src net.Conn, srcClosed chan struct{}, errors chan error) {
 	}
 	srcClosed <- struct{}{}
 }
+
+// setLinger sets the linger timeout of conn if it is a TCP connection.
+func setLinger(conn net.Conn, sec int) {
+	if conn, ok := conn.(*net.TCPConn); ok {
+		conn.SetLinger(sec)
+	}
+}
+
+// dialMux connects to the mux listener at addr and writes the header byte.
+// The connection uses TLS if config is not nil.
+func dialMux(addr string, header byte, timeout time.Duration, config *tls.Config) (net.Conn, error) {
+	conn, err := tcp.DialConn("tcp", addr, timeout, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte{header}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -1,6 +1,7 @@
 package meta
 
 import (
+	"crypto/tls"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -25,6 +26,7 @@ const (
 type rpc struct {
 	logger         *log.Logger
 	tracingEnabled bool
+	tlsConfig      *tls.Config
 
 	store interface {
 		cachedData() *Data
@@ -53,23 +55,22 @@ type Reply interface {
 }
 
 // proxyLeader proxies the connection to the current raft leader
-func (r *rpc) proxyLeader(conn *net.TCPConn, buf []byte) {
+func (r *rpc) proxyLeader(conn net.Conn, buf []byte) {
 	if r.store.Leader() == "" {
 		r.sendError(conn, "no leader detected during proxyLeader")
 		return
 	}
 
-	leaderConn, err := net.DialTimeout("tcp", r.store.Leader(), leaderDialTimeout)
+	leaderConn, err := dialMux(r.store.Leader(), MuxRPCHeader, leaderDialTimeout, r.tlsConfig)
 	if err != nil {
 		r.sendError(conn, fmt.Sprintf("dial leader: %v", err))
 		return
 	}
 	defer leaderConn.Close()
 
-	leaderConn.Write([]byte{MuxRPCHeader})
 	// re-write the original message to the leader
 	leaderConn.Write(buf)
-	if err := proxy(leaderConn.(*net.TCPConn), conn); err != nil {
+	if err := proxy(leaderConn, conn); err != nil {
 		r.sendError(conn, fmt.Sprintf("leader proxy error: %v", err))
 	}
 }
@@ -91,7 +92,7 @@ func (r *rpc) handleRPCConn(conn net.Conn) {
 	}
 
 	if !r.store.IsLeader() && typ != internal.RPCType_PromoteRaft {
-		r.proxyLeader(conn.(*net.TCPConn), pack(typ, buf))
+		r.proxyLeader(conn, pack(typ, buf))
 		return
 	}
 
@@ -439,19 +440,13 @@ func (r *rpc) call(dest string, req proto.Message) (proto.Message, error) {
 		return nil, fmt.Errorf("unknown rpc request type: %v", t)
 	}
 
-	// Create a connection to the leader.
-	conn, err := net.DialTimeout("tcp", dest, leaderDialTimeout)
+	// Create a connection to the leader and write a marker byte for rpc messages.
+	conn, err := dialMux(dest, MuxRPCHeader, leaderDialTimeout, r.tlsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("rpc dial: %v", err)
 	}
 	defer conn.Close()
 
-	// Write a marker byte for rpc messages.
-	_, err = conn.Write([]byte{MuxRPCHeader})
-	if err != nil {
-		return nil, err
-	}
-
 	b, err := proto.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("rpc marshal: %v", err)
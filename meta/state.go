@@ -127,7 +127,7 @@ func (r *localRaft) open() error {
 	}
 
 	// Build raft layer to multiplex listener.
-	r.raftLayer = newRaftLayer(s.RaftListener, s.RemoteAddr)
+	r.raftLayer = newRaftLayer(s.RaftListener, s.RemoteAddr, s.TLSConfig)
 
 	// Create a transport layer
 	r.transport = raft.NewNetworkTransport(r.raftLayer, 3, 10*time.Second, config.LogOutput)
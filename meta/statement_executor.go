@@ -3,6 +3,7 @@ package meta
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -20,6 +21,8 @@ type StatementExecutor struct {
 		Leader() string
 
 		DeleteNode(nodeID uint64, force bool) error
+		SetNodeLabels(id uint64, labels map[string]string) error
+		SetNodeState(id uint64, state string) error
 		Database(name string) (*DatabaseInfo, error)
 		Databases() ([]DatabaseInfo, error)
 		CreateDatabase(name string) (*DatabaseInfo, error)
@@ -41,12 +44,105 @@ type StatementExecutor struct {
 		UserPrivileges(username string) (map[string]influxql.Privilege, error)
 		UserPrivilege(username, database string) (*influxql.Privilege, error)
 
+		Tokens() ([]TokenInfo, error)
+		CreateToken(name, username string, expiration time.Time) (string, error)
+		DropToken(name string) error
+
 		CreateContinuousQuery(database, name, query string) error
+		UpdateContinuousQuery(database, name, query string) error
 		DropContinuousQuery(database, name string) error
 
 		CreateSubscription(database, rp, name, mode string, destinations []string) error
 		DropSubscription(database, rp, name string) error
 	}
+
+	// Reports the run status of continuous queries and runs backfills. Optional.
+	ContinuousQuerier interface {
+		ContinuousQueryStatus(database, name string) *ContinuousQueryStatus
+		BackfillContinuousQuery(database, name string, start, end time.Time) error
+	}
+
+	// Compares and repairs shard replicas. Optional.
+	AntiEntropy interface {
+		ShardDifferences() ([]ShardDifference, error)
+		RepairShard(id uint64) (int64, error)
+	}
+
+	// Copies and moves shards between nodes. Optional.
+	ShardCopier interface {
+		CopyShard(id, from, to uint64) error
+		MoveShard(id, from, to uint64) error
+	}
+
+	// Lists and manages the hinted handoff queues on this node. Optional.
+	HintedHandoff interface {
+		Queues() ([]HintedHandoffQueue, error)
+		PurgeQueue(nodeID uint64) error
+		ReplayQueue(nodeID uint64) error
+	}
+}
+
+// ContinuousQueryStatus represents the outcome of the most recent run of a
+// continuous query on this node.
+type ContinuousQueryStatus struct {
+	LastRun       time.Time
+	LastDuration  time.Duration
+	PointsWritten int64
+	LastError     string
+
+	// Backfill is the progress of the most recent backfill, if any.
+	Backfill *ContinuousQueryBackfill
+}
+
+// ContinuousQueryBackfill represents the progress of a BACKFILL CONTINUOUS
+// QUERY running on this node.
+type ContinuousQueryBackfill struct {
+	StartTime     time.Time
+	EndTime       time.Time
+	Intervals     int64 // intervals completed
+	Total         int64 // intervals in the range
+	PointsWritten int64
+	Running       bool
+	Err           string
+}
+
+// String returns a summary of the backfill's progress.
+func (b *ContinuousQueryBackfill) String() string {
+	state := "completed"
+	if b.Running {
+		state = "running"
+	} else if b.Err != "" {
+		state = "failed"
+	}
+
+	str := fmt.Sprintf("%s %d/%d intervals from %s to %s, %d points written", state, b.Intervals, b.Total,
+		b.StartTime.UTC().Format(time.RFC3339Nano), b.EndTime.UTC().Format(time.RFC3339Nano), b.PointsWritten)
+	if b.Err != "" {
+		str += ": " + b.Err
+	}
+	return str
+}
+
+// ShardDifference represents a time range of a series where this node's copy
+// of a shard differs from the copy on another owner.
+type ShardDifference struct {
+	ShardID   uint64
+	OwnerID   uint64
+	Key       string
+	StartTime time.Time
+	EndTime   time.Time
+	LocalN    int64
+	RemoteN   int64
+}
+
+// HintedHandoffQueue represents the queue of writes on this node that are
+// waiting to be sent to another node.
+type HintedHandoffQueue struct {
+	NodeID        uint64
+	Size          int64     // bytes on disk
+	Oldest        time.Time // first write to the oldest segment, zero if empty
+	LastError     string    // empty if the last attempt succeeded
+	RetryInterval time.Duration
 }
 
 // ExecuteStatement executes stmt against the meta store as user.
@@ -70,6 +166,12 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement) *influxql.
 		return e.executeDropUserStatement(stmt)
 	case *influxql.ShowUsersStatement:
 		return e.executeShowUsersStatement(stmt)
+	case *influxql.CreateTokenStatement:
+		return e.executeCreateTokenStatement(stmt)
+	case *influxql.DropTokenStatement:
+		return e.executeDropTokenStatement(stmt)
+	case *influxql.ShowTokensStatement:
+		return e.executeShowTokensStatement(stmt)
 	case *influxql.GrantStatement:
 		return e.executeGrantStatement(stmt)
 	case *influxql.GrantAdminStatement:
@@ -88,6 +190,10 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement) *influxql.
 		return e.executeShowRetentionPoliciesStatement(stmt)
 	case *influxql.CreateContinuousQueryStatement:
 		return e.executeCreateContinuousQueryStatement(stmt)
+	case *influxql.AlterContinuousQueryStatement:
+		return e.executeAlterContinuousQueryStatement(stmt)
+	case *influxql.BackfillContinuousQueryStatement:
+		return e.executeBackfillContinuousQueryStatement(stmt)
 	case *influxql.DropContinuousQueryStatement:
 		return e.executeDropContinuousQueryStatement(stmt)
 	case *influxql.ShowContinuousQueriesStatement:
@@ -96,10 +202,26 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement) *influxql.
 		return e.executeShowShardsStatement(stmt)
 	case *influxql.ShowShardGroupsStatement:
 		return e.executeShowShardGroupsStatement(stmt)
+	case *influxql.ShowShardDifferencesStatement:
+		return e.executeShowShardDifferencesStatement(stmt)
+	case *influxql.ShowHintedHandoffStatement:
+		return e.executeShowHintedHandoffStatement(stmt)
+	case *influxql.PurgeHintedHandoffStatement:
+		return e.executePurgeHintedHandoffStatement(stmt)
+	case *influxql.ReplayHintedHandoffStatement:
+		return e.executeReplayHintedHandoffStatement(stmt)
+	case *influxql.RepairShardStatement:
+		return e.executeRepairShardStatement(stmt)
+	case *influxql.CopyShardStatement:
+		return e.executeCopyShardStatement(stmt)
+	case *influxql.MoveShardStatement:
+		return e.executeMoveShardStatement(stmt)
 	case *influxql.ShowStatsStatement:
 		return e.executeShowStatsStatement(stmt)
 	case *influxql.DropServerStatement:
 		return e.executeDropServerStatement(stmt)
+	case *influxql.AlterServerStatement:
+		return e.executeAlterServerStatement(stmt)
 	case *influxql.CreateSubscriptionStatement:
 		return e.executeCreateSubscriptionStatement(stmt)
 	case *influxql.DropSubscriptionStatement:
@@ -171,9 +293,13 @@ func (e *StatementExecutor) executeShowServersStatement(q *influxql.ShowServersS
 
 	leader := e.Store.Leader()
 
-	row := &models.Row{Columns: []string{"id", "cluster_addr", "raft", "raft-leader"}}
+	row := &models.Row{Columns: []string{"id", "cluster_addr", "raft", "raft-leader", "state", "labels"}}
 	for _, ni := range nis {
-		row.Values = append(row.Values, []interface{}{ni.ID, ni.Host, contains(peers, ni.Host), leader == ni.Host})
+		state := ni.State
+		if state == "" {
+			state = NodeStateActive
+		}
+		row.Values = append(row.Values, []interface{}{ni.ID, ni.Host, contains(peers, ni.Host), leader == ni.Host, state, formatNodeLabels(ni.Labels)})
 	}
 	return &influxql.Result{Series: []*models.Row{row}}
 }
@@ -191,6 +317,42 @@ func (e *StatementExecutor) executeDropServerStatement(q *influxql.DropServerSta
 	return &influxql.Result{Err: err}
 }
 
+func (e *StatementExecutor) executeAlterServerStatement(q *influxql.AlterServerStatement) *influxql.Result {
+	ni, err := e.Store.Node(q.NodeID)
+	if err != nil {
+		return &influxql.Result{Err: err}
+	}
+	if ni == nil {
+		return &influxql.Result{Err: ErrNodeNotFound}
+	}
+
+	// Merge the labels into the existing labels of the node.
+	if len(q.Labels) > 0 {
+		labels := make(map[string]string)
+		for k, v := range ni.Labels {
+			labels[k] = v
+		}
+		for k, v := range q.Labels {
+			if v == "" {
+				delete(labels, k)
+			} else {
+				labels[k] = v
+			}
+		}
+		if err := e.Store.SetNodeLabels(q.NodeID, labels); err != nil {
+			return &influxql.Result{Err: err}
+		}
+	}
+
+	if q.State != "" {
+		if err := e.Store.SetNodeState(q.NodeID, q.State); err != nil {
+			return &influxql.Result{Err: err}
+		}
+	}
+
+	return &influxql.Result{}
+}
+
 func (e *StatementExecutor) executeCreateUserStatement(q *influxql.CreateUserStatement) *influxql.Result {
 	_, err := e.Store.CreateUser(q.Name, q.Password, q.Admin)
 	return &influxql.Result{Err: err}
@@ -217,6 +379,48 @@ func (e *StatementExecutor) executeShowUsersStatement(q *influxql.ShowUsersState
 	return &influxql.Result{Series: []*models.Row{row}}
 }
 
+func (e *StatementExecutor) executeCreateTokenStatement(q *influxql.CreateTokenStatement) *influxql.Result {
+	var expiration time.Time
+	if q.Duration > 0 {
+		expiration = time.Now().UTC().Add(q.Duration)
+	}
+
+	token, err := e.Store.CreateToken(q.Name, q.Username, expiration)
+	if err != nil {
+		return &influxql.Result{Err: err}
+	}
+
+	// The token value is only ever returned here.
+	row := &models.Row{Columns: []string{"name", "token"}}
+	row.Values = append(row.Values, []interface{}{q.Name, token})
+	return &influxql.Result{Series: []*models.Row{row}}
+}
+
+func (e *StatementExecutor) executeDropTokenStatement(q *influxql.DropTokenStatement) *influxql.Result {
+	return &influxql.Result{Err: e.Store.DropToken(q.Name)}
+}
+
+func (e *StatementExecutor) executeShowTokensStatement(q *influxql.ShowTokensStatement) *influxql.Result {
+	tis, err := e.Store.Tokens()
+	if err != nil {
+		return &influxql.Result{Err: err}
+	}
+
+	row := &models.Row{Columns: []string{"name", "user", "expiration"}}
+	for _, ti := range tis {
+		if q.Username != "" && ti.Username != q.Username {
+			continue
+		}
+
+		var expiration string
+		if !ti.Expiration.IsZero() {
+			expiration = ti.Expiration.UTC().Format(time.RFC3339)
+		}
+		row.Values = append(row.Values, []interface{}{ti.Name, ti.Username, expiration})
+	}
+	return &influxql.Result{Series: []*models.Row{row}}
+}
+
 func (e *StatementExecutor) executeGrantStatement(stmt *influxql.GrantStatement) *influxql.Result {
 	return &influxql.Result{Err: e.Store.SetPrivilege(stmt.User, stmt.On, stmt.Privilege)}
 }
@@ -249,6 +453,7 @@ func (e *StatementExecutor) executeCreateRetentionPolicyStatement(stmt *influxql
 	rpi := NewRetentionPolicyInfo(stmt.Name)
 	rpi.Duration = stmt.Duration
 	rpi.ReplicaN = stmt.Replication
+	rpi.ShardPlacement = stmt.Placement
 
 	// Create new retention policy.
 	_, err := e.Store.CreateRetentionPolicy(stmt.Database, rpi)
@@ -266,8 +471,9 @@ func (e *StatementExecutor) executeCreateRetentionPolicyStatement(stmt *influxql
 
 func (e *StatementExecutor) executeAlterRetentionPolicyStatement(stmt *influxql.AlterRetentionPolicyStatement) *influxql.Result {
 	rpu := &RetentionPolicyUpdate{
-		Duration: stmt.Duration,
-		ReplicaN: stmt.Replication,
+		Duration:       stmt.Duration,
+		ReplicaN:       stmt.Replication,
+		ShardPlacement: stmt.Placement,
 	}
 
 	// Update the retention policy.
@@ -309,6 +515,24 @@ func (e *StatementExecutor) executeCreateContinuousQueryStatement(q *influxql.Cr
 	}
 }
 
+func (e *StatementExecutor) executeAlterContinuousQueryStatement(q *influxql.AlterContinuousQueryStatement) *influxql.Result {
+	// Store the query in its CREATE form so the continuous query service can parse it.
+	return &influxql.Result{
+		Err: e.Store.UpdateContinuousQuery(q.Database, q.Name, q.CreateStatement().String()),
+	}
+}
+
+func (e *StatementExecutor) executeBackfillContinuousQueryStatement(q *influxql.BackfillContinuousQueryStatement) *influxql.Result {
+	if e.ContinuousQuerier == nil {
+		return &influxql.Result{Err: ErrContinuousQueriesDisabled}
+	}
+
+	// The backfill runs in the background. Its progress is reported by SHOW CONTINUOUS QUERIES.
+	return &influxql.Result{
+		Err: e.ContinuousQuerier.BackfillContinuousQuery(q.Database, q.Name, q.StartTime, q.EndTime),
+	}
+}
+
 func (e *StatementExecutor) executeDropContinuousQueryStatement(q *influxql.DropContinuousQueryStatement) *influxql.Result {
 	return &influxql.Result{
 		Err: e.Store.DropContinuousQuery(q.Database, q.Name),
@@ -323,15 +547,39 @@ func (e *StatementExecutor) executeShowContinuousQueriesStatement(stmt *influxql
 
 	rows := []*models.Row{}
 	for _, di := range dis {
-		row := &models.Row{Columns: []string{"name", "query"}, Name: di.Name}
+		// The run status is only known to the node that ran the query, so
+		// the status columns are labelled local and the lease owner is shown.
+		row := &models.Row{Columns: []string{"name", "query", "owner", "local_last_run", "local_last_duration", "local_points_written", "local_last_error", "local_backfill"}, Name: di.Name}
 		for _, cqi := range di.ContinuousQueries {
-			row.Values = append(row.Values, []interface{}{cqi.Name, cqi.Query})
+			var lastRun, lastDuration string
+			var pointsWritten int64
+			var lastError, backfill string
+			if status := e.continuousQueryStatus(di.Name, cqi.Name); status != nil {
+				if !status.LastRun.IsZero() {
+					lastRun = status.LastRun.UTC().Format(time.RFC3339Nano)
+					lastDuration = status.LastDuration.String()
+				}
+				pointsWritten = status.PointsWritten
+				lastError = status.LastError
+				if status.Backfill != nil {
+					backfill = status.Backfill.String()
+				}
+			}
+			row.Values = append(row.Values, []interface{}{cqi.Name, cqi.Query, cqi.LeaseNodeID, lastRun, lastDuration, pointsWritten, lastError, backfill})
 		}
 		rows = append(rows, row)
 	}
 	return &influxql.Result{Series: rows}
 }
 
+// continuousQueryStatus returns the run status of a continuous query, if known.
+func (e *StatementExecutor) continuousQueryStatus(database, name string) *ContinuousQueryStatus {
+	if e.ContinuousQuerier == nil {
+		return nil
+	}
+	return e.ContinuousQuerier.ContinuousQueryStatus(database, name)
+}
+
 func (e *StatementExecutor) executeCreateSubscriptionStatement(q *influxql.CreateSubscriptionStatement) *influxql.Result {
 	return &influxql.Result{
 		Err: e.Store.CreateSubscription(q.Database, q.RetentionPolicy, q.Name, q.Mode, q.Destinations),
@@ -442,6 +690,106 @@ func (e *StatementExecutor) executeShowStatsStatement(stmt *influxql.ShowStatsSt
 }
 
 // joinUint64 returns a comma-delimited string of uint64 numbers.
+func (e *StatementExecutor) executeShowShardDifferencesStatement(stmt *influxql.ShowShardDifferencesStatement) *influxql.Result {
+	if e.AntiEntropy == nil {
+		return &influxql.Result{Err: ErrAntiEntropyDisabled}
+	}
+
+	diffs, err := e.AntiEntropy.ShardDifferences()
+	if err != nil {
+		return &influxql.Result{Err: err}
+	}
+
+	row := &models.Row{Columns: []string{"shard_id", "owner", "key", "start_time", "end_time", "local_values", "owner_values"}}
+	for _, d := range diffs {
+		row.Values = append(row.Values, []interface{}{
+			d.ShardID,
+			d.OwnerID,
+			d.Key,
+			d.StartTime.UTC().Format(time.RFC3339),
+			d.EndTime.UTC().Format(time.RFC3339),
+			d.LocalN,
+			d.RemoteN,
+		})
+	}
+	return &influxql.Result{Series: []*models.Row{row}}
+}
+
+func (e *StatementExecutor) executeRepairShardStatement(stmt *influxql.RepairShardStatement) *influxql.Result {
+	if e.AntiEntropy == nil {
+		return &influxql.Result{Err: ErrAntiEntropyDisabled}
+	}
+
+	n, err := e.AntiEntropy.RepairShard(stmt.ID)
+	if err != nil {
+		return &influxql.Result{Err: err}
+	}
+
+	// Report the points copied in the same form as SELECT INTO.
+	row := &models.Row{
+		Name:    "result",
+		Columns: []string{"time", "written"},
+		Values:  [][]interface{}{{time.Unix(0, 0).UTC(), n}},
+	}
+	return &influxql.Result{Series: []*models.Row{row}}
+}
+
+func (e *StatementExecutor) executeCopyShardStatement(stmt *influxql.CopyShardStatement) *influxql.Result {
+	if e.ShardCopier == nil {
+		return &influxql.Result{Err: ErrShardCopierUnavailable}
+	}
+	return &influxql.Result{Err: e.ShardCopier.CopyShard(stmt.ID, stmt.From, stmt.To)}
+}
+
+func (e *StatementExecutor) executeMoveShardStatement(stmt *influxql.MoveShardStatement) *influxql.Result {
+	if e.ShardCopier == nil {
+		return &influxql.Result{Err: ErrShardCopierUnavailable}
+	}
+	return &influxql.Result{Err: e.ShardCopier.MoveShard(stmt.ID, stmt.From, stmt.To)}
+}
+
+func (e *StatementExecutor) executeShowHintedHandoffStatement(stmt *influxql.ShowHintedHandoffStatement) *influxql.Result {
+	if e.HintedHandoff == nil {
+		return &influxql.Result{Err: ErrHintedHandoffUnavailable}
+	}
+
+	queues, err := e.HintedHandoff.Queues()
+	if err != nil {
+		return &influxql.Result{Err: err}
+	}
+
+	now := time.Now()
+	row := &models.Row{Columns: []string{"node", "size", "oldest_age", "last_error", "retry_interval"}}
+	for _, q := range queues {
+		var age time.Duration
+		if !q.Oldest.IsZero() {
+			age = now.Sub(q.Oldest) / time.Second * time.Second
+		}
+		row.Values = append(row.Values, []interface{}{
+			q.NodeID,
+			q.Size,
+			age.String(),
+			q.LastError,
+			q.RetryInterval.String(),
+		})
+	}
+	return &influxql.Result{Series: []*models.Row{row}}
+}
+
+func (e *StatementExecutor) executePurgeHintedHandoffStatement(stmt *influxql.PurgeHintedHandoffStatement) *influxql.Result {
+	if e.HintedHandoff == nil {
+		return &influxql.Result{Err: ErrHintedHandoffUnavailable}
+	}
+	return &influxql.Result{Err: e.HintedHandoff.PurgeQueue(stmt.NodeID)}
+}
+
+func (e *StatementExecutor) executeReplayHintedHandoffStatement(stmt *influxql.ReplayHintedHandoffStatement) *influxql.Result {
+	if e.HintedHandoff == nil {
+		return &influxql.Result{Err: ErrHintedHandoffUnavailable}
+	}
+	return &influxql.Result{Err: e.HintedHandoff.ReplayQueue(stmt.NodeID)}
+}
+
 func joinUint64(a []uint64) string {
 	var buf bytes.Buffer
 	for i, x := range a {
@@ -452,3 +800,21 @@ func joinUint64(a []uint64) string {
 	}
 	return buf.String()
 }
+
+// formatNodeLabels returns labels as comma-separated key=value pairs sorted by key.
+func formatNodeLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(k + "=" + labels[k])
+	}
+	return buf.String()
+}
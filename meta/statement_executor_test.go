@@ -2,6 +2,7 @@ package meta_test
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -120,7 +121,7 @@ func TestStatementExecutor_ExecuteStatement_ShowServers(t *testing.T) {
 	e.Store.NodesFn = func() ([]meta.NodeInfo, error) {
 		return []meta.NodeInfo{
 			{ID: 1, Host: "node0"},
-			{ID: 2, Host: "node1"},
+			{ID: 2, Host: "node1", State: "draining", Labels: map[string]string{"zone": "a", "rack": "r1"}},
 		}, nil
 	}
 	e.Store.PeersFn = func() ([]string, error) {
@@ -134,10 +135,10 @@ func TestStatementExecutor_ExecuteStatement_ShowServers(t *testing.T) {
 		t.Fatal(res.Err)
 	} else if !reflect.DeepEqual(res.Series, models.Rows{
 		{
-			Columns: []string{"id", "cluster_addr", "raft", "raft-leader"},
+			Columns: []string{"id", "cluster_addr", "raft", "raft-leader", "state", "labels"},
 			Values: [][]interface{}{
-				{uint64(1), "node0", true, true},
-				{uint64(2), "node1", false, false},
+				{uint64(1), "node0", true, true, "active", ""},
+				{uint64(2), "node1", false, false, "draining", "rack=r1,zone=a"},
 			},
 		},
 	}) {
@@ -188,6 +189,41 @@ func TestStatementExecutor_ExecuteStatement_DropServer(t *testing.T) {
 	}
 }
 
+// Ensure an ALTER SERVER statement can be executed.
+func TestStatementExecutor_ExecuteStatement_AlterServer(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.NodeFn = func(id uint64) (*meta.NodeInfo, error) {
+		if id != 1 {
+			return nil, nil
+		}
+		return &meta.NodeInfo{ID: 1, Host: "node0", Labels: map[string]string{"zone": "a", "role": "data"}}, nil
+	}
+
+	var labels map[string]string
+	var state string
+	e.Store.SetNodeLabelsFn = func(id uint64, l map[string]string) error {
+		labels = l
+		return nil
+	}
+	e.Store.SetNodeStateFn = func(id uint64, s string) error {
+		state = s
+		return nil
+	}
+
+	// Labels are merged and empty labels are removed.
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`ALTER SERVER 1 STATE 'draining' LABELS rack = 'r1', role = ''`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(labels, map[string]string{"zone": "a", "rack": "r1"}) {
+		t.Fatalf("unexpected labels: %v", labels)
+	} else if state != "draining" {
+		t.Fatalf("unexpected state: %s", state)
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`ALTER SERVER 2 STATE 'draining'`)); res.Err != meta.ErrNodeNotFound {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
 // Ensure a SHOW SERVERS statement returns errors from the store.
 func TestStatementExecutor_ExecuteStatement_ShowServers_Err(t *testing.T) {
 	e := NewStatementExecutor()
@@ -330,6 +366,87 @@ func TestStatementExecutor_ExecuteStatement_ShowUsers_Err(t *testing.T) {
 	}
 }
 
+// Ensure a CREATE TOKEN statement can be executed.
+func TestStatementExecutor_ExecuteStatement_CreateToken(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.CreateTokenFn = func(name, username string, expiration time.Time) (string, error) {
+		if name != "grafana" {
+			t.Fatalf("unexpected name: %s", name)
+		} else if username != "susy" {
+			t.Fatalf("unexpected username: %s", username)
+		} else if d := expiration.Sub(time.Now()); d <= 23*time.Hour || d > 24*time.Hour {
+			t.Fatalf("unexpected expiration: %s", expiration)
+		}
+		return "abcd", nil
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`CREATE TOKEN grafana FOR susy DURATION 1d`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(res.Series, models.Rows{
+		{
+			Columns: []string{"name", "token"},
+			Values:  [][]interface{}{{"grafana", "abcd"}},
+		},
+	}) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
+// Ensure a CREATE TOKEN statement returns errors from the store.
+func TestStatementExecutor_ExecuteStatement_CreateToken_Err(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.CreateTokenFn = func(name, username string, expiration time.Time) (string, error) {
+		return "", errors.New("marker")
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`CREATE TOKEN grafana FOR susy`)); res.Err == nil || res.Err.Error() != "marker" {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
+// Ensure a DROP TOKEN statement can be executed.
+func TestStatementExecutor_ExecuteStatement_DropToken(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.DropTokenFn = func(name string) error {
+		if name != "grafana" {
+			t.Fatalf("unexpected name: %s", name)
+		}
+		return nil
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`DROP TOKEN grafana`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if res.Series != nil {
+		t.Fatalf("unexpected rows: %#v", res.Series)
+	}
+}
+
+// Ensure a SHOW TOKENS statement can be executed and filtered by user.
+func TestStatementExecutor_ExecuteStatement_ShowTokens(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.TokensFn = func() ([]meta.TokenInfo, error) {
+		return []meta.TokenInfo{
+			{Name: "grafana", Username: "susy", Expiration: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Name: "telegraf", Username: "bob"},
+			{Name: "kapacitor", Username: "susy"},
+		}, nil
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`SHOW TOKENS FOR susy`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(res.Series, models.Rows{
+		{
+			Columns: []string{"name", "user", "expiration"},
+			Values: [][]interface{}{
+				{"grafana", "susy", "2000-01-01T00:00:00Z"},
+				{"kapacitor", "susy", ""},
+			},
+		},
+	}) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
 // Ensure a GRANT statement can be executed.
 func TestStatementExecutor_ExecuteStatement_Grant(t *testing.T) {
 	e := NewStatementExecutor()
@@ -734,6 +851,77 @@ func TestStatementExecutor_ExecuteStatement_DropContinuousQuery_Err(t *testing.T
 	}
 }
 
+// Ensure an ALTER CONTINUOUS QUERY statement can be executed.
+func TestStatementExecutor_ExecuteStatement_AlterContinuousQuery(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.UpdateContinuousQueryFn = func(database, name, query string) error {
+		if database != "db0" {
+			t.Fatalf("unexpected database: %s", database)
+		} else if name != "cq0" {
+			t.Fatalf("unexpected name: %s", name)
+		} else if query != `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(field1) INTO db1 FROM db0 GROUP BY time(1h) END` {
+			t.Fatalf("unexpected query: %s", query)
+		}
+		return nil
+	}
+
+	stmt := influxql.MustParseStatement(`ALTER CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(field1) INTO db1 FROM db0 GROUP BY time(1h) END`)
+	if res := e.ExecuteStatement(stmt); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if res.Series != nil {
+		t.Fatalf("unexpected rows: %#v", res.Series)
+	}
+}
+
+// Ensure an ALTER CONTINUOUS QUERY statement can return an error from the store.
+func TestStatementExecutor_ExecuteStatement_AlterContinuousQuery_Err(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.UpdateContinuousQueryFn = func(database, name, query string) error {
+		return errors.New("marker")
+	}
+
+	stmt := influxql.MustParseStatement(`ALTER CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(field1) INTO db1 FROM db0 GROUP BY time(1h) END`)
+	if res := e.ExecuteStatement(stmt); res.Err == nil || res.Err.Error() != "marker" {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
+// Ensure a BACKFILL CONTINUOUS QUERY statement can be executed.
+func TestStatementExecutor_ExecuteStatement_BackfillContinuousQuery(t *testing.T) {
+	e := NewStatementExecutor()
+	e.ContinuousQuerier = &ContinuousQuerier{
+		BackfillContinuousQueryFn: func(database, name string, start, end time.Time) error {
+			if database != "db0" {
+				t.Fatalf("unexpected database: %s", database)
+			} else if name != "cq0" {
+				t.Fatalf("unexpected name: %s", name)
+			} else if !start.Equal(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected start: %s", start)
+			} else if !end.Equal(time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected end: %s", end)
+			}
+			return nil
+		},
+	}
+
+	stmt := influxql.MustParseStatement(`BACKFILL CONTINUOUS QUERY cq0 ON db0 FROM '2015-01-01' TO '2015-01-02'`)
+	if res := e.ExecuteStatement(stmt); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if res.Series != nil {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
+// Ensure a BACKFILL CONTINUOUS QUERY statement returns an error if the continuous query service isn't running.
+func TestStatementExecutor_ExecuteStatement_BackfillContinuousQuery_Disabled(t *testing.T) {
+	e := NewStatementExecutor()
+
+	stmt := influxql.MustParseStatement(`BACKFILL CONTINUOUS QUERY cq0 ON db0 FROM '2015-01-01' TO '2015-01-02'`)
+	if res := e.ExecuteStatement(stmt); res.Err != meta.ErrContinuousQueriesDisabled {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
 // Ensure a SHOW CONTINUOUS QUERIES statement can be executed.
 func TestStatementExecutor_ExecuteStatement_ShowContinuousQueries(t *testing.T) {
 	e := NewStatementExecutor()
@@ -761,17 +949,72 @@ func TestStatementExecutor_ExecuteStatement_ShowContinuousQueries(t *testing.T)
 	} else if !reflect.DeepEqual(res.Series, models.Rows{
 		{
 			Name:    "db0",
-			Columns: []string{"name", "query"},
+			Columns: []string{"name", "query", "owner", "local_last_run", "local_last_duration", "local_points_written", "local_last_error", "local_backfill"},
 			Values: [][]interface{}{
-				{"cq0", "SELECT count(field1) INTO db1 FROM db0"},
-				{"cq1", "SELECT count(field1) INTO db2 FROM db0"},
+				{"cq0", "SELECT count(field1) INTO db1 FROM db0", uint64(0), "", "", int64(0), "", ""},
+				{"cq1", "SELECT count(field1) INTO db2 FROM db0", uint64(0), "", "", int64(0), "", ""},
 			},
 		},
 		{
 			Name:    "db1",
-			Columns: []string{"name", "query"},
+			Columns: []string{"name", "query", "owner", "local_last_run", "local_last_duration", "local_points_written", "local_last_error", "local_backfill"},
 			Values: [][]interface{}{
-				{"cq2", "SELECT count(field1) INTO db3 FROM db1"},
+				{"cq2", "SELECT count(field1) INTO db3 FROM db1", uint64(0), "", "", int64(0), "", ""},
+			},
+		},
+	}) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
+// Ensure a SHOW CONTINUOUS QUERIES statement reports the run status of each query.
+func TestStatementExecutor_ExecuteStatement_ShowContinuousQueries_Status(t *testing.T) {
+	e := NewStatementExecutor()
+	e.Store.DatabasesFn = func() ([]meta.DatabaseInfo, error) {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				ContinuousQueries: []meta.ContinuousQueryInfo{
+					{Name: "cq0", Query: "SELECT count(field1) INTO db1 FROM db0"},
+					{Name: "cq1", Query: "SELECT count(field1) INTO db2 FROM db0", LeaseNodeID: 2},
+				},
+			},
+		}, nil
+	}
+	e.ContinuousQuerier = &ContinuousQuerier{
+		ContinuousQueryStatusFn: func(database, name string) *meta.ContinuousQueryStatus {
+			if database != "db0" {
+				t.Fatalf("unexpected database: %s", database)
+			} else if name != "cq1" {
+				return nil
+			}
+			return &meta.ContinuousQueryStatus{
+				LastRun:       time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+				LastDuration:  2 * time.Second,
+				PointsWritten: 10,
+				LastError:     "marker",
+				Backfill: &meta.ContinuousQueryBackfill{
+					StartTime:     time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndTime:       time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+					Intervals:     3,
+					Total:         24,
+					PointsWritten: 6,
+					Running:       true,
+				},
+			}
+		},
+	}
+
+	stmt := influxql.MustParseStatement(`SHOW CONTINUOUS QUERIES`)
+	if res := e.ExecuteStatement(stmt); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(res.Series, models.Rows{
+		{
+			Name:    "db0",
+			Columns: []string{"name", "query", "owner", "local_last_run", "local_last_duration", "local_points_written", "local_last_error", "local_backfill"},
+			Values: [][]interface{}{
+				{"cq0", "SELECT count(field1) INTO db1 FROM db0", uint64(0), "", "", int64(0), "", ""},
+				{"cq1", "SELECT count(field1) INTO db2 FROM db0", uint64(2), "2000-01-01T00:00:00Z", "2s", int64(10), "marker", "running 3/24 intervals from 2000-01-01T00:00:00Z to 2000-01-02T00:00:00Z, 6 points written"},
 			},
 		},
 	}) {
@@ -1076,6 +1319,177 @@ func TestStatementExecutor_ExecuteStatement_ShowShards(t *testing.T) {
 	}
 }
 
+// Ensure a SHOW SHARD DIFFERENCES statement can be executed.
+func TestStatementExecutor_ExecuteStatement_ShowShardDifferences(t *testing.T) {
+	e := NewStatementExecutor()
+	e.AntiEntropy = &AntiEntropy{
+		ShardDifferencesFn: func() ([]meta.ShardDifference, error) {
+			return []meta.ShardDifference{
+				{
+					ShardID:   1,
+					OwnerID:   2,
+					Key:       "cpu,host=a",
+					StartTime: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2015, 1, 1, 1, 0, 0, 0, time.UTC),
+					LocalN:    10,
+					RemoteN:   8,
+				},
+			}, nil
+		},
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`SHOW SHARD DIFFERENCES`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(res.Series, models.Rows{
+		{
+			Columns: []string{"shard_id", "owner", "key", "start_time", "end_time", "local_values", "owner_values"},
+			Values: [][]interface{}{
+				{uint64(1), uint64(2), "cpu,host=a", "2015-01-01T00:00:00Z", "2015-01-01T01:00:00Z", int64(10), int64(8)},
+			},
+		},
+	}) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
+// Ensure a REPAIR SHARD statement can be executed.
+func TestStatementExecutor_ExecuteStatement_RepairShard(t *testing.T) {
+	e := NewStatementExecutor()
+	e.AntiEntropy = &AntiEntropy{
+		RepairShardFn: func(id uint64) (int64, error) {
+			if id != 2 {
+				t.Fatalf("unexpected shard id: %d", id)
+			}
+			return 5, nil
+		},
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`REPAIR SHARD 2`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(res.Series, models.Rows{
+		{
+			Name:    "result",
+			Columns: []string{"time", "written"},
+			Values: [][]interface{}{
+				{time.Unix(0, 0).UTC(), int64(5)},
+			},
+		},
+	}) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
+// Ensure a REPAIR SHARD statement returns an error if the anti-entropy service isn't running.
+func TestStatementExecutor_ExecuteStatement_RepairShard_Disabled(t *testing.T) {
+	e := NewStatementExecutor()
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`REPAIR SHARD 2`)); res.Err != meta.ErrAntiEntropyDisabled {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
+// Ensure a COPY SHARD statement can be executed.
+func TestStatementExecutor_ExecuteStatement_CopyShard(t *testing.T) {
+	e := NewStatementExecutor()
+	e.ShardCopier = &ShardCopier{
+		CopyShardFn: func(id, from, to uint64) error {
+			if id != 2 || from != 1 || to != 3 {
+				t.Fatalf("unexpected args: id=%d, from=%d, to=%d", id, from, to)
+			}
+			return nil
+		},
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`COPY SHARD 2 FROM 1 TO 3`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if res.Series != nil {
+		t.Fatalf("unexpected rows: %#v", res.Series)
+	}
+}
+
+// Ensure a MOVE SHARD statement can be executed.
+func TestStatementExecutor_ExecuteStatement_MoveShard(t *testing.T) {
+	e := NewStatementExecutor()
+	e.ShardCopier = &ShardCopier{
+		MoveShardFn: func(id, from, to uint64) error {
+			if id != 2 || from != 1 || to != 3 {
+				t.Fatalf("unexpected args: id=%d, from=%d, to=%d", id, from, to)
+			}
+			return meta.ErrShardOwnerExists
+		},
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`MOVE SHARD 2 FROM 1 TO 3`)); res.Err != meta.ErrShardOwnerExists {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
+// Ensure a COPY SHARD statement returns an error if no copier is available.
+func TestStatementExecutor_ExecuteStatement_CopyShard_Unavailable(t *testing.T) {
+	e := NewStatementExecutor()
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`COPY SHARD 2 FROM 1 TO 3`)); res.Err != meta.ErrShardCopierUnavailable {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
+// Ensure a SHOW HINTED HANDOFF statement can be executed.
+func TestStatementExecutor_ExecuteStatement_ShowHintedHandoff(t *testing.T) {
+	e := NewStatementExecutor()
+	e.HintedHandoff = &HintedHandoff{
+		QueuesFn: func() ([]meta.HintedHandoffQueue, error) {
+			return []meta.HintedHandoffQueue{
+				{NodeID: 2, Size: 100, Oldest: time.Now().Add(-90 * time.Second), LastError: "marker", RetryInterval: 2 * time.Second},
+				{NodeID: 3, RetryInterval: time.Second},
+			}, nil
+		},
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`SHOW HINTED HANDOFF`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if !reflect.DeepEqual(res.Series, models.Rows{
+		{
+			Columns: []string{"node", "size", "oldest_age", "last_error", "retry_interval"},
+			Values: [][]interface{}{
+				{uint64(2), int64(100), "1m30s", "marker", "2s"},
+				{uint64(3), int64(0), "0s", "", "1s"},
+			},
+		},
+	}) {
+		t.Fatalf("unexpected rows: %s", spew.Sdump(res.Series))
+	}
+}
+
+// Ensure PURGE and REPLAY HINTED HANDOFF statements can be executed.
+func TestStatementExecutor_ExecuteStatement_PurgeReplayHintedHandoff(t *testing.T) {
+	var calls []string
+	e := NewStatementExecutor()
+	e.HintedHandoff = &HintedHandoff{
+		PurgeQueueFn: func(nodeID uint64) error {
+			calls = append(calls, fmt.Sprintf("purge %d", nodeID))
+			return nil
+		},
+		ReplayQueueFn: func(nodeID uint64) error {
+			calls = append(calls, fmt.Sprintf("replay %d", nodeID))
+			return errors.New("marker")
+		},
+	}
+
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`PURGE HINTED HANDOFF FOR 2`)); res.Err != nil {
+		t.Fatal(res.Err)
+	} else if res := e.ExecuteStatement(influxql.MustParseStatement(`REPLAY HINTED HANDOFF FOR 3`)); res.Err == nil || res.Err.Error() != "marker" {
+		t.Fatalf("unexpected error: %v", res.Err)
+	} else if !reflect.DeepEqual(calls, []string{"purge 2", "replay 3"}) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+// Ensure hinted handoff statements return an error if hinted handoff isn't available.
+func TestStatementExecutor_ExecuteStatement_HintedHandoff_Unavailable(t *testing.T) {
+	e := NewStatementExecutor()
+	if res := e.ExecuteStatement(influxql.MustParseStatement(`SHOW HINTED HANDOFF`)); res.Err != meta.ErrHintedHandoffUnavailable {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+}
+
 // StatementExecutor represents a test wrapper for meta.StatementExecutor.
 type StatementExecutor struct {
 	*meta.StatementExecutor
@@ -1101,6 +1515,8 @@ type StatementExecutorStore struct {
 	CreateDatabaseWithRetentionPolicyFn func(name string, rpi *meta.RetentionPolicyInfo) (*meta.DatabaseInfo, error)
 	DropDatabaseFn                      func(name string) error
 	DeleteNodeFn                        func(nodeID uint64, force bool) error
+	SetNodeLabelsFn                     func(id uint64, labels map[string]string) error
+	SetNodeStateFn                      func(id uint64, state string) error
 	DefaultRetentionPolicyFn            func(database string) (*meta.RetentionPolicyInfo, error)
 	CreateRetentionPolicyFn             func(database string, rpi *meta.RetentionPolicyInfo) (*meta.RetentionPolicyInfo, error)
 	UpdateRetentionPolicyFn             func(database, name string, rpu *meta.RetentionPolicyUpdate) error
@@ -1114,8 +1530,12 @@ type StatementExecutorStore struct {
 	SetAdminPrivilegeFn                 func(username string, admin bool) error
 	UserPrivilegesFn                    func(username string) (map[string]influxql.Privilege, error)
 	UserPrivilegeFn                     func(username, database string) (*influxql.Privilege, error)
+	TokensFn                            func() ([]meta.TokenInfo, error)
+	CreateTokenFn                       func(name, username string, expiration time.Time) (string, error)
+	DropTokenFn                         func(name string) error
 	ContinuousQueriesFn                 func() ([]meta.ContinuousQueryInfo, error)
 	CreateContinuousQueryFn             func(database, name, query string) error
+	UpdateContinuousQueryFn             func(database, name, query string) error
 	DropContinuousQueryFn               func(database, name string) error
 	CreateSubscriptionFn                func(database, rp, name, typ string, hosts []string) error
 	DropSubscriptionFn                  func(database, rp, name string) error
@@ -1144,6 +1564,14 @@ func (s *StatementExecutorStore) DeleteNode(nodeID uint64, force bool) error {
 	return s.DeleteNodeFn(nodeID, force)
 }
 
+func (s *StatementExecutorStore) SetNodeLabels(id uint64, labels map[string]string) error {
+	return s.SetNodeLabelsFn(id, labels)
+}
+
+func (s *StatementExecutorStore) SetNodeState(id uint64, state string) error {
+	return s.SetNodeStateFn(id, state)
+}
+
 func (s *StatementExecutorStore) Database(name string) (*meta.DatabaseInfo, error) {
 	return s.DatabaseFn(name)
 }
@@ -1200,6 +1628,18 @@ func (s *StatementExecutorStore) DropUser(name string) error {
 	return s.DropUserFn(name)
 }
 
+func (s *StatementExecutorStore) Tokens() ([]meta.TokenInfo, error) {
+	return s.TokensFn()
+}
+
+func (s *StatementExecutorStore) CreateToken(name, username string, expiration time.Time) (string, error) {
+	return s.CreateTokenFn(name, username, expiration)
+}
+
+func (s *StatementExecutorStore) DropToken(name string) error {
+	return s.DropTokenFn(name)
+}
+
 func (s *StatementExecutorStore) SetPrivilege(username, database string, p influxql.Privilege) error {
 	return s.SetPrivilegeFn(username, database, p)
 }
@@ -1224,6 +1664,10 @@ func (s *StatementExecutorStore) CreateContinuousQuery(database, name, query str
 	return s.CreateContinuousQueryFn(database, name, query)
 }
 
+func (s *StatementExecutorStore) UpdateContinuousQuery(database, name, query string) error {
+	return s.UpdateContinuousQueryFn(database, name, query)
+}
+
 func (s *StatementExecutorStore) DropContinuousQuery(database, name string) error {
 	return s.DropContinuousQueryFn(database, name)
 }
@@ -1235,3 +1679,51 @@ func (s *StatementExecutorStore) CreateSubscription(database, rp, name, typ stri
 func (s *StatementExecutorStore) DropSubscription(database, rp, name string) error {
 	return s.DropSubscriptionFn(database, rp, name)
 }
+
+// ContinuousQuerier is a mockable implementation of StatementExecutor.ContinuousQuerier.
+type ContinuousQuerier struct {
+	ContinuousQueryStatusFn   func(database, name string) *meta.ContinuousQueryStatus
+	BackfillContinuousQueryFn func(database, name string, start, end time.Time) error
+}
+
+func (c *ContinuousQuerier) ContinuousQueryStatus(database, name string) *meta.ContinuousQueryStatus {
+	return c.ContinuousQueryStatusFn(database, name)
+}
+
+func (c *ContinuousQuerier) BackfillContinuousQuery(database, name string, start, end time.Time) error {
+	return c.BackfillContinuousQueryFn(database, name, start, end)
+}
+
+// AntiEntropy is a mockable implementation of StatementExecutor.AntiEntropy.
+type AntiEntropy struct {
+	ShardDifferencesFn func() ([]meta.ShardDifference, error)
+	RepairShardFn      func(id uint64) (int64, error)
+}
+
+func (a *AntiEntropy) ShardDifferences() ([]meta.ShardDifference, error) {
+	return a.ShardDifferencesFn()
+}
+
+func (a *AntiEntropy) RepairShard(id uint64) (int64, error) {
+	return a.RepairShardFn(id)
+}
+
+// ShardCopier is a mockable implementation of StatementExecutor.ShardCopier.
+type ShardCopier struct {
+	CopyShardFn func(id, from, to uint64) error
+	MoveShardFn func(id, from, to uint64) error
+}
+
+func (c *ShardCopier) CopyShard(id, from, to uint64) error { return c.CopyShardFn(id, from, to) }
+func (c *ShardCopier) MoveShard(id, from, to uint64) error { return c.MoveShardFn(id, from, to) }
+
+// HintedHandoff is a mockable implementation of StatementExecutor.HintedHandoff.
+type HintedHandoff struct {
+	QueuesFn      func() ([]meta.HintedHandoffQueue, error)
+	PurgeQueueFn  func(nodeID uint64) error
+	ReplayQueueFn func(nodeID uint64) error
+}
+
+func (h *HintedHandoff) Queues() ([]meta.HintedHandoffQueue, error) { return h.QueuesFn() }
+func (h *HintedHandoff) PurgeQueue(nodeID uint64) error             { return h.PurgeQueueFn(nodeID) }
+func (h *HintedHandoff) ReplayQueue(nodeID uint64) error            { return h.ReplayQueueFn(nodeID) }
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -37,6 +39,9 @@ const (
 	// SaltBytes is the number of bytes used for salts
 	SaltBytes = 32
 
+	// TokenBytes is the number of random bytes in an API token
+	TokenBytes = 32
+
 	DefaultSyncNodeDelay = time.Second
 )
 
@@ -102,6 +107,9 @@ type Store struct {
 	// The listener for higher-level, cluster operations
 	RPCListener net.Listener
 
+	// If set, connections to other nodes use TLS.
+	TLSConfig *tls.Config
+
 	// The advertised hostname of the store.
 	Addr net.Addr
 
@@ -204,6 +212,9 @@ func (s *Store) Open() error {
 
 	s.Logger.Printf("Using data dir: %v", s.Path())
 
+	// Connections to other nodes from the RPC layer use the same TLS config.
+	s.rpc.tlsConfig = s.TLSConfig
+
 	if err := func() error {
 		s.mu.Lock()
 		defer s.mu.Unlock()
@@ -775,15 +786,14 @@ func (s *Store) handleExecConn(conn net.Conn) {
 			return
 		}
 
-		leaderConn, err := net.DialTimeout("tcp", s.Leader(), 10*time.Second)
+		leaderConn, err := dialMux(s.Leader(), MuxExecHeader, 10*time.Second, s.TLSConfig)
 		if err != nil {
 			s.Logger.Printf("Dial leader: %v", err)
 			return
 		}
 		defer leaderConn.Close()
-		leaderConn.Write([]byte{MuxExecHeader})
 
-		if err := proxy(leaderConn.(*net.TCPConn), conn.(*net.TCPConn)); err != nil {
+		if err := proxy(leaderConn, conn); err != nil {
 			s.Logger.Printf("Leader proxy error: %v", err)
 		}
 		conn.Close()
@@ -954,6 +964,28 @@ func (s *Store) UpdateNode(id uint64, host string) (*NodeInfo, error) {
 	return s.NodeByHost(host)
 }
 
+// SetNodeLabels replaces the labels of a node. Labels such as zone and rack
+// are used by shard placement.
+func (s *Store) SetNodeLabels(id uint64, labels map[string]string) error {
+	return s.exec(internal.Command_SetNodeLabelsCommand, internal.E_SetNodeLabelsCommand_Command,
+		&internal.SetNodeLabelsCommand{
+			ID:     proto.Uint64(id),
+			Labels: marshalNodeLabels(labels),
+		},
+	)
+}
+
+// SetNodeState sets the state of a node. Draining nodes get no new shards
+// and decommissioned nodes can be dropped without losing data.
+func (s *Store) SetNodeState(id uint64, state string) error {
+	return s.exec(internal.Command_SetNodeStateCommand, internal.E_SetNodeStateCommand_Command,
+		&internal.SetNodeStateCommand{
+			ID:    proto.Uint64(id),
+			State: proto.String(state),
+		},
+	)
+}
+
 // DeleteNode removes a node from the metastore by id.
 func (s *Store) DeleteNode(id uint64, force bool) error {
 	ni := s.data.Node(id)
@@ -1205,11 +1237,12 @@ func (s *Store) UpdateRetentionPolicy(database, name string, rpu *RetentionPolic
 
 	return s.exec(internal.Command_UpdateRetentionPolicyCommand, internal.E_UpdateRetentionPolicyCommand_Command,
 		&internal.UpdateRetentionPolicyCommand{
-			Database: proto.String(database),
-			Name:     proto.String(name),
-			NewName:  newName,
-			Duration: duration,
-			ReplicaN: replicaN,
+			Database:       proto.String(database),
+			Name:           proto.String(name),
+			NewName:        newName,
+			Duration:       duration,
+			ReplicaN:       replicaN,
+			ShardPlacement: rpu.ShardPlacement,
 		},
 	)
 }
@@ -1275,6 +1308,26 @@ func (s *Store) DeleteShardGroup(database, policy string, id uint64) error {
 	)
 }
 
+// AddShardOwner adds a node to the owners of a shard.
+func (s *Store) AddShardOwner(id, nodeID uint64) error {
+	return s.exec(internal.Command_AddShardOwnerCommand, internal.E_AddShardOwnerCommand_Command,
+		&internal.AddShardOwnerCommand{
+			ID:     proto.Uint64(id),
+			NodeID: proto.Uint64(nodeID),
+		},
+	)
+}
+
+// RemoveShardOwner removes a node from the owners of a shard.
+func (s *Store) RemoveShardOwner(id, nodeID uint64) error {
+	return s.exec(internal.Command_RemoveShardOwnerCommand, internal.E_RemoveShardOwnerCommand_Command,
+		&internal.RemoveShardOwnerCommand{
+			ID:     proto.Uint64(id),
+			NodeID: proto.Uint64(nodeID),
+		},
+	)
+}
+
 // ShardGroups returns a list of all shard groups for a policy by timestamp.
 func (s *Store) ShardGroups(database, policy string) (a []ShardGroupInfo, err error) {
 	err = s.read(func(data *Data) error {
@@ -1367,6 +1420,35 @@ func (s *Store) CreateContinuousQuery(database, name, query string) error {
 	)
 }
 
+// UpdateContinuousQuery replaces the query of an existing continuous query on the store.
+func (s *Store) UpdateContinuousQuery(database, name, query string) error {
+	return s.exec(internal.Command_UpdateContinuousQueryCommand, internal.E_UpdateContinuousQueryCommand_Command,
+		&internal.UpdateContinuousQueryCommand{
+			Database: proto.String(database),
+			Name:     proto.String(name),
+			Query:    proto.String(query),
+		},
+	)
+}
+
+// AcquireContinuousQueryLease acquires or renews the lease for nodeID to run a
+// continuous query for duration d. Returns ErrContinuousQueryLeaseHeld if
+// another node holds an unexpired lease. The leader stamps the lease with its
+// own clock so that leases are not affected by clock skew between nodes.
+func (s *Store) AcquireContinuousQueryLease(database, name string, nodeID uint64, d time.Duration) error {
+	now := time.Now()
+	return s.exec(internal.Command_AcquireContinuousQueryLeaseCommand, internal.E_AcquireContinuousQueryLeaseCommand_Command,
+		&internal.AcquireContinuousQueryLeaseCommand{
+			Database:   proto.String(database),
+			Name:       proto.String(name),
+			NodeID:     proto.Uint64(nodeID),
+			Time:       proto.Int64(now.UnixNano()),
+			Expiration: proto.Int64(now.Add(d).UnixNano()),
+			Duration:   proto.Int64(int64(d)),
+		},
+	)
+}
+
 // DropContinuousQuery removes a continuous query from the store.
 func (s *Store) DropContinuousQuery(database, name string) error {
 	return s.exec(internal.Command_DropContinuousQueryCommand, internal.E_DropContinuousQueryCommand_Command,
@@ -1533,6 +1615,72 @@ func (s *Store) DropUser(name string) error {
 	)
 }
 
+// Tokens returns a list of all tokens.
+func (s *Store) Tokens() (a []TokenInfo, err error) {
+	err = s.read(func(data *Data) error {
+		a = data.Tokens
+		return nil
+	})
+	return
+}
+
+// CreateToken creates a new API token for a user and returns the token value.
+// Only a hash of the value is stored so it cannot be retrieved again.
+// A zero expiration creates a token that never expires.
+func (s *Store) CreateToken(name, username string, expiration time.Time) (string, error) {
+	b := make([]byte, TokenBytes)
+	if _, err := io.ReadFull(crand.Reader, b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	if err := s.exec(internal.Command_CreateTokenCommand, internal.E_CreateTokenCommand_Command,
+		&internal.CreateTokenCommand{
+			Name:       proto.String(name),
+			Username:   proto.String(username),
+			Hash:       proto.String(hashToken(token)),
+			Expiration: proto.Int64(MarshalTime(expiration)),
+		},
+	); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// DropToken removes a token by name.
+func (s *Store) DropToken(name string) error {
+	return s.exec(internal.Command_DropTokenCommand, internal.E_DropTokenCommand_Command,
+		&internal.DropTokenCommand{
+			Name: proto.String(name),
+		},
+	)
+}
+
+// AuthenticateToken retrieves the user bound to an API token.
+func (s *Store) AuthenticateToken(token string) (ui *UserInfo, err error) {
+	err = s.read(func(data *Data) error {
+		ti := data.TokenByHash(hashToken(token))
+		if ti == nil {
+			return ErrAuthenticate
+		} else if ti.Expired(time.Now()) {
+			return ErrTokenExpired
+		}
+
+		if ui = data.User(ti.Username); ui == nil {
+			return ErrAuthenticate
+		}
+		return nil
+	})
+	return
+}
+
+// hashToken returns the hex encoded SHA-256 hash of an API token. Tokens are
+// random so a fast unsalted hash is enough to protect them at rest.
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
 // UpdateUser updates an existing user in the store.
 func (s *Store) UpdateUser(name, password string) error {
 	// Hash the password before serializing it.
@@ -1707,9 +1855,36 @@ func (s *Store) exec(typ internal.Command_Type, desc *proto.ExtensionDesc, value
 
 // apply applies a serialized command to the raft log.
 func (s *Store) apply(b []byte) error {
+	b, err := stampLeaderTime(b, time.Now())
+	if err != nil {
+		return err
+	}
 	return s.raftState.apply(b)
 }
 
+// stampLeaderTime sets the time on commands that are measured against the
+// leader's clock. Other commands are returned unchanged.
+func stampLeaderTime(b []byte, now time.Time) ([]byte, error) {
+	var cmd internal.Command
+	if err := proto.Unmarshal(b, &cmd); err != nil {
+		return nil, err
+	} else if cmd.GetType() != internal.Command_AcquireContinuousQueryLeaseCommand {
+		return b, nil
+	}
+
+	ext, _ := proto.GetExtension(&cmd, internal.E_AcquireContinuousQueryLeaseCommand_Command)
+	v := ext.(*internal.AcquireContinuousQueryLeaseCommand)
+	if v.Duration == nil {
+		return b, nil
+	}
+	v.Time = proto.Int64(now.UnixNano())
+	v.Expiration = proto.Int64(now.Add(time.Duration(v.GetDuration())).UnixNano())
+	if err := proto.SetExtension(&cmd, internal.E_AcquireContinuousQueryLeaseCommand_Command, v); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&cmd)
+}
+
 // remoteExec sends an encoded command to the remote leader.
 func (s *Store) remoteExec(b []byte) error {
 	// Retrieve the current known leader.
@@ -1718,19 +1893,13 @@ func (s *Store) remoteExec(b []byte) error {
 		return errors.New("no leader detected during remoteExec")
 	}
 
-	// Create a connection to the leader.
-	conn, err := net.DialTimeout("tcp", leader, 10*time.Second)
+	// Create a connection to the leader and write a marker byte for exec messages.
+	conn, err := dialMux(leader, MuxExecHeader, 10*time.Second, s.TLSConfig)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	// Write a marker byte for exec messages.
-	_, err = conn.Write([]byte{MuxExecHeader})
-	if err != nil {
-		return err
-	}
-
 	// Write a marker message.
 	_, err = conn.Write([]byte(ExecMagic))
 	if err != nil {
@@ -1848,8 +2017,20 @@ func (fsm *storeFSM) Apply(l *raft.Log) interface{} {
 			return fsm.applyCreateShardGroupCommand(&cmd)
 		case internal.Command_DeleteShardGroupCommand:
 			return fsm.applyDeleteShardGroupCommand(&cmd)
+		case internal.Command_AddShardOwnerCommand:
+			return fsm.applyAddShardOwnerCommand(&cmd)
+		case internal.Command_RemoveShardOwnerCommand:
+			return fsm.applyRemoveShardOwnerCommand(&cmd)
+		case internal.Command_SetNodeLabelsCommand:
+			return fsm.applySetNodeLabelsCommand(&cmd)
+		case internal.Command_SetNodeStateCommand:
+			return fsm.applySetNodeStateCommand(&cmd)
 		case internal.Command_CreateContinuousQueryCommand:
 			return fsm.applyCreateContinuousQueryCommand(&cmd)
+		case internal.Command_UpdateContinuousQueryCommand:
+			return fsm.applyUpdateContinuousQueryCommand(&cmd)
+		case internal.Command_AcquireContinuousQueryLeaseCommand:
+			return fsm.applyAcquireContinuousQueryLeaseCommand(&cmd)
 		case internal.Command_DropContinuousQueryCommand:
 			return fsm.applyDropContinuousQueryCommand(&cmd)
 		case internal.Command_CreateSubscriptionCommand:
@@ -1862,6 +2043,10 @@ func (fsm *storeFSM) Apply(l *raft.Log) interface{} {
 			return fsm.applyDropUserCommand(&cmd)
 		case internal.Command_UpdateUserCommand:
 			return fsm.applyUpdateUserCommand(&cmd)
+		case internal.Command_CreateTokenCommand:
+			return fsm.applyCreateTokenCommand(&cmd)
+		case internal.Command_DropTokenCommand:
+			return fsm.applyDropTokenCommand(&cmd)
 		case internal.Command_SetPrivilegeCommand:
 			return fsm.applySetPrivilegeCommand(&cmd)
 		case internal.Command_SetAdminPrivilegeCommand:
@@ -1946,6 +2131,34 @@ func (fsm *storeFSM) applyUpdateNodeCommand(cmd *internal.Command) interface{} {
 	return nil
 }
 
+func (fsm *storeFSM) applySetNodeLabelsCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_SetNodeLabelsCommand_Command)
+	v := ext.(*internal.SetNodeLabelsCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.SetNodeLabels(v.GetID(), unmarshalNodeLabels(v.GetLabels())); err != nil {
+		return err
+	}
+
+	fsm.data = other
+	return nil
+}
+
+func (fsm *storeFSM) applySetNodeStateCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_SetNodeStateCommand_Command)
+	v := ext.(*internal.SetNodeStateCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.SetNodeState(v.GetID(), v.GetState()); err != nil {
+		return err
+	}
+
+	fsm.data = other
+	return nil
+}
+
 func (fsm *storeFSM) applyDeleteNodeCommand(cmd *internal.Command) interface{} {
 	ext, _ := proto.GetExtension(cmd, internal.E_DeleteNodeCommand_Command)
 	v := ext.(*internal.DeleteNodeCommand)
@@ -2045,7 +2258,7 @@ func (fsm *storeFSM) applyUpdateRetentionPolicyCommand(cmd *internal.Command) in
 	v := ext.(*internal.UpdateRetentionPolicyCommand)
 
 	// Create update object.
-	rpu := RetentionPolicyUpdate{Name: v.NewName}
+	rpu := RetentionPolicyUpdate{Name: v.NewName, ShardPlacement: v.ShardPlacement}
 	if v.Duration != nil {
 		value := time.Duration(v.GetDuration())
 		rpu.Duration = &value
@@ -2093,6 +2306,34 @@ func (fsm *storeFSM) applyDeleteShardGroupCommand(cmd *internal.Command) interfa
 	return nil
 }
 
+func (fsm *storeFSM) applyAddShardOwnerCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_AddShardOwnerCommand_Command)
+	v := ext.(*internal.AddShardOwnerCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.AddShardOwner(v.GetID(), v.GetNodeID()); err != nil {
+		return err
+	}
+	fsm.data = other
+
+	return nil
+}
+
+func (fsm *storeFSM) applyRemoveShardOwnerCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_RemoveShardOwnerCommand_Command)
+	v := ext.(*internal.RemoveShardOwnerCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.RemoveShardOwner(v.GetID(), v.GetNodeID()); err != nil {
+		return err
+	}
+	fsm.data = other
+
+	return nil
+}
+
 func (fsm *storeFSM) applyCreateContinuousQueryCommand(cmd *internal.Command) interface{} {
 	ext, _ := proto.GetExtension(cmd, internal.E_CreateContinuousQueryCommand_Command)
 	v := ext.(*internal.CreateContinuousQueryCommand)
@@ -2107,6 +2348,35 @@ func (fsm *storeFSM) applyCreateContinuousQueryCommand(cmd *internal.Command) in
 	return nil
 }
 
+func (fsm *storeFSM) applyUpdateContinuousQueryCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_UpdateContinuousQueryCommand_Command)
+	v := ext.(*internal.UpdateContinuousQueryCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.UpdateContinuousQuery(v.GetDatabase(), v.GetName(), v.GetQuery()); err != nil {
+		return err
+	}
+	fsm.data = other
+
+	return nil
+}
+
+func (fsm *storeFSM) applyAcquireContinuousQueryLeaseCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_AcquireContinuousQueryLeaseCommand_Command)
+	v := ext.(*internal.AcquireContinuousQueryLeaseCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.AcquireContinuousQueryLease(v.GetDatabase(), v.GetName(), v.GetNodeID(),
+		time.Unix(0, v.GetTime()).UTC(), time.Unix(0, v.GetExpiration()).UTC()); err != nil {
+		return err
+	}
+	fsm.data = other
+
+	return nil
+}
+
 func (fsm *storeFSM) applyDropContinuousQueryCommand(cmd *internal.Command) interface{} {
 	ext, _ := proto.GetExtension(cmd, internal.E_DropContinuousQueryCommand_Command)
 	v := ext.(*internal.DropContinuousQueryCommand)
@@ -2177,6 +2447,32 @@ func (fsm *storeFSM) applyDropUserCommand(cmd *internal.Command) interface{} {
 	return nil
 }
 
+func (fsm *storeFSM) applyCreateTokenCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_CreateTokenCommand_Command)
+	v := ext.(*internal.CreateTokenCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.CreateToken(v.GetName(), v.GetUsername(), v.GetHash(), UnmarshalTime(v.GetExpiration())); err != nil {
+		return err
+	}
+	fsm.data = other
+	return nil
+}
+
+func (fsm *storeFSM) applyDropTokenCommand(cmd *internal.Command) interface{} {
+	ext, _ := proto.GetExtension(cmd, internal.E_DropTokenCommand_Command)
+	v := ext.(*internal.DropTokenCommand)
+
+	// Copy data and update.
+	other := fsm.data.Clone()
+	if err := other.DropToken(v.GetName()); err != nil {
+		return err
+	}
+	fsm.data = other
+	return nil
+}
+
 func (fsm *storeFSM) applyUpdateUserCommand(cmd *internal.Command) interface{} {
 	ext, _ := proto.GetExtension(cmd, internal.E_UpdateUserCommand_Command)
 	v := ext.(*internal.UpdateUserCommand)
@@ -2295,19 +2591,21 @@ func (s *storeFSMSnapshot) Release() {}
 
 // raftLayer wraps the connection so it can be re-used for forwarding.
 type raftLayer struct {
-	ln     net.Listener
-	addr   net.Addr
-	conn   chan net.Conn
-	closed chan struct{}
+	ln        net.Listener
+	addr      net.Addr
+	tlsConfig *tls.Config
+	conn      chan net.Conn
+	closed    chan struct{}
 }
 
 // newRaftLayer returns a new instance of raftLayer.
-func newRaftLayer(ln net.Listener, addr net.Addr) *raftLayer {
+func newRaftLayer(ln net.Listener, addr net.Addr, tlsConfig *tls.Config) *raftLayer {
 	return &raftLayer{
-		ln:     ln,
-		addr:   addr,
-		conn:   make(chan net.Conn),
-		closed: make(chan struct{}),
+		ln:        ln,
+		addr:      addr,
+		tlsConfig: tlsConfig,
+		conn:      make(chan net.Conn),
+		closed:    make(chan struct{}),
 	}
 }
 
@@ -2316,18 +2614,8 @@ func (l *raftLayer) Addr() net.Addr { return l.addr }
 
 // Dial creates a new network connection.
 func (l *raftLayer) Dial(addr string, timeout time.Duration) (net.Conn, error) {
-	conn, err := net.DialTimeout("tcp", addr, timeout)
-	if err != nil {
-		return nil, err
-	}
-
-	// Write a marker byte for raft messages.
-	_, err = conn.Write([]byte{MuxRaftHeader})
-	if err != nil {
-		conn.Close()
-		return nil, err
-	}
-	return conn, err
+	// Connect and write a marker byte for raft messages.
+	return dialMux(addr, MuxRaftHeader, timeout, l.tlsConfig)
 }
 
 // Accept waits for the next connection.
@@ -2338,9 +2626,10 @@ func (l *raftLayer) Close() error { return l.ln.Close() }
 
 // RetentionPolicyUpdate represents retention policy fields to be updated.
 type RetentionPolicyUpdate struct {
-	Name     *string
-	Duration *time.Duration
-	ReplicaN *int
+	Name           *string
+	Duration       *time.Duration
+	ReplicaN       *int
+	ShardPlacement *string
 }
 
 // SetName sets the RetentionPolicyUpdate.Name
@@ -2352,6 +2641,9 @@ func (rpu *RetentionPolicyUpdate) SetDuration(v time.Duration) { rpu.Duration =
 // SetReplicaN sets the RetentionPolicyUpdate.ReplicaN
 func (rpu *RetentionPolicyUpdate) SetReplicaN(v int) { rpu.ReplicaN = &v }
 
+// SetShardPlacement sets the RetentionPolicyUpdate.ShardPlacement
+func (rpu *RetentionPolicyUpdate) SetShardPlacement(v string) { rpu.ShardPlacement = &v }
+
 // assert will panic with a given formatted message if the given condition is false.
 func assert(condition bool, msg string, v ...interface{}) {
 	if !condition {
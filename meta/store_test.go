@@ -53,7 +53,7 @@ func TestStore_CreateNode(t *testing.T) {
 	// Create node.
 	if ni, err := s.CreateNode("host0"); err != nil {
 		t.Fatal(err)
-	} else if *ni != (meta.NodeInfo{ID: 2, Host: "host0"}) {
+	} else if !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 2, Host: "host0"}) {
 		t.Fatalf("unexpected node: %#v", ni)
 	}
 
@@ -68,7 +68,7 @@ func TestStore_CreateNode(t *testing.T) {
 	// Create another node.
 	if ni, err := s.CreateNode("host1"); err != nil {
 		t.Fatal(err)
-	} else if *ni != (meta.NodeInfo{ID: 3, Host: "host1"}) {
+	} else if !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 3, Host: "host1"}) {
 		t.Fatalf("unexpected node: %#v", ni)
 	}
 
@@ -80,6 +80,65 @@ func TestStore_CreateNode(t *testing.T) {
 	}
 }
 
+// Ensure the store can set the labels of a node.
+func TestStore_SetNodeLabels(t *testing.T) {
+	t.Parallel()
+	s := MustOpenStore()
+	defer s.Close()
+
+	if _, err := s.CreateNode("host0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set labels and verify they are stored.
+	labels := map[string]string{"zone": "us-east-1a", "rack": "r12"}
+	if err := s.SetNodeLabels(2, labels); err != nil {
+		t.Fatal(err)
+	} else if ni, _ := s.Node(2); !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 2, Host: "host0", Labels: labels}) {
+		t.Fatalf("unexpected node: %#v", ni)
+	}
+
+	// Clear labels.
+	if err := s.SetNodeLabels(2, nil); err != nil {
+		t.Fatal(err)
+	} else if ni, _ := s.Node(2); ni.Labels != nil {
+		t.Fatalf("unexpected labels: %v", ni.Labels)
+	}
+
+	// Setting labels on a missing node returns an error.
+	if err := s.SetNodeLabels(100, labels); err != meta.ErrNodeNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the store can drain and decommission a node.
+func TestStore_SetNodeState(t *testing.T) {
+	t.Parallel()
+	s := MustOpenStore()
+	defer s.Close()
+
+	if _, err := s.CreateNode("host0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetNodeState(2, meta.NodeStateDraining); err != nil {
+		t.Fatal(err)
+	} else if ni, _ := s.Node(2); ni.State != meta.NodeStateDraining {
+		t.Fatalf("unexpected state: %s", ni.State)
+	}
+
+	// The node owns no shards so it can be decommissioned.
+	if err := s.SetNodeState(2, meta.NodeStateDecommissioned); err != nil {
+		t.Fatal(err)
+	} else if ni, _ := s.Node(2); ni.State != meta.NodeStateDecommissioned {
+		t.Fatalf("unexpected state: %s", ni.State)
+	}
+
+	if err := s.SetNodeState(2, "gone"); err != meta.ErrInvalidNodeState {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure that creating an existing node returns an error.
 func TestStore_CreateNode_ErrNodeExists(t *testing.T) {
 	t.Parallel()
@@ -113,7 +172,7 @@ func TestStore_Node(t *testing.T) {
 	// Find second node.
 	if ni, err := s.Node(3); err != nil {
 		t.Fatal(err)
-	} else if *ni != (meta.NodeInfo{ID: 3, Host: "host1"}) {
+	} else if !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 3, Host: "host1"}) {
 		t.Fatalf("unexpected node: %#v", ni)
 	}
 }
@@ -134,7 +193,7 @@ func TestStore_NodeByHost(t *testing.T) {
 	// Find second node.
 	if ni, err := s.NodeByHost("host1"); err != nil {
 		t.Fatal(err)
-	} else if *ni != (meta.NodeInfo{ID: 3, Host: "host1"}) {
+	} else if !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 3, Host: "host1"}) {
 		t.Fatalf("unexpected node: %#v", ni)
 	}
 }
@@ -158,13 +217,13 @@ func TestStore_DeleteNode(t *testing.T) {
 	}
 
 	// Ensure remaining nodes are correct.
-	if ni, _ := s.Node(2); *ni != (meta.NodeInfo{ID: 2, Host: "host0"}) {
+	if ni, _ := s.Node(2); !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 2, Host: "host0"}) {
 		t.Fatalf("unexpected node(1): %#v", ni)
 	}
 	if ni, _ := s.Node(3); ni != nil {
 		t.Fatalf("unexpected node(2): %#v", ni)
 	}
-	if ni, _ := s.Node(4); *ni != (meta.NodeInfo{ID: 4, Host: "host2"}) {
+	if ni, _ := s.Node(4); !reflect.DeepEqual(*ni, meta.NodeInfo{ID: 4, Host: "host2"}) {
 		t.Fatalf("unexpected node(3): %#v", ni)
 	}
 }
@@ -527,6 +586,51 @@ func TestStore_DeleteShardGroup(t *testing.T) {
 	}
 }
 
+// Ensure the store can move a shard between nodes.
+func TestStore_ShardOwners(t *testing.T) {
+	t.Parallel()
+	s := MustOpenStore()
+	defer s.Close()
+
+	// Create nodes, database, policy, & group.
+	ni, err := s.CreateNode("host1")
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := s.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if _, err = s.CreateRetentionPolicy("db0", &meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: 1, Duration: 1 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	sgi, err := s.CreateShardGroup("db0", "rp0", time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := sgi.Shards[0]
+	if sh.OwnedBy(ni.ID) {
+		sh = sgi.Shards[1]
+	}
+	from, to := sh.Owners[0].NodeID, ni.ID
+
+	// Add the new owner and remove the old one.
+	if err := s.AddShardOwner(sh.ID, to); err != nil {
+		t.Fatal(err)
+	} else if err := s.RemoveShardOwner(sh.ID, from); err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the shard is now owned only by the new node.
+	if _, _, sgi := s.ShardOwner(sh.ID); sgi == nil {
+		t.Fatal("shard group not found")
+	} else if sgi.Shards[0].OwnedBy(from) || sgi.Shards[1].OwnedBy(from) {
+		t.Fatalf("shard still owned by node %d: %v", from, sgi.Shards)
+	}
+
+	// Ensure errors are returned through the store.
+	if err := s.RemoveShardOwner(sh.ID, from); err != meta.ErrShardOwnerNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
 // Ensure the store correctly precreates shard groups.
 func TestStore_PrecreateShardGroup(t *testing.T) {
 	t.Parallel()
@@ -652,6 +756,72 @@ func TestStore_DropContinuousQuery(t *testing.T) {
 	}
 }
 
+// Ensure the store can update a continuous query.
+func TestStore_UpdateContinuousQuery(t *testing.T) {
+	t.Parallel()
+	s := MustOpenStore()
+	defer s.Close()
+
+	// Create a query.
+	if _, err := s.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := s.CreateContinuousQuery("db0", "cq0", "SELECT count() FROM foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the query.
+	if err := s.UpdateContinuousQuery("db0", "cq0", "SELECT mean() FROM foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure the resulting query is correct.
+	if di, err := s.Database("db0"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(di.ContinuousQueries, []meta.ContinuousQueryInfo{
+		{Name: "cq0", Query: "SELECT mean() FROM foo"},
+	}) {
+		t.Fatalf("unexpected queries: %#v", di.ContinuousQueries)
+	}
+
+	// Updating a query that doesn't exist returns an error.
+	if err := s.UpdateContinuousQuery("db0", "cq1", "SELECT mean() FROM foo"); err != meta.ErrContinuousQueryNotFound {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// Ensure the store can grant and renew continuous query leases.
+func TestStore_AcquireContinuousQueryLease(t *testing.T) {
+	t.Parallel()
+	s := MustOpenStore()
+	defer s.Close()
+
+	if _, err := s.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	} else if err := s.CreateContinuousQuery("db0", "cq0", "SELECT count() FROM foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Acquire the lease for node 1 and renew it.
+	if err := s.AcquireContinuousQueryLease("db0", "cq0", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	} else if err := s.AcquireContinuousQueryLease("db0", "cq0", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if di, err := s.Database("db0"); err != nil {
+		t.Fatal(err)
+	} else if cqi := di.ContinuousQueries[0]; cqi.LeaseNodeID != 1 {
+		t.Fatalf("unexpected lease owner: %d", cqi.LeaseNodeID)
+	} else if cqi.LeaseExpiration.Before(time.Now()) {
+		t.Fatalf("unexpected lease expiration: %s", cqi.LeaseExpiration)
+	}
+
+	// Another node can't take the lease until it expires.
+	if err := s.AcquireContinuousQueryLease("db0", "cq0", 2, time.Hour); err != meta.ErrContinuousQueryLeaseHeld {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure the store can create a new subscription.
 func TestStore_CreateSubscription(t *testing.T) {
 	t.Parallel()
@@ -778,6 +948,72 @@ func TestStore_DropUser(t *testing.T) {
 	}
 }
 
+// Ensure the store can create tokens and authenticate users with them.
+func TestStore_CreateToken(t *testing.T) {
+	t.Parallel()
+	s := MustOpenStore()
+	defer s.Close()
+
+	if _, err := s.CreateUser("susy", "pass", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a token that never expires and one that has already expired.
+	token, err := s.CreateToken("grafana", "susy", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(token) != 2*meta.TokenBytes {
+		t.Fatalf("unexpected token: %s", token)
+	}
+	expired, err := s.CreateToken("old", "susy", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token names must be unique and belong to an existing user.
+	if _, err := s.CreateToken("grafana", "susy", time.Time{}); err != meta.ErrTokenExists {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := s.CreateToken("other", "bob", time.Time{}); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only the hash of the token is stored.
+	if a, err := s.Tokens(); err != nil {
+		t.Fatal(err)
+	} else if len(a) != 2 || a[0].Name != "grafana" || a[0].Hash == "" || a[0].Hash == token {
+		t.Fatalf("unexpected tokens: %#v", a)
+	}
+
+	if ui, err := s.AuthenticateToken(token); err != nil {
+		t.Fatal(err)
+	} else if ui.Name != "susy" {
+		t.Fatalf("unexpected user: %s", ui.Name)
+	}
+	if _, err := s.AuthenticateToken(expired); err != meta.ErrTokenExpired {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := s.AuthenticateToken("bad"); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Dropping the token revokes it.
+	if err := s.DropToken("grafana"); err != nil {
+		t.Fatal(err)
+	} else if _, err := s.AuthenticateToken(token); err != meta.ErrAuthenticate {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.DropToken("grafana"); err != meta.ErrTokenNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Dropping the user removes its remaining tokens.
+	if err := s.DropUser("susy"); err != nil {
+		t.Fatal(err)
+	} else if a, err := s.Tokens(); err != nil {
+		t.Fatal(err)
+	} else if len(a) != 0 {
+		t.Fatalf("unexpected tokens: %#v", a)
+	}
+}
+
 // Ensure the store can update a user.
 func TestStore_UpdateUser(t *testing.T) {
 	t.Parallel()
@@ -1,10 +1,12 @@
 package models
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"fmt"
 	"hash/fnv"
+	"io"
 	"math"
 	"sort"
 	"strconv"
@@ -188,6 +190,97 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 
 }
 
+// LineError is returned by PointReader when a single line fails to parse.
+type LineError struct {
+	Line int    // 1-based line number within the input
+	Text string // text of the line that failed
+	Err  error
+}
+
+// Error returns the string representation of the error.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("unable to parse '%s': %v", e.Text, e.Err)
+}
+
+// PointReader parses points from a stream of line protocol one line at a
+// time so the entire input never has to be held in memory.
+type PointReader struct {
+	r           *bufio.Reader
+	defaultTime time.Time
+	precision   string
+	line        int // number of lines read so far
+	last        int // line of the last point returned
+}
+
+// NewPointReader returns a new PointReader reading from r. Points without a
+// timestamp are assigned defaultTime at the given precision.
+func NewPointReader(r io.Reader, defaultTime time.Time, precision string) *PointReader {
+	return &PointReader{
+		r:           bufio.NewReaderSize(r, 64*1024),
+		defaultTime: defaultTime,
+		precision:   precision,
+	}
+}
+
+// ReadPoint returns the next point from the stream. If a line fails to parse
+// a *LineError is returned and reading can continue with the next call.
+// Any other error is fatal. Returns io.EOF once the stream is exhausted.
+func (r *PointReader) ReadPoint() (Point, error) {
+	for {
+		line, block, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip blank lines and comments.
+		start := skipWhitespace(block, 0)
+		if start >= len(block) || block[start] == '#' {
+			continue
+		}
+
+		pt, err := parsePoint(block[start:], r.defaultTime, r.precision)
+		if err != nil {
+			return nil, &LineError{Line: line, Text: string(block[start:]), Err: err}
+		}
+		r.last = line
+		return pt, nil
+	}
+}
+
+// Line returns the line number of the last point returned by ReadPoint.
+func (r *PointReader) Line() int { return r.last }
+
+// readLine returns the next line without its trailing newline along with the
+// line number it started on. Newlines inside quoted field values do not end
+// a line. Parsed points reference the returned bytes so a new slice is
+// allocated for every line.
+func (r *PointReader) readLine() (int, []byte, error) {
+	var buf []byte
+	for {
+		b, err := r.r.ReadSlice('\n')
+		buf = append(buf, b...)
+		if err == bufio.ErrBufferFull {
+			continue
+		} else if err == io.EOF {
+			if len(buf) == 0 {
+				return 0, nil, io.EOF
+			}
+			first := r.line + 1
+			r.line += bytes.Count(buf, []byte{'\n'}) + 1
+			return first, buf, nil
+		} else if err != nil {
+			return 0, nil, err
+		}
+
+		// The newline only terminates the line if it's outside of quotes.
+		if pos, _ := scanLine(buf, 0); pos < len(buf) {
+			first := r.line + 1
+			r.line += bytes.Count(buf, []byte{'\n'})
+			return first, buf[:len(buf)-1], nil
+		}
+	}
+}
+
 func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
 	// scan the first block which is measurement[,tag1=value1,tag2=value=2...]
 	pos, key, err := scanKey(buf, 0)
@@ -3,6 +3,7 @@ package models_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"reflect"
@@ -1658,3 +1659,38 @@ func TestParsePointsStringWithExtraBuffer(t *testing.T) {
 		t.Fatalf("expected both keys are same but got %s and %s", key, pointKey)
 	}
 }
+
+func TestPointReader(t *testing.T) {
+	buf := "# comment\ncpu value=1 1\n\ncpu\ncpu,host=a str=\"foo\nbar\" 2\ncpu value=3 3"
+	r := models.NewPointReader(strings.NewReader(buf), time.Unix(0, 0), "n")
+
+	if pt, err := r.ReadPoint(); err != nil {
+		t.Fatal(err)
+	} else if pt.String() != "cpu value=1 1" {
+		t.Fatalf("unexpected point: %s", pt.String())
+	}
+
+	if _, err := r.ReadPoint(); err == nil {
+		t.Fatal("expected error")
+	} else if lerr, ok := err.(*models.LineError); !ok {
+		t.Fatalf("unexpected error type: %T", err)
+	} else if lerr.Line != 4 || lerr.Text != "cpu" {
+		t.Fatalf("unexpected line error: %d %s", lerr.Line, lerr.Text)
+	}
+
+	if pt, err := r.ReadPoint(); err != nil {
+		t.Fatal(err)
+	} else if pt.Fields()["str"] != "foo\nbar" {
+		t.Fatalf("unexpected field: %q", pt.Fields()["str"])
+	}
+
+	if pt, err := r.ReadPoint(); err != nil {
+		t.Fatal(err)
+	} else if pt.String() != "cpu value=3 3" {
+		t.Fatalf("unexpected point: %s", pt.String())
+	}
+
+	if _, err := r.ReadPoint(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
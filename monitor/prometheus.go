@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/influxdb/influxdb"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4"
+
+func init() {
+	// The Go runtime statistics are gathered by the monitor itself.
+	influxdb.RegisterGauges("runtime", "Alloc", "Sys", "HeapAlloc", "HeapSys", "HeapIdle",
+		"HeapInUse", "HeapReleased", "HeapObjects", "NumGoroutine")
+}
+
+// promSample is a single labelled sample of a Prometheus metric.
+type promSample struct {
+	labels string
+	value  string
+}
+
+// promMetric holds every sample of a Prometheus metric.
+type promMetric struct {
+	typ     string
+	samples []promSample
+}
+
+// WritePrometheus writes statistics to w in the Prometheus text exposition
+// format. Each value is exported as a metric named after the statistic and
+// the value, e.g. "influxdb_httpd_points_written_ok_total", and the statistic
+// tags become labels.
+func WritePrometheus(w io.Writer, statistics []*Statistic) error {
+	metrics := make(map[string]*promMetric)
+	for _, s := range statistics {
+		labels := promLabels(s.Tags)
+		for _, k := range s.valueNames() {
+			v, ok := promValue(s.Values[k])
+			if !ok {
+				continue
+			}
+
+			name, typ := "influxdb_"+promName(s.Name)+"_"+promName(k), "gauge"
+			if !influxdb.IsGauge(s.Name, k) {
+				name, typ = name+"_total", "counter"
+			}
+
+			m := metrics[name]
+			if m == nil {
+				m = &promMetric{typ: typ}
+				metrics[name] = m
+			}
+			m.samples = append(m.samples, promSample{labels: labels, value: v})
+		}
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		m := metrics[name]
+		bw.WriteString("# TYPE " + name + " " + m.typ + "\n")
+		for _, s := range m.samples {
+			bw.WriteString(name + s.labels + " " + s.value + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// promName converts a camel case statistic name into a snake case metric name
+// containing only characters valid in a Prometheus metric name.
+func promName(s string) string {
+	runes := []rune(s)
+	var buf []rune
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// Start a new word at an upper case letter unless it continues an
+			// acronym, e.g. "pointsWrittenOK" becomes "points_written_ok".
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				buf = append(buf, '_')
+			}
+			buf = append(buf, unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			buf = append(buf, r)
+		default:
+			buf = append(buf, '_')
+		}
+	}
+	return string(buf)
+}
+
+// promLabels returns tags formatted as a sorted Prometheus label set.
+func promLabels(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = promName(k) + `="` + promLabelEscaper.Replace(tags[k]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// promLabelEscaper escapes label values for the exposition format.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// promValue returns the exposition format of a statistic value.
+func promValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN", true
+		case math.IsInf(v, 1):
+			return "+Inf", true
+		case math.IsInf(v, -1):
+			return "-Inf", true
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/influxdb/influxdb"
+)
+
+// Ensure statistics are written in the Prometheus text exposition format.
+func TestWritePrometheus(t *testing.T) {
+	influxdb.RegisterGauges("httpd", "queriesActive")
+	influxdb.RegisterGauges("wal", "memSize")
+
+	stats := []*Statistic{
+		newStatistic("httpd", map[string]string{"bind": ":8086"}, map[string]interface{}{
+			"pointsWrittenOK": int64(10),
+			"queriesActive":   int64(2),
+		}),
+		newStatistic("httpd", map[string]string{"bind": ":8087"}, map[string]interface{}{
+			"pointsWrittenOK": int64(5),
+		}),
+		newStatistic("wal", map[string]string{"path": `C:\data "wal"`}, map[string]interface{}{
+			"flushDuration": 1.5,
+			"memSize":       int64(1024),
+		}),
+		newStatistic("runtime", nil, map[string]interface{}{
+			"HeapAlloc": int64(100),
+			"NumGC":     int64(3),
+			"Invalid":   math.NaN(),
+		}),
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, stats); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `# TYPE influxdb_httpd_points_written_ok_total counter
+influxdb_httpd_points_written_ok_total{bind=":8086"} 10
+influxdb_httpd_points_written_ok_total{bind=":8087"} 5
+# TYPE influxdb_httpd_queries_active gauge
+influxdb_httpd_queries_active{bind=":8086"} 2
+# TYPE influxdb_runtime_heap_alloc gauge
+influxdb_runtime_heap_alloc 100
+# TYPE influxdb_runtime_invalid_total counter
+influxdb_runtime_invalid_total NaN
+# TYPE influxdb_runtime_num_gc_total counter
+influxdb_runtime_num_gc_total 3
+# TYPE influxdb_wal_flush_duration_total counter
+influxdb_wal_flush_duration_total{path="C:\\data \"wal\""} 1.5
+# TYPE influxdb_wal_mem_size gauge
+influxdb_wal_mem_size{path="C:\\data \"wal\""} 1024
+`
+	if got := buf.String(); got != exp {
+		t.Fatalf("unexpected output:\nexp: %s\ngot: %s", exp, got)
+	}
+}
+
+// Ensure statistic names are converted into valid metric names.
+func TestPromName(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		exp string
+	}{
+		{s: "req", exp: "req"},
+		{s: "pointsWrittenOK", exp: "points_written_ok"},
+		{s: "HeapInUse", exp: "heap_in_use"},
+		{s: "httpConnsHandled", exp: "http_conns_handled"},
+		{s: "HTTPConns", exp: "http_conns"},
+		{s: "hh_processor", exp: "hh_processor"},
+		{s: "write-req.bytes", exp: "write_req_bytes"},
+	} {
+		if got := promName(tt.s); got != tt.exp {
+			t.Errorf("%s: unexpected name: %s", tt.s, got)
+		}
+	}
+}
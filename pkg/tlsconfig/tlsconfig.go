@@ -0,0 +1,110 @@
+// Package tlsconfig builds the TLS configurations shared by the services
+// that accept TLS connections and by the connections between cluster nodes.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// Client certificate verification modes.
+const (
+	// ClientAuthNone does not request a client certificate.
+	ClientAuthNone = "none"
+
+	// ClientAuthOptional verifies a client certificate if one is sent.
+	ClientAuthOptional = "optional"
+
+	// ClientAuthRequired rejects connections without a valid client certificate.
+	ClientAuthRequired = "required"
+)
+
+// ClientAuthType returns the tls.ClientAuthType for a verification mode.
+// An empty mode is the same as ClientAuthNone.
+func ClientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequired:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("invalid client auth mode: %q", mode)
+	}
+}
+
+// New returns a server configuration using the PEM encoded certificate and
+// key in certFile. If clientAuth enables client certificate verification,
+// clientCAFile must contain the PEM encoded CAs that client certificates are
+// verified against.
+func New(certFile, clientCAFile, clientAuth string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := ClientAuthType(clientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   typ,
+	}
+	if typ == tls.NoClientCert {
+		return config, nil
+	}
+
+	if clientCAFile == "" {
+		return nil, fmt.Errorf("client CA required for client auth mode: %s", clientAuth)
+	}
+	pool, err := loadCertPool(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	config.ClientCAs = pool
+	return config, nil
+}
+
+// NewCluster returns a configuration for the connections between cluster
+// nodes. Every node uses the PEM encoded certificate and key in certFile
+// both to accept and to open connections. Peers must present a certificate
+// signed by one of the PEM encoded CAs in caFile in both directions.
+func NewCluster(certFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if caFile == "" {
+		return nil, fmt.Errorf("cluster CA required")
+	}
+	pool, err := loadCertPool(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// loadCertPool returns a pool of the PEM encoded certificates in path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA: %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,114 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
+)
+
+func TestClientAuthType(t *testing.T) {
+	for _, tt := range []struct {
+		mode string
+		typ  tls.ClientAuthType
+		err  bool
+	}{
+		{mode: "", typ: tls.NoClientCert},
+		{mode: "none", typ: tls.NoClientCert},
+		{mode: "optional", typ: tls.VerifyClientCertIfGiven},
+		{mode: "required", typ: tls.RequireAndVerifyClientCert},
+		{mode: "always", err: true},
+	} {
+		typ, err := tlsconfig.ClientAuthType(tt.mode)
+		if tt.err != (err != nil) {
+			t.Errorf("%q: unexpected error: %v", tt.mode, err)
+		} else if typ != tt.typ {
+			t.Errorf("%q: unexpected type: %v", tt.mode, typ)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := MustWriteCertificate()
+	defer os.Remove(path)
+
+	// Client certificates are not requested by default.
+	if c, err := tlsconfig.New(path, "", ""); err != nil {
+		t.Fatal(err)
+	} else if len(c.Certificates) != 1 || c.ClientAuth != tls.NoClientCert || c.ClientCAs != nil {
+		t.Fatalf("unexpected config: %#v", c)
+	}
+
+	// Verifying client certificates requires a CA.
+	if _, err := tlsconfig.New(path, "", "required"); err == nil || err.Error() != "client CA required for client auth mode: required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c, err := tlsconfig.New(path, path, "required"); err != nil {
+		t.Fatal(err)
+	} else if c.ClientAuth != tls.RequireAndVerifyClientCert || c.ClientCAs == nil {
+		t.Fatalf("unexpected config: %#v", c)
+	}
+}
+
+func TestNewCluster(t *testing.T) {
+	path := MustWriteCertificate()
+	defer os.Remove(path)
+
+	if _, err := tlsconfig.NewCluster(path, ""); err == nil || err.Error() != "cluster CA required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Peers are verified in both directions.
+	if c, err := tlsconfig.NewCluster(path, path); err != nil {
+		t.Fatal(err)
+	} else if len(c.Certificates) != 1 || c.RootCAs == nil || c.ClientCAs == nil || c.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf("unexpected config: %#v", c)
+	}
+}
+
+// MustWriteCertificate writes a self-signed certificate and its key to a
+// temporary file and returns the path. Panic on error.
+func MustWriteCertificate() string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "influxdb"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	b, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+
+	f, err := ioutil.TempFile("", "influxdb-tlsconfig-")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	pem.Encode(f, &pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+	return f.Name()
+}
@@ -1,5 +1,7 @@
 package admin
 
+import "github.com/influxdb/influxdb/pkg/tlsconfig"
+
 const (
 	// DefaultBindAddress is the default bind address for the HTTP server.
 	DefaultBindAddress = ":8083"
@@ -11,6 +13,8 @@ type Config struct {
 	BindAddress      string `toml:"bind-address"`
 	HTTPSEnabled     bool   `toml:"https-enabled"`
 	HTTPSCertificate string `toml:"https-certificate"`
+	HTTPSClientCA    string `toml:"https-client-ca"`
+	HTTPSClientAuth  string `toml:"https-client-auth"`
 }
 
 // NewConfig returns an instance of Config with defaults.
@@ -19,5 +23,6 @@ func NewConfig() Config {
 		BindAddress:      DefaultBindAddress,
 		HTTPSEnabled:     false,
 		HTTPSCertificate: "/etc/ssl/influxdb.pem",
+		HTTPSClientAuth:  tlsconfig.ClientAuthNone,
 	}
 }
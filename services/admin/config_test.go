@@ -15,6 +15,8 @@ enabled = true
 bind-address = ":8083"
 https-enabled = true
 https-certificate = "/dev/null"
+https-client-ca = "/etc/ssl/ca.pem"
+https-client-auth = "optional"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -28,5 +30,9 @@ https-certificate = "/dev/null"
 		t.Fatalf("unexpected https enabled: %v", c.HTTPSEnabled)
 	} else if c.HTTPSCertificate != "/dev/null" {
 		t.Fatalf("unexpected https certificate: %v", c.HTTPSCertificate)
+	} else if c.HTTPSClientCA != "/etc/ssl/ca.pem" {
+		t.Fatalf("unexpected https client ca: %v", c.HTTPSClientCA)
+	} else if c.HTTPSClientAuth != "optional" {
+		t.Fatalf("unexpected https client auth: %v", c.HTTPSClientAuth)
 	}
 }
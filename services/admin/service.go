@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
 	// Register static assets via statik.
 	_ "github.com/influxdb/influxdb/statik"
 	"github.com/rakyll/statik/fs"
@@ -16,11 +17,13 @@ import (
 
 // Service manages the listener for an admin endpoint.
 type Service struct {
-	listener net.Listener
-	addr     string
-	https    bool
-	cert     string
-	err      chan error
+	listener   net.Listener
+	addr       string
+	https      bool
+	cert       string
+	clientCA   string
+	clientAuth string
+	err        chan error
 
 	logger *log.Logger
 }
@@ -28,11 +31,13 @@ type Service struct {
 // NewService returns a new instance of Service.
 func NewService(c Config) *Service {
 	return &Service{
-		addr:   c.BindAddress,
-		https:  c.HTTPSEnabled,
-		cert:   c.HTTPSCertificate,
-		err:    make(chan error),
-		logger: log.New(os.Stderr, "[admin] ", log.LstdFlags),
+		addr:       c.BindAddress,
+		https:      c.HTTPSEnabled,
+		cert:       c.HTTPSCertificate,
+		clientCA:   c.HTTPSClientCA,
+		clientAuth: c.HTTPSClientAuth,
+		err:        make(chan error),
+		logger:     log.New(os.Stderr, "[admin] ", log.LstdFlags),
 	}
 }
 
@@ -42,14 +47,12 @@ func (s *Service) Open() error {
 
 	// Open listener.
 	if s.https {
-		cert, err := tls.LoadX509KeyPair(s.cert, s.cert)
+		config, err := tlsconfig.New(s.cert, s.clientCA, s.clientAuth)
 		if err != nil {
 			return err
 		}
 
-		listener, err := tls.Listen("tcp", s.addr, &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		})
+		listener, err := tls.Listen("tcp", s.addr, config)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,34 @@
+package antientropy
+
+import (
+	"time"
+
+	"github.com/influxdb/influxdb/toml"
+)
+
+const (
+	// DefaultCheckInterval is the default amount of time the system waits
+	// between comparing the replicas of every shard owned by the node.
+	DefaultCheckInterval = 30 * time.Minute
+
+	// DefaultDigestInterval is the default width of the time ranges that
+	// shard digests summarize. Each range that differs between replicas is
+	// repaired as a whole.
+	DefaultDigestInterval = time.Hour
+)
+
+// Config is an anti-entropy configuration.
+type Config struct {
+	Enabled        bool          `toml:"enabled"`
+	CheckInterval  toml.Duration `toml:"check-interval"`
+	DigestInterval toml.Duration `toml:"digest-interval"`
+}
+
+// NewConfig returns a new Config.
+func NewConfig() Config {
+	return Config{
+		Enabled:        false,
+		CheckInterval:  toml.Duration(DefaultCheckInterval),
+		DigestInterval: toml.Duration(DefaultDigestInterval),
+	}
+}
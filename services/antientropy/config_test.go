@@ -0,0 +1,30 @@
+package antientropy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdb/influxdb/services/antientropy"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c antientropy.Config
+	if _, err := toml.Decode(`
+enabled = true
+check-interval = "10m"
+digest-interval = "2h"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled: %v", c.Enabled)
+	} else if time.Duration(c.CheckInterval) != 10*time.Minute {
+		t.Fatalf("unexpected check interval: %s", c.CheckInterval)
+	} else if time.Duration(c.DigestInterval) != 2*time.Hour {
+		t.Fatalf("unexpected digest interval: %s", c.DigestInterval)
+	}
+}
@@ -0,0 +1,153 @@
+// Code generated by protoc-gen-gogo.
+// source: internal/internal.proto
+// DO NOT EDIT!
+
+/*
+Package internal is a generated protocol buffer package.
+
+It is generated from these files:
+	internal/internal.proto
+
+It has these top-level messages:
+	Request
+	Response
+	DigestEntry
+*/
+package internal
+
+import proto "github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type Request struct {
+	Type             *uint32 `protobuf:"varint,1,req,name=Type" json:"Type,omitempty"`
+	ShardID          *uint64 `protobuf:"varint,2,req,name=ShardID" json:"ShardID,omitempty"`
+	Interval         *int64  `protobuf:"varint,3,opt,name=Interval" json:"Interval,omitempty"`
+	Key              *string `protobuf:"bytes,4,opt,name=Key" json:"Key,omitempty"`
+	Min              *int64  `protobuf:"varint,5,opt,name=Min" json:"Min,omitempty"`
+	Max              *int64  `protobuf:"varint,6,opt,name=Max" json:"Max,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Request) Reset()         { *m = Request{} }
+func (m *Request) String() string { return proto.CompactTextString(m) }
+func (*Request) ProtoMessage()    {}
+
+func (m *Request) GetType() uint32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
+
+func (m *Request) GetShardID() uint64 {
+	if m != nil && m.ShardID != nil {
+		return *m.ShardID
+	}
+	return 0
+}
+
+func (m *Request) GetInterval() int64 {
+	if m != nil && m.Interval != nil {
+		return *m.Interval
+	}
+	return 0
+}
+
+func (m *Request) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *Request) GetMin() int64 {
+	if m != nil && m.Min != nil {
+		return *m.Min
+	}
+	return 0
+}
+
+func (m *Request) GetMax() int64 {
+	if m != nil && m.Max != nil {
+		return *m.Max
+	}
+	return 0
+}
+
+type Response struct {
+	Error            *string        `protobuf:"bytes,1,opt,name=Error" json:"Error,omitempty"`
+	Entries          []*DigestEntry `protobuf:"bytes,2,rep,name=Entries" json:"Entries,omitempty"`
+	Points           [][]byte       `protobuf:"bytes,3,rep,name=Points" json:"Points,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
+}
+
+func (m *Response) Reset()         { *m = Response{} }
+func (m *Response) String() string { return proto.CompactTextString(m) }
+func (*Response) ProtoMessage()    {}
+
+func (m *Response) GetError() string {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return ""
+}
+
+func (m *Response) GetEntries() []*DigestEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *Response) GetPoints() [][]byte {
+	if m != nil {
+		return m.Points
+	}
+	return nil
+}
+
+type DigestEntry struct {
+	Key              *string `protobuf:"bytes,1,req,name=Key" json:"Key,omitempty"`
+	Start            *int64  `protobuf:"varint,2,req,name=Start" json:"Start,omitempty"`
+	N                *int64  `protobuf:"varint,3,req,name=N" json:"N,omitempty"`
+	Checksum         *uint64 `protobuf:"varint,4,req,name=Checksum" json:"Checksum,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *DigestEntry) Reset()         { *m = DigestEntry{} }
+func (m *DigestEntry) String() string { return proto.CompactTextString(m) }
+func (*DigestEntry) ProtoMessage()    {}
+
+func (m *DigestEntry) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *DigestEntry) GetStart() int64 {
+	if m != nil && m.Start != nil {
+		return *m.Start
+	}
+	return 0
+}
+
+func (m *DigestEntry) GetN() int64 {
+	if m != nil && m.N != nil {
+		return *m.N
+	}
+	return 0
+}
+
+func (m *DigestEntry) GetChecksum() uint64 {
+	if m != nil && m.Checksum != nil {
+		return *m.Checksum
+	}
+	return 0
+}
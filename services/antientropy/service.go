@@ -0,0 +1,758 @@
+// Package antientropy detects and repairs differences between the replicas
+// of a shard.
+//
+// Each node summarizes its copy of a shard as a digest with one checksum per
+// series and time range. Digests are compared with the other owners of the
+// shard and the ranges that differ are copied in both directions so that
+// every replica ends up with the union of the points.
+package antientropy
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdb/influxdb"
+	"github.com/influxdb/influxdb/cluster"
+	"github.com/influxdb/influxdb/meta"
+	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/services/antientropy/internal"
+	"github.com/influxdb/influxdb/tcp"
+	"github.com/influxdb/influxdb/tsdb"
+)
+
+//go:generate protoc --gogo_out=. internal/internal.proto
+
+// MuxHeader is the header byte used for the TCP muxer.
+const MuxHeader = 7
+
+// Request types.
+const (
+	requestDigest = 1
+	requestPoints = 2
+	requestRepair = 3
+)
+
+// repairQueueSize is the number of read repairs that can be queued.
+const repairQueueSize = 100
+
+// Statistics for the anti-entropy service.
+const (
+	statDigestReq      = "digestReq"
+	statPointsReq      = "pointsReq"
+	statRepairOK       = "repairOk"
+	statRepairFail     = "repairFail"
+	statDifferences    = "differences"
+	statPointsRepaired = "pointsRepaired"
+	statRepairQueued   = "repairQueued"
+	statRepairDropped  = "repairDropped"
+)
+
+// Service compares the replicas of shards owned by this node with the other
+// owners and serves digests and points to them.
+type Service struct {
+	mu      sync.Mutex // serializes repairs
+	wg      sync.WaitGroup
+	closing chan struct{}
+	repairs chan queuedRepair
+
+	Config Config
+
+	MetaStore interface {
+		NodeID() uint64
+		Node(id uint64) (*meta.NodeInfo, error)
+		Databases() ([]meta.DatabaseInfo, error)
+	}
+
+	TSDBStore interface {
+		Shard(id uint64) *tsdb.Shard
+		WriteToShard(shardID uint64, points []models.Point) error
+	}
+
+	ShardWriter interface {
+		WriteShard(shardID, ownerID uint64, points []models.Point) error
+	}
+
+	Listener net.Listener
+
+	// If set, connections to other nodes use TLS.
+	TLSConfig *tls.Config
+
+	Logger  *log.Logger
+	statMap *expvar.Map
+}
+
+// NewService returns a new instance of Service.
+func NewService(c Config) *Service {
+	return &Service{
+		repairs: make(chan queuedRepair, repairQueueSize),
+		Config:  c,
+		Logger:  log.New(os.Stderr, "[anti-entropy] ", log.LstdFlags),
+		statMap: influxdb.NewStatistics("antientropy", "antientropy", nil),
+	}
+}
+
+// Open starts the service.
+func (s *Service) Open() error {
+	s.Logger.Println("Starting anti-entropy service")
+	s.closing = make(chan struct{})
+
+	// Digests and points are always served so other nodes can compare with
+	// this node even when it doesn't check its own shards.
+	if s.Listener != nil {
+		s.wg.Add(1)
+		go s.serve()
+	}
+
+	// Read repairs are processed even when periodic checks are disabled.
+	s.wg.Add(1)
+	go s.processRepairs()
+
+	if s.Config.Enabled {
+		s.wg.Add(1)
+		go s.run()
+	}
+	return nil
+}
+
+// Close stops the service.
+func (s *Service) Close() error {
+	if s.closing != nil {
+		close(s.closing)
+	}
+	if s.Listener != nil {
+		s.Listener.Close()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// SetLogger sets the internal logger to the logger passed in.
+func (s *Service) SetLogger(l *log.Logger) {
+	s.Logger = l
+}
+
+// run periodically repairs the shards owned by this node.
+func (s *Service) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.Config.CheckInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check repairs every replicated shard owned by this node that is no longer
+// being written to. Shards still accepting writes are skipped because writes
+// in flight and queued in hinted handoff would show up as differences.
+func (s *Service) check() {
+	shards, err := s.ownedShards(true)
+	if err != nil {
+		s.Logger.Printf("error listing shards: %s", err)
+		return
+	}
+
+	for _, si := range shards {
+		select {
+		case <-s.closing:
+			return
+		default:
+		}
+
+		n, err := s.repairShard(si)
+		if err != nil {
+			s.Logger.Printf("error repairing shard %d: %s", si.ID, err)
+			continue
+		} else if n > 0 {
+			s.Logger.Printf("repaired shard %d: %d points copied", si.ID, n)
+		}
+	}
+}
+
+// ShardDifferences compares every replicated shard owned by this node with
+// the copies on the other owners.
+func (s *Service) ShardDifferences() ([]meta.ShardDifference, error) {
+	shards, err := s.ownedShards(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var a []meta.ShardDifference
+	for _, si := range shards {
+		diffs, err := s.shardDifferences(si)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %s", si.ID, err)
+		}
+		a = append(a, diffs...)
+	}
+	return a, nil
+}
+
+// CompareShard compares the copies of a shard on two nodes, either of which
+// can be this node. The differences are reported from the point of view of
+// the first node.
+func (s *Service) CompareShard(shardID, nodeA, nodeB uint64) ([]meta.ShardDifference, error) {
+	interval := time.Duration(s.Config.DigestInterval)
+	a, err := s.digest(shardID, nodeA, interval)
+	if err != nil {
+		return nil, fmt.Errorf("digest from node %d: %s", nodeA, err)
+	}
+	b, err := s.digest(shardID, nodeB, interval)
+	if err != nil {
+		return nil, fmt.Errorf("digest from node %d: %s", nodeB, err)
+	}
+	return CompareDigests(shardID, nodeB, interval, a, b), nil
+}
+
+// digest returns a node's digest of a shard.
+func (s *Service) digest(shardID, nodeID uint64, interval time.Duration) ([]tsdb.DigestEntry, error) {
+	if nodeID == s.MetaStore.NodeID() {
+		sh := s.TSDBStore.Shard(shardID)
+		if sh == nil {
+			return nil, meta.ErrShardNotFound
+		}
+		return sh.Digest(interval)
+	}
+
+	c, err := s.client(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return c.Digest(shardID, interval)
+}
+
+// RepairShard copies the data that differs between this node's copy of a
+// shard and the copies on the other owners. It returns the number of points
+// copied in both directions.
+func (s *Service) RepairShard(id uint64) (int64, error) {
+	shards, err := s.ownedShards(false)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, si := range shards {
+		if si.ID == id {
+			return s.repairShard(si)
+		}
+	}
+	return 0, meta.ErrShardNotFound
+}
+
+// queuedRepair is a shard repair requested by a read repair.
+type queuedRepair struct {
+	shardID uint64
+	forward bool // if true, ask an owner to repair a shard not owned locally
+}
+
+// QueueRepair queues a shard to be repaired in the background. Shards not
+// owned by this node are repaired by one of their owners. The repair is
+// dropped if the queue is full.
+func (s *Service) QueueRepair(shardID uint64) {
+	s.queueRepair(queuedRepair{shardID: shardID, forward: true})
+}
+
+func (s *Service) queueRepair(r queuedRepair) {
+	select {
+	case s.repairs <- r:
+		s.statMap.Add(statRepairQueued, 1)
+	default:
+		s.statMap.Add(statRepairDropped, 1)
+	}
+}
+
+// processRepairs repairs queued shards until the service is closed.
+func (s *Service) processRepairs() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.closing:
+			return
+		case r := <-s.repairs:
+			if err := s.processRepair(r); err != nil {
+				s.Logger.Printf("error repairing shard %d: %s", r.shardID, err)
+			}
+		}
+	}
+}
+
+// processRepair repairs a queued shard if it is owned by this node.
+// Otherwise the repair is forwarded to the first owner of the shard.
+func (s *Service) processRepair(r queuedRepair) error {
+	n, err := s.RepairShard(r.shardID)
+	if err == meta.ErrShardNotFound && r.forward {
+		return s.forwardRepair(r.shardID)
+	} else if err != nil {
+		return err
+	} else if n > 0 {
+		s.Logger.Printf("repaired shard %d: %d points copied", r.shardID, n)
+	}
+	return nil
+}
+
+// forwardRepair asks the first owner of a shard to repair it.
+func (s *Service) forwardRepair(shardID uint64) error {
+	dbs, err := s.MetaStore.Databases()
+	if err != nil {
+		return err
+	}
+
+	for _, di := range dbs {
+		for _, rpi := range di.RetentionPolicies {
+			for _, sgi := range rpi.ShardGroups {
+				for _, si := range sgi.Shards {
+					if si.ID != shardID || len(si.Owners) == 0 {
+						continue
+					}
+
+					c, err := s.client(si.Owners[0].NodeID)
+					if err != nil {
+						return err
+					}
+					return c.Repair(shardID)
+				}
+			}
+		}
+	}
+	return meta.ErrShardNotFound
+}
+
+// repairShard copies each differing range from the other owners to this node
+// and then from this node back to the other owners.
+func (s *Service) repairShard(si meta.ShardInfo) (n int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer func() {
+		if err != nil {
+			s.statMap.Add(statRepairFail, 1)
+		} else {
+			s.statMap.Add(statRepairOK, 1)
+		}
+	}()
+
+	diffs, err := s.shardDifferences(si)
+	if err != nil {
+		return 0, err
+	}
+
+	sh := s.TSDBStore.Shard(si.ID)
+	for _, d := range diffs {
+		min, max := d.StartTime.UnixNano(), d.EndTime.UnixNano()-1
+
+		// Pull the owner's points for the range.
+		c, err := s.client(d.OwnerID)
+		if err != nil {
+			return n, err
+		}
+		points, err := c.SeriesPoints(si.ID, d.Key, min, max)
+		if err != nil {
+			return n, err
+		}
+		if len(points) > 0 {
+			if err := s.TSDBStore.WriteToShard(si.ID, points); err != nil {
+				return n, err
+			}
+			n += int64(len(points))
+		}
+
+		// Push the merged points back to the owner.
+		if points, err = sh.SeriesPoints(d.Key, min, max); err != nil {
+			return n, err
+		}
+		if len(points) > 0 {
+			if err := s.ShardWriter.WriteShard(si.ID, d.OwnerID, points); err != nil {
+				return n, err
+			}
+			n += int64(len(points))
+		}
+	}
+
+	s.statMap.Add(statPointsRepaired, n)
+	return n, nil
+}
+
+// shardDifferences compares this node's copy of a shard with the copy on
+// every other owner.
+func (s *Service) shardDifferences(si meta.ShardInfo) ([]meta.ShardDifference, error) {
+	sh := s.TSDBStore.Shard(si.ID)
+	if sh == nil {
+		return nil, meta.ErrShardNotFound
+	}
+
+	interval := time.Duration(s.Config.DigestInterval)
+	local, err := sh.Digest(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var a []meta.ShardDifference
+	for _, owner := range si.Owners {
+		if owner.NodeID == s.MetaStore.NodeID() {
+			continue
+		}
+
+		c, err := s.client(owner.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := c.Digest(si.ID, interval)
+		if err != nil {
+			return nil, fmt.Errorf("digest from node %d: %s", owner.NodeID, err)
+		}
+
+		a = append(a, CompareDigests(si.ID, owner.NodeID, interval, local, remote)...)
+	}
+
+	s.statMap.Add(statDifferences, int64(len(a)))
+	return a, nil
+}
+
+// ownedShards returns the replicated shards owned by this node. If cold is
+// true then only shards whose shard group has ended are returned.
+func (s *Service) ownedShards(cold bool) ([]meta.ShardInfo, error) {
+	dbs, err := s.MetaStore.Databases()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var a []meta.ShardInfo
+	for _, di := range dbs {
+		for _, rpi := range di.RetentionPolicies {
+			for _, sgi := range rpi.ShardGroups {
+				if sgi.Deleted() || (cold && sgi.EndTime.After(now)) {
+					continue
+				}
+
+				for _, si := range sgi.Shards {
+					if len(si.Owners) > 1 && si.OwnedBy(s.MetaStore.NodeID()) {
+						a = append(a, si)
+					}
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// client returns a client for the anti-entropy service on a node.
+func (s *Service) client(nodeID uint64) (*Client, error) {
+	ni, err := s.MetaStore.Node(nodeID)
+	if err != nil {
+		return nil, err
+	} else if ni == nil {
+		return nil, fmt.Errorf("node %d not found", nodeID)
+	}
+
+	c := NewClient(ni.Host)
+	c.TLSConfig = s.TLSConfig
+	return c, nil
+}
+
+// CompareDigests returns the ranges where the local and remote digests of
+// a shard differ, sorted by series key and time.
+func CompareDigests(shardID, ownerID uint64, interval time.Duration, local, remote []tsdb.DigestEntry) []meta.ShardDifference {
+	type rangeKey struct {
+		key   string
+		start int64
+	}
+
+	m := make(map[rangeKey]*meta.ShardDifference)
+	diff := func(e tsdb.DigestEntry) *meta.ShardDifference {
+		k := rangeKey{e.Key, e.Start}
+		d := m[k]
+		if d == nil {
+			d = &meta.ShardDifference{
+				ShardID:   shardID,
+				OwnerID:   ownerID,
+				Key:       e.Key,
+				StartTime: time.Unix(0, e.Start).UTC(),
+				EndTime:   time.Unix(0, e.Start).Add(interval).UTC(),
+			}
+			m[k] = d
+		}
+		return d
+	}
+
+	checksums := make(map[rangeKey]uint64, len(local))
+	for _, e := range local {
+		diff(e).LocalN = e.N
+		checksums[rangeKey{e.Key, e.Start}] = e.Checksum
+	}
+	for _, e := range remote {
+		d := diff(e)
+		d.RemoteN = e.N
+
+		// Drop ranges where both replicas hold the same points.
+		k := rangeKey{e.Key, e.Start}
+		if sum, ok := checksums[k]; ok && sum == e.Checksum && d.LocalN == e.N {
+			delete(m, k)
+		}
+	}
+
+	a := make([]meta.ShardDifference, 0, len(m))
+	for _, d := range m {
+		a = append(a, *d)
+	}
+	sort.Sort(differences(a))
+	return a
+}
+
+// differences sorts shard differences by series key and time.
+type differences []meta.ShardDifference
+
+func (a differences) Len() int      { return len(a) }
+func (a differences) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a differences) Less(i, j int) bool {
+	if a[i].Key != a[j].Key {
+		return a[i].Key < a[j].Key
+	}
+	return a[i].StartTime.Before(a[j].StartTime)
+}
+
+// serve serves digest and point requests from the listener.
+func (s *Service) serve() {
+	defer s.wg.Done()
+
+	for {
+		// Wait for next connection.
+		conn, err := s.Listener.Accept()
+		if err != nil && strings.Contains(err.Error(), "connection closed") {
+			s.Logger.Println("anti-entropy listener closed")
+			return
+		} else if err != nil {
+			s.Logger.Println("error accepting anti-entropy request: ", err.Error())
+			continue
+		}
+
+		// Handle connection in separate goroutine.
+		s.wg.Add(1)
+		go func(conn net.Conn) {
+			defer s.wg.Done()
+			defer conn.Close()
+			if err := s.handleConn(conn); err != nil {
+				s.Logger.Println(err)
+			}
+		}(conn)
+	}
+}
+
+// handleConn processes conn. This is run in a separate goroutine.
+func (s *Service) handleConn(conn net.Conn) error {
+	// Read request from connection.
+	var req internal.Request
+	if err := readMessage(conn, &req); err != nil {
+		return fmt.Errorf("read request: %s", err)
+	}
+
+	resp, err := s.processRequest(&req)
+	if err != nil {
+		resp = &internal.Response{Error: proto.String(err.Error())}
+	}
+
+	if err := writeMessage(conn, resp); err != nil {
+		return fmt.Errorf("write response: %s", err)
+	}
+	return nil
+}
+
+// processRequest returns the response to a digest, points or repair request.
+func (s *Service) processRequest(req *internal.Request) (*internal.Response, error) {
+	sh := s.TSDBStore.Shard(req.GetShardID())
+	if sh == nil {
+		return nil, fmt.Errorf("shard not found: id=%d", req.GetShardID())
+	}
+
+	switch req.GetType() {
+	case requestDigest:
+		s.statMap.Add(statDigestReq, 1)
+
+		entries, err := sh.Digest(time.Duration(req.GetInterval()))
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &internal.Response{Entries: make([]*internal.DigestEntry, len(entries))}
+		for i, e := range entries {
+			resp.Entries[i] = &internal.DigestEntry{
+				Key:      proto.String(e.Key),
+				Start:    proto.Int64(e.Start),
+				N:        proto.Int64(e.N),
+				Checksum: proto.Uint64(e.Checksum),
+			}
+		}
+		return resp, nil
+
+	case requestPoints:
+		s.statMap.Add(statPointsReq, 1)
+
+		points, err := sh.SeriesPoints(req.GetKey(), req.GetMin(), req.GetMax())
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &internal.Response{Points: make([][]byte, len(points))}
+		for i, p := range points {
+			resp.Points[i] = []byte(p.String())
+		}
+		return resp, nil
+
+	case requestRepair:
+		// Never forward again so requests can't loop between nodes.
+		s.queueRepair(queuedRepair{shardID: req.GetShardID()})
+		return &internal.Response{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown request type: %d", req.GetType())
+	}
+}
+
+// Client represents a client for connecting remotely to an anti-entropy service.
+type Client struct {
+	host string
+
+	// If set, connections to the remote node use TLS.
+	TLSConfig *tls.Config
+}
+
+// NewClient return a new instance of Client.
+func NewClient(host string) *Client {
+	return &Client{
+		host: host,
+	}
+}
+
+// Digest returns the remote node's digest of a shard.
+func (c *Client) Digest(shardID uint64, interval time.Duration) ([]tsdb.DigestEntry, error) {
+	resp, err := c.do(&internal.Request{
+		Type:     proto.Uint32(requestDigest),
+		ShardID:  proto.Uint64(shardID),
+		Interval: proto.Int64(int64(interval)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]tsdb.DigestEntry, len(resp.GetEntries()))
+	for i, e := range resp.GetEntries() {
+		entries[i] = tsdb.DigestEntry{
+			Key:      e.GetKey(),
+			Start:    e.GetStart(),
+			N:        e.GetN(),
+			Checksum: e.GetChecksum(),
+		}
+	}
+	return entries, nil
+}
+
+// SeriesPoints returns the remote node's points for a series of a shard with
+// timestamps between min and max, inclusive.
+func (c *Client) SeriesPoints(shardID uint64, key string, min, max int64) ([]models.Point, error) {
+	resp, err := c.do(&internal.Request{
+		Type:    proto.Uint32(requestPoints),
+		ShardID: proto.Uint64(shardID),
+		Key:     proto.String(key),
+		Min:     proto.Int64(min),
+		Max:     proto.Int64(max),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]models.Point, 0, len(resp.GetPoints()))
+	for _, buf := range resp.GetPoints() {
+		a, err := models.ParsePoints(buf)
+		if err != nil {
+			return nil, fmt.Errorf("parse point: %s", err)
+		}
+		points = append(points, a...)
+	}
+	return points, nil
+}
+
+// Repair asks the remote node to repair its copy of a shard in the background.
+func (c *Client) Repair(shardID uint64) error {
+	_, err := c.do(&internal.Request{
+		Type:    proto.Uint32(requestRepair),
+		ShardID: proto.Uint64(shardID),
+	})
+	return err
+}
+
+// do sends a request to the remote node and returns its response.
+func (c *Client) do(req *internal.Request) (*internal.Response, error) {
+	// Connect to remote server.
+	conn, err := tcp.DialTLS("tcp", c.host, MuxHeader, c.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Send request to server.
+	if err := writeMessage(conn, req); err != nil {
+		return nil, fmt.Errorf("write request: %s", err)
+	}
+
+	// Read response from the server.
+	var resp internal.Response
+	if err := readMessage(conn, &resp); err != nil {
+		return nil, fmt.Errorf("read response: %s", err)
+	} else if resp.GetError() != "" {
+		return nil, errors.New(resp.GetError())
+	}
+	return &resp, nil
+}
+
+// writeMessage marshals and writes a length-prefixed message to w.
+func writeMessage(w io.Writer, pb proto.Message) error {
+	buf, err := proto.Marshal(pb)
+	if err != nil {
+		return fmt.Errorf("marshal: %s", err)
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(buf))); err != nil {
+		return fmt.Errorf("write length: %s", err)
+	}
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("write body: %s", err)
+	}
+	return nil
+}
+
+// readMessage reads and unmarshals a length-prefixed message from r.
+func readMessage(r io.Reader, pb proto.Message) error {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return fmt.Errorf("read length: %s", err)
+	} else if n >= cluster.MaxMessageSize {
+		return fmt.Errorf("max message size of %d exceeded: %d", cluster.MaxMessageSize, n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return fmt.Errorf("read body: %s", err)
+	}
+
+	if err := proto.Unmarshal(buf, pb); err != nil {
+		return fmt.Errorf("unmarshal: %s", err)
+	}
+	return nil
+}
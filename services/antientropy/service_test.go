@@ -0,0 +1,296 @@
+package antientropy_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdb/influxdb/meta"
+	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/services/antientropy"
+	"github.com/influxdb/influxdb/tcp"
+	"github.com/influxdb/influxdb/tsdb"
+	_ "github.com/influxdb/influxdb/tsdb/engine"
+)
+
+// Ensure digests are compared by series and time range.
+func TestCompareDigests(t *testing.T) {
+	local := []tsdb.DigestEntry{
+		{Key: "cpu,host=a", Start: 0, N: 2, Checksum: 100},
+		{Key: "cpu,host=a", Start: int64(time.Hour), N: 1, Checksum: 200},
+		{Key: "cpu,host=b", Start: 0, N: 1, Checksum: 300},
+	}
+	remote := []tsdb.DigestEntry{
+		{Key: "cpu,host=a", Start: 0, N: 2, Checksum: 100},
+		{Key: "cpu,host=a", Start: int64(time.Hour), N: 1, Checksum: 201},
+		{Key: "mem,host=a", Start: 0, N: 4, Checksum: 400},
+	}
+
+	diffs := antientropy.CompareDigests(1, 2, time.Hour, local, remote)
+	if exp := []meta.ShardDifference{
+		{ShardID: 1, OwnerID: 2, Key: "cpu,host=a", StartTime: time.Unix(3600, 0).UTC(), EndTime: time.Unix(7200, 0).UTC(), LocalN: 1, RemoteN: 1},
+		{ShardID: 1, OwnerID: 2, Key: "cpu,host=b", StartTime: time.Unix(0, 0).UTC(), EndTime: time.Unix(3600, 0).UTC(), LocalN: 1, RemoteN: 0},
+		{ShardID: 1, OwnerID: 2, Key: "mem,host=a", StartTime: time.Unix(0, 0).UTC(), EndTime: time.Unix(3600, 0).UTC(), LocalN: 0, RemoteN: 4},
+	}; !reflect.DeepEqual(diffs, exp) {
+		t.Fatalf("unexpected differences:\nexp=%+v\ngot=%+v", exp, diffs)
+	}
+}
+
+// Ensure a shard is repaired from and to another owner.
+func TestService_RepairShard(t *testing.T) {
+	local, remote := MustOpenShard(1), MustOpenShard(1)
+	defer local.Close()
+	defer remote.Close()
+
+	points := []models.Point{
+		models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(10, 0)),
+		models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(20, 0)),
+		models.MustNewPoint("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(30, 0)),
+	}
+	if err := local.WritePoints(points[:2]); err != nil {
+		t.Fatal(err)
+	} else if err := remote.WritePoints(points[1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve the remote shard from node 2.
+	rs := MustOpenService(2)
+	defer rs.Close()
+	rs.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return remote.Shard }
+
+	s := MustOpenService(1)
+	defer s.Close()
+	s.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return local.Shard }
+	s.TSDBStore.WriteToShardFn = func(id uint64, points []models.Point) error { return local.WritePoints(points) }
+	s.ShardWriter.WriteShardFn = func(shardID, ownerID uint64, points []models.Point) error {
+		if ownerID != 2 {
+			t.Fatalf("unexpected owner: %d", ownerID)
+		}
+		return remote.WritePoints(points)
+	}
+	s.MetaStore.NodeFn = func(id uint64) (*meta.NodeInfo, error) {
+		return &meta.NodeInfo{ID: id, Host: rs.Addr().String()}, nil
+	}
+
+	// Ensure differences are reported for both series.
+	if diffs, err := s.ShardDifferences(); err != nil {
+		t.Fatal(err)
+	} else if len(diffs) != 2 {
+		t.Fatalf("unexpected differences: %+v", diffs)
+	}
+
+	// Repair the shard and ensure both copies are the same.
+	if n, err := s.RepairShard(1); err != nil {
+		t.Fatal(err)
+	} else if n != 5 {
+		t.Fatalf("unexpected points copied: %d", n)
+	}
+
+	if diffs, err := s.ShardDifferences(); err != nil {
+		t.Fatal(err)
+	} else if len(diffs) != 0 {
+		t.Fatalf("unexpected differences after repair: %+v", diffs)
+	}
+
+	// Ensure an unknown shard cannot be repaired.
+	if _, err := s.RepairShard(100); err != meta.ErrShardNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a queued repair on a node that doesn't own the shard is forwarded
+// to an owner and repaired in the background.
+func TestService_QueueRepair(t *testing.T) {
+	local, remote := MustOpenShard(1), MustOpenShard(1)
+	defer local.Close()
+	defer remote.Close()
+
+	p := models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(10, 0))
+	if err := remote.WritePoints([]models.Point{p}); err != nil {
+		t.Fatal(err)
+	}
+
+	rs := MustOpenService(2)
+	defer rs.Close()
+	rs.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return remote.Shard }
+
+	s := MustOpenService(1)
+	defer s.Close()
+	s.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return local.Shard }
+	s.TSDBStore.WriteToShardFn = func(id uint64, points []models.Point) error { return local.WritePoints(points) }
+	s.ShardWriter.WriteShardFn = func(shardID, ownerID uint64, points []models.Point) error { return remote.WritePoints(points) }
+	s.MetaStore.NodeFn = func(id uint64) (*meta.NodeInfo, error) {
+		return &meta.NodeInfo{ID: id, Host: rs.Addr().String()}, nil
+	}
+
+	// Queue the repair on node 3, which forwards it to node 1.
+	other := MustOpenService(3)
+	defer other.Close()
+	other.MetaStore.NodeFn = func(id uint64) (*meta.NodeInfo, error) {
+		if id != 1 {
+			t.Errorf("unexpected node: %d", id)
+		}
+		return &meta.NodeInfo{ID: id, Host: s.Addr().String()}, nil
+	}
+	other.QueueRepair(1)
+
+	// Wait for the point to be copied to node 1.
+	timeout := time.After(5 * time.Second)
+	for {
+		if diffs, err := s.ShardDifferences(); err != nil {
+			t.Fatal(err)
+		} else if len(diffs) == 0 {
+			break
+		}
+
+		select {
+		case <-timeout:
+			t.Fatal("timed out waiting for repair")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// Service represents a test wrapper for antientropy.Service.
+type Service struct {
+	*antientropy.Service
+
+	ln          net.Listener
+	MetaStore   ServiceMetaStore
+	TSDBStore   ServiceTSDBStore
+	ShardWriter ServiceShardWriter
+}
+
+// NewService returns a new instance of Service for a node.
+func NewService(nodeID uint64) *Service {
+	s := &Service{
+		Service: antientropy.NewService(antientropy.NewConfig()),
+	}
+	s.Service.MetaStore = &s.MetaStore
+	s.Service.TSDBStore = &s.TSDBStore
+	s.Service.ShardWriter = &s.ShardWriter
+
+	// Every node owns a replicated shard 1.
+	s.MetaStore.NodeIDFn = func() uint64 { return nodeID }
+	s.MetaStore.DatabasesFn = func() ([]meta.DatabaseInfo, error) {
+		return []meta.DatabaseInfo{{
+			Name: "db0",
+			RetentionPolicies: []meta.RetentionPolicyInfo{{
+				Name: "rp0",
+				ShardGroups: []meta.ShardGroupInfo{{
+					ID:     1,
+					Shards: []meta.ShardInfo{{ID: 1, Owners: []meta.ShardOwner{{NodeID: 1}, {NodeID: 2}}}},
+				}},
+			}},
+		}}, nil
+	}
+
+	if !testing.Verbose() {
+		s.SetLogger(log.New(ioutil.Discard, "", 0))
+	}
+	return s
+}
+
+// MustOpenService returns a new, opened service. Panic on error.
+func MustOpenService(nodeID uint64) *Service {
+	// Open randomly assigned port.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	// Start muxer.
+	mux := tcp.NewMux()
+
+	// Create new service and attach mux'd listener.
+	s := NewService(nodeID)
+	s.ln = ln
+	s.Listener = mux.Listen(antientropy.MuxHeader)
+	go mux.Serve(ln)
+
+	if err := s.Open(); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Close shuts down the service and the attached listener.
+func (s *Service) Close() error {
+	s.ln.Close()
+	return s.Service.Close()
+}
+
+// Addr returns the address of the service.
+func (s *Service) Addr() net.Addr { return s.ln.Addr() }
+
+// ServiceMetaStore is a mock that implements antientropy.Service.MetaStore.
+type ServiceMetaStore struct {
+	NodeIDFn    func() uint64
+	NodeFn      func(id uint64) (*meta.NodeInfo, error)
+	DatabasesFn func() ([]meta.DatabaseInfo, error)
+}
+
+func (ms *ServiceMetaStore) NodeID() uint64                          { return ms.NodeIDFn() }
+func (ms *ServiceMetaStore) Node(id uint64) (*meta.NodeInfo, error)  { return ms.NodeFn(id) }
+func (ms *ServiceMetaStore) Databases() ([]meta.DatabaseInfo, error) { return ms.DatabasesFn() }
+
+// ServiceTSDBStore is a mock that implements antientropy.Service.TSDBStore.
+type ServiceTSDBStore struct {
+	ShardFn        func(id uint64) *tsdb.Shard
+	WriteToShardFn func(shardID uint64, points []models.Point) error
+}
+
+func (ss *ServiceTSDBStore) Shard(id uint64) *tsdb.Shard { return ss.ShardFn(id) }
+func (ss *ServiceTSDBStore) WriteToShard(shardID uint64, points []models.Point) error {
+	return ss.WriteToShardFn(shardID, points)
+}
+
+// ServiceShardWriter is a mock that implements antientropy.Service.ShardWriter.
+type ServiceShardWriter struct {
+	WriteShardFn func(shardID, ownerID uint64, points []models.Point) error
+}
+
+func (sw *ServiceShardWriter) WriteShard(shardID, ownerID uint64, points []models.Point) error {
+	return sw.WriteShardFn(shardID, ownerID, points)
+}
+
+// Shard is a test wrapper for tsdb.Shard.
+type Shard struct {
+	*tsdb.Shard
+	path string
+}
+
+// MustOpenShard returns a temporary, opened shard.
+func MustOpenShard(id uint64) *Shard {
+	path, err := ioutil.TempDir("", "antientropy-")
+	if err != nil {
+		panic(err)
+	}
+
+	sh := &Shard{
+		Shard: tsdb.NewShard(id,
+			tsdb.NewDatabaseIndex(),
+			filepath.Join(path, "data"),
+			filepath.Join(path, "wal"),
+			tsdb.NewEngineOptions(),
+		),
+		path: path,
+	}
+	if err := sh.Open(); err != nil {
+		sh.Close()
+		panic(err)
+	}
+	return sh
+}
+
+// Close closes the shard and removes its data.
+func (sh *Shard) Close() error {
+	err := sh.Shard.Close()
+	os.RemoveAll(sh.path)
+	return err
+}
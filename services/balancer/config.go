@@ -0,0 +1,35 @@
+package balancer
+
+import (
+	"time"
+
+	"github.com/influxdb/influxdb/toml"
+)
+
+const (
+	// DefaultCheckInterval is the default amount of time the system waits
+	// between checking whether shards need to be copied or moved.
+	DefaultCheckInterval = time.Minute
+
+	// DefaultCopyRateLimit is the default rate at which a node streams shards
+	// to other nodes. The rate is in bytes per second. A value of 0 disables
+	// the rate limit. The limit applies to every shard copy, including COPY
+	// SHARD and MOVE SHARD, even when the balancer is disabled.
+	DefaultCopyRateLimit = 10 * 1024 * 1024
+)
+
+// Config is a balancer configuration.
+type Config struct {
+	Enabled       bool          `toml:"enabled"`
+	CheckInterval toml.Duration `toml:"check-interval"`
+	CopyRateLimit int64         `toml:"copy-rate-limit"`
+}
+
+// NewConfig returns a new Config.
+func NewConfig() Config {
+	return Config{
+		Enabled:       false,
+		CheckInterval: toml.Duration(DefaultCheckInterval),
+		CopyRateLimit: DefaultCopyRateLimit,
+	}
+}
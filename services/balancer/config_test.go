@@ -0,0 +1,30 @@
+package balancer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdb/influxdb/services/balancer"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c balancer.Config
+	if _, err := toml.Decode(`
+enabled = true
+check-interval = "5m"
+copy-rate-limit = 1024
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled: %v", c.Enabled)
+	} else if time.Duration(c.CheckInterval) != 5*time.Minute {
+		t.Fatalf("unexpected check interval: %s", c.CheckInterval)
+	} else if c.CopyRateLimit != 1024 {
+		t.Fatalf("unexpected copy rate limit: %d", c.CopyRateLimit)
+	}
+}
@@ -0,0 +1,469 @@
+// Package balancer keeps shard ownership even across the nodes of a cluster.
+//
+// The raft leader periodically plans changes to the owners of each shard.
+// Only shards that are no longer written to are changed as points written
+// during a copy would be lost. Such shards that have fewer owners than their
+// retention policy's replication factor, for example after a node is dropped,
+// are copied to another node. Shards are then moved from the nodes that own
+// the most shards to the nodes that own the fewest. Planned changes are made
+// one shard at a time through the copier service.
+//
+// Draining nodes are never given shards. Their shards are moved to active
+// nodes once the shards are no longer written to, and a draining node that
+// owns no shards is decommissioned so it can be dropped without losing data.
+package balancer
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdb/influxdb"
+	"github.com/influxdb/influxdb/meta"
+)
+
+// Statistics for the balancer service.
+const (
+	statCopyOK   = "copyOk"
+	statCopyFail = "copyFail"
+	statMoveOK   = "moveOk"
+	statMoveFail = "moveFail"
+	statPending  = "pending"
+)
+
+func init() {
+	influxdb.RegisterGauges("balancer", statPending)
+}
+
+// maxRetryInterval is the longest a shard is skipped after its copy or move fails.
+const maxRetryInterval = time.Hour
+
+// Service plans and makes changes to the owners of shards.
+type Service struct {
+	mu      sync.Mutex // serializes rebalancing
+	wg      sync.WaitGroup
+	closing chan struct{}
+
+	// IDs of the nodes seen by the last rebalance.
+	nodeIDs []uint64
+
+	// Shards whose last copy or move failed, and when they can be retried.
+	retries map[uint64]*retry
+
+	Config Config
+
+	MetaStore interface {
+		IsLeader() bool
+		Nodes() ([]meta.NodeInfo, error)
+		Databases() ([]meta.DatabaseInfo, error)
+		SetNodeState(id uint64, state string) error
+	}
+
+	ShardCopier interface {
+		CopyShard(id, from, to uint64) error
+		MoveShard(id, from, to uint64) error
+	}
+
+	Logger  *log.Logger
+	statMap *expvar.Map
+	pending expvar.Int
+}
+
+// NewService returns a new instance of Service.
+func NewService(c Config) *Service {
+	s := &Service{
+		Config:  c,
+		Logger:  log.New(os.Stderr, "[balancer] ", log.LstdFlags),
+		statMap: influxdb.NewStatistics("balancer", "balancer", nil),
+		retries: make(map[uint64]*retry),
+	}
+	s.statMap.Set(statPending, &s.pending)
+	return s
+}
+
+// Open starts the service.
+func (s *Service) Open() error {
+	s.Logger.Println("Starting balancer service")
+	s.closing = make(chan struct{})
+
+	if s.Config.Enabled {
+		s.wg.Add(1)
+		go s.run()
+	}
+	return nil
+}
+
+// Close stops the service.
+func (s *Service) Close() error {
+	if s.closing != nil {
+		close(s.closing)
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// SetLogger sets the internal logger to the logger passed in.
+func (s *Service) SetLogger(l *log.Logger) {
+	s.Logger = l
+}
+
+// run periodically rebalances shards while this node is the leader.
+func (s *Service) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.Config.CheckInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+			if !s.MetaStore.IsLeader() {
+				continue
+			}
+			if err := s.Rebalance(); err != nil {
+				s.Logger.Printf("error rebalancing shards: %s", err)
+			}
+		}
+	}
+}
+
+// Rebalance plans changes to shard owners and makes them one shard at a
+// time. A change that fails is logged and the rest of the plan is still
+// made. The shard is skipped by later rebalances for a period that doubles
+// with each failure, up to maxRetryInterval.
+func (s *Service) Rebalance() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.MetaStore.Nodes()
+	if err != nil {
+		return err
+	}
+	dbs, err := s.MetaStore.Databases()
+	if err != nil {
+		return err
+	}
+	s.checkNodes(nodes)
+
+	now := time.Now().UTC()
+	moves := Plan(nodes, dbs, now)
+	s.pending.Set(int64(len(moves)))
+	var failed []uint64 // shards whose changes failed during this rebalance
+	for i, m := range moves {
+		select {
+		case <-s.closing:
+			return nil
+		default:
+		}
+
+		if r := s.retries[m.ShardID]; contains(failed, m.ShardID) || (r != nil && now.Before(r.at)) {
+			s.Logger.Printf("skipping %s after earlier failure", m)
+		} else {
+			s.Logger.Println(m)
+			if err := s.apply(m); err != nil {
+				s.Logger.Printf("failed to %s: %s", m, err)
+				s.backoff(m.ShardID, now)
+				failed = append(failed, m.ShardID)
+			} else {
+				delete(s.retries, m.ShardID)
+			}
+		}
+		s.pending.Set(int64(len(moves) - i - 1))
+	}
+
+	return s.decommission(nodes)
+}
+
+// decommission marks draining nodes that no longer own shards as decommissioned.
+func (s *Service) decommission(nodes []meta.NodeInfo) error {
+	var draining []uint64
+	for _, n := range nodes {
+		if n.State == meta.NodeStateDraining {
+			draining = append(draining, n.ID)
+		}
+	}
+	if len(draining) == 0 {
+		return nil
+	}
+
+	// Read the owners again as the planned moves have changed them.
+	dbs, err := s.MetaStore.Databases()
+	if err != nil {
+		return err
+	}
+	for _, id := range draining {
+		if ownsShards(dbs, id) {
+			continue
+		}
+		s.Logger.Printf("node %d is drained, decommissioning", id)
+		if err := s.MetaStore.SetNodeState(id, meta.NodeStateDecommissioned); err != nil {
+			return fmt.Errorf("decommission node %d: %s", id, err)
+		}
+	}
+	return nil
+}
+
+// apply copies or moves a shard and records the outcome.
+func (s *Service) apply(m Move) error {
+	if m.Copy {
+		if err := s.ShardCopier.CopyShard(m.ShardID, m.From, m.To); err != nil {
+			s.statMap.Add(statCopyFail, 1)
+			return err
+		}
+		s.statMap.Add(statCopyOK, 1)
+		return nil
+	}
+
+	if err := s.ShardCopier.MoveShard(m.ShardID, m.From, m.To); err != nil {
+		s.statMap.Add(statMoveFail, 1)
+		return err
+	}
+	s.statMap.Add(statMoveOK, 1)
+	return nil
+}
+
+// retry records when a shard whose copy or move failed can be tried again.
+type retry struct {
+	at       time.Time
+	interval time.Duration
+}
+
+// backoff delays the next attempt to change a shard after a failure.
+func (s *Service) backoff(shardID uint64, now time.Time) {
+	r := s.retries[shardID]
+	if r == nil {
+		r = &retry{interval: time.Duration(s.Config.CheckInterval)}
+		s.retries[shardID] = r
+	} else if r.interval *= 2; r.interval > maxRetryInterval {
+		r.interval = maxRetryInterval
+	}
+	r.at = now.Add(r.interval)
+}
+
+// checkNodes logs when nodes have joined or left the cluster since the last rebalance.
+func (s *Service) checkNodes(nodes []meta.NodeInfo) {
+	ids := make([]uint64, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].ID
+	}
+	sort.Sort(uint64Slice(ids))
+
+	if s.nodeIDs != nil && fmt.Sprint(ids) != fmt.Sprint(s.nodeIDs) {
+		s.Logger.Printf("nodes changed from %v to %v", s.nodeIDs, ids)
+	}
+	s.nodeIDs = ids
+}
+
+// Move represents a planned change to the owners of a shard.
+type Move struct {
+	ShardID uint64
+	From    uint64
+	To      uint64
+
+	// If true, the shard is copied and the source node remains an owner.
+	Copy bool
+}
+
+// String returns a string representation of the move.
+func (m Move) String() string {
+	op := "move"
+	if m.Copy {
+		op = "copy"
+	}
+	return fmt.Sprintf("%s shard %d from node %d to node %d", op, m.ShardID, m.From, m.To)
+}
+
+// Plan returns the changes to shard owners that restore the replication
+// factor of every shard, drain the nodes that aren't active and even out the
+// number of shards owned by each active node. Copies that restore replication
+// are returned first. Only shards in groups that ended before now are copied
+// or moved. Shards without any owners cannot be restored and are ignored.
+func Plan(nodes []meta.NodeInfo, dbs []meta.DatabaseInfo, now time.Time) []Move {
+	// Count the shards owned by each node. Only active nodes receive shards.
+	var ids []uint64
+	load := make(map[uint64]int, len(nodes))
+	for _, n := range nodes {
+		load[n.ID] = 0
+		if n.Active() {
+			ids = append(ids, n.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Sort(uint64Slice(ids))
+
+	var shards []*plannedShard
+	for _, di := range dbs {
+		for _, rpi := range di.RetentionPolicies {
+			replicaN := rpi.ReplicaN
+			if replicaN < 1 {
+				replicaN = 1
+			} else if replicaN > len(ids) {
+				replicaN = len(ids)
+			}
+
+			for _, sgi := range rpi.ShardGroups {
+				if sgi.Deleted() {
+					continue
+				}
+				for _, si := range sgi.Shards {
+					sh := &plannedShard{id: si.ID, replicaN: replicaN, cold: sgi.EndTime.Before(now)}
+					for _, o := range si.Owners {
+						if _, ok := load[o.NodeID]; ok {
+							sh.owners = append(sh.owners, o.NodeID)
+							load[o.NodeID]++
+						}
+					}
+					shards = append(shards, sh)
+				}
+			}
+		}
+	}
+
+	var moves []Move
+
+	// Copy under-replicated cold shards to the least loaded nodes.
+	for _, sh := range shards {
+		for sh.cold && len(sh.owners) > 0 && len(sh.owners) < sh.replicaN {
+			to := leastLoaded(ids, load, sh.owners)
+			if to == 0 {
+				break
+			}
+			moves = append(moves, Move{ShardID: sh.id, From: sh.owners[0], To: to, Copy: true})
+			sh.owners = append(sh.owners, to)
+			load[to]++
+		}
+	}
+
+	// Move cold shards off the nodes that aren't active.
+	for _, sh := range shards {
+		if !sh.cold {
+			continue
+		}
+		for i, from := range sh.owners {
+			if contains(ids, from) {
+				continue
+			}
+			to := leastLoaded(ids, load, sh.owners)
+			if to == 0 {
+				break
+			}
+			moves = append(moves, Move{ShardID: sh.id, From: from, To: to})
+			sh.owners[i] = to
+			load[from]--
+			load[to]++
+		}
+	}
+
+	// Move cold shards from the most loaded active node to the least loaded
+	// active node until the number of shards owned by each differs by at most one.
+	for {
+		from, to := mostLoaded(ids, load), leastLoaded(ids, load, nil)
+		if load[from]-load[to] <= 1 {
+			break
+		}
+
+		sh := movableShard(shards, from, to)
+		if sh == nil {
+			break
+		}
+		moves = append(moves, Move{ShardID: sh.id, From: from, To: to})
+		for i := range sh.owners {
+			if sh.owners[i] == from {
+				sh.owners[i] = to
+			}
+		}
+		load[from]--
+		load[to]++
+	}
+
+	return moves
+}
+
+// plannedShard tracks the owners of a shard while planning.
+type plannedShard struct {
+	id       uint64
+	owners   []uint64
+	replicaN int
+	cold     bool
+}
+
+// ownedBy returns true if the node owns the shard.
+func (sh *plannedShard) ownedBy(id uint64) bool {
+	return contains(sh.owners, id)
+}
+
+// movableShard returns a cold shard owned by from and not by to.
+func movableShard(shards []*plannedShard, from, to uint64) *plannedShard {
+	for _, sh := range shards {
+		if sh.cold && sh.ownedBy(from) && !sh.ownedBy(to) {
+			return sh
+		}
+	}
+	return nil
+}
+
+// leastLoaded returns the node, not in exclude, that owns the fewest shards.
+func leastLoaded(ids []uint64, load map[uint64]int, exclude []uint64) uint64 {
+	var min uint64
+	for _, id := range ids {
+		if contains(exclude, id) {
+			continue
+		} else if min == 0 || load[id] < load[min] {
+			min = id
+		}
+	}
+	return min
+}
+
+// mostLoaded returns the node that owns the most shards.
+func mostLoaded(ids []uint64, load map[uint64]int) uint64 {
+	var max uint64
+	for _, id := range ids {
+		if max == 0 || load[id] > load[max] {
+			max = id
+		}
+	}
+	return max
+}
+
+// ownsShards returns true if the node owns a shard in a shard group that
+// hasn't been deleted.
+func ownsShards(dbs []meta.DatabaseInfo, id uint64) bool {
+	for _, di := range dbs {
+		for _, rpi := range di.RetentionPolicies {
+			for _, sgi := range rpi.ShardGroups {
+				if sgi.Deleted() {
+					continue
+				}
+				for _, si := range sgi.Shards {
+					if si.OwnedBy(id) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func contains(a []uint64, v uint64) bool {
+	for _, x := range a {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+type uint64Slice []uint64
+
+func (a uint64Slice) Len() int           { return len(a) }
+func (a uint64Slice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a uint64Slice) Less(i, j int) bool { return a[i] < a[j] }
@@ -0,0 +1,250 @@
+package balancer_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdb/influxdb/meta"
+	"github.com/influxdb/influxdb/services/balancer"
+)
+
+// Ensure cold shards that lost an owner are copied to the least loaded node.
+func TestPlan_Replication(t *testing.T) {
+	nodes := []meta.NodeInfo{{ID: 1}, {ID: 2}, {ID: 3}}
+	dbs := NewDatabases(2, time.Now().Add(-time.Hour), [][]uint64{
+		{1, 2},
+		{1},
+		{},
+	})
+
+	moves := balancer.Plan(nodes, dbs, time.Now())
+	if exp := []balancer.Move{
+		{ShardID: 2, From: 1, To: 3, Copy: true},
+	}; !reflect.DeepEqual(moves, exp) {
+		t.Fatalf("unexpected moves:\nexp=%v\ngot=%v", exp, moves)
+	}
+
+	// Ensure shards that are still being written to aren't copied.
+	dbs = NewDatabases(2, time.Now().Add(time.Hour), [][]uint64{{1, 2}, {1}})
+	if moves := balancer.Plan(nodes, dbs, time.Now()); len(moves) != 0 {
+		t.Fatalf("unexpected moves: %v", moves)
+	}
+}
+
+// Ensure cold shards are moved from the most to the least loaded nodes.
+func TestPlan_Balance(t *testing.T) {
+	nodes := []meta.NodeInfo{{ID: 1}, {ID: 2}, {ID: 3}}
+	dbs := NewDatabases(1, time.Now().Add(-time.Hour), [][]uint64{
+		{1}, {1}, {1}, {1}, {2},
+	})
+
+	moves := balancer.Plan(nodes, dbs, time.Now())
+	if exp := []balancer.Move{
+		{ShardID: 1, From: 1, To: 3},
+		{ShardID: 2, From: 1, To: 2},
+	}; !reflect.DeepEqual(moves, exp) {
+		t.Fatalf("unexpected moves:\nexp=%v\ngot=%v", exp, moves)
+	}
+
+	// Ensure shards that are still being written to aren't moved.
+	dbs = NewDatabases(1, time.Now().Add(time.Hour), [][]uint64{
+		{1}, {1}, {1}, {1}, {2},
+	})
+	if moves := balancer.Plan(nodes, dbs, time.Now()); len(moves) != 0 {
+		t.Fatalf("unexpected moves: %v", moves)
+	}
+}
+
+// Ensure cold shards are moved off draining nodes and draining nodes get no shards.
+func TestPlan_Drain(t *testing.T) {
+	nodes := []meta.NodeInfo{{ID: 1}, {ID: 2}, {ID: 3, State: meta.NodeStateDraining}}
+	dbs := NewDatabases(2, time.Now().Add(-time.Hour), [][]uint64{
+		{1, 3},
+		{2, 3},
+		{1},
+	})
+
+	moves := balancer.Plan(nodes, dbs, time.Now())
+	if exp := []balancer.Move{
+		{ShardID: 3, From: 1, To: 2, Copy: true},
+		{ShardID: 1, From: 3, To: 2},
+		{ShardID: 2, From: 3, To: 1},
+	}; !reflect.DeepEqual(moves, exp) {
+		t.Fatalf("unexpected moves:\nexp=%v\ngot=%v", exp, moves)
+	}
+
+	// Ensure shards that are still being written to aren't moved.
+	dbs = NewDatabases(1, time.Now().Add(time.Hour), [][]uint64{{3}})
+	if moves := balancer.Plan(nodes, dbs, time.Now()); len(moves) != 0 {
+		t.Fatalf("unexpected moves: %v", moves)
+	}
+}
+
+// Ensure the service makes planned changes one shard at a time.
+func TestService_Rebalance(t *testing.T) {
+	s := NewService()
+	s.MetaStore.NodesFn = func() ([]meta.NodeInfo, error) {
+		return []meta.NodeInfo{{ID: 1}, {ID: 2}}, nil
+	}
+	s.MetaStore.DatabasesFn = func() ([]meta.DatabaseInfo, error) {
+		return NewDatabases(2, time.Now().Add(-time.Hour), [][]uint64{{1}, {2}}), nil
+	}
+
+	var calls []string
+	s.ShardCopier.CopyShardFn = func(id, from, to uint64) error {
+		calls = append(calls, balancer.Move{ShardID: id, From: from, To: to, Copy: true}.String())
+		return nil
+	}
+	if err := s.Rebalance(); err != nil {
+		t.Fatal(err)
+	} else if exp := []string{
+		"copy shard 1 from node 1 to node 2",
+		"copy shard 2 from node 2 to node 1",
+	}; !reflect.DeepEqual(calls, exp) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+
+}
+
+// Ensure a failed change is skipped and the rest of the plan is still made.
+func TestService_Rebalance_Error(t *testing.T) {
+	s := NewService()
+	s.MetaStore.NodesFn = func() ([]meta.NodeInfo, error) {
+		return []meta.NodeInfo{{ID: 1}, {ID: 2}}, nil
+	}
+	s.MetaStore.DatabasesFn = func() ([]meta.DatabaseInfo, error) {
+		return NewDatabases(2, time.Now().Add(-time.Hour), [][]uint64{{1}, {2}}), nil
+	}
+
+	// The first copy always fails.
+	var calls []string
+	s.ShardCopier.CopyShardFn = func(id, from, to uint64) error {
+		calls = append(calls, balancer.Move{ShardID: id, From: from, To: to, Copy: true}.String())
+		if id == 1 {
+			return errors.New("marker")
+		}
+		return nil
+	}
+	if err := s.Rebalance(); err != nil {
+		t.Fatal(err)
+	} else if exp := []string{
+		"copy shard 1 from node 1 to node 2",
+		"copy shard 2 from node 2 to node 1",
+	}; !reflect.DeepEqual(calls, exp) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+
+	// Ensure the failed shard is skipped by the next rebalance.
+	calls = nil
+	if err := s.Rebalance(); err != nil {
+		t.Fatal(err)
+	} else if exp := []string{
+		"copy shard 2 from node 2 to node 1",
+	}; !reflect.DeepEqual(calls, exp) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+// Ensure a draining node is decommissioned once its shards are moved off.
+func TestService_Rebalance_Decommission(t *testing.T) {
+	s := NewService()
+	s.MetaStore.NodesFn = func() ([]meta.NodeInfo, error) {
+		return []meta.NodeInfo{{ID: 1}, {ID: 2, State: meta.NodeStateDraining}}, nil
+	}
+
+	owners := [][]uint64{{2}}
+	s.MetaStore.DatabasesFn = func() ([]meta.DatabaseInfo, error) {
+		return NewDatabases(1, time.Now().Add(-time.Hour), owners), nil
+	}
+	s.ShardCopier.MoveShardFn = func(id, from, to uint64) error {
+		owners = [][]uint64{{to}}
+		return nil
+	}
+
+	var decommissioned []uint64
+	s.MetaStore.SetNodeStateFn = func(id uint64, state string) error {
+		if state != meta.NodeStateDecommissioned {
+			t.Fatalf("unexpected state: %s", state)
+		}
+		decommissioned = append(decommissioned, id)
+		return nil
+	}
+
+	if err := s.Rebalance(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(decommissioned, []uint64{2}) {
+		t.Fatalf("unexpected decommissioned nodes: %v", decommissioned)
+	}
+}
+
+// NewDatabases returns a database with a shard group per shard. Each shard
+// has the given owners and each shard group ends at end.
+func NewDatabases(replicaN int, end time.Time, owners [][]uint64) []meta.DatabaseInfo {
+	rpi := meta.RetentionPolicyInfo{Name: "rp0", ReplicaN: replicaN}
+	for i, a := range owners {
+		si := meta.ShardInfo{ID: uint64(i + 1)}
+		for _, id := range a {
+			si.Owners = append(si.Owners, meta.ShardOwner{NodeID: id})
+		}
+		rpi.ShardGroups = append(rpi.ShardGroups, meta.ShardGroupInfo{
+			ID:        uint64(i + 1),
+			StartTime: end.Add(-time.Hour),
+			EndTime:   end,
+			Shards:    []meta.ShardInfo{si},
+		})
+	}
+	return []meta.DatabaseInfo{{Name: "db0", RetentionPolicies: []meta.RetentionPolicyInfo{rpi}}}
+}
+
+// Service represents a test wrapper for balancer.Service.
+type Service struct {
+	*balancer.Service
+	MetaStore   ServiceMetaStore
+	ShardCopier ServiceShardCopier
+}
+
+// NewService returns a new instance of Service.
+func NewService() *Service {
+	s := &Service{
+		Service: balancer.NewService(balancer.NewConfig()),
+	}
+	s.Service.MetaStore = &s.MetaStore
+	s.Service.ShardCopier = &s.ShardCopier
+
+	if !testing.Verbose() {
+		s.SetLogger(log.New(ioutil.Discard, "", 0))
+	}
+	return s
+}
+
+// ServiceMetaStore is a mock that implements balancer.Service.MetaStore.
+type ServiceMetaStore struct {
+	IsLeaderFn     func() bool
+	NodesFn        func() ([]meta.NodeInfo, error)
+	DatabasesFn    func() ([]meta.DatabaseInfo, error)
+	SetNodeStateFn func(id uint64, state string) error
+}
+
+func (ms *ServiceMetaStore) IsLeader() bool                          { return ms.IsLeaderFn() }
+func (ms *ServiceMetaStore) Nodes() ([]meta.NodeInfo, error)         { return ms.NodesFn() }
+func (ms *ServiceMetaStore) Databases() ([]meta.DatabaseInfo, error) { return ms.DatabasesFn() }
+func (ms *ServiceMetaStore) SetNodeState(id uint64, state string) error {
+	return ms.SetNodeStateFn(id, state)
+}
+
+// ServiceShardCopier is a mock that implements balancer.Service.ShardCopier.
+type ServiceShardCopier struct {
+	CopyShardFn func(id, from, to uint64) error
+	MoveShardFn func(id, from, to uint64) error
+}
+
+func (sc *ServiceShardCopier) CopyShard(id, from, to uint64) error {
+	return sc.CopyShardFn(id, from, to)
+}
+func (sc *ServiceShardCopier) MoveShard(id, from, to uint64) error {
+	return sc.MoveShardFn(id, from, to)
+}
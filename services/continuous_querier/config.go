@@ -12,6 +12,8 @@ const (
 	DefaultRecomputeNoOlderThan   = 10 * time.Minute
 	DefaultComputeRunsPerInterval = 10
 	DefaultComputeNoMoreThan      = 2 * time.Minute
+	DefaultBackfillThrottle       = 100 * time.Millisecond
+	DefaultLeaseDuration          = 30 * time.Second
 )
 
 // Config represents a configuration for the continuous query service.
@@ -48,6 +50,15 @@ type Config struct {
 	// If you have a group by time(5m) then you'll get five computes per interval. Any group by time window larger
 	// than 10m will get computed 10 times for each interval.
 	ComputeNoMoreThan toml.Duration `toml:"compute-no-more-than"`
+
+	// BackfillThrottle is how long BACKFILL CONTINUOUS QUERY waits between intervals so
+	// that replaying a large time range doesn't starve regular queries and writes.
+	BackfillThrottle toml.Duration `toml:"backfill-throttle"`
+
+	// LeaseDuration is how long a data node owns a CQ before it must renew its lease.
+	// If the owner goes down, another node takes over the CQ once the lease has
+	// been expired for a further lease period.
+	LeaseDuration toml.Duration `toml:"lease-duration"`
 }
 
 // NewConfig returns a new instance of Config with defaults.
@@ -59,5 +70,7 @@ func NewConfig() Config {
 		RecomputeNoOlderThan:   toml.Duration(DefaultRecomputeNoOlderThan),
 		ComputeRunsPerInterval: DefaultComputeRunsPerInterval,
 		ComputeNoMoreThan:      toml.Duration(DefaultComputeNoMoreThan),
+		BackfillThrottle:       toml.Duration(DefaultBackfillThrottle),
+		LeaseDuration:          toml.Duration(DefaultLeaseDuration),
 	}
 }
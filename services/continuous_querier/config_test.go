@@ -16,6 +16,8 @@ recompute-previous-n = 1
 recompute-no-older-than = "10s"
 compute-runs-per-interval = 2
 compute-no-more-than = "20s"
+backfill-throttle = "1s"
+lease-duration = "1m"
 enabled = true
 `, &c); err != nil {
 		t.Fatal(err)
@@ -30,6 +32,10 @@ enabled = true
 		t.Fatalf("unexpected compute runs per interval: %d", c.ComputeRunsPerInterval)
 	} else if time.Duration(c.ComputeNoMoreThan) != 20*time.Second {
 		t.Fatalf("unexpected compute no more than: %v", c.ComputeNoMoreThan)
+	} else if time.Duration(c.BackfillThrottle) != time.Second {
+		t.Fatalf("unexpected backfill throttle: %v", c.BackfillThrottle)
+	} else if time.Duration(c.LeaseDuration) != time.Minute {
+		t.Fatalf("unexpected lease duration: %v", c.LeaseDuration)
 	} else if c.Enabled != true {
 		t.Fatalf("unexpected enabled: %v", c.Enabled)
 	}
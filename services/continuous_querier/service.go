@@ -4,6 +4,7 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"os"
 	"strings"
@@ -28,8 +29,13 @@ const (
 	statQueryOK       = "queryOk"
 	statQueryFail     = "queryFail"
 	statPointsWritten = "pointsWritten"
+	statBackfillOK    = "backfillOk"
+	statBackfillFail  = "backfillFail"
 )
 
+// ErrBackfillRunning is returned when backfilling a CQ that is already being backfilled.
+var ErrBackfillRunning = errors.New("continuous query backfill already running")
+
 // ContinuousQuerier represents a service that executes continuous queries.
 type ContinuousQuerier interface {
 	// Run executes the named query in the named database.  Blank database or name matches all.
@@ -43,9 +49,11 @@ type queryExecutor interface {
 
 // metaStore is an internal interface to make testing easier.
 type metaStore interface {
-	IsLeader() bool
+	NodeID() uint64
+	Nodes() ([]meta.NodeInfo, error)
 	Databases() ([]meta.DatabaseInfo, error)
 	Database(name string) (*meta.DatabaseInfo, error)
+	AcquireContinuousQueryLease(database, name string, nodeID uint64, d time.Duration) error
 }
 
 // RunRequest is a request to run one or more CQs.
@@ -86,6 +94,17 @@ type Service struct {
 	lastRuns map[string]time.Time
 	stop     chan struct{}
 	wg       *sync.WaitGroup
+
+	// statuses maps database and CQ name to the outcome of its last run and
+	// backfills maps them to the progress of the most recent backfill.
+	statusMu  sync.RWMutex
+	statuses  map[string]meta.ContinuousQueryStatus
+	backfills map[string]*meta.ContinuousQueryBackfill
+
+	// unleased maps database and CQ name to when this node first saw the CQ
+	// without a lease.
+	leaseMu  sync.Mutex
+	unleased map[string]time.Time
 }
 
 // NewService returns a new instance of Service.
@@ -98,6 +117,9 @@ func NewService(c Config) *Service {
 		statMap:        influxdb.NewStatistics("cq", "cq", nil),
 		Logger:         log.New(os.Stderr, "[continuous_querier] ", log.LstdFlags),
 		lastRuns:       map[string]time.Time{},
+		statuses:       map[string]meta.ContinuousQueryStatus{},
+		backfills:      map[string]*meta.ContinuousQueryBackfill{},
+		unleased:       map[string]time.Time{},
 	}
 
 	return s
@@ -126,10 +148,16 @@ func (s *Service) Close() error {
 	if s.stop == nil {
 		return nil
 	}
+	// Closing under the status lock ensures no backfill starts once Close has begun.
+	s.statusMu.Lock()
 	close(s.stop)
+	s.statusMu.Unlock()
 	s.wg.Wait()
+
+	s.statusMu.Lock()
 	s.wg = nil
 	s.stop = nil
+	s.statusMu.Unlock()
 	return nil
 }
 
@@ -179,6 +207,32 @@ func (s *Service) Run(database, name string, t time.Time) error {
 	return nil
 }
 
+// ContinuousQueryStatus returns the outcome of the last run and backfill of the
+// named CQ on this node. Returns nil if the CQ hasn't run or been backfilled
+// since the service started.
+func (s *Service) ContinuousQueryStatus(database, name string) *meta.ContinuousQueryStatus {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	status, ok := s.statuses[statusKey(database, name)]
+	if b := s.backfills[statusKey(database, name)]; b != nil {
+		backfill := *b
+		status.Backfill = &backfill
+	} else if !ok {
+		return nil
+	}
+	return &status
+}
+
+// setStatus records the outcome of a CQ run.
+func (s *Service) setStatus(database, name string, status meta.ContinuousQueryStatus) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.statuses[statusKey(database, name)] = status
+}
+
+// statusKey returns the key used to look up the status of a CQ.
+func statusKey(database, name string) string { return database + "\x00" + name }
+
 // backgroundLoop runs on a go routine and periodically executes CQs.
 func (s *Service) backgroundLoop() {
 	defer s.wg.Done()
@@ -188,19 +242,15 @@ func (s *Service) backgroundLoop() {
 			s.Logger.Println("continuous query service terminating")
 			return
 		case req := <-s.RunCh:
-			if s.MetaStore.IsLeader() {
-				s.Logger.Printf("running continuous queries by request for time: %v", req.Now.UnixNano())
-				s.runContinuousQueries(req)
-			}
+			s.Logger.Printf("running continuous queries by request for time: %v", req.Now.UnixNano())
+			s.runContinuousQueries(req)
 		case <-time.After(s.RunInterval):
-			if s.MetaStore.IsLeader() {
-				s.runContinuousQueries(&RunRequest{Now: time.Now()})
-			}
+			s.runContinuousQueries(&RunRequest{Now: time.Now()})
 		}
 	}
 }
 
-// runContinuousQueries gets CQs from the meta store and runs them.
+// runContinuousQueries gets CQs from the meta store and runs the ones this node owns.
 func (s *Service) runContinuousQueries(req *RunRequest) {
 	// Get list of all databases.
 	dbs, err := s.MetaStore.Databases()
@@ -208,11 +258,16 @@ func (s *Service) runContinuousQueries(req *RunRequest) {
 		s.Logger.Println("error getting databases")
 		return
 	}
+	nodes, err := s.MetaStore.Nodes()
+	if err != nil {
+		s.Logger.Println("error getting nodes")
+		return
+	}
 	// Loop through all databases executing CQs.
+	now := time.Now()
 	for _, db := range dbs {
-		// TODO: distribute across nodes
 		for _, cq := range db.ContinuousQueries {
-			if !req.matches(&cq) {
+			if !req.matches(&cq) || !s.ownsContinuousQuery(nodes, &db, &cq, now) {
 				continue
 			}
 			if err := s.ExecuteContinuousQuery(&db, &cq, req.Now); err != nil {
@@ -225,8 +280,94 @@ func (s *Service) runContinuousQueries(req *RunRequest) {
 	}
 }
 
+// ownsContinuousQuery returns true if this node holds the lease to run a CQ,
+// acquiring or renewing the lease as needed. Each CQ has a preferred owner so
+// that CQs are spread across the data nodes. Only the preferred owner acquires
+// a CQ as soon as it is unleased. Other nodes wait for a grace period, counted
+// from when they first saw the CQ unleased, before taking it over. Only the
+// preferred owner renews its lease. Other nodes let their lease lapse so the
+// preferred owner takes the CQ back once it returns.
+func (s *Service) ownsContinuousQuery(nodes []meta.NodeInfo, dbi *meta.DatabaseInfo, cqi *meta.ContinuousQueryInfo, now time.Time) bool {
+	nodeID := s.MetaStore.NodeID()
+	d := time.Duration(s.Config.LeaseDuration)
+	preferred := preferredOwner(nodes, dbi.Name, cqi.Name) == nodeID
+	held := cqi.LeaseNodeID != 0 && now.Before(cqi.LeaseExpiration)
+	key := statusKey(dbi.Name, cqi.Name)
+
+	switch {
+	case held && cqi.LeaseNodeID == nodeID:
+		// Renew halfway through the lease so clock skew between this node
+		// and the leader, which stamps the lease, doesn't let it lapse.
+		if preferred && cqi.LeaseExpiration.Sub(now) < d/2 {
+			s.acquireLease(dbi.Name, cqi.Name, nodeID, d)
+		}
+		return true
+	case held:
+		s.clearUnleasedSince(key)
+		return false
+	case preferred:
+		return s.acquireLease(dbi.Name, cqi.Name, nodeID, d)
+	}
+
+	// Give the preferred owner a lease period to pick up the CQ. The node
+	// whose lease just lapsed only waits for the preferred owner to run
+	// twice so the CQ isn't left unowned while the preferred owner is down.
+	grace := d
+	if cqi.LeaseNodeID == nodeID {
+		grace = 2 * s.RunInterval
+	}
+	since := s.unleasedSince(key, now)
+	if now.Sub(since) < grace {
+		return false
+	} else if !s.acquireLease(dbi.Name, cqi.Name, nodeID, d) {
+		return false
+	}
+	s.clearUnleasedSince(key)
+	return true
+}
+
+// unleasedSince returns when this node first saw a CQ unleased, recording now
+// if it hasn't seen the CQ unleased before.
+func (s *Service) unleasedSince(key string, now time.Time) time.Time {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	if t, ok := s.unleased[key]; ok {
+		return t
+	}
+	s.unleased[key] = now
+	return now
+}
+
+// clearUnleasedSince forgets when this node first saw a CQ unleased.
+func (s *Service) clearUnleasedSince(key string) {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	delete(s.unleased, key)
+}
+
+// acquireLease acquires the lease on a CQ for this node. Returns true if successful.
+func (s *Service) acquireLease(database, name string, nodeID uint64, d time.Duration) bool {
+	if err := s.MetaStore.AcquireContinuousQueryLease(database, name, nodeID, d); err == meta.ErrContinuousQueryLeaseHeld {
+		return false
+	} else if err != nil {
+		s.Logger.Printf("error acquiring lease for continuous query %s on %s: %s", name, database, err)
+		return false
+	}
+	return true
+}
+
+// preferredOwner returns the ID of the node that should run a CQ.
+func preferredOwner(nodes []meta.NodeInfo, database, name string) uint64 {
+	if len(nodes) == 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(database + "\x00" + name))
+	return nodes[h.Sum32()%uint32(len(nodes))].ID
+}
+
 // ExecuteContinuousQuery executes a single CQ.
-func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.ContinuousQueryInfo, now time.Time) error {
+func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.ContinuousQueryInfo, now time.Time) (err error) {
 	// TODO: re-enable stats
 	//s.stats.Inc("continuousQueryExecuted")
 
@@ -260,6 +401,20 @@ func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.Conti
 	cq.LastRun = lastRun
 	s.lastRuns[cqi.Name] = lastRun
 
+	// Record the outcome of this run so it can be reported by SHOW CONTINUOUS QUERIES.
+	var written int64
+	defer func() {
+		status := meta.ContinuousQueryStatus{
+			LastRun:       lastRun,
+			LastDuration:  time.Since(lastRun),
+			PointsWritten: written,
+		}
+		if err != nil {
+			status.LastError = err.Error()
+		}
+		s.setStatus(dbi.Name, cqi.Name, status)
+	}()
+
 	// Get the group by interval.
 	interval, err := cq.q.GroupByInterval()
 	if err != nil {
@@ -274,46 +429,192 @@ func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.Conti
 		startTime = startTime.Add(-interval)
 	}
 
-	if err := cq.q.SetTimeRange(startTime, startTime.Add(interval)); err != nil {
-		s.Logger.Printf("error setting time range: %s\n", err)
-	}
-
 	if s.loggingEnabled {
 		s.Logger.Printf("executing continuous query %s", cq.Info.Name)
 	}
 
 	// Do the actual processing of the query & writing of results.
-	if err := s.runContinuousQueryAndWriteResult(cq); err != nil {
-		s.Logger.Printf("error: %s. running: %s\n", err, cq.q.String())
+	n, err := s.executeInterval(cq, startTime, startTime.Add(interval))
+	written += n
+	if err != nil {
 		return err
 	}
 
+	// Use the query's RESAMPLE FOR window, if set, in place of the service defaults.
+	// A RESAMPLE EVERY longer than the interval without a FOR must still cover
+	// every interval since the last run, so the window defaults to EVERY.
+	recomputePreviousN := s.Config.RecomputePreviousN
 	recomputeNoOlderThan := time.Duration(s.Config.RecomputeNoOlderThan)
+	resampleFor := cqi.ResampleFor
+	if resampleFor == 0 && cqi.ResampleEvery > interval {
+		resampleFor = cqi.ResampleEvery
+	}
+	if resampleFor != 0 {
+		recomputePreviousN = int((resampleFor+interval-1)/interval) - 1
+		recomputeNoOlderThan = resampleFor
+	}
 
-	for i := 0; i < s.Config.RecomputePreviousN; i++ {
+	for i := 0; i < recomputePreviousN; i++ {
 		// if we're already more time past the previous window than we're going to look back, stop
 		if now.Sub(startTime) > recomputeNoOlderThan {
 			return nil
 		}
 		newStartTime := startTime.Add(-interval)
 
-		if err := cq.q.SetTimeRange(newStartTime, startTime); err != nil {
-			s.Logger.Printf("error setting time range: %s\n", err)
+		n, err = s.executeInterval(cq, newStartTime, startTime)
+		written += n
+		if err != nil {
 			return err
 		}
 
-		if err := s.runContinuousQueryAndWriteResult(cq); err != nil {
-			s.Logger.Printf("error during recompute previous: %s. running: %s\n", err, cq.q.String())
-			return err
+		startTime = newStartTime
+	}
+	return nil
+}
+
+// BackfillContinuousQuery starts running the named CQ over each of its GROUP BY
+// intervals between start and end in the background, pausing for the configured
+// throttle between intervals. Progress is reported by ContinuousQueryStatus.
+func (s *Service) BackfillContinuousQuery(database, name string, start, end time.Time) error {
+	// Find the requested database and CQ.
+	dbi, err := s.MetaStore.Database(database)
+	if err != nil {
+		return err
+	} else if dbi == nil {
+		return tsdb.ErrDatabaseNotFound(database)
+	}
+
+	var cqi *meta.ContinuousQueryInfo
+	for i := range dbi.ContinuousQueries {
+		if dbi.ContinuousQueries[i].Name == name {
+			cqi = &dbi.ContinuousQueries[i]
+			break
 		}
+	}
+	if cqi == nil {
+		return meta.ErrContinuousQueryNotFound
+	}
 
-		startTime = newStartTime
+	cq, err := NewContinuousQuery(dbi.Name, cqi)
+	if err != nil {
+		return err
+	} else if cq.q.IsRawQuery {
+		return errors.New("continuous queries must be aggregate queries")
+	}
+
+	// Set the retention policy to default if it wasn't specified in the query.
+	if cq.intoRP() == "" {
+		cq.setIntoRP(dbi.DefaultRetentionPolicy)
+	}
+
+	interval, err := cq.q.GroupByInterval()
+	if err != nil {
+		return err
+	} else if interval == 0 {
+		return errors.New("continuous query has no GROUP BY interval")
+	}
+
+	// Align the range to the GROUP BY intervals.
+	start = start.Truncate(interval)
+	backfill := &meta.ContinuousQueryBackfill{
+		StartTime: start,
+		EndTime:   end,
+		Total:     int64((end.Sub(start) + interval - 1) / interval),
+		Running:   true,
+	}
+
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if s.stop == nil || isClosed(s.stop) {
+		return errors.New("continuous query service is closed")
+	} else if b := s.backfills[statusKey(database, name)]; b != nil && b.Running {
+		return ErrBackfillRunning
 	}
+	s.backfills[statusKey(database, name)] = backfill
+
+	s.wg.Add(1)
+	go s.backfill(cq, interval, *backfill)
 	return nil
 }
 
-// runContinuousQueryAndWriteResult will run the query against the cluster and write the results back in
-func (s *Service) runContinuousQueryAndWriteResult(cq *ContinuousQuery) error {
+// backfill runs a CQ over each interval of a backfill and records its progress.
+func (s *Service) backfill(cq *ContinuousQuery, interval time.Duration, b meta.ContinuousQueryBackfill) {
+	defer s.wg.Done()
+
+	s.Logger.Printf("backfilling continuous query %s on %s from %s to %s", cq.Info.Name, cq.Database, b.StartTime.UTC(), b.EndTime.UTC())
+	throttle := time.Duration(s.Config.BackfillThrottle)
+
+	err := func() error {
+		for t := b.StartTime; t.Before(b.EndTime); t = t.Add(interval) {
+			n, err := s.executeInterval(cq, t, t.Add(interval))
+			b.PointsWritten += n
+			if err != nil {
+				return err
+			}
+
+			b.Intervals++
+			s.setBackfill(cq.Database, cq.Info.Name, b)
+
+			// Throttle between intervals, stopping early if the service is closed.
+			if b.Intervals < b.Total {
+				select {
+				case <-s.stop:
+					return errors.New("backfill interrupted: continuous query service closed")
+				case <-time.After(throttle):
+				}
+			}
+		}
+		return nil
+	}()
+
+	b.Running = false
+	if err != nil {
+		s.Logger.Printf("error during backfill of continuous query %s on %s: %s", cq.Info.Name, cq.Database, err)
+		b.Err = err.Error()
+		s.statMap.Add(statBackfillFail, 1)
+	} else {
+		s.Logger.Printf("backfilled continuous query %s on %s: %d intervals, %d points written", cq.Info.Name, cq.Database, b.Intervals, b.PointsWritten)
+		s.statMap.Add(statBackfillOK, 1)
+	}
+	s.setBackfill(cq.Database, cq.Info.Name, b)
+}
+
+// isClosed returns true if ch is closed.
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// setBackfill records the progress of a backfill.
+func (s *Service) setBackfill(database, name string, b meta.ContinuousQueryBackfill) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.backfills[statusKey(database, name)] = &b
+}
+
+// executeInterval runs a CQ over the time range from start to end and writes
+// the results. It returns the number of points written.
+func (s *Service) executeInterval(cq *ContinuousQuery, start, end time.Time) (int64, error) {
+	if err := cq.q.SetTimeRange(start, end); err != nil {
+		s.Logger.Printf("error setting time range: %s\n", err)
+		return 0, err
+	}
+
+	n, err := s.runContinuousQueryAndWriteResult(cq)
+	if err != nil {
+		s.Logger.Printf("error: %s. running: %s\n", err, cq.q.String())
+		return n, err
+	}
+	return n, nil
+}
+
+// runContinuousQueryAndWriteResult will run the query against the cluster and write the results back in.
+// It returns the number of points written.
+func (s *Service) runContinuousQueryAndWriteResult(cq *ContinuousQuery) (int64, error) {
 	// Wrap the CQ's inner SELECT statement in a Query for the QueryExecutor.
 	q := &influxql.Query{
 		Statements: influxql.Statements([]influxql.Statement{cq.q}),
@@ -325,7 +626,7 @@ func (s *Service) runContinuousQueryAndWriteResult(cq *ContinuousQuery) error {
 	// Execute the SELECT.
 	ch, err := s.QueryExecutor.ExecuteQuery(q, cq.Database, NoChunkingSize, closing)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	// There is only one statement, so we will only ever receive one result
 	res, ok := <-ch
@@ -333,9 +634,26 @@ func (s *Service) runContinuousQueryAndWriteResult(cq *ContinuousQuery) error {
 		panic("result channel was closed")
 	}
 	if res.Err != nil {
-		return res.Err
+		return 0, res.Err
 	}
-	return nil
+	return pointsWritten(res), nil
+}
+
+// pointsWritten returns the number of points reported written by the result of a SELECT INTO.
+func pointsWritten(res *influxql.Result) int64 {
+	for _, row := range res.Series {
+		for i, col := range row.Columns {
+			if col != "written" {
+				continue
+			}
+			for _, values := range row.Values {
+				if n, ok := values[i].(int64); ok {
+					return n
+				}
+			}
+		}
+	}
+	return 0
 }
 
 // ContinuousQuery is a local wrapper / helper around continuous queries.
@@ -372,7 +690,7 @@ func NewContinuousQuery(database string, cqi *meta.ContinuousQueryInfo) (*Contin
 
 // shouldRunContinuousQuery returns true if the CQ should be schedule to run. It will use the
 // lastRunTime of the CQ and the rules for when to run set through the config to determine
-// if this CQ should be run
+// if this CQ should be run. A RESAMPLE EVERY interval on the CQ overrides runsPerInterval.
 func (cq *ContinuousQuery) shouldRunContinuousQuery(runsPerInterval int, noMoreThan time.Duration) (bool, error) {
 	// if it's not aggregated we don't run it
 	if cq.q.IsRawQuery {
@@ -388,6 +706,9 @@ func (cq *ContinuousQuery) shouldRunContinuousQuery(runsPerInterval int, noMoreT
 	// determine how often we should run this continuous query.
 	// group by time / the number of times to compute
 	computeEvery := time.Duration(interval.Nanoseconds()/int64(runsPerInterval)) * time.Nanosecond
+	if cq.Info.ResampleEvery != 0 {
+		computeEvery = cq.Info.ResampleEvery
+	}
 	// make sure we're running no more frequently than the setting in the config
 	if computeEvery < noMoreThan {
 		computeEvery = noMoreThan
@@ -92,16 +92,22 @@ func TestContinuousQueryService_Run(t *testing.T) {
 	s.Close()
 }
 
-// Test service when not the cluster leader (CQs shouldn't run).
-func TestContinuousQueryService_NotLeader(t *testing.T) {
+// Test service when another node holds the CQ leases (CQs shouldn't run).
+func TestContinuousQueryService_LeaseHeld(t *testing.T) {
 	s := NewTestService(t)
 	// Set RunInterval high so we can test triggering with the RunCh below.
 	s.RunInterval = 10 * time.Second
-	s.MetaStore.(*MetaStore).Leader = false
+	ms := s.MetaStore.(*MetaStore)
+	for i := range ms.DatabaseInfos {
+		for j := range ms.DatabaseInfos[i].ContinuousQueries {
+			ms.DatabaseInfos[i].ContinuousQueries[j].LeaseNodeID = 2
+			ms.DatabaseInfos[i].ContinuousQueries[j].LeaseExpiration = time.Now().Add(time.Hour)
+		}
+	}
 
 	done := make(chan struct{})
 	qe := s.QueryExecutor.(*QueryExecutor)
-	// Set a callback for ExecuteQuery. Shouldn't get called because we're not the leader.
+	// Set a callback for ExecuteQuery. Shouldn't get called because we don't own the CQs.
 	qe.ExecuteQueryFn = func(query *influxql.Query, database string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
 		done <- struct{}{}
 		return nil, errUnexpected
@@ -186,6 +192,331 @@ func TestExecuteContinuousQuery_QueryExecutor_Error(t *testing.T) {
 	}
 }
 
+// Test ExecuteContinuousQuery records the outcome of each run.
+func TestExecuteContinuousQuery_Status(t *testing.T) {
+	s := NewTestService(t)
+	s.Config.RecomputePreviousN = 0
+	qe := s.QueryExecutor.(*QueryExecutor)
+	qe.Results = []*influxql.Result{{
+		Series: models.Rows{{
+			Name:    "result",
+			Columns: []string{"time", "written"},
+			Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(10)}},
+		}},
+	}}
+
+	dbis, _ := s.MetaStore.Databases()
+	dbi := dbis[0]
+	cqi := dbi.ContinuousQueries[0]
+
+	if status := s.ContinuousQueryStatus(dbi.Name, cqi.Name); status != nil {
+		t.Fatalf("unexpected status before first run: %#v", status)
+	}
+
+	if err := s.ExecuteContinuousQuery(&dbi, &cqi, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	status := s.ContinuousQueryStatus(dbi.Name, cqi.Name)
+	if status == nil {
+		t.Fatal("expected status")
+	} else if status.LastRun.IsZero() {
+		t.Error("expected last run time to be set")
+	} else if status.PointsWritten != 10 {
+		t.Errorf("exp points written = 10, got = %d", status.PointsWritten)
+	} else if status.LastError != "" {
+		t.Errorf("unexpected error: %s", status.LastError)
+	}
+
+	// A failed run replaces the status with the error.
+	qe.Results = nil
+	qe.Err = errExpected
+	s.lastRuns[cqi.Name] = time.Time{}
+	if err := s.ExecuteContinuousQuery(&dbi, &cqi, time.Now()); err != errExpected {
+		t.Fatalf("exp = %s, got = %v", errExpected, err)
+	}
+
+	status = s.ContinuousQueryStatus(dbi.Name, cqi.Name)
+	if status == nil {
+		t.Fatal("expected status")
+	} else if status.PointsWritten != 0 {
+		t.Errorf("exp points written = 0, got = %d", status.PointsWritten)
+	} else if status.LastError != errExpected.Error() {
+		t.Errorf("exp error = %s, got = %s", errExpected, status.LastError)
+	}
+}
+
+// Test ExecuteContinuousQuery recomputes the window set by RESAMPLE FOR.
+func TestExecuteContinuousQuery_ResampleFor(t *testing.T) {
+	s := NewTestService(t)
+	qe := s.QueryExecutor.(*QueryExecutor)
+
+	var n int
+	qe.ExecuteQueryFn = func(query *influxql.Query, database string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		n++
+		return nil, nil
+	}
+
+	dbis, _ := s.MetaStore.Databases()
+	dbi := dbis[0]
+	cqi := dbi.ContinuousQueries[0]
+	cqi.ResampleFor = 3 * time.Second
+
+	if err := s.ExecuteContinuousQuery(&dbi, &cqi, time.Now()); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("exp query executions = 3, got = %d", n)
+	}
+}
+
+// Test ExecuteContinuousQuery covers every interval since the last run when
+// RESAMPLE EVERY is longer than the GROUP BY interval and FOR is not set.
+func TestExecuteContinuousQuery_ResampleEveryDefaultsFor(t *testing.T) {
+	s := NewTestService(t)
+	qe := s.QueryExecutor.(*QueryExecutor)
+
+	var n int
+	qe.ExecuteQueryFn = func(query *influxql.Query, database string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		n++
+		return nil, nil
+	}
+
+	dbis, _ := s.MetaStore.Databases()
+	dbi := dbis[0]
+	cqi := dbi.ContinuousQueries[0]
+	cqi.ResampleEvery = 4 * time.Second
+
+	if err := s.ExecuteContinuousQuery(&dbi, &cqi, time.Now()); err != nil {
+		t.Fatal(err)
+	} else if n != 4 {
+		t.Fatalf("exp query executions = 4, got = %d", n)
+	}
+}
+
+// Test shouldRunContinuousQuery honours RESAMPLE EVERY.
+func TestContinuousQuery_ShouldRun_ResampleEvery(t *testing.T) {
+	cqi := &meta.ContinuousQueryInfo{
+		Name:  "cq",
+		Query: `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT count(cpu) INTO cpu_count FROM cpu GROUP BY time(1h) END`,
+	}
+	cq, err := NewContinuousQuery("db", cqi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cq.LastRun = time.Now().Add(-2 * time.Second)
+
+	// Without RESAMPLE EVERY the query runs once per GROUP BY interval.
+	if run, err := cq.shouldRunContinuousQuery(1, 0); err != nil {
+		t.Fatal(err)
+	} else if run {
+		t.Error("expected query not to run")
+	}
+
+	cqi.ResampleEvery = time.Second
+	if run, err := cq.shouldRunContinuousQuery(1, 0); err != nil {
+		t.Fatal(err)
+	} else if !run {
+		t.Error("expected query to run")
+	}
+}
+
+// Test BackfillContinuousQuery runs the CQ once per interval in the range in the background.
+func TestBackfillContinuousQuery(t *testing.T) {
+	s := NewTestService(t)
+	s.Config.BackfillThrottle = 0
+	s.stop, s.wg = make(chan struct{}), &sync.WaitGroup{}
+	qe := s.QueryExecutor.(*QueryExecutor)
+	qe.Results = []*influxql.Result{{
+		Series: models.Rows{{
+			Name:    "result",
+			Columns: []string{"time", "written"},
+			Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(2)}},
+		}},
+	}}
+
+	var mins []time.Time
+	qe.ExecuteQueryFn = func(query *influxql.Query, database string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		min, _ := influxql.TimeRange(query.Statements[0].(*influxql.SelectStatement).Condition)
+		mins = append(mins, min)
+		return nil, nil
+	}
+
+	start := time.Unix(100, 0)
+	if err := s.BackfillContinuousQuery("db", "cq", start, start.Add(5*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	s.wg.Wait()
+
+	if len(mins) != 5 {
+		t.Fatalf("exp query executions = 5, got = %d", len(mins))
+	}
+	for i, min := range mins {
+		if exp := start.Add(time.Duration(i) * time.Second); !min.Equal(exp) {
+			t.Errorf("%d. exp start = %s, got = %s", i, exp, min)
+		}
+	}
+
+	// The progress of the backfill is reported with the CQ's status.
+	if status := s.ContinuousQueryStatus("db", "cq"); status == nil || status.Backfill == nil {
+		t.Fatal("expected backfill status")
+	} else if b := status.Backfill; b.Running || b.Err != "" || b.Intervals != 5 || b.Total != 5 || b.PointsWritten != 10 {
+		t.Fatalf("unexpected backfill status: %+v", b)
+	}
+
+	if err := s.BackfillContinuousQuery("db", "no_such_cq", start, start.Add(time.Second)); err != meta.ErrContinuousQueryNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Test BackfillContinuousQuery rejects a second backfill of a CQ while one is running.
+func TestBackfillContinuousQuery_Running(t *testing.T) {
+	s := NewTestService(t)
+	s.Config.BackfillThrottle = 0
+	s.stop, s.wg = make(chan struct{}), &sync.WaitGroup{}
+	qe := s.QueryExecutor.(*QueryExecutor)
+
+	release := make(chan struct{})
+	qe.ExecuteQueryFn = func(query *influxql.Query, database string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		<-release
+		return nil, nil
+	}
+
+	start := time.Unix(100, 0)
+	if err := s.BackfillContinuousQuery("db", "cq", start, start.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	} else if err := s.BackfillContinuousQuery("db", "cq", start, start.Add(time.Second)); err != ErrBackfillRunning {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(release)
+	s.wg.Wait()
+}
+
+// Test CQs are spread across nodes and taken over when a lease expires.
+func TestService_OwnsContinuousQuery(t *testing.T) {
+	s := NewTestService(t)
+	ms := s.MetaStore.(*MetaStore)
+	ms.NodeInfos = []meta.NodeInfo{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	// Find a CQ whose preferred owner is another node.
+	dbis, _ := ms.Databases()
+	var dbi *meta.DatabaseInfo
+	var cqi *meta.ContinuousQueryInfo
+	for i := range dbis {
+		if preferredOwner(ms.NodeInfos, dbis[i].Name, dbis[i].ContinuousQueries[0].Name) != 1 {
+			dbi, cqi = &dbis[i], &dbis[i].ContinuousQueries[0]
+			break
+		}
+	}
+	if cqi == nil {
+		t.Fatal("expected a CQ owned by another node")
+	}
+	now := time.Now()
+	d := time.Duration(s.Config.LeaseDuration)
+
+	// Don't take over a lease that was just released by the preferred owner.
+	cqi.LeaseNodeID, cqi.LeaseExpiration = 2, now.Add(-time.Second)
+	if s.ownsContinuousQuery(ms.NodeInfos, dbi, cqi, now) {
+		t.Fatal("expected lease to be left for preferred owner")
+	}
+
+	// Take over the lease once it has been unleased for a full lease period.
+	now = now.Add(d)
+	if !s.ownsContinuousQuery(ms.NodeInfos, dbi, cqi, now) {
+		t.Fatal("expected lease to be acquired")
+	} else if di, _ := ms.Database(dbi.Name); di.ContinuousQueries[0].LeaseNodeID != 1 {
+		t.Fatalf("unexpected lease owner: %d", di.ContinuousQueries[0].LeaseNodeID)
+	}
+
+	// Don't renew the lease since this isn't the preferred owner.
+	cqi.LeaseNodeID, cqi.LeaseExpiration = 1, now.Add(time.Second)
+	if !s.ownsContinuousQuery(ms.NodeInfos, dbi, cqi, now) {
+		t.Fatal("expected lease to be held")
+	} else if di, _ := ms.Database(dbi.Name); di.ContinuousQueries[0].LeaseExpiration.After(now.Add(time.Second)) {
+		t.Fatalf("unexpected lease renewal: %s", di.ContinuousQueries[0].LeaseExpiration)
+	}
+
+	// Acquire the lease again shortly after it lapses if the preferred owner doesn't.
+	now = now.Add(2 * time.Second)
+	if s.ownsContinuousQuery(ms.NodeInfos, dbi, cqi, now) {
+		t.Fatal("expected lease to be left for preferred owner")
+	} else if !s.ownsContinuousQuery(ms.NodeInfos, dbi, cqi, now.Add(2*s.RunInterval)) {
+		t.Fatal("expected lease to be acquired")
+	}
+}
+
+// Ensure CQs stay with their preferred owner when other nodes run first and
+// move back to it once it returns.
+func TestService_OwnsContinuousQuery_MultiNode(t *testing.T) {
+	s1 := NewTestService(t)
+	ms := s1.MetaStore.(*MetaStore)
+	ms.NodeInfos = []meta.NodeInfo{{ID: 1}, {ID: 2}}
+	s2 := NewTestService(t)
+	s2.MetaStore = &nodeMetaStore{MetaStore: ms, id: 2}
+	d := time.Duration(s1.Config.LeaseDuration)
+
+	// owner returns the node holding the lease on each CQ preferred by node 2
+	// after node 1 and then node 2 check their CQs.
+	var names []string
+	for _, dbi := range ms.DatabaseInfos {
+		if preferredOwner(ms.NodeInfos, dbi.Name, dbi.ContinuousQueries[0].Name) == 2 {
+			names = append(names, dbi.Name)
+		}
+	}
+	if len(names) == 0 {
+		t.Fatal("expected a CQ preferred by node 2")
+	}
+	owner := func(now time.Time, services ...*Service) map[string]uint64 {
+		for _, s := range services {
+			for _, name := range names {
+				dbi, _ := ms.Database(name)
+				s.ownsContinuousQuery(ms.NodeInfos, dbi, &dbi.ContinuousQueries[0], now)
+			}
+		}
+		m := make(map[string]uint64)
+		for _, name := range names {
+			dbi, _ := ms.Database(name)
+			m[name] = dbi.ContinuousQueries[0].LeaseNodeID
+		}
+		return m
+	}
+
+	// Node 1 checks the unleased CQs first but leaves them for node 2.
+	now := time.Now()
+	for name, id := range owner(now, s1, s2) {
+		if id != 2 {
+			t.Fatalf("unexpected owner of %s: %d", name, id)
+		}
+	}
+
+	// Node 2 goes down and node 1 takes over once the grace period has passed.
+	expire := func() {
+		ms.mu.Lock()
+		defer ms.mu.Unlock()
+		for i := range ms.DatabaseInfos {
+			ms.DatabaseInfos[i].ContinuousQueries[0].LeaseExpiration = now.Add(-time.Second)
+		}
+	}
+	expire()
+	for name, id := range owner(now, s1) {
+		if id != 2 {
+			t.Fatalf("unexpected owner of %s: %d", name, id)
+		}
+	}
+	for name, id := range owner(now.Add(d), s1) {
+		if id != 1 {
+			t.Fatalf("unexpected owner of %s: %d", name, id)
+		}
+	}
+
+	// Node 2 returns and takes the CQs back once node 1's lease lapses.
+	expire()
+	for name, id := range owner(now, s1, s2) {
+		if id != 2 {
+			t.Fatalf("unexpected owner of %s: %d", name, id)
+		}
+	}
+}
+
 // NewTestService returns a new *Service with default mock object members.
 func NewTestService(t *testing.T) *Service {
 	s := NewService(NewConfig())
@@ -213,7 +544,8 @@ func NewTestService(t *testing.T) *Service {
 // MetaStore is a mock meta store.
 type MetaStore struct {
 	mu            sync.RWMutex
-	Leader        bool
+	ID            uint64
+	NodeInfos     []meta.NodeInfo
 	DatabaseInfos []meta.DatabaseInfo
 	Err           error
 	t             *testing.T
@@ -222,16 +554,55 @@ type MetaStore struct {
 // NewMetaStore returns a *MetaStore.
 func NewMetaStore(t *testing.T) *MetaStore {
 	return &MetaStore{
-		Leader: true,
-		t:      t,
+		ID:        1,
+		NodeInfos: []meta.NodeInfo{{ID: 1}},
+		t:         t,
 	}
 }
 
-// IsLeader returns true if the node is the cluster leader.
-func (ms *MetaStore) IsLeader() bool {
+// NodeID returns the ID of the local node.
+func (ms *MetaStore) NodeID() uint64 { return ms.ID }
+
+// nodeMetaStore shares a mock meta store between services on different nodes.
+type nodeMetaStore struct {
+	*MetaStore
+	id uint64
+}
+
+// NodeID returns the ID of the local node.
+func (ms *nodeMetaStore) NodeID() uint64 { return ms.id }
+
+// Nodes returns a list of the nodes in the cluster.
+func (ms *MetaStore) Nodes() ([]meta.NodeInfo, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
-	return ms.Leader
+	return ms.NodeInfos, ms.Err
+}
+
+// AcquireContinuousQueryLease grants a node the lease on a CQ.
+func (ms *MetaStore) AcquireContinuousQueryLease(database, name string, nodeID uint64, d time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	dbi, err := ms.database(database)
+	if err != nil {
+		return err
+	} else if dbi == nil {
+		return fmt.Errorf("database not found: %s", database)
+	}
+
+	now := time.Now()
+	for i := range dbi.ContinuousQueries {
+		cqi := &dbi.ContinuousQueries[i]
+		if cqi.Name != name {
+			continue
+		} else if cqi.LeaseNodeID != 0 && cqi.LeaseNodeID != nodeID && now.Before(cqi.LeaseExpiration) {
+			return meta.ErrContinuousQueryLeaseHeld
+		}
+		cqi.LeaseNodeID, cqi.LeaseExpiration = nodeID, now.Add(d)
+		return nil
+	}
+	return meta.ErrContinuousQueryNotFound
 }
 
 // Databases returns a list of database info about each database in the cluster.
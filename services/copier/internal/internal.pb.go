@@ -25,6 +25,10 @@ var _ = math.Inf
 
 type Request struct {
 	ShardID          *uint64 `protobuf:"varint,1,req,name=ShardID" json:"ShardID,omitempty"`
+	Type             *uint32 `protobuf:"varint,2,opt,name=Type" json:"Type,omitempty"`
+	Database         *string `protobuf:"bytes,3,opt,name=Database" json:"Database,omitempty"`
+	Policy           *string `protobuf:"bytes,4,opt,name=Policy" json:"Policy,omitempty"`
+	Host             *string `protobuf:"bytes,5,opt,name=Host" json:"Host,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -39,6 +43,34 @@ func (m *Request) GetShardID() uint64 {
 	return 0
 }
 
+func (m *Request) GetType() uint32 {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return 0
+}
+
+func (m *Request) GetDatabase() string {
+	if m != nil && m.Database != nil {
+		return *m.Database
+	}
+	return ""
+}
+
+func (m *Request) GetPolicy() string {
+	if m != nil && m.Policy != nil {
+		return *m.Policy
+	}
+	return ""
+}
+
+func (m *Request) GetHost() string {
+	if m != nil && m.Host != nil {
+		return *m.Host
+	}
+	return ""
+}
+
 type Response struct {
 	Error            *string `protobuf:"bytes,1,opt,name=Error" json:"Error,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
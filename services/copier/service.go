@@ -1,6 +1,7 @@
 package copier
 
 import (
+	"crypto/tls"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -10,8 +11,10 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/services/copier/internal"
 	"github.com/influxdb/influxdb/tcp"
 	"github.com/influxdb/influxdb/tsdb"
@@ -22,17 +25,58 @@ import (
 // MuxHeader is the header byte used for the TCP muxer.
 const MuxHeader = 6
 
+// ErrShardHot is returned when copying a shard whose shard group hasn't ended.
+// Points written to such a shard while it is copied would be missing from the copy.
+var ErrShardHot = errors.New("shard group has not ended")
+
+// ErrShardOwned is returned when asked to delete a shard the node still owns.
+var ErrShardOwned = errors.New("shard is owned by this node")
+
+// Request types.
+const (
+	// shardRequest streams the contents of a shard to the client.
+	shardRequest = iota
+
+	// restoreRequest copies a shard from another host into the local store.
+	restoreRequest
+
+	// deleteRequest removes a shard from the local store.
+	deleteRequest
+)
+
 // Service manages the listener for the endpoint.
 type Service struct {
 	wg  sync.WaitGroup
 	err chan error
 
+	MetaStore interface {
+		NodeID() uint64
+		Node(id uint64) (*meta.NodeInfo, error)
+		ShardOwner(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
+		AddShardOwner(id, nodeID uint64) error
+		RemoveShardOwner(id, nodeID uint64) error
+	}
+
 	TSDBStore interface {
 		Shard(id uint64) *tsdb.Shard
+		RestoreShard(database, policy string, shardID uint64, r io.Reader) error
+		DeleteShard(shardID uint64) error
+	}
+
+	// Compares the copies of a shard on two nodes to verify a copy.
+	ShardComparer interface {
+		CompareShard(shardID, nodeA, nodeB uint64) ([]meta.ShardDifference, error)
 	}
 
 	Listener net.Listener
 	Logger   *log.Logger
+
+	// If set, connections to other nodes use TLS.
+	TLSConfig *tls.Config
+
+	// Maximum rate, in bytes per second, at which shards are streamed to
+	// other nodes. A value of 0 disables the rate limit.
+	RateLimit int64
 }
 
 // NewService returns a new instance of Service.
@@ -69,6 +113,132 @@ func (s *Service) SetLogger(l *log.Logger) {
 // Err returns a channel for fatal out-of-band errors.
 func (s *Service) Err() <-chan error { return s.err }
 
+// CopyShard copies a shard from one node to another. The destination node is
+// added to the shard's owners once its copy matches the source. Only shards
+// whose shard group has ended can be copied as points written to the source
+// node during the copy would be lost.
+func (s *Service) CopyShard(id, from, to uint64) error {
+	database, policy, sgi := s.MetaStore.ShardOwner(id)
+	if sgi == nil {
+		return meta.ErrShardNotFound
+	} else if sgi.EndTime.After(time.Now()) {
+		return ErrShardHot
+	}
+
+	// Ensure the shard can be copied between the nodes.
+	for _, sh := range sgi.Shards {
+		if sh.ID != id {
+			continue
+		} else if !sh.OwnedBy(from) {
+			return meta.ErrShardOwnerNotFound
+		} else if sh.OwnedBy(to) {
+			return meta.ErrShardOwnerExists
+		}
+	}
+
+	src, err := s.node(from)
+	if err != nil {
+		return err
+	}
+	dst, err := s.node(to)
+	if err != nil {
+		return err
+	}
+
+	// Pull the shard into the destination node's store.
+	s.Logger.Printf("copying shard %d from node %d to node %d", id, from, to)
+	if to == s.MetaStore.NodeID() {
+		err = s.restoreShard(database, policy, id, src.Host)
+	} else {
+		err = s.client(dst.Host).RestoreShard(database, policy, id, src.Host)
+	}
+	if err != nil {
+		return fmt.Errorf("copy shard %d: %s", id, err)
+	} else if err := s.verifyShard(id, from, to); err != nil {
+		return err
+	}
+
+	return s.MetaStore.AddShardOwner(id, to)
+}
+
+// MoveShard copies a shard from one node to another and then removes the
+// shard from the source node. The source node's copy is only removed once
+// the copies are verified to match again after the destination became an owner.
+func (s *Service) MoveShard(id, from, to uint64) error {
+	if err := s.CopyShard(id, from, to); err != nil {
+		return err
+	} else if err := s.verifyShard(id, from, to); err != nil {
+		return err
+	} else if err := s.MetaStore.RemoveShardOwner(id, from); err != nil {
+		return err
+	}
+
+	// Remove the source node's copy now that it no longer owns the shard.
+	s.Logger.Printf("removing shard %d from node %d", id, from)
+	if from == s.MetaStore.NodeID() {
+		return s.deleteShard(id)
+	}
+	src, err := s.node(from)
+	if err != nil {
+		return err
+	}
+	return s.client(src.Host).DeleteShard(id)
+}
+
+// verifyShard returns an error if the copy of a shard on node to differs
+// from the copy on node from.
+func (s *Service) verifyShard(id, from, to uint64) error {
+	diffs, err := s.ShardComparer.CompareShard(id, from, to)
+	if err != nil {
+		return fmt.Errorf("verify shard %d: %s", id, err)
+	} else if len(diffs) > 0 {
+		return fmt.Errorf("verify shard %d: copy on node %d differs from node %d in %d ranges", id, to, from, len(diffs))
+	}
+	return nil
+}
+
+// deleteShard removes a shard from the local store. Shards that this node
+// still owns are refused so a stale or replayed request can't remove them.
+func (s *Service) deleteShard(id uint64) error {
+	if _, _, sgi := s.MetaStore.ShardOwner(id); sgi != nil {
+		for _, sh := range sgi.Shards {
+			if sh.ID == id && sh.OwnedBy(s.MetaStore.NodeID()) {
+				return ErrShardOwned
+			}
+		}
+	}
+	return s.TSDBStore.DeleteShard(id)
+}
+
+// node returns a node by id. Returns ErrNodeNotFound if it doesn't exist.
+func (s *Service) node(id uint64) (*meta.NodeInfo, error) {
+	ni, err := s.MetaStore.Node(id)
+	if err != nil {
+		return nil, err
+	} else if ni == nil {
+		return nil, meta.ErrNodeNotFound
+	}
+	return ni, nil
+}
+
+// client returns a client for the copier service on host.
+func (s *Service) client(host string) *Client {
+	c := NewClient(host)
+	c.TLSConfig = s.TLSConfig
+	return c
+}
+
+// restoreShard streams a shard from the copier service on host into the local store.
+func (s *Service) restoreShard(database, policy string, id uint64, host string) error {
+	r, err := s.client(host).ShardReader(id)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return s.TSDBStore.RestoreShard(database, policy, id, r)
+}
+
 // serve serves shard copy requests from the listener.
 func (s *Service) serve() {
 	defer s.wg.Done()
@@ -104,6 +274,15 @@ func (s *Service) handleConn(conn net.Conn) error {
 		return fmt.Errorf("read request: %s", err)
 	}
 
+	switch req.GetType() {
+	case restoreRequest:
+		err = s.restoreShard(req.GetDatabase(), req.GetPolicy(), req.GetShardID(), req.GetHost())
+		return s.writeResult(conn, err)
+	case deleteRequest:
+		err = s.deleteShard(req.GetShardID())
+		return s.writeResult(conn, err)
+	}
+
 	// Retrieve shard.
 	sh := s.TSDBStore.Shard(req.GetShardID())
 
@@ -123,13 +302,68 @@ func (s *Service) handleConn(conn net.Conn) error {
 	}
 
 	// Write shard to response.
-	if _, err := sh.WriteTo(conn); err != nil {
+	var w io.Writer = conn
+	if s.RateLimit > 0 {
+		w = newLimitedWriter(conn, s.RateLimit)
+	}
+	if _, err := sh.WriteTo(w); err != nil {
 		return fmt.Errorf("write shard: %s", err)
 	}
 
 	return nil
 }
 
+// writeResult writes a response containing the error, if any, to w.
+func (s *Service) writeResult(w io.Writer, err error) error {
+	resp := &internal.Response{}
+	if err != nil {
+		resp.Error = proto.String(err.Error())
+	}
+
+	if err := s.writeResponse(w, resp); err != nil {
+		return fmt.Errorf("write response: %s", err)
+	}
+	return nil
+}
+
+// limitedWriter is a writer that limits the rate that bytes are written.
+type limitedWriter struct {
+	w     io.Writer
+	limit int64 // bytes per second
+	n     int64 // bytes written
+	start time.Time
+}
+
+// newLimitedWriter returns a writer that writes to w at up to limit bytes per second.
+func newLimitedWriter(w io.Writer, limit int64) *limitedWriter {
+	return &limitedWriter{w: w, limit: limit, start: time.Now()}
+}
+
+// Write writes p in chunks, sleeping between chunks to stay under the limit.
+func (w *limitedWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if int64(len(chunk)) > w.limit {
+			chunk = chunk[:w.limit]
+		}
+
+		nn, err := w.w.Write(chunk)
+		n += nn
+		w.n += int64(nn)
+		if err != nil {
+			return n, err
+		}
+		p = p[nn:]
+
+		// Wait until the average rate is back under the limit.
+		want := time.Duration(float64(w.n) / float64(w.limit) * float64(time.Second))
+		if d := want - time.Since(w.start); d > 0 {
+			time.Sleep(d)
+		}
+	}
+	return n, nil
+}
+
 // readRequest reads and unmarshals a Request from r.
 func (s *Service) readRequest(r io.Reader) (*internal.Request, error) {
 	// Read request length.
@@ -177,6 +411,9 @@ func (s *Service) writeResponse(w io.Writer, resp *internal.Response) error {
 // Client represents a client for connecting remotely to a copier service.
 type Client struct {
 	host string
+
+	// If set, connections to the remote node use TLS.
+	TLSConfig *tls.Config
 }
 
 // NewClient return a new instance of Client.
@@ -190,7 +427,7 @@ func NewClient(host string) *Client {
 // Returned ReadCloser must be closed by the caller.
 func (c *Client) ShardReader(id uint64) (io.ReadCloser, error) {
 	// Connect to remote server.
-	conn, err := tcp.Dial("tcp", c.host, MuxHeader)
+	conn, err := tcp.DialTLS("tcp", c.host, MuxHeader, c.TLSConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +453,49 @@ func (c *Client) ShardReader(id uint64) (io.ReadCloser, error) {
 	return conn, nil
 }
 
+// RestoreShard requests that the remote node copy a shard from host into its store.
+func (c *Client) RestoreShard(database, policy string, id uint64, host string) error {
+	return c.exec(&internal.Request{
+		Type:     proto.Uint32(restoreRequest),
+		ShardID:  proto.Uint64(id),
+		Database: proto.String(database),
+		Policy:   proto.String(policy),
+		Host:     proto.String(host),
+	})
+}
+
+// DeleteShard requests that the remote node remove a shard from its store.
+func (c *Client) DeleteShard(id uint64) error {
+	return c.exec(&internal.Request{
+		Type:    proto.Uint32(deleteRequest),
+		ShardID: proto.Uint64(id),
+	})
+}
+
+// exec sends a request to the remote node and waits for its response.
+func (c *Client) exec(req *internal.Request) error {
+	// Connect to remote server.
+	conn, err := tcp.DialTLS("tcp", c.host, MuxHeader, c.TLSConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Send request to server.
+	if err := c.writeRequest(conn, req); err != nil {
+		return fmt.Errorf("write request: %s", err)
+	}
+
+	// Read response from the server.
+	resp, err := c.readResponse(conn)
+	if err != nil {
+		return fmt.Errorf("read response: %s", err)
+	} else if resp.GetError() != "" {
+		return errors.New(resp.GetError())
+	}
+	return nil
+}
+
 // writeRequest marshals and writes req to w.
 func (c *Client) writeRequest(w io.Writer, req *internal.Request) error {
 	// Marshal request.
@@ -10,7 +10,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/influxdb/influxdb/meta"
+	"github.com/influxdb/influxdb/models"
 	"github.com/influxdb/influxdb/services/copier"
 	"github.com/influxdb/influxdb/tcp"
 	"github.com/influxdb/influxdb/tsdb"
@@ -86,12 +89,224 @@ func TestService_handleConn_Error(t *testing.T) {
 	}
 }
 
+// Ensure the service limits the rate that shards are streamed.
+func TestService_handleConn_RateLimit(t *testing.T) {
+	s := MustOpenService()
+	defer s.Close()
+
+	sh := MustOpenShard(123)
+	defer sh.Close()
+	s.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return sh.Shard }
+
+	// Determine the size of the stream without a limit.
+	c := copier.NewClient(s.Addr().String())
+	r, err := c.ShardReader(123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(ioutil.Discard, r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stream the shard at half its size per second.
+	s.RateLimit = n / 2
+	start := time.Now()
+	if r, err = c.ShardReader(123); err != nil {
+		t.Fatal(err)
+	} else if err := ReadShard(r); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	if d := time.Since(start); d < 900*time.Millisecond {
+		t.Fatalf("shard streamed too quickly: %s", d)
+	}
+}
+
+// Ensure a shard can be copied to a remote node.
+func TestService_CopyShard(t *testing.T) {
+	src, dst := MustOpenService(), MustOpenService()
+	defer src.Close()
+	defer dst.Close()
+
+	// Serve a shard with data from the source node.
+	sh := MustOpenShard(1)
+	defer sh.Close()
+	if err := sh.WritePoints([]models.Point{
+		models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(10, 0)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	src.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return sh.Shard }
+
+	// Restore the shard on the destination node.
+	var restored bool
+	dst.TSDBStore.RestoreShardFn = func(database, policy string, id uint64, r io.Reader) error {
+		if database != "db0" || policy != "rp0" || id != 1 {
+			t.Fatalf("unexpected restore: %s.%s.%d", database, policy, id)
+		}
+		restored = true
+		return ReadShard(r)
+	}
+
+	// Copy the shard from the source node to the destination node.
+	var added bool
+	src.MetaStore.NodeIDFn = func() uint64 { return 1 }
+	src.MetaStore.NodeFn = NodeHosts(src, dst)
+	src.MetaStore.AddShardOwnerFn = func(id, nodeID uint64) error {
+		if id != 1 || nodeID != 2 {
+			t.Fatalf("unexpected owner: shard=%d, node=%d", id, nodeID)
+		}
+		added = true
+		return nil
+	}
+	if err := src.CopyShard(1, 1, 2); err != nil {
+		t.Fatal(err)
+	} else if !restored {
+		t.Fatal("expected shard to be restored")
+	} else if !added {
+		t.Fatal("expected owner to be added")
+	}
+
+	// Ensure the shard can't be copied to a node that already owns it.
+	if err := src.CopyShard(1, 1, 1); err != meta.ErrShardOwnerExists {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := src.CopyShard(1, 2, 1); err != meta.ErrShardOwnerNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a shard can be moved from a remote node to the local node.
+func TestService_MoveShard(t *testing.T) {
+	src, dst := MustOpenService(), MustOpenService()
+	defer src.Close()
+	defer dst.Close()
+
+	// Serve an empty shard from the source node and track its deletion.
+	sh := MustOpenShard(1)
+	defer sh.Close()
+	var deleted, added, removed bool
+	src.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return sh.Shard }
+	src.TSDBStore.DeleteShardFn = func(id uint64) error {
+		if id != 1 {
+			t.Fatalf("unexpected id: %d", id)
+		}
+		deleted = true
+		return nil
+	}
+
+	// The source node sees itself removed as an owner.
+	src.MetaStore.NodeIDFn = func() uint64 { return 1 }
+	src.MetaStore.ShardOwnerFn = func(id uint64) (string, string, *meta.ShardGroupInfo) {
+		owners := []meta.ShardOwner{{NodeID: 2}}
+		if !removed {
+			owners = append(owners, meta.ShardOwner{NodeID: 1})
+		}
+		return "db0", "rp0", &meta.ShardGroupInfo{ID: 1, Shards: []meta.ShardInfo{{ID: 1, Owners: owners}}}
+	}
+
+	// Move the shard to the destination node and track owner changes.
+	dst.TSDBStore.RestoreShardFn = func(database, policy string, id uint64, r io.Reader) error { return ReadShard(r) }
+	dst.MetaStore.NodeIDFn = func() uint64 { return 2 }
+	dst.MetaStore.NodeFn = NodeHosts(src, dst)
+	dst.MetaStore.AddShardOwnerFn = func(id, nodeID uint64) error { added = nodeID == 2; return nil }
+	dst.MetaStore.RemoveShardOwnerFn = func(id, nodeID uint64) error { removed = nodeID == 1; return nil }
+	if err := dst.MoveShard(1, 1, 2); err != nil {
+		t.Fatal(err)
+	} else if !added || !removed {
+		t.Fatalf("unexpected owner changes: added=%v, removed=%v", added, removed)
+	} else if !deleted {
+		t.Fatal("expected source shard to be deleted")
+	}
+
+	// Ensure an unknown shard returns an error.
+	if err := dst.MoveShard(100, 1, 2); err != meta.ErrShardNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a shard is not deleted while the node still owns it.
+func TestService_DeleteShard_Owned(t *testing.T) {
+	s := MustOpenService()
+	defer s.Close()
+
+	s.MetaStore.NodeIDFn = func() uint64 { return 1 }
+	s.TSDBStore.DeleteShardFn = func(id uint64) error {
+		t.Fatal("unexpected delete")
+		return nil
+	}
+
+	c := copier.NewClient(s.Addr().String())
+	if err := c.DeleteShard(1); err == nil || err.Error() != copier.ErrShardOwned.Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a shard whose shard group hasn't ended is not copied.
+func TestService_CopyShard_Hot(t *testing.T) {
+	s := MustOpenService()
+	defer s.Close()
+
+	s.MetaStore.ShardOwnerFn = func(id uint64) (string, string, *meta.ShardGroupInfo) {
+		return "db0", "rp0", &meta.ShardGroupInfo{
+			ID:      1,
+			EndTime: time.Now().Add(time.Hour),
+			Shards:  []meta.ShardInfo{{ID: 1, Owners: []meta.ShardOwner{{NodeID: 1}}}},
+		}
+	}
+	if err := s.CopyShard(1, 1, 2); err != copier.ErrShardHot {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.MoveShard(1, 1, 2); err != copier.ErrShardHot {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the source copy of a moved shard is kept if the copies differ.
+func TestService_MoveShard_Differences(t *testing.T) {
+	src, dst := MustOpenService(), MustOpenService()
+	defer src.Close()
+	defer dst.Close()
+
+	sh := MustOpenShard(1)
+	defer sh.Close()
+	src.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return sh.Shard }
+	src.TSDBStore.DeleteShardFn = func(id uint64) error {
+		t.Fatal("unexpected delete")
+		return nil
+	}
+
+	dst.TSDBStore.RestoreShardFn = func(database, policy string, id uint64, r io.Reader) error { return ReadShard(r) }
+	dst.MetaStore.NodeIDFn = func() uint64 { return 2 }
+	dst.MetaStore.NodeFn = NodeHosts(src, dst)
+	dst.MetaStore.AddShardOwnerFn = func(id, nodeID uint64) error {
+		t.Fatal("unexpected owner added")
+		return nil
+	}
+	dst.MetaStore.RemoveShardOwnerFn = func(id, nodeID uint64) error {
+		t.Fatal("unexpected owner removed")
+		return nil
+	}
+	dst.ShardComparer.CompareShardFn = func(shardID, nodeA, nodeB uint64) ([]meta.ShardDifference, error) {
+		if shardID != 1 || nodeA != 1 || nodeB != 2 {
+			t.Fatalf("unexpected comparison: shard=%d, nodes=%d,%d", shardID, nodeA, nodeB)
+		}
+		return []meta.ShardDifference{{ShardID: 1, OwnerID: 2, Key: "cpu", LocalN: 1}}, nil
+	}
+	if err := dst.MoveShard(1, 1, 2); err == nil || err.Error() != "verify shard 1: copy on node 2 differs from node 1 in 1 ranges" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Service represents a test wrapper for copier.Service.
 type Service struct {
 	*copier.Service
 
-	ln        net.Listener
-	TSDBStore ServiceTSDBStore
+	ln            net.Listener
+	MetaStore     ServiceMetaStore
+	TSDBStore     ServiceTSDBStore
+	ShardComparer ServiceShardComparer
 }
 
 // NewService returns a new instance of Service.
@@ -99,7 +314,25 @@ func NewService() *Service {
 	s := &Service{
 		Service: copier.NewService(),
 	}
+	s.Service.MetaStore = &s.MetaStore
 	s.Service.TSDBStore = &s.TSDBStore
+	s.Service.ShardComparer = &s.ShardComparer
+
+	// Copies match by default.
+	s.ShardComparer.CompareShardFn = func(shardID, nodeA, nodeB uint64) ([]meta.ShardDifference, error) {
+		return nil, nil
+	}
+
+	// Node 1 owns shard 1.
+	s.MetaStore.ShardOwnerFn = func(id uint64) (string, string, *meta.ShardGroupInfo) {
+		if id != 1 {
+			return "", "", nil
+		}
+		return "db0", "rp0", &meta.ShardGroupInfo{
+			ID:     1,
+			Shards: []meta.ShardInfo{{ID: 1, Owners: []meta.ShardOwner{{NodeID: 1}}}},
+		}
+	}
 
 	if !testing.Verbose() {
 		s.SetLogger(log.New(ioutil.Discard, "", 0))
@@ -141,12 +374,71 @@ func (s *Service) Close() error {
 // Addr returns the address of the service.
 func (s *Service) Addr() net.Addr { return s.ln.Addr() }
 
+// NodeHosts returns a function that returns the address of node 1 & 2.
+func NodeHosts(n1, n2 *Service) func(id uint64) (*meta.NodeInfo, error) {
+	return func(id uint64) (*meta.NodeInfo, error) {
+		switch id {
+		case 1:
+			return &meta.NodeInfo{ID: 1, Host: n1.Addr().String()}, nil
+		case 2:
+			return &meta.NodeInfo{ID: 2, Host: n2.Addr().String()}, nil
+		}
+		return nil, nil
+	}
+}
+
+// ReadShard reads the size and contents of a shard written by Shard.WriteTo.
+func ReadShard(r io.Reader) error {
+	var n uint64
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	_, err := io.CopyN(ioutil.Discard, r, int64(n))
+	return err
+}
+
+// ServiceMetaStore is a mock that implements copier.Service.MetaStore.
+type ServiceMetaStore struct {
+	NodeIDFn           func() uint64
+	NodeFn             func(id uint64) (*meta.NodeInfo, error)
+	ShardOwnerFn       func(shardID uint64) (string, string, *meta.ShardGroupInfo)
+	AddShardOwnerFn    func(id, nodeID uint64) error
+	RemoveShardOwnerFn func(id, nodeID uint64) error
+}
+
+func (ms *ServiceMetaStore) NodeID() uint64                         { return ms.NodeIDFn() }
+func (ms *ServiceMetaStore) Node(id uint64) (*meta.NodeInfo, error) { return ms.NodeFn(id) }
+func (ms *ServiceMetaStore) ShardOwner(shardID uint64) (string, string, *meta.ShardGroupInfo) {
+	return ms.ShardOwnerFn(shardID)
+}
+func (ms *ServiceMetaStore) AddShardOwner(id, nodeID uint64) error {
+	return ms.AddShardOwnerFn(id, nodeID)
+}
+func (ms *ServiceMetaStore) RemoveShardOwner(id, nodeID uint64) error {
+	return ms.RemoveShardOwnerFn(id, nodeID)
+}
+
 // ServiceTSDBStore is a mock that implements copier.Service.TSDBStore.
 type ServiceTSDBStore struct {
-	ShardFn func(id uint64) *tsdb.Shard
+	ShardFn        func(id uint64) *tsdb.Shard
+	RestoreShardFn func(database, policy string, shardID uint64, r io.Reader) error
+	DeleteShardFn  func(shardID uint64) error
 }
 
 func (ss *ServiceTSDBStore) Shard(id uint64) *tsdb.Shard { return ss.ShardFn(id) }
+func (ss *ServiceTSDBStore) RestoreShard(database, policy string, shardID uint64, r io.Reader) error {
+	return ss.RestoreShardFn(database, policy, shardID, r)
+}
+func (ss *ServiceTSDBStore) DeleteShard(shardID uint64) error { return ss.DeleteShardFn(shardID) }
+
+// ServiceShardComparer is a mock that implements copier.Service.ShardComparer.
+type ServiceShardComparer struct {
+	CompareShardFn func(shardID, nodeA, nodeB uint64) ([]meta.ShardDifference, error)
+}
+
+func (sc *ServiceShardComparer) CompareShard(shardID, nodeA, nodeB uint64) ([]meta.ShardDifference, error) {
+	return sc.CompareShardFn(shardID, nodeA, nodeB)
+}
 
 // Shard is a test wrapper for tsdb.Shard.
 type Shard struct {
@@ -27,9 +27,15 @@ type NodeProcessor struct {
 	nodeID           uint64
 	dir              string
 
-	mu   sync.RWMutex
-	wg   sync.WaitGroup
-	done chan struct{}
+	mu     sync.RWMutex
+	wg     sync.WaitGroup
+	done   chan struct{}
+	replay chan struct{}
+
+	// Outcome of the most recent attempt to send the queue.
+	statusMu     sync.Mutex
+	lastErr      error
+	currInterval time.Duration
 
 	queue  *queue
 	meta   metaStore
@@ -53,6 +59,7 @@ func NewNodeProcessor(nodeID uint64, dir string, w shardWriter, m metaStore) *No
 		MaxAge:           DefaultMaxAge,
 		nodeID:           nodeID,
 		dir:              dir,
+		replay:           make(chan struct{}, 1),
 		writer:           w,
 		meta:             m,
 		statMap:          influxdb.NewStatistics(key, "hh_processor", tags),
@@ -160,6 +167,7 @@ func (n *NodeProcessor) run() {
 	if currInterval > time.Duration(n.RetryMaxInterval) {
 		currInterval = time.Duration(n.RetryMaxInterval)
 	}
+	n.setStatus(nil, currInterval)
 
 	for {
 		select {
@@ -171,36 +179,92 @@ func (n *NodeProcessor) run() {
 				n.Logger.Printf("failed to purge for node %d: %s", n.nodeID, err.Error())
 			}
 
+		case <-n.replay:
+			// Retry now and start the backoff over.
+			currInterval = n.sendWrites(time.Duration(n.RetryInterval))
+
 		case <-time.After(currInterval):
-			limiter := NewRateLimiter(n.RetryRateLimit)
-			for {
-				c, err := n.SendWrite()
-				if err != nil {
-					if err == io.EOF {
-						// No more data, return to configured interval
-						currInterval = time.Duration(n.RetryInterval)
-					} else {
-						currInterval = currInterval * 2
-						if currInterval > time.Duration(n.RetryMaxInterval) {
-							currInterval = time.Duration(n.RetryMaxInterval)
-						}
-					}
-					break
-				}
+			currInterval = n.sendWrites(currInterval)
+		}
+	}
+}
 
-				// Success! Ensure backoff is cancelled.
+// sendWrites sends queued writes to the node until the queue is empty or a
+// write fails. It returns the interval to wait before the next attempt.
+func (n *NodeProcessor) sendWrites(currInterval time.Duration) time.Duration {
+	limiter := NewRateLimiter(n.RetryRateLimit)
+	for {
+		c, err := n.SendWrite()
+		if err != nil {
+			if err == io.EOF {
+				// No more data, return to configured interval
 				currInterval = time.Duration(n.RetryInterval)
-
-				// Update how many bytes we've sent
-				limiter.Update(c)
-
-				// Block to maintain the throughput rate
-				time.Sleep(limiter.Delay())
+				n.setStatus(nil, currInterval)
+			} else {
+				currInterval = currInterval * 2
+				if currInterval > time.Duration(n.RetryMaxInterval) {
+					currInterval = time.Duration(n.RetryMaxInterval)
+				}
+				n.setStatus(err, currInterval)
 			}
+			return currInterval
 		}
+
+		// Success! Ensure backoff is cancelled.
+		currInterval = time.Duration(n.RetryInterval)
+
+		// Update how many bytes we've sent
+		limiter.Update(c)
+
+		// Block to maintain the throughput rate
+		time.Sleep(limiter.Delay())
 	}
 }
 
+// Replay sends the queued data to the node now instead of waiting for the
+// next retry.
+func (n *NodeProcessor) Replay() {
+	select {
+	case n.replay <- struct{}{}:
+	default:
+		// A replay is already pending.
+	}
+}
+
+// setStatus records the outcome of sending the queue.
+func (n *NodeProcessor) setStatus(err error, interval time.Duration) {
+	n.statusMu.Lock()
+	defer n.statusMu.Unlock()
+	n.lastErr, n.currInterval = err, interval
+}
+
+// LastError returns the error from the last attempt to send the queue, or nil
+// if the queue was sent successfully.
+func (n *NodeProcessor) LastError() error {
+	n.statusMu.Lock()
+	defer n.statusMu.Unlock()
+	return n.lastErr
+}
+
+// CurrentRetryInterval returns the time to wait between attempts to send the
+// queue, including any backoff after failures.
+func (n *NodeProcessor) CurrentRetryInterval() time.Duration {
+	n.statusMu.Lock()
+	defer n.statusMu.Unlock()
+	return n.currInterval
+}
+
+// Size returns the size on disk of the processor's queue.
+func (n *NodeProcessor) Size() int64 {
+	return n.queue.Size()
+}
+
+// Oldest returns the time the oldest segment of the processor's queue was
+// first written to. Returns the zero time if the queue is empty.
+func (n *NodeProcessor) Oldest() (time.Time, error) {
+	return n.queue.Oldest()
+}
+
 // SendWrite attempts to sent the current block of hinted data to the target node. If successful,
 // it returns the number of bytes it sent and advances to the next block. Otherwise returns EOF
 // when there is no more data or the node is inactive.
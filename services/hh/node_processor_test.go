@@ -1,6 +1,7 @@
 package hh
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
 	"os"
@@ -153,3 +154,71 @@ func TestNodeProcessorSendBlock(t *testing.T) {
 		t.Fatalf("Node processor directory still present after purge")
 	}
 }
+
+func TestNodeProcessorReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node_processor_test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Each write waits for the test to return its result.
+	results := make(chan error)
+	sh := &fakeShardWriter{
+		ShardWriteFn: func(shardID, nodeID uint64, points []models.Point) error { return <-results },
+	}
+	metastore := &fakeMetaStore{
+		NodeFn: func(nodeID uint64) (*meta.NodeInfo, error) { return &meta.NodeInfo{}, nil },
+	}
+
+	// Retries never happen on their own during the test.
+	n := NewNodeProcessor(1, dir, sh, metastore)
+	n.RetryInterval = time.Hour
+	n.RetryMaxInterval = 4 * time.Hour
+	if err := n.Open(); err != nil {
+		t.Fatalf("Failed to open node processor: %v", err)
+	}
+	defer n.Close()
+
+	pt := models.MustNewPoint("cpu", models.Tags{"foo": "bar"}, models.Fields{"value": 1.0}, time.Unix(0, 0))
+	if err := n.WriteShard(100, []models.Point{pt}); err != nil {
+		t.Fatalf("failed to queue write: %v", err)
+	}
+	if n.Size() == 0 {
+		t.Fatal("expected queued data")
+	} else if oldest, err := n.Oldest(); err != nil || oldest.IsZero() {
+		t.Fatalf("unexpected oldest: %v, %v", oldest, err)
+	}
+
+	// Replay and fail the write. The error is recorded and the retry backs off.
+	n.Replay()
+	results <- errors.New("marker")
+	waitFor(t, func() bool { return n.LastError() != nil })
+	if err := n.LastError(); err.Error() != "marker" {
+		t.Fatalf("unexpected last error: %v", err)
+	} else if d := n.CurrentRetryInterval(); d != 2*time.Hour {
+		t.Fatalf("unexpected retry interval: %s", d)
+	}
+
+	// Replay and succeed. The backoff is reset and the queue is empty.
+	n.Replay()
+	results <- nil
+	waitFor(t, func() bool { return n.LastError() == nil })
+	if d := n.CurrentRetryInterval(); d != time.Hour {
+		t.Fatalf("unexpected retry interval: %s", d)
+	} else if oldest, err := n.Oldest(); err != nil || !oldest.IsZero() {
+		t.Fatalf("unexpected oldest: %v, %v", oldest, err)
+	}
+}
+
+// waitFor waits up to a second for fn to return true.
+func waitFor(t *testing.T, fn func() bool) {
+	timeout := time.After(time.Second)
+	for !fn() {
+		select {
+		case <-timeout:
+			t.Fatal("timed out")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -21,7 +21,12 @@ var (
 
 const (
 	defaultSegmentSize = 10 * 1024 * 1024
+	headerSize         = 16
 	footerSize         = 8
+
+	// segmentMagic marks segments that begin with a header. It can't be
+	// confused with the length of a block in segments written without one.
+	segmentMagic = 0x6868736567000001
 )
 
 // queue is a bounded, disk-backed, append-only type that combines queue and
@@ -233,6 +238,26 @@ func (l *queue) Position() (*queuePos, error) {
 	return qp, nil
 }
 
+// Size returns the total size on disk used by the queue.
+func (l *queue) Size() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.diskUsage()
+}
+
+// Oldest returns the time the first entry of the head segment was written.
+// Every entry still in the queue was written at or after this time. Returns
+// the zero time if the queue is empty.
+func (l *queue) Oldest() (time.Time, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.head == nil || l.head.empty() {
+		return time.Time{}, nil
+	}
+	return l.head.firstWritten()
+}
+
 // diskUsage returns the total size on disk used by the queue
 func (l *queue) diskUsage() int64 {
 	var size int64
@@ -385,17 +410,20 @@ func (l *queue) trimHead() error {
 	return nil
 }
 
-// Segment is a queue using a single file.  The structure of a segment is a series
-// lengths + block with a single footer point to the position in the segment of the
-// current head block.
+// Segment is a queue using a single file.  The structure of a segment is a header
+// followed by a series lengths + block with a single footer point to the position in
+// the segment of the current head block.
+//
+// ┌──────────────────────────┐ ┌──────────────────────────┐ ┌──────────────────────────┐ ┌────────────┐
+// │          Header          │ │         Block 1          │ │         Block 2          │ │   Footer   │
+// └──────────────────────────┘ └──────────────────────────┘ └──────────────────────────┘ └────────────┘
+// ┌────────────┐┌────────────┐ ┌────────────┐┌────────────┐ ┌────────────┐┌────────────┐ ┌────────────┐
+// │   Magic    ││First Write │ │Block 1 Len ││Block 1 Body│ │Block 2 Len ││Block 2 Body│ │Head Offset │
+// │  8 bytes   ││  8 bytes   │ │  8 bytes   ││  N bytes   │ │  8 bytes   ││  N bytes   │ │  8 bytes   │
+// └────────────┘└────────────┘ └────────────┘└────────────┘ └────────────┘└────────────┘ └────────────┘
 //
-// ┌──────────────────────────┐ ┌──────────────────────────┐ ┌────────────┐
-// │         Block 1          │ │         Block 2          │ │   Footer   │
-// └──────────────────────────┘ └──────────────────────────┘ └────────────┘
-// ┌────────────┐┌────────────┐ ┌────────────┐┌────────────┐ ┌────────────┐
-// │Block 1 Len ││Block 1 Body│ │Block 2 Len ││Block 2 Body│ │Head Offset │
-// │  8 bytes   ││  N bytes   │ │  8 bytes   ││  N bytes   │ │  8 bytes   │
-// └────────────┘└────────────┘ └────────────┘└────────────┘ └────────────┘
+// The header holds the time in nanoseconds the first block was written, or zero if the
+// segment is empty. Segments written by earlier versions have no header.
 //
 // The footer holds the pointer to the head entry at the end of the segment to allow writes
 // to seek to the end and write sequentially (vs having to seek back to the beginning of
@@ -414,6 +442,9 @@ type segment struct {
 	pos         int64
 	currentSize int64
 	maxSize     int64
+
+	header     bool      // true if the segment begins with a header
+	firstWrite time.Time // time the first block was written, zero if unknown
 }
 
 func newSegment(path string, maxSize int64) (*segment, error) {
@@ -440,10 +471,20 @@ func (l *segment) open() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	// If it's a new segment then write the location of the current record in this segment
+	// If it's a new segment then write the header and the location of the current
+	// record in this segment
 	if l.size == 0 {
-		l.pos = 0
+		l.pos = headerSize
 		l.currentSize = 0
+		l.header = true
+
+		if err := l.writeUint64(segmentMagic); err != nil {
+			return err
+		}
+
+		if err := l.writeUint64(0); err != nil {
+			return err
+		}
 
 		if err := l.writeUint64(uint64(l.pos)); err != nil {
 			return err
@@ -453,11 +494,35 @@ func (l *segment) open() error {
 			return err
 		}
 
-		l.size = footerSize
+		l.size = headerSize + footerSize
 
 		return nil
 	}
 
+	// Read the time of the first write if the segment has a header
+	if l.size >= headerSize+footerSize {
+		if err := l.seek(0); err != nil {
+			return err
+		}
+
+		magic, err := l.readUint64()
+		if err != nil {
+			return err
+		}
+
+		if magic == segmentMagic {
+			l.header = true
+
+			ts, err := l.readUint64()
+			if err != nil {
+				return err
+			}
+			if ts != 0 {
+				l.firstWrite = time.Unix(0, int64(ts)).UTC()
+			}
+		}
+	}
+
 	// Existing segment so read the current position and the size of the current block
 	if err := l.seekEnd(-footerSize); err != nil {
 		return err
@@ -499,6 +564,20 @@ func (l *segment) append(b []byte) error {
 		return ErrSegmentFull
 	}
 
+	// Record the time of the first write in the header
+	if l.header && l.firstWrite.IsZero() {
+		now := time.Now().UTC()
+
+		if err := l.seek(8); err != nil {
+			return err
+		}
+
+		if err := l.writeUint64(uint64(now.UnixNano())); err != nil {
+			return err
+		}
+		l.firstWrite = now
+	}
+
 	if err := l.seekEnd(-footerSize); err != nil {
 		return err
 	}
@@ -628,6 +707,26 @@ func (l *segment) lastModified() (time.Time, error) {
 	return stats.ModTime().UTC(), nil
 }
 
+// firstWritten returns the time the first block was written to the segment.
+// Segments without a header return the last time they were modified instead.
+func (l *segment) firstWritten() (time.Time, error) {
+	l.mu.RLock()
+	firstWrite := l.firstWrite
+	l.mu.RUnlock()
+
+	if firstWrite.IsZero() {
+		return l.lastModified()
+	}
+	return firstWrite, nil
+}
+
+// empty returns true if every entry in the segment has been read.
+func (l *segment) empty() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.pos == l.size-footerSize
+}
+
 func (l *segment) diskUsage() int64 {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -60,8 +60,8 @@ func TestQueueAppendOne(t *testing.T) {
 		t.Fatalf("Queue.Append file not exists. exp %v to exist", exp)
 	}
 
-	// 8 byte header ptr + 8 byte record len + record len
-	if exp := int64(8 + 8 + 4); stats.Size() != exp {
+	// 16 byte header + 8 byte record len + record len + 8 byte head ptr
+	if exp := int64(16 + 8 + 4 + 8); stats.Size() != exp {
 		t.Fatalf("Queue.Append file size mismatch. got %v, exp %v", stats.Size(), exp)
 	}
 
@@ -138,7 +138,7 @@ func TestQueueAdvancePastEnd(t *testing.T) {
 	}
 
 	// set the segment size low to force a new segment to be created
-	q.SetMaxSegmentSize(12)
+	q.SetMaxSegmentSize(32)
 
 	// Should go into a new segment
 	if err := q.Append([]byte("two")); err != nil {
@@ -279,6 +279,109 @@ func TestQueueReopen(t *testing.T) {
 	}
 }
 
+func TestQueueOldest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hh_queue")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// create the queue
+	q, err := newQueue(dir, 1024)
+	if err != nil {
+		t.Fatalf("failed to create queue: %v", err)
+	}
+
+	if err := q.Open(); err != nil {
+		t.Fatalf("failed to open queue: %v", err)
+	}
+
+	if oldest, err := q.Oldest(); err != nil || !oldest.IsZero() {
+		t.Fatalf("Queue.Oldest mismatch: got %v, %v, exp zero time", oldest, err)
+	}
+
+	before := time.Now()
+	if err := q.Append([]byte("one")); err != nil {
+		t.Fatalf("Queue.Append failed: %v", err)
+	}
+
+	oldest, err := q.Oldest()
+	if err != nil {
+		t.Fatalf("Queue.Oldest failed: %v", err)
+	} else if oldest.Before(before) || oldest.After(time.Now()) {
+		t.Fatalf("Queue.Oldest out of range: got %v", oldest)
+	}
+
+	// later writes don't change the time of the first write
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Append([]byte("two")); err != nil {
+		t.Fatalf("Queue.Append failed: %v", err)
+	}
+
+	if got, err := q.Oldest(); err != nil || !got.Equal(oldest) {
+		t.Fatalf("Queue.Oldest mismatch: got %v, %v, exp %v", got, err, oldest)
+	}
+
+	// the time of the first write is kept across re-opens
+	if err := q.Close(); err != nil {
+		t.Fatalf("Queue.Close failed: %v", err)
+	}
+
+	if err := q.Open(); err != nil {
+		t.Fatalf("failed to re-open queue: %v", err)
+	}
+
+	if got, err := q.Oldest(); err != nil || !got.Equal(oldest) {
+		t.Fatalf("Queue.Oldest mismatch: got %v, %v, exp %v", got, err, oldest)
+	}
+}
+
+func TestQueueOpenWithoutHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hh_queue")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// write a segment without a header: record len + record + head ptr
+	b := append(u64tob(3), []byte("one")...)
+	b = append(b, u64tob(0)...)
+	if err := ioutil.WriteFile(filepath.Join(dir, "1"), b, 0600); err != nil {
+		t.Fatalf("failed to write segment: %v", err)
+	}
+
+	q, err := newQueue(dir, 1024)
+	if err != nil {
+		t.Fatalf("failed to create queue: %v", err)
+	}
+
+	if err := q.Open(); err != nil {
+		t.Fatalf("failed to open queue: %v", err)
+	}
+
+	cur, err := q.Current()
+	if err != nil {
+		t.Fatalf("Queue.Current failed: %v", err)
+	}
+
+	if exp := "one"; string(cur) != exp {
+		t.Errorf("Queue.Current mismatch: got %v, exp %v", string(cur), exp)
+	}
+
+	// the modification time is used without a header
+	if oldest, err := q.Oldest(); err != nil || oldest.IsZero() {
+		t.Fatalf("Queue.Oldest mismatch: got %v, %v", oldest, err)
+	}
+
+	if err := q.Advance(); err != nil {
+		t.Fatalf("Queue.Advance failed: %v", err)
+	}
+
+	if oldest, err := q.Oldest(); err != nil || !oldest.IsZero() {
+		t.Fatalf("Queue.Oldest mismatch: got %v, %v, exp zero time", oldest, err)
+	}
+}
+
 func TestPurgeQueue(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping purge queue")
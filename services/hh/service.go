@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,6 +23,10 @@ import (
 // disabled hinted handoff service.
 var ErrHintedHandoffDisabled = fmt.Errorf("hinted handoff disabled")
 
+// ErrQueueNotFound is returned when managing the queue of a node that has no
+// hinted handoff data on this node.
+var ErrQueueNotFound = fmt.Errorf("hinted handoff queue not found")
+
 const (
 	writeShardReq       = "writeShardReq"
 	writeShardReqPoints = "writeShardReqPoints"
@@ -217,6 +222,81 @@ func (s *Service) Diagnostics() (*monitor.Diagnostic, error) {
 	return d, nil
 }
 
+// Queues returns the status of the hinted handoff queue for each node,
+// sorted by node ID.
+func (s *Service) Queues() ([]meta.HintedHandoffQueue, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrHintedHandoffDisabled
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a := make([]meta.HintedHandoffQueue, 0, len(s.processors))
+	for id, p := range s.processors {
+		oldest, err := p.Oldest()
+		if err != nil {
+			return nil, err
+		}
+
+		q := meta.HintedHandoffQueue{
+			NodeID:        id,
+			Size:          p.Size(),
+			Oldest:        oldest,
+			RetryInterval: p.CurrentRetryInterval(),
+		}
+		if err := p.LastError(); err != nil {
+			q.LastError = err.Error()
+		}
+		a = append(a, q)
+	}
+	sort.Sort(queuesByNodeID(a))
+	return a, nil
+}
+
+// PurgeQueue deletes the queued data for a node.
+func (s *Service) PurgeQueue(nodeID uint64) error {
+	if !s.cfg.Enabled {
+		return ErrHintedHandoffDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.processors[nodeID]
+	if !ok {
+		return ErrQueueNotFound
+	}
+	if err := p.Close(); err != nil {
+		return err
+	}
+	if err := p.Purge(); err != nil {
+		return err
+	}
+	delete(s.processors, nodeID)
+
+	s.Logger.Printf("purged hinted handoff queue for node %d", nodeID)
+	return nil
+}
+
+// ReplayQueue sends the queued data for a node now instead of waiting for
+// the next retry.
+func (s *Service) ReplayQueue(nodeID uint64) error {
+	if !s.cfg.Enabled {
+		return ErrHintedHandoffDisabled
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.processors[nodeID]
+	if !ok {
+		return ErrQueueNotFound
+	}
+	p.Replay()
+	return nil
+}
+
 // purgeInactiveProcessors will cause the service to remove processors for inactive nodes.
 func (s *Service) purgeInactiveProcessors() {
 	defer s.wg.Done()
@@ -273,3 +353,9 @@ func (s *Service) purgeInactiveProcessors() {
 func (s *Service) pathforNode(nodeID uint64) string {
 	return filepath.Join(s.cfg.Dir, fmt.Sprintf("%d", nodeID))
 }
+
+type queuesByNodeID []meta.HintedHandoffQueue
+
+func (a queuesByNodeID) Len() int           { return len(a) }
+func (a queuesByNodeID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a queuesByNodeID) Less(i, j int) bool { return a[i].NodeID < a[j].NodeID }
@@ -1,5 +1,27 @@
 package httpd
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
+	"github.com/influxdb/influxdb/toml"
+)
+
+const (
+	// DefaultMaxBodySize is the default maximum size of a write request body.
+	// Lines are buffered until a newline is read so this also bounds the
+	// memory held by a single line.
+	DefaultMaxBodySize = 25000000
+
+	// DefaultMaxQueuedQueries is the default number of queries that can wait
+	// for a slot when the concurrent query limit is reached.
+	DefaultMaxQueuedQueries = 100
+
+	// DefaultQueryQueueTimeout is the default time a query waits for a slot.
+	DefaultQueryQueueTimeout = 30 * time.Second
+)
+
 // Config represents a configuration for a HTTP service.
 type Config struct {
 	Enabled          bool   `toml:"enabled"`
@@ -10,15 +32,46 @@ type Config struct {
 	PprofEnabled     bool   `toml:"pprof-enabled"`
 	HTTPSEnabled     bool   `toml:"https-enabled"`
 	HTTPSCertificate string `toml:"https-certificate"`
+	HTTPSClientCA    string `toml:"https-client-ca"`
+	HTTPSClientAuth  string `toml:"https-client-auth"`
+	MaxBodySize      int64  `toml:"max-body-size"`
+	WriteBatchSize   int    `toml:"write-batch-size"`
+	SharedSecret     string `toml:"shared-secret"`
+
+	// Write rate limits are tracked per database or per user, see RateLimitBy.
+	RateLimitBy               string `toml:"rate-limit-by"`
+	MaxWriteRequestsPerSecond int    `toml:"max-write-requests-per-second"`
+	MaxWritePointsPerSecond   int    `toml:"max-write-points-per-second"`
+
+	MaxConcurrentQueriesPerUser int           `toml:"max-concurrent-queries-per-user"`
+	MaxConcurrentQueries        int           `toml:"max-concurrent-queries"`
+	MaxQueuedQueries            int           `toml:"max-queued-queries"`
+	QueryQueueTimeout           toml.Duration `toml:"query-queue-timeout"`
 }
 
 // NewConfig returns a new Config with default settings.
 func NewConfig() Config {
 	return Config{
-		Enabled:          true,
-		BindAddress:      ":8086",
-		LogEnabled:       true,
-		HTTPSEnabled:     false,
-		HTTPSCertificate: "/etc/ssl/influxdb.pem",
+		Enabled:           true,
+		BindAddress:       ":8086",
+		LogEnabled:        true,
+		HTTPSEnabled:      false,
+		HTTPSCertificate:  "/etc/ssl/influxdb.pem",
+		HTTPSClientAuth:   tlsconfig.ClientAuthNone,
+		MaxBodySize:       DefaultMaxBodySize,
+		WriteBatchSize:    DefaultWriteBatchSize,
+		RateLimitBy:       RateLimitByDatabase,
+		MaxQueuedQueries:  DefaultMaxQueuedQueries,
+		QueryQueueTimeout: toml.Duration(DefaultQueryQueueTimeout),
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	switch c.RateLimitBy {
+	case RateLimitByDatabase, RateLimitByUser:
+	default:
+		return fmt.Errorf("invalid rate-limit-by: %q, must be %q or %q", c.RateLimitBy, RateLimitByDatabase, RateLimitByUser)
 	}
+	return nil
 }
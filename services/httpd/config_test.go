@@ -2,6 +2,7 @@ package httpd_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/influxdb/influxdb/services/httpd"
@@ -19,6 +20,18 @@ write-tracing = true
 pprof-enabled = true
 https-enabled = true
 https-certificate = "/dev/null"
+https-client-ca = "/etc/ssl/ca.pem"
+https-client-auth = "required"
+max-body-size = 1000
+write-batch-size = 100
+shared-secret = "secret"
+rate-limit-by = "user"
+max-write-requests-per-second = 10
+max-write-points-per-second = 1000
+max-concurrent-queries-per-user = 2
+max-concurrent-queries = 20
+max-queued-queries = 5
+query-queue-timeout = "10s"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -40,6 +53,43 @@ https-certificate = "/dev/null"
 		t.Fatalf("unexpected https enabled: %v", c.HTTPSEnabled)
 	} else if c.HTTPSCertificate != "/dev/null" {
 		t.Fatalf("unexpected https certificate: %v", c.HTTPSCertificate)
+	} else if c.HTTPSClientCA != "/etc/ssl/ca.pem" {
+		t.Fatalf("unexpected https client ca: %v", c.HTTPSClientCA)
+	} else if c.HTTPSClientAuth != "required" {
+		t.Fatalf("unexpected https client auth: %v", c.HTTPSClientAuth)
+	} else if c.MaxBodySize != 1000 {
+		t.Fatalf("unexpected max body size: %v", c.MaxBodySize)
+	} else if c.WriteBatchSize != 100 {
+		t.Fatalf("unexpected write batch size: %v", c.WriteBatchSize)
+	} else if c.SharedSecret != "secret" {
+		t.Fatalf("unexpected shared secret: %v", c.SharedSecret)
+	} else if c.RateLimitBy != "user" {
+		t.Fatalf("unexpected rate limit by: %v", c.RateLimitBy)
+	} else if c.MaxWriteRequestsPerSecond != 10 {
+		t.Fatalf("unexpected max write requests per second: %v", c.MaxWriteRequestsPerSecond)
+	} else if c.MaxWritePointsPerSecond != 1000 {
+		t.Fatalf("unexpected max write points per second: %v", c.MaxWritePointsPerSecond)
+	} else if c.MaxConcurrentQueriesPerUser != 2 {
+		t.Fatalf("unexpected max concurrent queries per user: %v", c.MaxConcurrentQueriesPerUser)
+	} else if c.MaxConcurrentQueries != 20 {
+		t.Fatalf("unexpected max concurrent queries: %v", c.MaxConcurrentQueries)
+	} else if c.MaxQueuedQueries != 5 {
+		t.Fatalf("unexpected max queued queries: %v", c.MaxQueuedQueries)
+	} else if time.Duration(c.QueryQueueTimeout) != 10*time.Second {
+		t.Fatalf("unexpected query queue timeout: %v", c.QueryQueueTimeout)
+	}
+}
+
+// Ensure the config rejects an unknown rate-limit-by value.
+func TestConfig_Validate_RateLimitBy(t *testing.T) {
+	c := httpd.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.RateLimitBy = "users"
+	if err := c.Validate(); err == nil || err.Error() != `invalid rate-limit-by: "users", must be "database" or "user"` {
+		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
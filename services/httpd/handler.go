@@ -1,6 +1,7 @@
 package httpd
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
@@ -10,21 +11,29 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bmizerany/pat"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/client"
 	"github.com/influxdb/influxdb/cluster"
 	"github.com/influxdb/influxdb/influxql"
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/monitor"
 	"github.com/influxdb/influxdb/services/continuous_querier"
+	"github.com/influxdb/influxdb/services/prometheus"
+	"github.com/influxdb/influxdb/services/prometheus/remote"
+	"github.com/influxdb/influxdb/tsdb"
 	"github.com/influxdb/influxdb/uuid"
 )
 
@@ -35,8 +44,23 @@ const (
 	//
 	// Could be many more bytes depending on fields returned.
 	DefaultChunkSize = 10000
+
+	// DefaultWriteBatchSize is the number of points parsed from a line
+	// protocol write before they are passed to the points writer.
+	DefaultWriteBatchSize = 5000
+
+	// maxLineErrors is the maximum number of line errors returned
+	// to the client for a single write request.
+	maxLineErrors = 1000
+
+	// statusTooManyRequests is returned for rate limited requests.
+	// statusTooManyRequests requires Go 1.6.
+	statusTooManyRequests = 429
 )
 
+// errBodyTooLarge is returned when a request body exceeds the maximum size.
+var errBodyTooLarge = errors.New("request body too large")
+
 // TODO: Standard response headers (see: HeaderHandler)
 // TODO: Compression (see: CompressionHeaderHandler)
 
@@ -61,12 +85,14 @@ type Handler struct {
 		WaitForLeader(timeout time.Duration) error
 		Database(name string) (*meta.DatabaseInfo, error)
 		Authenticate(username, password string) (ui *meta.UserInfo, err error)
+		User(name string) (*meta.UserInfo, error)
+		AuthenticateToken(token string) (ui *meta.UserInfo, err error)
 		Users() ([]meta.UserInfo, error)
 	}
 
 	QueryExecutor interface {
 		Authorize(u *meta.UserInfo, q *influxql.Query, db string) error
-		ExecuteQuery(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error)
+		ExecuteQueryWithLimits(q *influxql.Query, db string, chunkSize int, limits tsdb.QueryLimits, closing chan struct{}) (<-chan *influxql.Result, error)
 	}
 
 	PointsWriter interface {
@@ -75,10 +101,23 @@ type Handler struct {
 
 	ContinuousQuerier continuous_querier.ContinuousQuerier
 
+	Monitor interface {
+		Statistics(tags map[string]string) ([]*monitor.Statistic, error)
+	}
+
 	Logger         *log.Logger
-	loggingEnabled bool // Log every HTTP access.
-	WriteTrace     bool // Detailed logging of write path
-	statMap        *expvar.Map
+	loggingEnabled bool   // Log every HTTP access.
+	WriteTrace     bool   // Detailed logging of write path
+	MaxBodySize    int64  // Maximum size of a write request body. Zero means no limit.
+	WriteBatchSize int    // Number of points sent to the points writer at once.
+	SharedSecret   string // Secret used to verify JWT bearer tokens.
+
+	RateLimitBy         string        // Key write rate limits by "database" or "user".
+	WriteRequestLimiter *RateLimiter  // Limits write requests per second.
+	WritePointLimiter   *RateLimiter  // Limits points written per second.
+	QueryLimiter        *QueryLimiter // Limits concurrent queries.
+
+	statMap *expvar.Map
 }
 
 // NewHandler returns a new instance of handler with routes.
@@ -109,6 +148,14 @@ func NewHandler(requireAuthentication, loggingEnabled, writeTrace bool, statMap
 			"write", // Data-ingest route.
 			"POST", "/write", true, true, h.serveWrite,
 		},
+		route{
+			"prometheus-write", // Prometheus remote write
+			"POST", "/api/v1/prom/write", false, true, h.servePromWrite,
+		},
+		route{
+			"prometheus-read", // Prometheus remote read
+			"POST", "/api/v1/prom/read", false, true, h.servePromRead,
+		},
 		route{ // Ping
 			"ping",
 			"GET", "/ping", true, true, h.servePing,
@@ -173,6 +220,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	} else if strings.HasPrefix(r.URL.Path, "/debug/vars") {
 		serveExpvar(w, r)
+	} else if r.URL.Path == "/metrics" {
+		h.serveMetrics(w, r)
 	} else {
 		h.mux.ServeHTTP(w, r)
 	}
@@ -237,6 +286,18 @@ func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request, user *meta.
 	p := influxql.NewParser(strings.NewReader(qp))
 	db := q.Get("db")
 
+	// Bind any parameters to the query.
+	if rawParams := q.Get("params"); rawParams != "" {
+		var params map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(rawParams))
+		decoder.UseNumber()
+		if err := decoder.Decode(&params); err != nil {
+			httpError(w, "error parsing query parameters: "+err.Error(), pretty, http.StatusBadRequest)
+			return
+		}
+		p.SetParams(params)
+	}
+
 	// Parse query from query string.
 	query, err := p.ParseQuery()
 	if err != nil {
@@ -272,6 +333,13 @@ func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request, user *meta.
 		}
 	}
 
+	// Parse any limits requested for this query.
+	limits, err := parseQueryLimits(q)
+	if err != nil {
+		httpError(w, err.Error(), pretty, http.StatusBadRequest)
+		return
+	}
+
 	// Make sure if the client disconnects we signal the query to abort
 	closing := make(chan struct{})
 	if notifier, ok := w.(http.CloseNotifier); ok {
@@ -282,9 +350,24 @@ func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request, user *meta.
 		}()
 	}
 
+	// Wait for a free query slot.
+	release, err := h.QueryLimiter.Acquire(userKey(r, user), closing)
+	if err != nil {
+		h.statMap.Add(statQueryRequestLimited, 1)
+		w.Header().Set("Retry-After", "1")
+		httpError(w, err.Error(), pretty, statusTooManyRequests)
+		return
+	}
+	defer release()
+	h.statMap.Add(statQueriesActive, 1)
+	defer h.statMap.Add(statQueriesActive, -1)
+
+	// Select the response format from the Accept header.
+	rw := newResponseFormatter(r, pretty)
+
 	// Execute query.
-	w.Header().Add("content-type", "application/json")
-	results, err := h.QueryExecutor.ExecuteQuery(query, db, chunkSize, closing)
+	w.Header().Add("content-type", rw.ContentType())
+	results, err := h.QueryExecutor.ExecuteQueryWithLimits(query, db, chunkSize, limits, closing)
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -311,9 +394,9 @@ func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request, user *meta.
 
 		// Write out result immediately if chunked.
 		if chunked {
-			n, _ := w.Write(MarshalJSON(Response{
+			n, _ := rw.WriteResponse(w, Response{
 				Results: []*influxql.Result{r},
-			}, pretty))
+			})
 			h.statMap.Add(statQueryRequestBytesTransmitted, int64(n))
 			w.(http.Flusher).Flush()
 			continue
@@ -351,7 +434,7 @@ func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request, user *meta.
 
 	// If it's not chunked we buffered everything in memory, so write it out
 	if !chunked {
-		n, _ := w.Write(MarshalJSON(resp, pretty))
+		n, _ := rw.WriteResponse(w, resp)
 		h.statMap.Add(statQueryRequestBytesTransmitted, int64(n))
 	}
 }
@@ -359,36 +442,59 @@ func (h *Handler) serveQuery(w http.ResponseWriter, r *http.Request, user *meta.
 func (h *Handler) serveWrite(w http.ResponseWriter, r *http.Request, user *meta.UserInfo) {
 	h.statMap.Add(statWriteRequest, 1)
 
+	// Reject bodies that declare a length over the limit before reading anything.
+	if h.MaxBodySize > 0 && r.ContentLength > h.MaxBodySize {
+		resultError(w, influxql.Result{Err: errBodyTooLarge}, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Count and limit the bytes read from the request body.
+	cr := &countingReader{r: r.Body, max: h.MaxBodySize}
+	defer func() { h.statMap.Add(statWriteRequestBytesReceived, cr.n) }()
+
 	// Handle gzip decoding of the body
-	body := r.Body
+	var body io.Reader = cr
 	if r.Header.Get("Content-encoding") == "gzip" {
-		b, err := gzip.NewReader(r.Body)
+		b, err := gzip.NewReader(cr)
 		if err != nil {
 			resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
 			return
 		}
+		defer b.Close()
 		body = b
 	}
-	defer body.Close()
+	defer r.Body.Close()
 
-	b, err := ioutil.ReadAll(body)
-	if err != nil {
-		if h.WriteTrace {
-			h.Logger.Print("write handler unable to read bytes from request body")
-		}
-		resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
-		return
-	}
-	h.statMap.Add(statWriteRequestBytesReceived, int64(len(b)))
+	// Keep a copy of the body for the write trace log.
+	var trace bytes.Buffer
 	if h.WriteTrace {
-		h.Logger.Printf("write body received by handler: %s", string(b))
+		body = io.TeeReader(body, &trace)
+		defer func() { h.Logger.Printf("write body received by handler: %s", trace.String()) }()
+	}
+
+	// Some clients may not set the content-type header appropriately and send JSON with a non-json
+	// content-type.  If the body looks like JSON, try to handle it as JSON instead.
+	br := bufio.NewReaderSize(body, 64*1024)
+
+	// An empty body writes no points.
+	if _, err := br.Peek(1); err == io.EOF {
+		w.WriteHeader(http.StatusOK)
+		return
 	}
 
-	if r.Header.Get("Content-Type") == "application/json" {
+	if r.Header.Get("Content-Type") == "application/json" || looksLikeJSON(br) {
+		b, err := ioutil.ReadAll(br)
+		if err != nil {
+			if h.WriteTrace {
+				h.Logger.Print("write handler unable to read bytes from request body")
+			}
+			writeBodyError(w, err)
+			return
+		}
 		h.serveWriteJSON(w, r, b, user)
 		return
 	}
-	h.serveWriteLine(w, r, b, user)
+	h.serveWriteLine(w, r, br, user)
 }
 
 // serveWriteJSON receives incoming series data in JSON and writes it to the database.
@@ -430,12 +536,25 @@ func (h *Handler) serveWriteJSON(w http.ResponseWriter, r *http.Request, body []
 		return
 	}
 
+	key := h.rateLimitKey(r, bp.Database, user)
+	if err := h.WriteRequestLimiter.Take(key, 1); err != nil {
+		h.statMap.Add(statWriteRequestLimited, 1)
+		rateLimitError(w, err.(*RateLimitError))
+		return
+	}
+
 	points, err := NormalizeBatchPoints(bp)
 	if err != nil {
 		resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
 		return
 	}
 
+	if err := h.WritePointLimiter.Take(key, len(points)); err != nil {
+		h.statMap.Add(statPointsLimited, int64(len(points)))
+		rateLimitError(w, err.(*RateLimitError))
+		return
+	}
+
 	// Convert the json batch struct to a points writer struct
 	if err := h.PointsWriter.WritePoints(&cluster.WritePointsRequest{
 		Database:         bp.Database,
@@ -457,42 +576,234 @@ func (h *Handler) serveWriteJSON(w http.ResponseWriter, r *http.Request, body []
 }
 
 // serveWriteLine receives incoming series data in line protocol format and writes it to the database.
-func (h *Handler) serveWriteLine(w http.ResponseWriter, r *http.Request, body []byte, user *meta.UserInfo) {
-	// Some clients may not set the content-type header appropriately and send JSON with a non-json
-	// content-type.  If the body looks JSON, try to handle it as as JSON instead
-	if len(body) > 0 {
-		var i int
-		for {
-			// JSON requests must start w/ an opening bracket
-			if body[i] == '{' {
-				h.serveWriteJSON(w, r, body, user)
-				return
-			}
+// The body is parsed one line at a time and points are sent to the points writer in batches.
+func (h *Handler) serveWriteLine(w http.ResponseWriter, r *http.Request, body io.Reader, user *meta.UserInfo) {
+	// Parameters are only read from the URL. Parsing a form would consume
+	// the body, which is still being streamed.
+	q := r.URL.Query()
 
-			// check that the byte is in the standard ascii code range
-			if body[i] > 32 || i >= len(body)-1 {
-				break
-			}
-			i++
-		}
+	database := q.Get("db")
+	if database == "" {
+		resultError(w, influxql.Result{Err: fmt.Errorf("database is required")}, http.StatusBadRequest)
+		return
 	}
 
-	precision := r.FormValue("precision")
+	if di, err := h.MetaStore.Database(database); err != nil {
+		resultError(w, influxql.Result{Err: fmt.Errorf("metastore database error: %s", err)}, http.StatusInternalServerError)
+		return
+	} else if di == nil {
+		resultError(w, influxql.Result{Err: fmt.Errorf("database not found: %q", database)}, http.StatusNotFound)
+		return
+	}
+
+	if h.requireAuthentication && user == nil {
+		resultError(w, influxql.Result{Err: fmt.Errorf("user is required to write to database %q", database)}, http.StatusUnauthorized)
+		return
+	}
+
+	if h.requireAuthentication && !user.Authorize(influxql.WritePrivilege, database) {
+		resultError(w, influxql.Result{Err: fmt.Errorf("%q user is not authorized to write to database %q", user.Name, database)}, http.StatusUnauthorized)
+		return
+	}
+
+	key := h.rateLimitKey(r, database, user)
+	if err := h.WriteRequestLimiter.Take(key, 1); err != nil {
+		h.statMap.Add(statWriteRequestLimited, 1)
+		rateLimitError(w, err.(*RateLimitError))
+		return
+	}
+
+	precision := q.Get("precision")
 	if precision == "" {
 		precision = "n"
 	}
 
-	points, parseError := models.ParsePointsWithPrecision(body, time.Now().UTC(), precision)
-	// Not points parsed correctly so return the error now
-	if parseError != nil && len(points) == 0 {
-		if parseError.Error() == "EOF" {
-			w.WriteHeader(http.StatusOK)
+	// Determine required consistency level.
+	consistency := cluster.ConsistencyLevelOne
+	switch q.Get("consistency") {
+	case "all":
+		consistency = cluster.ConsistencyLevelAll
+	case "any":
+		consistency = cluster.ConsistencyLevelAny
+	case "one":
+		consistency = cluster.ConsistencyLevelOne
+	case "quorum":
+		consistency = cluster.ConsistencyLevelQuorum
+	}
+
+	batchSize := h.WriteBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultWriteBatchSize
+	}
+
+	var (
+		batch        = make([]models.Point, 0, batchSize)
+		lines        = make([]int, 0, batchSize) // line number of each point in the batch
+		resp         WriteResponse
+		limitChecked bool // first batch passed the points rate limit
+	)
+
+	// reject records a line that was not written.
+	reject := func(line int, kind influxdb.WriteErrorKind, err error) {
+		if len(resp.Errors) < maxLineErrors {
+			resp.Errors = append(resp.Errors, WriteError{Line: line, Kind: kind, Message: err.Error()})
+		}
+		resp.Rejected++
+	}
+
+	// writeBatch sends the current batch to the points writer. Points rejected
+	// individually are recorded and only other errors are returned.
+	writeBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// Only the first batch can be rejected by the points limit so a
+		// request is never cut off part way. Later batches are charged to
+		// the limit which stays in debt until it refills.
+		if !limitChecked {
+			if err := h.WritePointLimiter.Take(key, len(batch)); err != nil {
+				h.statMap.Add(statPointsLimited, int64(len(batch)))
+				resp.Rejected += len(batch)
+				return err
+			}
+			limitChecked = true
+		} else {
+			h.WritePointLimiter.Charge(key, len(batch))
+		}
+		err := h.PointsWriter.WritePoints(&cluster.WritePointsRequest{
+			Database:         database,
+			RetentionPolicy:  q.Get("rp"),
+			ConsistencyLevel: consistency,
+			Points:           batch,
+		})
+		if perr, ok := err.(*influxdb.PartialWriteError); ok {
+			for _, e := range perr.Errors {
+				reject(lines[e.Index], e.Kind, e.Err)
+			}
+			h.statMap.Add(statPointsWrittenFail, int64(len(perr.Errors)))
+			h.statMap.Add(statPointsWrittenOK, int64(len(batch)-len(perr.Errors)))
+			resp.Accepted += len(batch) - len(perr.Errors)
+		} else if err != nil {
+			h.statMap.Add(statPointsWrittenFail, int64(len(batch)))
+			resp.Rejected += len(batch)
+			return err
+		} else {
+			h.statMap.Add(statPointsWrittenOK, int64(len(batch)))
+			resp.Accepted += len(batch)
+		}
+		batch = make([]models.Point, 0, batchSize)
+		lines = lines[:0]
+		return nil
+	}
+
+	pr := models.NewPointReader(body, time.Now().UTC(), precision)
+	for {
+		pt, err := pr.ReadPoint()
+		if err == io.EOF {
+			break
+		} else if lerr, ok := err.(*models.LineError); ok {
+			// Record the failed line and keep going.
+			h.statMap.Add(statPointsParseFail, 1)
+			reject(lerr.Line, influxdb.WriteErrorParse, lerr)
+			continue
+		} else if err != nil {
+			writeBodyError(w, err)
 			return
 		}
-		resultError(w, influxql.Result{Err: parseError}, http.StatusBadRequest)
+
+		batch = append(batch, pt)
+		lines = append(lines, pr.Line())
+		if len(batch) < batchSize {
+			continue
+		}
+		if err := writeBatch(); err != nil {
+			h.writeResponseError(w, &resp, err)
+			return
+		}
+	}
+
+	if err := writeBatch(); err != nil {
+		h.writeResponseError(w, &resp, err)
 		return
 	}
 
+	if resp.Rejected > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		if resp.Rejected > len(resp.Errors) {
+			messages = append(messages, fmt.Sprintf("and %d more lines failed", resp.Rejected-len(resp.Errors)))
+		}
+		resp.Err = strings.Join(messages, "\n")
+
+		// We wrote some of the points.
+		// The other points were rejected which means the client sent invalid line protocol or
+		// conflicting data.  We return a 400 response code as well as the lines that failed.
+		if resp.Accepted > 0 {
+			resp.Err = "partial write:\n" + resp.Err
+		}
+		writeResponse(w, &resp, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeResponseError writes a write response for an error that aborted the write.
+// Batches written before the error are not rolled back so the response reports
+// them as a partial write.
+func (h *Handler) writeResponseError(w http.ResponseWriter, resp *WriteResponse, err error) {
+	resp.Err = err.Error()
+	if resp.Accepted > 0 {
+		resp.Err = fmt.Sprintf("partial write: %d points written before error: %s", resp.Accepted, err)
+	}
+	if err, ok := err.(*RateLimitError); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfterSeconds()))
+		writeResponse(w, resp, statusTooManyRequests)
+		return
+	}
+	resp.Kind = influxdb.WriteErrorKindOf(err)
+	if influxdb.IsClientError(err) {
+		writeResponse(w, resp, http.StatusBadRequest)
+		return
+	}
+	writeResponse(w, resp, http.StatusInternalServerError)
+}
+
+// rateLimitKey returns the key that write rate limits are tracked by.
+// Anonymous requests are limited by client address when limiting by user.
+func (h *Handler) rateLimitKey(r *http.Request, database string, user *meta.UserInfo) string {
+	if h.RateLimitBy == RateLimitByUser {
+		return userKey(r, user)
+	}
+	return database
+}
+
+// userKey returns the key that per-user limits are tracked by. Anonymous
+// requests are keyed by client address so they aren't limited together.
+func userKey(r *http.Request, user *meta.UserInfo) string {
+	if user == nil {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		return "@" + host
+	}
+	return user.Name
+}
+
+// rateLimitError writes a rate limit error with a Retry-After header.
+func rateLimitError(w http.ResponseWriter, err *RateLimitError) {
+	w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfterSeconds()))
+	resultError(w, influxql.Result{Err: err}, statusTooManyRequests)
+}
+
+// servePromWrite receives Prometheus remote write requests and writes the
+// samples to the database given by the "db" parameter.
+func (h *Handler) servePromWrite(w http.ResponseWriter, r *http.Request, user *meta.UserInfo) {
+	h.statMap.Add(statPromWriteRequest, 1)
+
 	database := r.FormValue("db")
 	if database == "" {
 		resultError(w, influxql.Result{Err: fmt.Errorf("database is required")}, http.StatusBadRequest)
@@ -517,44 +828,176 @@ func (h *Handler) serveWriteLine(w http.ResponseWriter, r *http.Request, body []
 		return
 	}
 
-	// Determine required consistency level.
-	consistency := cluster.ConsistencyLevelOne
-	switch r.Form.Get("consistency") {
-	case "all":
-		consistency = cluster.ConsistencyLevelAll
-	case "any":
-		consistency = cluster.ConsistencyLevelAny
-	case "one":
-		consistency = cluster.ConsistencyLevelOne
-	case "quorum":
-		consistency = cluster.ConsistencyLevelQuorum
+	key := h.rateLimitKey(r, database, user)
+	if err := h.WriteRequestLimiter.Take(key, 1); err != nil {
+		h.statMap.Add(statWriteRequestLimited, 1)
+		rateLimitError(w, err.(*RateLimitError))
+		return
+	}
+
+	var req remote.WriteRequest
+	if err := h.readPromRequest(r, &req); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	points, err := prometheus.WriteRequestToPoints(&req)
+	if err == prometheus.ErrNaNDropped {
+		if h.WriteTrace {
+			h.Logger.Printf("prometheus write: %s", err)
+		}
+	} else if err != nil {
+		resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.WritePointLimiter.Take(key, len(points)); err != nil {
+		h.statMap.Add(statPointsLimited, int64(len(points)))
+		rateLimitError(w, err.(*RateLimitError))
+		return
 	}
 
-	// Write points.
 	if err := h.PointsWriter.WritePoints(&cluster.WritePointsRequest{
 		Database:         database,
 		RetentionPolicy:  r.FormValue("rp"),
-		ConsistencyLevel: consistency,
+		ConsistencyLevel: cluster.ConsistencyLevelOne,
 		Points:           points,
-	}); influxdb.IsClientError(err) {
+	}); err != nil {
 		h.statMap.Add(statPointsWrittenFail, int64(len(points)))
+		writePointsError(w, err)
+		return
+	}
+	h.statMap.Add(statPointsWrittenOK, int64(len(points)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// servePromRead receives Prometheus remote read requests and returns the
+// matching series from the database given by the "db" parameter.
+func (h *Handler) servePromRead(w http.ResponseWriter, r *http.Request, user *meta.UserInfo) {
+	h.statMap.Add(statPromReadRequest, 1)
+
+	q := r.URL.Query()
+	db := q.Get("db")
+	if db == "" {
+		resultError(w, influxql.Result{Err: fmt.Errorf("database is required")}, http.StatusBadRequest)
+		return
+	}
+
+	var req remote.ReadRequest
+	if err := h.readPromRequest(r, &req); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	query, err := prometheus.ReadRequestToInfluxQLQuery(&req, db, q.Get("rp"))
+	if err != nil {
 		resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
 		return
-	} else if err != nil {
-		h.statMap.Add(statPointsWrittenFail, int64(len(points)))
+	}
+
+	if h.requireAuthentication {
+		if err := h.QueryExecutor.Authorize(user, query, db); err != nil {
+			resultError(w, influxql.Result{Err: fmt.Errorf("error authorizing query: %s", err)}, http.StatusUnauthorized)
+			return
+		}
+	}
+
+	limits, err := parseQueryLimits(q)
+	if err != nil {
+		resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
+		return
+	}
+
+	// Make sure if the client disconnects we signal the query to abort
+	closing := make(chan struct{})
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		notify := notifier.CloseNotify()
+		go func() {
+			<-notify
+			close(closing)
+		}()
+	}
+
+	// Wait for a free query slot.
+	release, err := h.QueryLimiter.Acquire(userKey(r, user), closing)
+	if err != nil {
+		h.statMap.Add(statQueryRequestLimited, 1)
+		w.Header().Set("Retry-After", "1")
+		resultError(w, influxql.Result{Err: err}, statusTooManyRequests)
+		return
+	}
+	defer release()
+	h.statMap.Add(statQueriesActive, 1)
+	defer h.statMap.Add(statQueriesActive, -1)
+
+	results, err := h.QueryExecutor.ExecuteQueryWithLimits(query, db, DefaultChunkSize, limits, closing)
+	if err != nil {
 		resultError(w, influxql.Result{Err: err}, http.StatusInternalServerError)
 		return
-	} else if parseError != nil {
-		// We wrote some of the points
-		h.statMap.Add(statPointsWrittenOK, int64(len(points)))
-		// The other points failed to parse which means the client sent invalid line protocol.  We return a 400
-		// response code as well as the lines that failed to parse.
-		resultError(w, influxql.Result{Err: fmt.Errorf("partial write:\n%v", parseError)}, http.StatusBadRequest)
+	}
+
+	// Collect the rows of each statement. The channel is always drained so
+	// the executor is not blocked when a statement fails.
+	rows := make([][]*models.Row, len(query.Statements))
+	for r := range results {
+		if r == nil {
+			continue
+		} else if r.Err != nil {
+			if err == nil {
+				err = r.Err
+			}
+			continue
+		}
+		if r.StatementID >= 0 && r.StatementID < len(rows) {
+			rows[r.StatementID] = append(rows[r.StatementID], r.Series...)
+		}
+	}
+	if err != nil {
+		resultError(w, influxql.Result{Err: err}, http.StatusInternalServerError)
 		return
 	}
 
-	h.statMap.Add(statPointsWrittenOK, int64(len(points)))
-	w.WriteHeader(http.StatusNoContent)
+	resp := &remote.ReadResponse{Results: make([]*remote.QueryResult, len(rows))}
+	for i := range rows {
+		resp.Results[i] = &remote.QueryResult{Timeseries: prometheus.RowsToTimeSeries(rows[i])}
+	}
+
+	buf, err := proto.Marshal(resp)
+	if err != nil {
+		resultError(w, influxql.Result{Err: err}, http.StatusInternalServerError)
+		return
+	}
+	buf = snappy.Encode(nil, buf)
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	n, _ := w.Write(buf)
+	h.statMap.Add(statQueryRequestBytesTransmitted, int64(n))
+}
+
+// readPromRequest reads a snappy compressed protobuf message from the body of r into pb.
+func (h *Handler) readPromRequest(r *http.Request, pb proto.Message) error {
+	defer r.Body.Close()
+
+	if h.MaxBodySize > 0 && r.ContentLength > h.MaxBodySize {
+		return errBodyTooLarge
+	}
+
+	compressed, err := ioutil.ReadAll(&countingReader{r: r.Body, max: h.MaxBodySize})
+	if err != nil {
+		return err
+	}
+
+	buf, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return fmt.Errorf("snappy decode error: %s", err)
+	}
+
+	if err := proto.Unmarshal(buf, pb); err != nil {
+		return fmt.Errorf("protobuf decode error: %s", err)
+	}
+	return nil
 }
 
 // serveOptions returns an empty response to comply with OPTIONS pre-flight requests
@@ -656,6 +1099,23 @@ func serveExpvar(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "\n}\n")
 }
 
+// serveMetrics serves the monitor statistics in the Prometheus text format.
+func (h *Handler) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if h.Monitor == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	stats, err := h.Monitor.Statistics(nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", monitor.PrometheusContentType)
+	monitor.WritePrometheus(w, stats)
+}
+
 // httpError writes an error to the client in a standard format.
 func httpError(w http.ResponseWriter, error string, pretty bool, code int) {
 	w.Header().Add("content-type", "application/json")
@@ -677,6 +1137,68 @@ func resultError(w http.ResponseWriter, result influxql.Result, code int) {
 	_ = json.NewEncoder(w).Encode(&result)
 }
 
+// writeResponse writes a write response as JSON.
+func writeResponse(w http.ResponseWriter, resp *WriteResponse, code int) {
+	w.Header().Add("content-type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writePointsError writes an error returned by the points writer.
+func writePointsError(w http.ResponseWriter, err error) {
+	if influxdb.IsClientError(err) {
+		resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
+		return
+	}
+	resultError(w, influxql.Result{Err: err}, http.StatusInternalServerError)
+}
+
+// writeBodyError writes an error returned while reading a request body.
+func writeBodyError(w http.ResponseWriter, err error) {
+	if err == errBodyTooLarge {
+		resultError(w, influxql.Result{Err: err}, http.StatusRequestEntityTooLarge)
+		return
+	}
+	resultError(w, influxql.Result{Err: err}, http.StatusBadRequest)
+}
+
+// looksLikeJSON returns true if the first non-whitespace byte in br is an opening bracket.
+func looksLikeJSON(br *bufio.Reader) bool {
+	for i := 1; ; i++ {
+		b, err := br.Peek(i)
+		if err != nil {
+			return false
+		}
+
+		// JSON requests must start w/ an opening bracket
+		if b[i-1] == '{' {
+			return true
+		}
+
+		// check that the byte is in the standard ascii code range
+		if b[i-1] > 32 {
+			return false
+		}
+	}
+}
+
+// countingReader counts the bytes read from r and returns errBodyTooLarge
+// once more than max bytes have been read. A max of zero means no limit.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.max > 0 && r.n > r.max {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
+
 // Filters and filter helpers
 
 // parseCredentials returns the username and password encoded in
@@ -696,6 +1218,98 @@ func parseCredentials(r *http.Request) (string, string, error) {
 	return "", "", fmt.Errorf("unable to parse Basic Auth credentials")
 }
 
+// parseBearerToken returns the JSON Web Token passed in the Authorization
+// header of a request, if any.
+// as header: Authorization: Bearer <jwt>
+func parseBearerToken(r *http.Request) (string, bool) {
+	return parseAuthorization(r, "Bearer")
+}
+
+// authenticateBearer verifies a JSON Web Token against the shared secret and
+// returns the user named by its username claim.
+func (h *Handler) authenticateBearer(token string) (*meta.UserInfo, error) {
+	if h.SharedSecret == "" {
+		return nil, errors.New("bearer authentication not configured")
+	}
+
+	claims, err := parseJWT(token, []byte(h.SharedSecret), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return h.lookupUser(claims.Username)
+}
+
+// parseCertificateName returns the subject common name of the verified
+// client certificate of a request, if any.
+func parseCertificateName(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName, true
+}
+
+// lookupUser returns the user with the given name or an error if it does not exist.
+func (h *Handler) lookupUser(name string) (*meta.UserInfo, error) {
+	ui, err := h.MetaStore.User(name)
+	if err != nil {
+		return nil, err
+	} else if ui == nil {
+		return nil, meta.ErrUserNotFound
+	}
+	return ui, nil
+}
+
+// parseQueryLimits returns the query limits requested by the timeout,
+// max_select_point, max_select_series and max_select_buckets parameters.
+// Requested limits can only lower the limits configured on the server.
+func parseQueryLimits(q url.Values) (tsdb.QueryLimits, error) {
+	var limits tsdb.QueryLimits
+	if s := q.Get("timeout"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return limits, fmt.Errorf("invalid timeout: %q", s)
+		}
+		limits.Timeout = d
+	}
+
+	for _, p := range []struct {
+		name string
+		n    *int
+	}{
+		{"max_select_point", &limits.MaxSelectPointN},
+		{"max_select_series", &limits.MaxSelectSeriesN},
+		{"max_select_buckets", &limits.MaxSelectBucketsN},
+	} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return limits, fmt.Errorf("invalid %s: %q", p.name, s)
+		}
+		*p.n = n
+	}
+	return limits, nil
+}
+
+// parseToken returns the API token passed in the Authorization header
+// of a request, if any.
+// as header: Authorization: Token <token>
+func parseToken(r *http.Request) (string, bool) {
+	return parseAuthorization(r, "Token")
+}
+
+// parseAuthorization returns the credentials passed in the Authorization
+// header of a request with the given scheme, if any.
+func parseAuthorization(r *http.Request, scheme string) (string, bool) {
+	prefix := scheme + " "
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, prefix) {
+		return strings.TrimSpace(auth[len(prefix):]), true
+	}
+	return "", false
+}
+
 // authenticate wraps a handler and ensures that if user credentials are passed in
 // an attempt is made to authenticate that user. If authentication fails, an error is returned.
 //
@@ -719,6 +1333,42 @@ func authenticate(inner func(http.ResponseWriter, *http.Request, *meta.UserInfo)
 
 		// TODO corylanou: never allow this in the future without users
 		if requireAuthentication && len(uis) > 0 {
+			// A verified client certificate authenticates the user named by its common name.
+			// Certificates that don't name a user fall through to the other credentials.
+			if name, ok := parseCertificateName(r); ok {
+				user, err = h.lookupUser(name)
+				if err == nil {
+					inner(w, r, user)
+					return
+				} else if err != meta.ErrUserNotFound {
+					h.statMap.Add(statAuthFail, 1)
+					httpError(w, err.Error(), false, http.StatusUnauthorized)
+					return
+				}
+			}
+
+			if token, ok := parseToken(r); ok {
+				user, err = h.MetaStore.AuthenticateToken(token)
+				if err != nil {
+					h.statMap.Add(statAuthFail, 1)
+					httpError(w, err.Error(), false, http.StatusUnauthorized)
+					return
+				}
+				inner(w, r, user)
+				return
+			}
+
+			if token, ok := parseBearerToken(r); ok {
+				user, err = h.authenticateBearer(token)
+				if err != nil {
+					h.statMap.Add(statAuthFail, 1)
+					httpError(w, err.Error(), false, http.StatusUnauthorized)
+					return
+				}
+				inner(w, r, user)
+				return
+			}
+
 			username, password, err := parseCredentials(r)
 			if err != nil {
 				h.statMap.Add(statAuthFail, 1)
@@ -909,6 +1559,22 @@ func (r *Response) Error() error {
 	return nil
 }
 
+// WriteResponse is returned by the write endpoint when points were rejected.
+type WriteResponse struct {
+	Err      string                  `json:"error"`
+	Kind     influxdb.WriteErrorKind `json:"kind,omitempty"`
+	Accepted int                     `json:"accepted"`
+	Rejected int                     `json:"rejected"`
+	Errors   []WriteError            `json:"errors,omitempty"`
+}
+
+// WriteError describes a single line rejected by a write.
+type WriteError struct {
+	Line    int                     `json:"line"`
+	Kind    influxdb.WriteErrorKind `json:"kind"`
+	Message string                  `json:"message"`
+}
+
 // NormalizeBatchPoints returns a slice of Points, created by populating individual
 // points within the batch, which do not have times or tags, with the top-level
 // values.
@@ -2,23 +2,36 @@ package httpd_test
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/client"
+	"github.com/influxdb/influxdb/cluster"
 	"github.com/influxdb/influxdb/influxql"
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/monitor"
 	"github.com/influxdb/influxdb/services/httpd"
+	"github.com/influxdb/influxdb/services/prometheus/remote"
 	"github.com/influxdb/influxdb/tsdb"
 )
 
@@ -158,6 +171,36 @@ func TestHandler_Query(t *testing.T) {
 	}
 }
 
+// Ensure the handler binds query parameters.
+func TestHandler_Query_Params(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		if q.String() != `SELECT * FROM bar WHERE host = 'server01\' OR 1=1' LIMIT 10` {
+			t.Fatalf("unexpected query: %s", q.String())
+		}
+		return NewResultChan(&influxql.Result{StatementID: 1, Series: models.Rows([]*models.Row{{Name: "series0"}})}), nil
+	}
+
+	w := httptest.NewRecorder()
+	params := url.QueryEscape(`{"m": {"identifier": "bar"}, "host": "server01' OR 1=1", "limit": 10}`)
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+$m+WHERE+host+%3D+$host+LIMIT+$limit&params="+params, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler returns a status 400 if the query parameters are invalid JSON.
+func TestHandler_Query_ErrInvalidParams(t *testing.T) {
+	h := NewHandler(false)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&params=%7B", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if w.Body.String() != `{"error":"error parsing query parameters: unexpected EOF"}` {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
 // Ensure the handler returns results from a query (including nil results).
 func TestHandler_QueryRegex(t *testing.T) {
 	h := NewHandler(false)
@@ -217,6 +260,553 @@ func TestHandler_Query_Chunked(t *testing.T) {
 	}
 }
 
+// Ensure the handler returns results as CSV when requested.
+func TestHandler_Query_CSV(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(
+			&influxql.Result{StatementID: 1, Series: models.Rows([]*models.Row{{
+				Name:    "cpu",
+				Tags:    map[string]string{"region": "west", "host": "a"},
+				Columns: []string{"time", "value", "count"},
+				Values: [][]interface{}{
+					{time.Unix(0, 0).UTC(), float64(1.5), int64(2)},
+					{time.Unix(10, 0).UTC(), float64(2), nil},
+				},
+			}})},
+		), nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.Header.Set("Accept", "text/csv")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if ct := w.Header().Get("content-type"); ct != "text/csv" {
+		t.Fatalf("unexpected content-type: %s", ct)
+	} else if w.Body.String() != "name,tags,time,value,count\n"+
+		"cpu,\"host=a,region=west\",1970-01-01T00:00:00Z,1.5,2\n"+
+		"cpu,\"host=a,region=west\",1970-01-01T00:00:10Z,2,\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// Ensure the handler writes the CSV header once across chunks with the same columns.
+func TestHandler_Query_CSV_Chunked(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(
+			&influxql.Result{StatementID: 1, Series: models.Rows([]*models.Row{{Name: "cpu", Columns: []string{"time", "value"}, Values: [][]interface{}{{int64(1), int64(10)}}}})},
+			&influxql.Result{StatementID: 1, Series: models.Rows([]*models.Row{{Name: "cpu", Columns: []string{"time", "value"}, Values: [][]interface{}{{int64(2), int64(20)}}}})},
+			&influxql.Result{StatementID: 2, Err: errors.New("marker")},
+		), nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&chunked=true", nil)
+	r.Header.Set("Accept", "text/csv")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if w.Body.String() != "name,tags,time,value\ncpu,,1,10\ncpu,,2,20\nerror\nmarker\n" {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// Ensure the handler returns results as MessagePack when requested.
+func TestHandler_Query_MsgPack(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(
+			&influxql.Result{StatementID: 1, Series: models.Rows([]*models.Row{{
+				Name:    "cpu",
+				Columns: []string{"time", "value"},
+				Values:  [][]interface{}{{int64(1000), float64(1.5)}},
+			}})},
+		), nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.Header.Set("Accept", "application/x-msgpack")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if ct := w.Header().Get("content-type"); ct != "application/x-msgpack" {
+		t.Fatalf("unexpected content-type: %s", ct)
+	}
+
+	// {"results":[{"series":[{"name":"cpu","columns":["time","value"],"values":[[1000,1.5]]}]}]}
+	exp := []byte{
+		0x81, 0xa7, 'r', 'e', 's', 'u', 'l', 't', 's', 0x91,
+		0x81, 0xa6, 's', 'e', 'r', 'i', 'e', 's', 0x91,
+		0x83,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+		0xa7, 'c', 'o', 'l', 'u', 'm', 'n', 's', 0x92, 0xa4, 't', 'i', 'm', 'e', 0xa5, 'v', 'a', 'l', 'u', 'e',
+		0xa6, 'v', 'a', 'l', 'u', 'e', 's', 0x91, 0x92, 0xcd, 0x03, 0xe8, 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+	}
+	if !bytes.Equal(w.Body.Bytes(), exp) {
+		t.Fatalf("unexpected body: %x", w.Body.Bytes())
+	}
+}
+
+// Ensure the handler writes one MessagePack document per chunk.
+func TestHandler_Query_MsgPack_Chunked(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(
+			&influxql.Result{StatementID: 1, Series: models.Rows([]*models.Row{{Name: "a"}})},
+			&influxql.Result{StatementID: 1, Err: errors.New("b")},
+		), nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&chunked=true", nil)
+	r.Header.Set("Accept", "application/x-msgpack")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	exp := []byte{
+		0x81, 0xa7, 'r', 'e', 's', 'u', 'l', 't', 's', 0x91,
+		0x81, 0xa6, 's', 'e', 'r', 'i', 'e', 's', 0x91, 0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a',
+		0x81, 0xa7, 'r', 'e', 's', 'u', 'l', 't', 's', 0x91,
+		0x81, 0xa5, 'e', 'r', 'r', 'o', 'r', 0xa1, 'b',
+	}
+	if !bytes.Equal(w.Body.Bytes(), exp) {
+		t.Fatalf("unexpected body: %x", w.Body.Bytes())
+	}
+}
+
+// Ensure the handler writes line protocol to the points writer in batches.
+func TestHandler_Write_Batched(t *testing.T) {
+	h := NewHandler(false)
+	h.WriteBatchSize = 2
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+
+	var sizes []int
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		if p.Database != "foo" {
+			t.Fatalf("unexpected database: %s", p.Database)
+		}
+		sizes = append(sizes, len(p.Points))
+		return nil
+	}
+
+	body := "cpu value=1 1\ncpu value=2 2\n\n# comment\ncpu value=3 3\ncpu value=4 4\ncpu value=5 5"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader(body)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if !reflect.DeepEqual(sizes, []int{2, 2, 1}) {
+		t.Fatalf("unexpected batch sizes: %v", sizes)
+	}
+}
+
+// Ensure every point is written when a streamed body is sent with the form
+// content type, which clients like curl send by default.
+func TestHandler_Write_FormContentType(t *testing.T) {
+	h := NewHandler(false)
+	h.WriteBatchSize = 1000
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+
+	var n int
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		if p.Database != "foo" {
+			t.Fatalf("unexpected database: %s", p.Database)
+		} else if p.RetentionPolicy != "bar" {
+			t.Fatalf("unexpected retention policy: %s", p.RetentionPolicy)
+		} else if p.ConsistencyLevel != cluster.ConsistencyLevelAll {
+			t.Fatalf("unexpected consistency level: %v", p.ConsistencyLevel)
+		}
+		n += len(p.Points)
+		return nil
+	}
+
+	// The body is larger than the buffer used to detect its format.
+	var body bytes.Buffer
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&body, "cpu value=%d %d\n", i, i)
+	}
+
+	r := MustNewRequest("POST", "/write?db=foo&rp=bar&consistency=all&precision=s", &body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if n != 20000 {
+		t.Fatalf("unexpected points written: %d", n)
+	}
+}
+
+// Ensure the handler returns a status 200 for an empty write body.
+func TestHandler_Write_EmptyBody(t *testing.T) {
+	h := NewHandler(false)
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		t.Fatal("unexpected write")
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader("")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler rejects write requests over the rate limit of a database.
+func TestHandler_Write_RateLimit(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error { return nil }
+
+	now := time.Unix(0, 0)
+	h.WriteRequestLimiter = httpd.NewRateLimiter(1)
+	h.WriteRequestLimiter.Now = func() time.Time { return now }
+
+	write := func(db string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", "/write?db="+db, strings.NewReader("cpu value=1 1")))
+		return w
+	}
+
+	if w := write("foo"); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := write("foo"); w.Code != 429 {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if v := w.Header().Get("Retry-After"); v != "1" {
+		t.Fatalf("unexpected Retry-After: %s", v)
+	}
+
+	// Other databases have their own limit.
+	if w := write("bar"); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// The limit is restored after a second.
+	now = now.Add(time.Second)
+	if w := write("foo"); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler only rejects a write by the points rate limit before
+// its first batch and charges the rest of the write to the limit.
+func TestHandler_Write_PointsRateLimit(t *testing.T) {
+	h := NewHandler(false)
+	h.WriteBatchSize = 2
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+
+	var n int
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		n += len(p.Points)
+		return nil
+	}
+
+	now := time.Unix(0, 0)
+	h.WritePointLimiter = httpd.NewRateLimiter(2)
+	h.WritePointLimiter.Now = func() time.Time { return now }
+
+	write := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader("cpu value=1 1\ncpu value=2 2\ncpu value=3 3\ncpu value=4 4")))
+		return w
+	}
+
+	// The whole write is accepted even though it exceeds the limit.
+	if w := write(); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if n != 4 {
+		t.Fatalf("unexpected points written: %d", n)
+	}
+
+	// The next write is rejected until the debt is paid off.
+	now = now.Add(time.Second)
+	if w := write(); w.Code != 429 {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if v := w.Header().Get("Retry-After"); v != "1" {
+		t.Fatalf("unexpected Retry-After: %s", v)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"rate limit exceeded, retry after 500ms","accepted":0,"rejected":2}` {
+		t.Fatalf("unexpected body: %s", body)
+	} else if n != 4 {
+		t.Fatalf("unexpected points written: %d", n)
+	}
+}
+
+// Ensure anonymous writes are rate limited by client address when limiting by user.
+func TestHandler_Write_RateLimitByUser_Anonymous(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error { return nil }
+
+	h.RateLimitBy = httpd.RateLimitByUser
+	h.WriteRequestLimiter = httpd.NewRateLimiter(1)
+	h.WriteRequestLimiter.Now = func() time.Time { return time.Unix(0, 0) }
+
+	write := func(addr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := MustNewRequest("POST", "/write?db=foo", strings.NewReader("cpu value=1 1"))
+		r.RemoteAddr = addr
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := write("10.0.0.1:1000"); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := write("10.0.0.1:2000"); w.Code != 429 {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := write("10.0.0.2:1000"); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the rate limiter removes buckets that have refilled.
+func TestRateLimiter_Sweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := httpd.NewRateLimiter(10)
+	l.Now = func() time.Time { return now }
+
+	if err := l.Take("foo", 100); err != nil {
+		t.Fatal(err)
+	} else if err := l.Take("bar", 1); err != nil {
+		t.Fatal(err)
+	} else if n := l.Len(); n != 2 {
+		t.Fatalf("unexpected buckets: %d", n)
+	}
+
+	// Only the bucket still in debt is kept.
+	now = now.Add(time.Minute)
+	if err := l.Take("baz", 1); err != nil {
+		t.Fatal(err)
+	} else if n := l.Len(); n != 1 {
+		t.Fatalf("unexpected buckets: %d", n)
+	}
+}
+
+// Ensure the handler writes the valid lines and reports the lines that failed to parse.
+func TestHandler_Write_PartialParseError(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+
+	var n int
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		n += len(p.Points)
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader("cpu value=1\ncpu\ncpu value=2\n")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if n != 2 {
+		t.Fatalf("unexpected points written: %d", n)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"partial write:\nunable to parse 'cpu': missing fields","accepted":2,"rejected":1,"errors":[{"line":2,"kind":"parse","message":"unable to parse 'cpu': missing fields"}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler reports points rejected by the points writer with their line numbers.
+func TestHandler_Write_PartialWriteError(t *testing.T) {
+	h := NewHandler(false)
+	h.WriteBatchSize = 2
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		// Reject the second point of every batch.
+		if len(p.Points) < 2 {
+			return nil
+		}
+		return &influxdb.PartialWriteError{Errors: []influxdb.PointError{
+			{Index: 1, Kind: influxdb.WriteErrorFieldTypeConflict, Err: influxdb.ErrFieldTypeConflict},
+		}}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader("cpu value=1\n\ncpu value=2\ncpu value=3\ncpu value=4\ncpu value=5")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	var resp httpd.WriteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.Accepted != 3 || resp.Rejected != 2 {
+		t.Fatalf("unexpected counts: accepted=%d rejected=%d", resp.Accepted, resp.Rejected)
+	} else if !reflect.DeepEqual(resp.Errors, []httpd.WriteError{
+		{Line: 3, Kind: influxdb.WriteErrorFieldTypeConflict, Message: "field type conflict"},
+		{Line: 5, Kind: influxdb.WriteErrorFieldTypeConflict, Message: "field type conflict"},
+	}) {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+}
+
+// Ensure the handler reports the kind of error that aborted a write.
+func TestHandler_Write_ErrRetentionPolicyNotFound(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		return influxdb.ErrRetentionPolicyNotFound(p.RetentionPolicy)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo&rp=bar", strings.NewReader("cpu value=1\ncpu value=2")))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"retention policy not found: bar","kind":"retention_policy_not_found","accepted":0,"rejected":2}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler returns a status 413 if the write body is larger than the maximum size.
+func TestHandler_Write_ErrBodyTooLarge(t *testing.T) {
+	h := NewHandler(false)
+	h.MaxBodySize = 16
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error { return nil }
+
+	// Body length is declared up front.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader("cpu value=1\ncpu value=2\n")))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	// Body length is unknown until it has been read.
+	w = httptest.NewRecorder()
+	r := MustNewRequest("POST", "/write?db=foo", strings.NewReader("cpu value=1\ncpu value=2\n"))
+	r.ContentLength = -1
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure the handler writes Prometheus remote write requests.
+func TestHandler_PromWrite(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaStore.DatabaseFn = func(name string) (*meta.DatabaseInfo, error) {
+		return &meta.DatabaseInfo{Name: name}, nil
+	}
+
+	var points []models.Point
+	h.PointsWriter.WritePointsFn = func(p *cluster.WritePointsRequest) error {
+		if p.Database != "foo" {
+			t.Fatalf("unexpected database: %s", p.Database)
+		} else if p.RetentionPolicy != "bar" {
+			t.Fatalf("unexpected retention policy: %s", p.RetentionPolicy)
+		}
+		points = p.Points
+		return nil
+	}
+
+	body := MustMarshalSnappyProto(&remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels:  []*remote.LabelPair{{Name: "__name__", Value: "cpu"}, {Name: "host", Value: "a"}},
+				Samples: []*remote.Sample{{Value: 1, TimestampMs: 1000}, {Value: 2, TimestampMs: 2000}},
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/write?db=foo&rp=bar", bytes.NewReader(body)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if len(points) != 2 {
+		t.Fatalf("unexpected point count: %d", len(points))
+	} else if s := points[0].String(); s != "cpu,host=a f64=1 1000000000" {
+		t.Fatalf("unexpected point: %s", s)
+	}
+
+	// Ensure bodies that are not snappy compressed are rejected.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/write?db=foo", strings.NewReader("cpu value=1")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler answers Prometheus remote read requests.
+func TestHandler_PromRead(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		if db != "foo" {
+			t.Fatalf("unexpected database: %s", db)
+		} else if s := q.String(); s != `SELECT f64 FROM foo..cpu WHERE time >= '1970-01-01T00:00:01Z' AND time <= '1970-01-01T00:00:02Z' AND host = 'a' GROUP BY *` {
+			t.Fatalf("unexpected query: %s", s)
+		}
+		return NewResultChan(
+			&influxql.Result{StatementID: 0, Series: models.Rows{{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "a"},
+				Columns: []string{"time", "f64"},
+				Values:  [][]interface{}{{time.Unix(1, 0), 1.5}},
+			}}},
+		), nil
+	}
+
+	body := MustMarshalSnappyProto(&remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000,
+			EndTimestampMs:   2000,
+			Matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_EQUAL, Name: "__name__", Value: "cpu"},
+				{Type: remote.MatchType_EQUAL, Name: "host", Value: "a"},
+			},
+		}},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/prom/read?db=foo", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if v := w.Header().Get("Content-Encoding"); v != "snappy" {
+		t.Fatalf("unexpected content encoding: %s", v)
+	}
+
+	buf, err := snappy.Decode(nil, w.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp remote.ReadResponse
+	if err := proto.Unmarshal(buf, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &remote.ReadResponse{Results: []*remote.QueryResult{{
+		Timeseries: []*remote.TimeSeries{{
+			Labels:  []*remote.LabelPair{{Name: "__name__", Value: "cpu"}, {Name: "host", Value: "a"}},
+			Samples: []*remote.Sample{{Value: 1.5, TimestampMs: 1000}},
+		}},
+	}}}
+	if !reflect.DeepEqual(&resp, exp) {
+		t.Fatalf("unexpected response: %s", resp.String())
+	}
+}
+
 // Ensure the handler returns a status 400 if the query is not passed in.
 func TestHandler_Query_ErrQueryRequired(t *testing.T) {
 	h := NewHandler(false)
@@ -285,6 +875,290 @@ func TestHandler_Query_ErrResult(t *testing.T) {
 	}
 }
 
+// Ensure the handler authenticates users with an API token.
+func TestHandler_Query_Token(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaStore.UsersFn = func() ([]meta.UserInfo, error) {
+		return []meta.UserInfo{{Name: "susy"}}, nil
+	}
+	h.MetaStore.AuthenticateTokenFn = func(token string) (*meta.UserInfo, error) {
+		if token != "abcd" {
+			return nil, meta.ErrAuthenticate
+		}
+		return &meta.UserInfo{Name: "susy"}, nil
+	}
+	h.QueryExecutor.AuthorizeFn = func(u *meta.UserInfo, q *influxql.Query, db string) error {
+		if u == nil || u.Name != "susy" {
+			t.Fatalf("unexpected user: %#v", u)
+		}
+		return nil
+	}
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(&influxql.Result{StatementID: 1}), nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.Header.Set("Authorization", "Token abcd")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// Ensure an invalid token is rejected.
+	w = httptest.NewRecorder()
+	r = MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.Header.Set("Authorization", "Token bad")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"authentication failed"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler authenticates users with a JWT bearer token.
+func TestHandler_Query_BearerToken(t *testing.T) {
+	h := NewHandler(true)
+	h.SharedSecret = "secret"
+	h.MetaStore.UsersFn = func() ([]meta.UserInfo, error) {
+		return []meta.UserInfo{{Name: "susy"}}, nil
+	}
+	h.MetaStore.UserFn = func(name string) (*meta.UserInfo, error) {
+		if name != "susy" {
+			return nil, nil
+		}
+		return &meta.UserInfo{Name: "susy"}, nil
+	}
+	h.QueryExecutor.AuthorizeFn = func(u *meta.UserInfo, q *influxql.Query, db string) error {
+		if u == nil || u.Name != "susy" {
+			t.Fatalf("unexpected user: %#v", u)
+		}
+		return nil
+	}
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(&influxql.Result{StatementID: 1}), nil
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	for i, tt := range []struct {
+		token  string
+		status int
+		err    string
+	}{
+		{token: MustSignJWT("HS256", "secret", fmt.Sprintf(`{"username":"susy","exp":%d}`, exp)), status: http.StatusOK},
+		{token: MustSignJWT("HS256", "bad", fmt.Sprintf(`{"username":"susy","exp":%d}`, exp)), status: http.StatusUnauthorized, err: "invalid token"},
+		{token: MustSignJWT("none", "secret", fmt.Sprintf(`{"username":"susy","exp":%d}`, exp)), status: http.StatusUnauthorized, err: "token must be signed with HS256"},
+		{token: MustSignJWT("HS256", "secret", `{"username":"susy","exp":1}`), status: http.StatusUnauthorized, err: "token expired"},
+		{token: MustSignJWT("HS256", "secret", fmt.Sprintf(`{"username":"susy","exp":%d.5}`, exp)), status: http.StatusOK},
+		{token: MustSignJWT("HS256", "secret", `{"username":"susy","exp":1.5}`), status: http.StatusUnauthorized, err: "token expired"},
+		{token: MustSignJWT("HS256", "secret", `{"username":"susy"}`), status: http.StatusUnauthorized, err: "token expiration required"},
+		{token: MustSignJWT("HS256", "secret", fmt.Sprintf(`{"username":"bob","exp":%d}`, exp)), status: http.StatusUnauthorized, err: "user not found"},
+		{token: "abc.def", status: http.StatusUnauthorized, err: "invalid token"},
+	} {
+		w := httptest.NewRecorder()
+		r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+		h.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%d. unexpected status: %d: %s", i, w.Code, w.Body.String())
+		} else if tt.err != "" && strings.TrimSpace(w.Body.String()) != fmt.Sprintf(`{"error":%q}`, tt.err) {
+			t.Errorf("%d. unexpected body: %s", i, w.Body.String())
+		}
+	}
+}
+
+// Ensure the handler authenticates users with a verified client certificate.
+func TestHandler_Query_ClientCertificate(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaStore.UsersFn = func() ([]meta.UserInfo, error) {
+		return []meta.UserInfo{{Name: "susy"}}, nil
+	}
+	h.MetaStore.UserFn = func(name string) (*meta.UserInfo, error) {
+		if name != "susy" {
+			return nil, nil
+		}
+		return &meta.UserInfo{Name: "susy"}, nil
+	}
+	h.QueryExecutor.AuthorizeFn = func(u *meta.UserInfo, q *influxql.Query, db string) error {
+		if u == nil || u.Name != "susy" {
+			t.Fatalf("unexpected user: %#v", u)
+		}
+		return nil
+	}
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(&influxql.Result{StatementID: 1}), nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "susy"}}}}}
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// Ensure a certificate for an unknown user requires other credentials.
+	w = httptest.NewRecorder()
+	r = MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "bob"}}}}}
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"unable to parse Basic Auth credentials"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// Ensure a certificate for an unknown user falls through to basic authentication.
+	h.MetaStore.AuthenticateFn = func(username, password string) (*meta.UserInfo, error) {
+		if username != "susy" || password != "pass" {
+			t.Fatalf("unexpected credentials: %s, %s", username, password)
+		}
+		return &meta.UserInfo{Name: "susy"}, nil
+	}
+	w = httptest.NewRecorder()
+	r = MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "bob"}}}}}
+	r.SetBasicAuth("susy", "pass")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler rejects queries over the concurrency limits.
+func TestHandler_Query_ConcurrencyLimit(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(&influxql.Result{StatementID: 1}), nil
+	}
+
+	query := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+		r.RemoteAddr = "127.0.0.1:1234"
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i, tt := range []struct {
+		limiter *httpd.QueryLimiter
+		err     string
+	}{
+		{limiter: httpd.NewQueryLimiter(1, 0, 0, 0), err: "too many concurrent queries for user"},
+		{limiter: httpd.NewQueryLimiter(0, 1, 0, 0), err: "too many queued queries"},
+		{limiter: httpd.NewQueryLimiter(0, 1, 1, time.Millisecond), err: "timeout waiting to run query"},
+	} {
+		h.QueryLimiter = tt.limiter
+
+		// Hold the only slot while querying from the same client.
+		release, err := h.QueryLimiter.Acquire("@127.0.0.1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w := query(); w.Code != 429 {
+			t.Fatalf("%d. unexpected status: %d: %s", i, w.Code, w.Body.String())
+		} else if v := w.Header().Get("Retry-After"); v != "1" {
+			t.Fatalf("%d. unexpected Retry-After: %s", i, v)
+		} else if body := strings.TrimSpace(w.Body.String()); body != fmt.Sprintf(`{"error":%q}`, tt.err) {
+			t.Fatalf("%d. unexpected body: %s", i, body)
+		}
+
+		// The query runs once the slot is released.
+		release()
+		if w := query(); w.Code != http.StatusOK {
+			t.Fatalf("%d. unexpected status: %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+}
+
+// Ensure anonymous queries are limited per client address when
+// authentication is disabled.
+func TestHandler_Query_ConcurrencyLimit_Anonymous(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryLimiter = httpd.NewQueryLimiter(1, 0, 0, 0)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(&influxql.Result{StatementID: 1}), nil
+	}
+
+	query := func(addr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar", nil)
+		r.RemoteAddr = addr
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Hold the only slot of one client.
+	release, err := h.QueryLimiter.Acquire("@10.0.0.1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	if w := query("10.0.0.1:1234"); w.Code != 429 {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if w := query("10.0.0.2:1234"); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the handler passes the query limits requested to the query executor.
+func TestHandler_Query_Limits(t *testing.T) {
+	h := NewHandler(false)
+	h.QueryExecutor.ExecuteQueryFn = func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+		return NewResultChan(&influxql.Result{StatementID: 1}), nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&timeout=5s&max_select_point=10&max_select_series=2&max_select_buckets=3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if exp := (tsdb.QueryLimits{Timeout: 5 * time.Second, MaxSelectPointN: 10, MaxSelectSeriesN: 2, MaxSelectBucketsN: 3}); h.QueryExecutor.Limits != exp {
+		t.Fatalf("unexpected limits: %+v", h.QueryExecutor.Limits)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&max_select_point=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"invalid max_select_point: \"x\""}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler serves statistics in the Prometheus format.
+func TestHandler_Metrics(t *testing.T) {
+	h := NewHandler(false)
+
+	// Statistics are not available without a monitor.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	h.Handler.Monitor = &HandlerMonitor{
+		StatisticsFn: func(tags map[string]string) ([]*monitor.Statistic, error) {
+			return []*monitor.Statistic{{
+				Name:   "httpd",
+				Tags:   map[string]string{"bind": ":8086"},
+				Values: map[string]interface{}{"req": int64(3)},
+			}}, nil
+		},
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if v := w.Header().Get("Content-Type"); v != monitor.PrometheusContentType {
+		t.Fatalf("unexpected content type: %s", v)
+	} else if body := w.Body.String(); body != "# TYPE influxdb_httpd_req_total counter\ninfluxdb_httpd_req_total{bind=\":8086\"} 3\n" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
 // Ensure the handler handles ping requests correctly.
 func TestHandler_Ping(t *testing.T) {
 	h := NewHandler(false)
@@ -451,6 +1325,7 @@ type Handler struct {
 	*httpd.Handler
 	MetaStore     HandlerMetaStore
 	QueryExecutor HandlerQueryExecutor
+	PointsWriter  HandlerPointsWriter
 	TSDBStore     HandlerTSDBStore
 }
 
@@ -462,16 +1337,19 @@ func NewHandler(requireAuthentication bool) *Handler {
 	}
 	h.Handler.MetaStore = &h.MetaStore
 	h.Handler.QueryExecutor = &h.QueryExecutor
+	h.Handler.PointsWriter = &h.PointsWriter
 	h.Handler.Version = "0.0.0"
 	return h
 }
 
 // HandlerMetaStore is a mock implementation of Handler.MetaStore.
 type HandlerMetaStore struct {
-	WaitForLeaderFn func(d time.Duration) error
-	DatabaseFn      func(name string) (*meta.DatabaseInfo, error)
-	AuthenticateFn  func(username, password string) (ui *meta.UserInfo, err error)
-	UsersFn         func() ([]meta.UserInfo, error)
+	WaitForLeaderFn     func(d time.Duration) error
+	DatabaseFn          func(name string) (*meta.DatabaseInfo, error)
+	AuthenticateFn      func(username, password string) (ui *meta.UserInfo, err error)
+	UserFn              func(name string) (*meta.UserInfo, error)
+	AuthenticateTokenFn func(token string) (ui *meta.UserInfo, err error)
+	UsersFn             func() ([]meta.UserInfo, error)
 }
 
 func (s *HandlerMetaStore) WaitForLeader(d time.Duration) error {
@@ -490,6 +1368,14 @@ func (s *HandlerMetaStore) Authenticate(username, password string) (ui *meta.Use
 	return s.AuthenticateFn(username, password)
 }
 
+func (s *HandlerMetaStore) User(name string) (*meta.UserInfo, error) {
+	return s.UserFn(name)
+}
+
+func (s *HandlerMetaStore) AuthenticateToken(token string) (ui *meta.UserInfo, err error) {
+	return s.AuthenticateTokenFn(token)
+}
+
 func (s *HandlerMetaStore) Users() ([]meta.UserInfo, error) {
 	return s.UsersFn()
 }
@@ -498,16 +1384,37 @@ func (s *HandlerMetaStore) Users() ([]meta.UserInfo, error) {
 type HandlerQueryExecutor struct {
 	AuthorizeFn    func(u *meta.UserInfo, q *influxql.Query, db string) error
 	ExecuteQueryFn func(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error)
+
+	Limits tsdb.QueryLimits // limits passed to the last query
 }
 
 func (e *HandlerQueryExecutor) Authorize(u *meta.UserInfo, q *influxql.Query, db string) error {
 	return e.AuthorizeFn(u, q, db)
 }
 
-func (e *HandlerQueryExecutor) ExecuteQuery(q *influxql.Query, db string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+func (e *HandlerQueryExecutor) ExecuteQueryWithLimits(q *influxql.Query, db string, chunkSize int, limits tsdb.QueryLimits, closing chan struct{}) (<-chan *influxql.Result, error) {
+	e.Limits = limits
 	return e.ExecuteQueryFn(q, db, chunkSize, closing)
 }
 
+// HandlerMonitor is a mock implementation of Handler.Monitor.
+type HandlerMonitor struct {
+	StatisticsFn func(tags map[string]string) ([]*monitor.Statistic, error)
+}
+
+func (m *HandlerMonitor) Statistics(tags map[string]string) ([]*monitor.Statistic, error) {
+	return m.StatisticsFn(tags)
+}
+
+// HandlerPointsWriter is a mock implementation of Handler.PointsWriter.
+type HandlerPointsWriter struct {
+	WritePointsFn func(p *cluster.WritePointsRequest) error
+}
+
+func (w *HandlerPointsWriter) WritePoints(p *cluster.WritePointsRequest) error {
+	return w.WritePointsFn(p)
+}
+
 // HandlerTSDBStore is a mock implementation of Handler.TSDBStore
 type HandlerTSDBStore struct {
 	CreateMapperFn func(shardID uint64, query string, chunkSize int) (tsdb.Mapper, error)
@@ -533,11 +1440,39 @@ func MustNewJSONRequest(method, urlStr string, body io.Reader) *http.Request {
 	return r
 }
 
+// MustSignJWT returns a JSON Web Token with claims signed by secret using HMAC-SHA256.
+// The alg header is set to alg regardless of the signing algorithm. Panic on error.
+func MustSignJWT(alg, secret, claims string) string {
+	hdr, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		panic(err)
+	}
+
+	s := encodeJWTBase64(hdr) + "." + encodeJWTBase64([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(s))
+	return s + "." + encodeJWTBase64(mac.Sum(nil))
+}
+
+// encodeJWTBase64 returns b encoded as unpadded base64url.
+func encodeJWTBase64(b []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(b), "=")
+}
+
 // matchRegex returns true if a s matches pattern.
 func matchRegex(pattern, s string) bool {
 	return regexp.MustCompile(pattern).MatchString(s)
 }
 
+// MustMarshalSnappyProto returns pb encoded as protobuf and compressed with snappy.
+func MustMarshalSnappyProto(pb proto.Message) []byte {
+	buf, err := proto.Marshal(pb)
+	if err != nil {
+		panic(err)
+	}
+	return snappy.Encode(nil, buf)
+}
+
 // NewResultChan returns a channel that sends all results and then closes.
 func NewResultChan(results ...*influxql.Result) <-chan *influxql.Result {
 	ch := make(chan *influxql.Result, len(results))
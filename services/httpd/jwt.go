@@ -0,0 +1,107 @@
+package httpd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// errInvalidJWT is returned when a bearer token is malformed or its
+	// signature does not match the shared secret.
+	errInvalidJWT = errors.New("invalid token")
+
+	// errJWTAlgorithm is returned when a bearer token is not signed with HS256.
+	errJWTAlgorithm = errors.New("token must be signed with HS256")
+
+	// errJWTExpired is returned when a bearer token is past its expiration.
+	errJWTExpired = errors.New("token expired")
+
+	// errJWTExpirationRequired is returned when a bearer token has no expiration.
+	errJWTExpirationRequired = errors.New("token expiration required")
+
+	// errJWTUsernameRequired is returned when a bearer token has no username claim.
+	errJWTUsernameRequired = errors.New("token username required")
+)
+
+// jwtHeader represents the JOSE header of a JSON Web Token.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+}
+
+// jwtClaims represents the claims of a JSON Web Token used for authentication.
+type jwtClaims struct {
+	Username  string  `json:"username"`
+	ExpiresAt float64 `json:"exp"`
+}
+
+// Expiration returns the time the token expires. The exp claim is a NumericDate
+// which may contain fractional seconds.
+func (c *jwtClaims) Expiration() time.Time {
+	sec := int64(c.ExpiresAt)
+	return time.Unix(sec, int64((c.ExpiresAt-float64(sec))*float64(time.Second)))
+}
+
+// parseJWT verifies a JSON Web Token signed with HS256 against secret and
+// returns its claims. Tokens without an expiration or expired at now are rejected.
+func parseJWT(token string, secret []byte, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidJWT
+	}
+
+	// Decode the header and ensure the algorithm is the one we verify with.
+	var hdr jwtHeader
+	if err := decodeJWTSegment(parts[0], &hdr); err != nil {
+		return nil, err
+	} else if hdr.Algorithm != "HS256" {
+		return nil, errJWTAlgorithm
+	}
+
+	// Verify the signature before trusting any claims.
+	sig, err := decodeJWTBase64(parts[2])
+	if err != nil {
+		return nil, errInvalidJWT
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errInvalidJWT
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	} else if claims.ExpiresAt == 0 {
+		return nil, errJWTExpirationRequired
+	} else if !now.Before(claims.Expiration()) {
+		return nil, errJWTExpired
+	} else if claims.Username == "" {
+		return nil, errJWTUsernameRequired
+	}
+	return &claims, nil
+}
+
+// decodeJWTSegment decodes a base64url encoded JSON segment of a token into v.
+func decodeJWTSegment(s string, v interface{}) error {
+	buf, err := decodeJWTBase64(s)
+	if err != nil {
+		return errInvalidJWT
+	}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return errInvalidJWT
+	}
+	return nil
+}
+
+// decodeJWTBase64 decodes an unpadded base64url segment of a token.
+func decodeJWTBase64(s string) ([]byte, error) {
+	if n := len(s) % 4; n != 0 {
+		s += strings.Repeat("=", 4-n)
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,273 @@
+package httpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// RateLimitByDatabase applies write rate limits to each database.
+	RateLimitByDatabase = "database"
+
+	// RateLimitByUser applies write rate limits to each user.
+	RateLimitByUser = "user"
+)
+
+var (
+	// ErrUserQueryLimit is returned when a user has too many queries running.
+	ErrUserQueryLimit = errors.New("too many concurrent queries for user")
+
+	// ErrQueryQueueFull is returned when the query wait queue is full.
+	ErrQueryQueueFull = errors.New("too many queued queries")
+
+	// ErrQueryQueueTimeout is returned when a query waited too long for a slot.
+	ErrQueryQueueTimeout = errors.New("timeout waiting to run query")
+)
+
+// RateLimitError is returned when a request exceeds a rate limit.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+// Error returns a string representation of the error.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// RetryAfterSeconds returns the wait in whole seconds for the Retry-After header.
+func (e *RateLimitError) RetryAfterSeconds() int {
+	if n := int(math.Ceil(e.RetryAfter.Seconds())); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// rateLimiterSweepInterval is how often full buckets are removed from a RateLimiter.
+const rateLimiterSweepInterval = time.Minute
+
+// RateLimiter limits the rate of events for a set of keys using one token
+// bucket per key. Each bucket refills at rate tokens per second and holds at
+// most rate tokens. A nil RateLimiter allows everything.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	buckets map[string]*tokenBucket
+	swept   time.Time
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// tokenBucket holds the tokens available for a single key.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing rate events per second per key.
+// Returns nil if rate is not positive.
+func NewRateLimiter(rate int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		rate:    float64(rate),
+		buckets: make(map[string]*tokenBucket),
+		Now:     time.Now,
+	}
+}
+
+// Take removes n tokens from the bucket for key. The tokens are taken as long
+// as the bucket is not empty so a single request larger than the rate can
+// still succeed; the bucket then stays in debt until it refills. Returns a
+// *RateLimitError if the bucket is empty.
+func (l *RateLimiter) Take(key string, n int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key)
+	if b.tokens <= 0 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return &RateLimitError{RetryAfter: wait}
+	}
+	b.tokens -= float64(n)
+	return nil
+}
+
+// Charge removes n tokens from the bucket for key even if it is empty. It is
+// used for the rest of a request once Take has let it through.
+func (l *RateLimiter) Charge(key string, n int) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bucket(key).tokens -= float64(n)
+}
+
+// Len returns the number of keys tracked by the limiter.
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// bucket returns the bucket for key refilled up to the current time.
+// Must be called with the lock held.
+func (l *RateLimiter) bucket(key string) *tokenBucket {
+	now := l.Now()
+	if now.Sub(l.swept) >= rateLimiterSweepInterval {
+		l.sweep(now)
+	}
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &tokenBucket{tokens: l.rate, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill the bucket for the time elapsed since the last take.
+	b.tokens = math.Min(l.rate, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	return b
+}
+
+// sweep removes the buckets that have refilled since they were last used.
+// A full bucket behaves the same as a missing one so keys that are no longer
+// written to don't hold memory. Must be called with the lock held.
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.rate {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
+
+// QueryLimiter limits the number of queries running at once, both per user
+// and in total. Queries over the total limit wait in a bounded queue until a
+// slot is released. A nil QueryLimiter allows everything.
+type QueryLimiter struct {
+	mu        sync.Mutex
+	perUser   int
+	active    map[string]int
+	slots     chan struct{}
+	maxQueued int
+	queued    int
+	timeout   time.Duration
+}
+
+// NewQueryLimiter returns a limiter allowing perUser concurrent queries for
+// each user and max concurrent queries in total. At most maxQueued queries
+// wait for up to timeout when max is reached. Zero limits are unlimited and
+// nil is returned if every limit is zero.
+func NewQueryLimiter(perUser, max, maxQueued int, timeout time.Duration) *QueryLimiter {
+	if perUser <= 0 && max <= 0 {
+		return nil
+	}
+
+	l := &QueryLimiter{
+		perUser:   perUser,
+		active:    make(map[string]int),
+		maxQueued: maxQueued,
+		timeout:   timeout,
+	}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+	return l
+}
+
+// Acquire reserves a query slot for user and returns a function that releases
+// it. If no slot is free, Acquire waits in the queue until one is released,
+// closing is closed or the queue timeout expires.
+func (l *QueryLimiter) Acquire(user string, closing <-chan struct{}) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.perUser > 0 && l.active[user] >= l.perUser {
+		l.mu.Unlock()
+		return nil, ErrUserQueryLimit
+	}
+	l.active[user]++
+	l.mu.Unlock()
+
+	if err := l.acquireSlot(closing); err != nil {
+		l.releaseUser(user)
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if l.slots != nil {
+				<-l.slots
+			}
+			l.releaseUser(user)
+		})
+	}, nil
+}
+
+// acquireSlot reserves one of the global query slots.
+func (l *QueryLimiter) acquireSlot(closing <-chan struct{}) error {
+	if l.slots == nil {
+		return nil
+	}
+
+	// Take a free slot without queueing if possible.
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	if l.queued >= l.maxQueued {
+		l.mu.Unlock()
+		return ErrQueryQueueFull
+	}
+	l.queued++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrQueryQueueTimeout
+	case <-closing:
+		return ErrQueryQueueTimeout
+	}
+}
+
+// releaseUser decrements the number of running queries for user.
+func (l *QueryLimiter) releaseUser(user string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[user]--; l.active[user] <= 0 {
+		delete(l.active, user)
+	}
+}
@@ -0,0 +1,434 @@
+package httpd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdb/influxdb/influxql"
+	"github.com/influxdb/influxdb/models"
+)
+
+// Content types supported by the query endpoint.
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeCSV     = "text/csv"
+	contentTypeMsgPack = "application/x-msgpack"
+)
+
+// responseFormatter encodes query responses onto a writer. A formatter may
+// be called several times for the same request when results are chunked so
+// implementations can keep state between calls.
+type responseFormatter interface {
+	// ContentType returns the value for the content-type header.
+	ContentType() string
+
+	// WriteResponse encodes resp to w and returns the number of bytes written.
+	WriteResponse(w io.Writer, resp Response) (int, error)
+}
+
+// newResponseFormatter returns the formatter matching the Accept header of r.
+// JSON is returned when no supported content type is requested.
+func newResponseFormatter(r *http.Request, pretty bool) responseFormatter {
+	for _, typ := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(typ))
+		if err != nil {
+			continue
+		}
+
+		switch mt {
+		case contentTypeJSON:
+			return &jsonFormatter{pretty: pretty}
+		case contentTypeCSV:
+			return &csvFormatter{}
+		case contentTypeMsgPack:
+			return &msgpackFormatter{}
+		}
+	}
+	return &jsonFormatter{pretty: pretty}
+}
+
+// jsonFormatter encodes responses as JSON.
+type jsonFormatter struct {
+	pretty bool
+}
+
+// ContentType returns the JSON content type.
+func (f *jsonFormatter) ContentType() string { return contentTypeJSON }
+
+// WriteResponse writes resp to w as a JSON document.
+func (f *jsonFormatter) WriteResponse(w io.Writer, resp Response) (int, error) {
+	return w.Write(MarshalJSON(resp, f.pretty))
+}
+
+// csvFormatter encodes responses as CSV with one line per point. Every line
+// begins with the series name and tags followed by the row's columns. A
+// header line is written whenever the set of columns changes.
+type csvFormatter struct {
+	columns []string
+}
+
+// ContentType returns the CSV content type.
+func (f *csvFormatter) ContentType() string { return contentTypeCSV }
+
+// WriteResponse writes resp to w as CSV.
+func (f *csvFormatter) WriteResponse(w io.Writer, resp Response) (int, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+
+	if resp.Err != nil {
+		f.writeError(cw, resp.Err)
+	}
+
+	for _, result := range resp.Results {
+		if result.Err != nil {
+			f.writeError(cw, result.Err)
+			continue
+		}
+
+		for _, row := range result.Series {
+			if !stringsEqual(f.columns, row.Columns) {
+				f.columns = row.Columns
+				cw.Write(append([]string{"name", "tags"}, row.Columns...))
+			}
+
+			tags := csvTags(row.Tags)
+			for _, values := range row.Values {
+				record := make([]string, 0, len(values)+2)
+				record = append(record, row.Name, tags)
+				for _, v := range values {
+					record = append(record, csvValue(v))
+				}
+				cw.Write(record)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return 0, err
+	}
+	return w.Write(buf.Bytes())
+}
+
+// writeError writes err as a single "error" column. The next series always
+// writes a new header afterwards.
+func (f *csvFormatter) writeError(cw *csv.Writer, err error) {
+	f.columns = nil
+	cw.Write([]string{"error"})
+	cw.Write([]string{err.Error()})
+}
+
+// csvTags returns tags as a comma separated list of sorted key=value pairs.
+func csvTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + tags[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// csvValue returns the string representation of a single field value.
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// stringsEqual returns true if a and b contain the same strings in the same order.
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// msgpackFormatter encodes responses as MessagePack. The document has the
+// same layout as the JSON response but integers and floats keep their types.
+// Chunked responses are written as a stream of consecutive documents.
+type msgpackFormatter struct{}
+
+// ContentType returns the MessagePack content type.
+func (f *msgpackFormatter) ContentType() string { return contentTypeMsgPack }
+
+// WriteResponse writes resp to w as a MessagePack document.
+func (f *msgpackFormatter) WriteResponse(w io.Writer, resp Response) (int, error) {
+	var enc msgpackEncoder
+
+	n := 0
+	if len(resp.Results) > 0 {
+		n++
+	}
+	if resp.Err != nil {
+		n++
+	}
+	enc.writeMapHeader(n)
+
+	if len(resp.Results) > 0 {
+		enc.writeString("results")
+		enc.writeArrayHeader(len(resp.Results))
+		for _, result := range resp.Results {
+			enc.writeResult(result)
+		}
+	}
+	if resp.Err != nil {
+		enc.writeString("error")
+		enc.writeString(resp.Err.Error())
+	}
+
+	return w.Write(enc.buf.Bytes())
+}
+
+// msgpackEncoder is a minimal MessagePack encoder for query results.
+type msgpackEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *msgpackEncoder) writeResult(r *influxql.Result) {
+	n := 0
+	if len(r.Series) > 0 {
+		n++
+	}
+	if r.Err != nil {
+		n++
+	}
+	e.writeMapHeader(n)
+
+	if len(r.Series) > 0 {
+		e.writeString("series")
+		e.writeArrayHeader(len(r.Series))
+		for _, row := range r.Series {
+			e.writeRow(row)
+		}
+	}
+	if r.Err != nil {
+		e.writeString("error")
+		e.writeString(r.Err.Error())
+	}
+}
+
+func (e *msgpackEncoder) writeRow(row *models.Row) {
+	n := 0
+	if row.Name != "" {
+		n++
+	}
+	if len(row.Tags) > 0 {
+		n++
+	}
+	if len(row.Columns) > 0 {
+		n++
+	}
+	if len(row.Values) > 0 {
+		n++
+	}
+	e.writeMapHeader(n)
+
+	if row.Name != "" {
+		e.writeString("name")
+		e.writeString(row.Name)
+	}
+	if len(row.Tags) > 0 {
+		keys := make([]string, 0, len(row.Tags))
+		for k := range row.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		e.writeString("tags")
+		e.writeMapHeader(len(keys))
+		for _, k := range keys {
+			e.writeString(k)
+			e.writeString(row.Tags[k])
+		}
+	}
+	if len(row.Columns) > 0 {
+		e.writeString("columns")
+		e.writeArrayHeader(len(row.Columns))
+		for _, c := range row.Columns {
+			e.writeString(c)
+		}
+	}
+	if len(row.Values) > 0 {
+		e.writeString("values")
+		e.writeArrayHeader(len(row.Values))
+		for _, values := range row.Values {
+			e.writeArrayHeader(len(values))
+			for _, v := range values {
+				e.writeValue(v)
+			}
+		}
+	}
+}
+
+func (e *msgpackEncoder) writeValue(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		e.buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case int:
+		e.writeInt(int64(v))
+	case int32:
+		e.writeInt(int64(v))
+	case int64:
+		e.writeInt(v)
+	case uint64:
+		e.writeUint(v)
+	case float32:
+		e.buf.WriteByte(0xca)
+		e.writeUint32(math.Float32bits(v))
+	case float64:
+		e.buf.WriteByte(0xcb)
+		e.writeUint64(math.Float64bits(v))
+	case string:
+		e.writeString(v)
+	case time.Time:
+		e.writeString(v.Format(time.RFC3339Nano))
+	case []interface{}:
+		e.writeArrayHeader(len(v))
+		for _, elem := range v {
+			e.writeValue(elem)
+		}
+	default:
+		e.writeString(fmt.Sprint(v))
+	}
+}
+
+func (e *msgpackEncoder) writeInt(v int64) {
+	switch {
+	case v >= 0:
+		e.writeUint(uint64(v))
+	case v >= -32:
+		e.buf.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		e.buf.WriteByte(0xd0)
+		e.buf.WriteByte(byte(v))
+	case v >= math.MinInt16:
+		e.buf.WriteByte(0xd1)
+		e.writeUint16(uint16(v))
+	case v >= math.MinInt32:
+		e.buf.WriteByte(0xd2)
+		e.writeUint32(uint32(v))
+	default:
+		e.buf.WriteByte(0xd3)
+		e.writeUint64(uint64(v))
+	}
+}
+
+func (e *msgpackEncoder) writeUint(v uint64) {
+	switch {
+	case v <= 0x7f:
+		e.buf.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		e.buf.WriteByte(0xcc)
+		e.buf.WriteByte(byte(v))
+	case v <= math.MaxUint16:
+		e.buf.WriteByte(0xcd)
+		e.writeUint16(uint16(v))
+	case v <= math.MaxUint32:
+		e.buf.WriteByte(0xce)
+		e.writeUint32(uint32(v))
+	default:
+		e.buf.WriteByte(0xcf)
+		e.writeUint64(v)
+	}
+}
+
+func (e *msgpackEncoder) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(0xd9)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xda)
+		e.writeUint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdb)
+		e.writeUint32(uint32(n))
+	}
+	e.buf.WriteString(s)
+}
+
+func (e *msgpackEncoder) writeArrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xdc)
+		e.writeUint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdd)
+		e.writeUint32(uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) writeMapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xde)
+		e.writeUint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdf)
+		e.writeUint32(uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) writeUint16(v uint16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *msgpackEncoder) writeUint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *msgpackEncoder) writeUint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.buf.Write(b[:])
+}
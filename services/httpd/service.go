@@ -9,8 +9,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/influxdb/influxdb"
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
 )
 
 // statistics gathered by the httpd package.
@@ -24,16 +26,29 @@ const (
 	statQueryRequestBytesTransmitted = "queryRespBytes"    // Sum of all bytes returned in query reponses
 	statPointsWrittenOK              = "pointsWrittenOK"   // Number of points written OK
 	statPointsWrittenFail            = "pointsWrittenFail" // Number of points that failed to be written
+	statPointsParseFail              = "pointsParseFail"   // Number of lines that failed to parse
 	statAuthFail                     = "authFail"          // Number of authentication failures
+	statWriteRequestLimited          = "writeReqLimited"   // Number of write requests rejected by the rate limit
+	statPointsLimited                = "pointsLimited"     // Number of points rejected by the rate limit
+	statQueryRequestLimited          = "queryReqLimited"   // Number of query requests rejected by the concurrency limit
+	statQueriesActive                = "queriesActive"     // Number of queries currently running
+	statPromWriteRequest             = "promWriteReq"      // Number of Prometheus remote write requests served
+	statPromReadRequest              = "promReadReq"       // Number of Prometheus remote read requests served
 )
 
+func init() {
+	influxdb.RegisterGauges("httpd", statQueriesActive)
+}
+
 // Service manages the listener and handler for an HTTP endpoint.
 type Service struct {
-	ln    net.Listener
-	addr  string
-	https bool
-	cert  string
-	err   chan error
+	ln         net.Listener
+	addr       string
+	https      bool
+	cert       string
+	clientCA   string
+	clientAuth string
+	err        chan error
 
 	Handler *Handler
 
@@ -50,10 +65,12 @@ func NewService(c Config) *Service {
 	statMap := influxdb.NewStatistics(key, "httpd", tags)
 
 	s := &Service{
-		addr:  c.BindAddress,
-		https: c.HTTPSEnabled,
-		cert:  c.HTTPSCertificate,
-		err:   make(chan error),
+		addr:       c.BindAddress,
+		https:      c.HTTPSEnabled,
+		cert:       c.HTTPSCertificate,
+		clientCA:   c.HTTPSClientCA,
+		clientAuth: c.HTTPSClientAuth,
+		err:        make(chan error),
 		Handler: NewHandler(
 			c.AuthEnabled,
 			c.LogEnabled,
@@ -63,6 +80,14 @@ func NewService(c Config) *Service {
 		Logger: log.New(os.Stderr, "[httpd] ", log.LstdFlags),
 	}
 	s.Handler.Logger = s.Logger
+	s.Handler.MaxBodySize = c.MaxBodySize
+	s.Handler.WriteBatchSize = c.WriteBatchSize
+	s.Handler.SharedSecret = c.SharedSecret
+	s.Handler.RateLimitBy = c.RateLimitBy
+	s.Handler.WriteRequestLimiter = NewRateLimiter(c.MaxWriteRequestsPerSecond)
+	s.Handler.WritePointLimiter = NewRateLimiter(c.MaxWritePointsPerSecond)
+	s.Handler.QueryLimiter = NewQueryLimiter(c.MaxConcurrentQueriesPerUser, c.MaxConcurrentQueries,
+		c.MaxQueuedQueries, time.Duration(c.QueryQueueTimeout))
 	return s
 }
 
@@ -73,14 +98,12 @@ func (s *Service) Open() error {
 
 	// Open listener.
 	if s.https {
-		cert, err := tls.LoadX509KeyPair(s.cert, s.cert)
+		config, err := tlsconfig.New(s.cert, s.clientCA, s.clientAuth)
 		if err != nil {
 			return err
 		}
 
-		listener, err := tls.Listen("tcp", s.addr, &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		})
+		listener, err := tls.Listen("tcp", s.addr, config)
 		if err != nil {
 			return err
 		}
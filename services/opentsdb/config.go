@@ -3,6 +3,7 @@ package opentsdb
 import (
 	"time"
 
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
 	"github.com/influxdb/influxdb/toml"
 )
 
@@ -38,6 +39,8 @@ type Config struct {
 	ConsistencyLevel string        `toml:"consistency-level"`
 	TLSEnabled       bool          `toml:"tls-enabled"`
 	Certificate      string        `toml:"certificate"`
+	TLSClientCA      string        `toml:"tls-client-ca"`
+	TLSClientAuth    string        `toml:"tls-client-auth"`
 	BatchSize        int           `toml:"batch-size"`
 	BatchPending     int           `toml:"batch-pending"`
 	BatchTimeout     toml.Duration `toml:"batch-timeout"`
@@ -53,6 +56,7 @@ func NewConfig() Config {
 		ConsistencyLevel: DefaultConsistencyLevel,
 		TLSEnabled:       false,
 		Certificate:      "/etc/ssl/influxdb.pem",
+		TLSClientAuth:    tlsconfig.ClientAuthNone,
 		BatchSize:        DefaultBatchSize,
 		BatchPending:     DefaultBatchPending,
 		BatchTimeout:     toml.Duration(DefaultBatchTimeout),
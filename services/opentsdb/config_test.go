@@ -17,6 +17,8 @@ database = "xxx"
 consistency-level ="all"
 tls-enabled = true
 certificate = "/etc/ssl/cert.pem"
+tls-client-ca = "/etc/ssl/ca.pem"
+tls-client-auth = "required"
 log-point-errors = true
 `, &c); err != nil {
 		t.Fatal(err)
@@ -35,6 +37,10 @@ log-point-errors = true
 		t.Fatalf("unexpected tls-enabled: %v", c.TLSEnabled)
 	} else if c.Certificate != "/etc/ssl/cert.pem" {
 		t.Fatalf("unexpected certificate: %s", c.Certificate)
+	} else if c.TLSClientCA != "/etc/ssl/ca.pem" {
+		t.Fatalf("unexpected tls-client-ca: %s", c.TLSClientCA)
+	} else if c.TLSClientAuth != "required" {
+		t.Fatalf("unexpected tls-client-auth: %s", c.TLSClientAuth)
 	} else if !c.LogPointErrors {
 		t.Fatalf("unexpected log-point-errors: %v", c.LogPointErrors)
 	}
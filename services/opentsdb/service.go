@@ -20,6 +20,7 @@ import (
 	"github.com/influxdb/influxdb/cluster"
 	"github.com/influxdb/influxdb/meta"
 	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/pkg/tlsconfig"
 	"github.com/influxdb/influxdb/tsdb"
 )
 
@@ -45,17 +46,23 @@ const (
 	statDroppedPointsInvalid     = "droppedPointsInvalid"
 )
 
+func init() {
+	influxdb.RegisterGauges("opentsdb", statConnectionsActive, statTelnetConnectionsActive)
+}
+
 // Service manages the listener and handler for an HTTP endpoint.
 type Service struct {
 	ln     net.Listener  // main listener
 	httpln *chanListener // http channel-based listener
 
-	mu   sync.Mutex
-	wg   sync.WaitGroup
-	done chan struct{}
-	err  chan error
-	tls  bool
-	cert string
+	mu         sync.Mutex
+	wg         sync.WaitGroup
+	done       chan struct{}
+	err        chan error
+	tls        bool
+	cert       string
+	clientCA   string
+	clientAuth string
 
 	BindAddress      string
 	Database         string
@@ -92,6 +99,8 @@ func NewService(c Config) (*Service, error) {
 		done:             make(chan struct{}),
 		tls:              c.TLSEnabled,
 		cert:             c.Certificate,
+		clientCA:         c.TLSClientCA,
+		clientAuth:       c.TLSClientAuth,
 		err:              make(chan error),
 		BindAddress:      c.BindAddress,
 		Database:         c.Database,
@@ -138,14 +147,12 @@ func (s *Service) Open() error {
 
 	// Open listener.
 	if s.tls {
-		cert, err := tls.LoadX509KeyPair(s.cert, s.cert)
+		config, err := tlsconfig.New(s.cert, s.clientCA, s.clientAuth)
 		if err != nil {
 			return err
 		}
 
-		listener, err := tls.Listen("tcp", s.BindAddress, &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		})
+		listener, err := tls.Listen("tcp", s.BindAddress, config)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,244 @@
+// Package prometheus converts between the Prometheus remote storage protocol
+// and InfluxDB points and queries.
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/influxdb/influxdb/influxql"
+	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/services/prometheus/remote"
+)
+
+const (
+	// MetricNameLabel is the Prometheus label holding the metric name.
+	// It is stored as the measurement name rather than as a tag.
+	MetricNameLabel = "__name__"
+
+	// FieldName is the field that Prometheus sample values are stored in.
+	FieldName = "f64"
+)
+
+var (
+	// ErrNaNDropped is returned when samples with NaN values were dropped
+	// because NaN cannot be stored.
+	ErrNaNDropped = errors.New("dropped unsupported NaN value")
+
+	// ErrMetricNameRequired is returned when a time series has no metric name.
+	ErrMetricNameRequired = errors.New("metric name label required")
+)
+
+// WriteRequestToPoints converts a Prometheus remote write request into points.
+// The metric name is the measurement, the other labels are tags and the sample
+// value is stored in the "f64" field. Samples with NaN values are dropped and
+// ErrNaNDropped is returned along with the remaining points.
+func WriteRequestToPoints(req *remote.WriteRequest) ([]models.Point, error) {
+	var maxPoints int
+	for _, ts := range req.GetTimeseries() {
+		maxPoints += len(ts.GetSamples())
+	}
+	points := make([]models.Point, 0, maxPoints)
+
+	var dropped bool
+	for _, ts := range req.GetTimeseries() {
+		var name string
+		tags := make(models.Tags, len(ts.GetLabels()))
+		for _, l := range ts.GetLabels() {
+			if l.Name == MetricNameLabel {
+				name = l.Value
+				continue
+			}
+			tags[l.Name] = l.Value
+		}
+		if name == "" {
+			return nil, ErrMetricNameRequired
+		}
+
+		for _, s := range ts.GetSamples() {
+			if math.IsNaN(s.Value) {
+				dropped = true
+				continue
+			}
+
+			t := time.Unix(0, s.TimestampMs*int64(time.Millisecond))
+			pt, err := models.NewPoint(name, tags, models.Fields{FieldName: s.Value}, t)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, pt)
+		}
+	}
+
+	if dropped {
+		return points, ErrNaNDropped
+	}
+	return points, nil
+}
+
+// ReadRequestToInfluxQLQuery converts a Prometheus remote read request into an
+// InfluxQL query with one SELECT statement per Prometheus query. The metric
+// name matcher selects the measurements and the other label matchers become
+// tag predicates.
+func ReadRequestToInfluxQLQuery(req *remote.ReadRequest, db, rp string) (*influxql.Query, error) {
+	q := &influxql.Query{}
+	for _, rq := range req.GetQueries() {
+		stmt, err := selectStatement(rq, db, rp)
+		if err != nil {
+			return nil, err
+		}
+		q.Statements = append(q.Statements, stmt)
+	}
+	return q, nil
+}
+
+// selectStatement returns the SELECT statement for a single Prometheus query.
+func selectStatement(q *remote.Query, db, rp string) (*influxql.SelectStatement, error) {
+	// Select every measurement unless a metric name is given.
+	m := &influxql.Measurement{
+		Database:        db,
+		RetentionPolicy: rp,
+		Regex:           &influxql.RegexLiteral{Val: regexp.MustCompile(".+")},
+	}
+
+	// Restrict the query to the requested time range.
+	var cond influxql.Expr = &influxql.BinaryExpr{
+		Op: influxql.AND,
+		LHS: &influxql.BinaryExpr{
+			Op:  influxql.GTE,
+			LHS: &influxql.VarRef{Val: "time"},
+			RHS: &influxql.TimeLiteral{Val: time.Unix(0, q.StartTimestampMs*int64(time.Millisecond)).UTC()},
+		},
+		RHS: &influxql.BinaryExpr{
+			Op:  influxql.LTE,
+			LHS: &influxql.VarRef{Val: "time"},
+			RHS: &influxql.TimeLiteral{Val: time.Unix(0, q.EndTimestampMs*int64(time.Millisecond)).UTC()},
+		},
+	}
+
+	for _, lm := range q.GetMatchers() {
+		if lm.Name == MetricNameLabel {
+			switch lm.Type {
+			case remote.MatchType_EQUAL:
+				m.Name, m.Regex = lm.Value, nil
+			case remote.MatchType_REGEX_MATCH:
+				re, err := anchoredRegex(lm.Value)
+				if err != nil {
+					return nil, err
+				}
+				m.Regex = &influxql.RegexLiteral{Val: re}
+			default:
+				return nil, fmt.Errorf("unsupported match type for %s: %s", MetricNameLabel, lm.Type)
+			}
+			continue
+		}
+
+		expr, err := tagPredicate(lm)
+		if err != nil {
+			return nil, err
+		}
+		cond = &influxql.BinaryExpr{Op: influxql.AND, LHS: cond, RHS: expr}
+	}
+
+	return &influxql.SelectStatement{
+		Fields:     influxql.Fields{{Expr: &influxql.VarRef{Val: FieldName}}},
+		Sources:    influxql.Sources{m},
+		Condition:  cond,
+		Dimensions: influxql.Dimensions{{Expr: &influxql.Wildcard{}}},
+	}, nil
+}
+
+// tagPredicate returns the InfluxQL expression for a label matcher.
+func tagPredicate(lm *remote.LabelMatcher) (influxql.Expr, error) {
+	tag := &influxql.VarRef{Val: lm.Name}
+	switch lm.Type {
+	case remote.MatchType_EQUAL:
+		return &influxql.BinaryExpr{Op: influxql.EQ, LHS: tag, RHS: &influxql.StringLiteral{Val: lm.Value}}, nil
+	case remote.MatchType_NOT_EQUAL:
+		return &influxql.BinaryExpr{Op: influxql.NEQ, LHS: tag, RHS: &influxql.StringLiteral{Val: lm.Value}}, nil
+	case remote.MatchType_REGEX_MATCH, remote.MatchType_REGEX_NO_MATCH:
+		re, err := anchoredRegex(lm.Value)
+		if err != nil {
+			return nil, err
+		}
+		op := influxql.EQREGEX
+		if lm.Type == remote.MatchType_REGEX_NO_MATCH {
+			op = influxql.NEQREGEX
+		}
+		return &influxql.BinaryExpr{Op: op, LHS: tag, RHS: &influxql.RegexLiteral{Val: re}}, nil
+	default:
+		return nil, fmt.Errorf("unknown match type: %d", lm.Type)
+	}
+}
+
+// anchoredRegex compiles a Prometheus regex. Prometheus regexes must match
+// the whole label value.
+func anchoredRegex(s string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + s + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %s", s, err)
+	}
+	return re, nil
+}
+
+// RowsToTimeSeries converts the rows returned by a query created by
+// ReadRequestToInfluxQLQuery into Prometheus time series. Rows for the same
+// series are merged into a single time series.
+func RowsToTimeSeries(rows []*models.Row) []*remote.TimeSeries {
+	var a []*remote.TimeSeries
+	index := make(map[string]*remote.TimeSeries)
+	for _, row := range rows {
+		key := string(models.MakeKey([]byte(row.Name), row.Tags))
+		ts := index[key]
+		if ts == nil {
+			ts = &remote.TimeSeries{Labels: labels(row.Name, row.Tags)}
+			index[key] = ts
+			a = append(a, ts)
+		}
+
+		for _, values := range row.Values {
+			if len(values) < 2 {
+				continue
+			}
+			t, ok := values[0].(time.Time)
+			if !ok {
+				continue
+			}
+			v, ok := values[1].(float64)
+			if !ok {
+				continue
+			}
+			ts.Samples = append(ts.Samples, &remote.Sample{
+				Value:       v,
+				TimestampMs: t.UnixNano() / int64(time.Millisecond),
+			})
+		}
+	}
+	return a
+}
+
+// labels returns the Prometheus labels of a series sorted by name.
+func labels(name string, tags map[string]string) []*remote.LabelPair {
+	a := make([]*remote.LabelPair, 0, len(tags)+1)
+	a = append(a, &remote.LabelPair{Name: MetricNameLabel, Value: name})
+	for k, v := range tags {
+		// Prometheus has no empty labels so skip tags the series does not have.
+		if v == "" {
+			continue
+		}
+		a = append(a, &remote.LabelPair{Name: k, Value: v})
+	}
+	sort.Sort(labelPairs(a))
+	return a
+}
+
+// labelPairs sorts label pairs by name.
+type labelPairs []*remote.LabelPair
+
+func (a labelPairs) Len() int           { return len(a) }
+func (a labelPairs) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a labelPairs) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
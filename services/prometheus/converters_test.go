@@ -0,0 +1,161 @@
+package prometheus_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdb/influxdb/models"
+	"github.com/influxdb/influxdb/services/prometheus"
+	"github.com/influxdb/influxdb/services/prometheus/remote"
+)
+
+// Ensure a write request is converted into points.
+func TestWriteRequestToPoints(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels: []*remote.LabelPair{
+					{Name: "__name__", Value: "cpu"},
+					{Name: "host", Value: "a"},
+				},
+				Samples: []*remote.Sample{
+					{Value: 1.5, TimestampMs: 1000},
+					{Value: math.NaN(), TimestampMs: 2000},
+					{Value: 2, TimestampMs: 3000},
+				},
+			},
+		},
+	}
+
+	// Ensure the request survives a protobuf round trip.
+	buf, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other remote.WriteRequest
+	if err := proto.Unmarshal(buf, &other); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := prometheus.WriteRequestToPoints(&other)
+	if err != prometheus.ErrNaNDropped {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(points) != 2 {
+		t.Fatalf("unexpected point count: %d", len(points))
+	}
+
+	exp := []string{"cpu,host=a f64=1.5 1000000000", "cpu,host=a f64=2 3000000000"}
+	for i, pt := range points {
+		if pt.String() != exp[i] {
+			t.Errorf("%d. unexpected point: %s", i, pt.String())
+		}
+	}
+}
+
+// Ensure a time series without a metric name is rejected.
+func TestWriteRequestToPoints_ErrMetricNameRequired(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels:  []*remote.LabelPair{{Name: "host", Value: "a"}},
+				Samples: []*remote.Sample{{Value: 1, TimestampMs: 1000}},
+			},
+		},
+	}
+	if _, err := prometheus.WriteRequestToPoints(req); err != prometheus.ErrMetricNameRequired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a read request is converted into an InfluxQL query.
+func TestReadRequestToInfluxQLQuery(t *testing.T) {
+	for i, tt := range []struct {
+		matchers []*remote.LabelMatcher
+		s        string
+		err      string
+	}{
+		{
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_EQUAL, Name: "__name__", Value: "cpu"},
+				{Type: remote.MatchType_EQUAL, Name: "host", Value: "a"},
+				{Type: remote.MatchType_NOT_EQUAL, Name: "region", Value: "west"},
+			},
+			s: `SELECT f64 FROM db0.rp0.cpu WHERE time >= '1970-01-01T00:00:01Z' AND time <= '1970-01-01T00:00:02Z' AND host = 'a' AND region != 'west' GROUP BY *`,
+		},
+		{
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_REGEX_MATCH, Name: "__name__", Value: "cpu|mem"},
+				{Type: remote.MatchType_REGEX_NO_MATCH, Name: "host", Value: "a.*"},
+			},
+			s: `SELECT f64 FROM db0.rp0./^(?:cpu|mem)$/ WHERE time >= '1970-01-01T00:00:01Z' AND time <= '1970-01-01T00:00:02Z' AND host !~ /^(?:a.*)$/ GROUP BY *`,
+		},
+		{
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_NOT_EQUAL, Name: "__name__", Value: "cpu"},
+			},
+			err: `unsupported match type for __name__: NOT_EQUAL`,
+		},
+		{
+			matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchType_REGEX_MATCH, Name: "host", Value: "("},
+			},
+			err: "invalid regex \"(\": error parsing regexp: missing closing ): `^(?:()$`",
+		},
+	} {
+		req := &remote.ReadRequest{
+			Queries: []*remote.Query{{StartTimestampMs: 1000, EndTimestampMs: 2000, Matchers: tt.matchers}},
+		}
+
+		q, err := prometheus.ReadRequestToInfluxQLQuery(req, "db0", "rp0")
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%d. unexpected error: %v", i, err)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if s := q.String(); s != tt.s {
+			t.Errorf("%d. unexpected query:\nexp: %s\ngot: %s", i, tt.s, s)
+		}
+	}
+}
+
+// Ensure query rows are converted into time series.
+func TestRowsToTimeSeries(t *testing.T) {
+	rows := []*models.Row{
+		{
+			Name:    "cpu",
+			Tags:    map[string]string{"host": "a", "region": ""},
+			Columns: []string{"time", "f64"},
+			Values:  [][]interface{}{{time.Unix(1, 0), 1.5}},
+		},
+		{
+			Name:    "cpu",
+			Tags:    map[string]string{"host": "a", "region": ""},
+			Columns: []string{"time", "f64"},
+			Values:  [][]interface{}{{time.Unix(2, 0), 2.0}, {time.Unix(3, 0), nil}},
+		},
+	}
+
+	exp := []*remote.TimeSeries{
+		{
+			Labels: []*remote.LabelPair{
+				{Name: "__name__", Value: "cpu"},
+				{Name: "host", Value: "a"},
+			},
+			Samples: []*remote.Sample{
+				{Value: 1.5, TimestampMs: 1000},
+				{Value: 2, TimestampMs: 2000},
+			},
+		},
+	}
+	if got := prometheus.RowsToTimeSeries(rows); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected time series: %v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package tcp
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -26,6 +27,10 @@ type Mux struct {
 	// The amount of time to wait for the first header byte.
 	Timeout time.Duration
 
+	// If set, connections must complete a TLS handshake before the header
+	// byte is read.
+	TLSConfig *tls.Config
+
 	// Out-of-band error logger
 	Logger *log.Logger
 }
@@ -75,6 +80,11 @@ func (mux *Mux) handleConn(conn net.Conn) {
 		return
 	}
 
+	// Wrap the connection so the handshake happens on the first read.
+	if mux.TLSConfig != nil {
+		conn = tls.Server(conn, mux.TLSConfig)
+	}
+
 	// Read first byte from connection to determine handler.
 	var typ [1]byte
 	if _, err := io.ReadFull(conn, typ[:]); err != nil {
@@ -141,14 +151,82 @@ func (ln *listener) Addr() net.Addr { return nil }
 
 // Dial connects to a remote mux listener with a given header byte.
 func Dial(network, address string, header byte) (net.Conn, error) {
-	conn, err := net.Dial(network, address)
+	return DialTLS(network, address, header, nil)
+}
+
+// DialTLS connects to a remote mux listener with a given header byte. The
+// connection uses TLS if config is not nil.
+func DialTLS(network, address string, header byte, config *tls.Config) (net.Conn, error) {
+	conn, err := DialConn(network, address, 0, config)
 	if err != nil {
 		return nil, err
 	}
 
 	if _, err := conn.Write([]byte{header}); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("write mux header: %s", err)
 	}
 
 	return conn, nil
 }
+
+// DialConn connects to address with an optional timeout. If config is not nil
+// the TLS handshake is completed before returning. The certificate of the
+// remote node is verified against the host of address unless config sets a
+// server name.
+func DialConn(network, address string, timeout time.Duration, config *tls.Config) (net.Conn, error) {
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, err
+	} else if config == nil {
+		return conn, nil
+	}
+
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		config = cloneTLSConfig(config)
+		config.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if timeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(timeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("tls handshake: %s", err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	return tlsConn, nil
+}
+
+// cloneTLSConfig returns a copy of c. tls.Config.Clone requires Go 1.8 and the
+// config can't be copied by value once used so the fields are copied one by one.
+func cloneTLSConfig(c *tls.Config) *tls.Config {
+	return &tls.Config{
+		Rand:                     c.Rand,
+		Time:                     c.Time,
+		Certificates:             c.Certificates,
+		NameToCertificate:        c.NameToCertificate,
+		GetCertificate:           c.GetCertificate,
+		RootCAs:                  c.RootCAs,
+		NextProtos:               c.NextProtos,
+		ServerName:               c.ServerName,
+		ClientAuth:               c.ClientAuth,
+		ClientCAs:                c.ClientCAs,
+		InsecureSkipVerify:       c.InsecureSkipVerify,
+		CipherSuites:             c.CipherSuites,
+		PreferServerCipherSuites: c.PreferServerCipherSuites,
+		SessionTicketsDisabled:   c.SessionTicketsDisabled,
+		SessionTicketKey:         c.SessionTicketKey,
+		ClientSessionCache:       c.ClientSessionCache,
+		MinVersion:               c.MinVersion,
+		MaxVersion:               c.MaxVersion,
+		CurvePreferences:         c.CurvePreferences,
+	}
+}
@@ -2,9 +2,16 @@ package tcp_test
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"io"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"net"
 	"strings"
 	"sync"
@@ -135,3 +142,111 @@ func TestMux_Listen_ErrAlreadyRegistered(t *testing.T) {
 	mux.Listen(5)
 	mux.Listen(5)
 }
+
+// Ensure the muxer demultiplexes TLS connections and rejects peers without
+// a certificate signed by the cluster CA.
+func TestMux_TLS(t *testing.T) {
+	config := MustTLSConfig()
+
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpListener.Close()
+
+	mux := tcp.NewMux()
+	mux.TLSConfig = config
+	mux.Timeout = 200 * time.Millisecond
+	if !testing.Verbose() {
+		mux.Logger = log.New(ioutil.Discard, "", 0)
+	}
+	ln := mux.Listen(5)
+	go mux.Serve(tcpListener)
+
+	// Echo a message back on each connection.
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 5)
+			if _, err := io.ReadFull(conn, buf); err == nil {
+				conn.Write(buf)
+			}
+			conn.Close()
+		}
+	}()
+
+	conn, err := tcp.DialTLS("tcp", tcpListener.Addr().String(), 5, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "hello" {
+		t.Fatalf("unexpected message: %s", buf)
+	}
+
+	// Clients without a certificate are rejected.
+	noCert := &tls.Config{RootCAs: config.RootCAs}
+	if conn, err := tcp.DialTLS("tcp", tcpListener.Addr().String(), 5, noCert); err == nil {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Read(buf); err == nil {
+			t.Fatal("expected error")
+		}
+		conn.Close()
+	}
+
+	// Plaintext clients are rejected.
+	if conn, err := tcp.Dial("tcp", tcpListener.Addr().String(), 5); err == nil {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Read(buf); err == nil {
+			t.Fatal("expected error")
+		}
+		conn.Close()
+	}
+}
+
+// MustTLSConfig returns a mutual TLS configuration with a self-signed
+// certificate valid for 127.0.0.1. Panic on error.
+func MustTLSConfig() *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "influxdb"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+}
@@ -557,6 +557,8 @@ type AggregateMapper struct {
 	selectFields []string
 	selectTags   []string
 	whereFields  []string
+
+	limiter *SelectLimiter
 }
 
 // NewAggregateMapper returns a new instance of AggregateMapper.
@@ -696,6 +698,10 @@ func (m *AggregateMapper) openMeasurement(mm *Measurement) error {
 			cursorSet.Cursors = append(cursorSet.Cursors, NewTagsCursor(c, t.Filters[i], seriesTags))
 		}
 
+		if err := m.limiter.AddSeries(len(cursorSet.Cursors)); err != nil {
+			return err
+		}
+
 		// tsc.Init(m.qmin)
 		m.cursors = append(m.cursors, cursorSet)
 	}
@@ -740,6 +746,9 @@ func (m *AggregateMapper) initializeMapFunctions() error {
 	return nil
 }
 
+// SetSelectLimiter sets the limiter for the points and series read by the mapper.
+func (m *AggregateMapper) SetSelectLimiter(l *SelectLimiter) { m.limiter = l }
+
 // Close closes the mapper.
 func (m *AggregateMapper) Close() {
 	if m != nil && m.tx != nil {
@@ -806,6 +815,9 @@ func (m *AggregateMapper) NextChunk() (interface{}, error) {
 			TMin:  -1,
 			Items: readMapItems(cursorSet.Cursors, m.fieldNames[i], qmin, qmin, qmax),
 		}
+		if err := m.limiter.AddPoints(len(input.Items)); err != nil {
+			return nil, err
+		}
 
 		if len(m.stmt.Dimensions) > 0 && !m.stmt.HasTimeFieldSpecified() {
 			input.TMin = tmin
@@ -97,6 +97,12 @@ type Config struct {
 	// Query logging
 	QueryLogEnabled bool `toml:"query-log-enabled"`
 
+	// Query limits. Zero means no limit.
+	QueryTimeout     toml.Duration `toml:"query-timeout"`
+	MaxSelectPointN  int           `toml:"max-select-point"`
+	MaxSelectSeriesN int           `toml:"max-select-series"`
+	MaxSelectBuckets int           `toml:"max-select-buckets"`
+
 	// Compaction options for tsm1 (descriptions above with defaults)
 	CacheMaxMemorySize             uint64        `toml:"cache-max-memory-size"`
 	CacheSnapshotMemorySize        uint64        `toml:"cache-snapshot-memory-size"`
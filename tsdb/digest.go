@@ -0,0 +1,218 @@
+package tsdb
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdb/influxdb/models"
+)
+
+// DigestEntry summarizes the points of a single series within a single time
+// range of a shard. Two replicas of a shard hold the same data for a series
+// and range when their entries are equal.
+type DigestEntry struct {
+	Key      string // series key
+	Start    int64  // start of the time range, in nanoseconds
+	N        int64  // number of field values
+	Checksum uint64 // checksum of the field names, timestamps and values
+}
+
+// Digest returns a summary of the shard's data with one entry for each series
+// and time range of width interval that holds data. Entries are sorted by
+// series key and then by time.
+func (s *Shard) Digest(interval time.Duration) ([]DigestEntry, error) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	tx, err := s.ReadOnlyTx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var entries []DigestEntry
+	for _, m := range s.digestMeasurements() {
+		for _, key := range m.keys {
+			ranges := make(map[int64]*DigestEntry)
+			for _, field := range m.fields {
+				cur := tx.Cursor(key, []string{field}, m.codec, true)
+				if cur == nil {
+					continue
+				}
+
+				for k, v := cur.SeekTo(0); k != EOF; k, v = cur.Next() {
+					// Points without a value for the field are still returned.
+					if v == nil {
+						continue
+					}
+
+					start := k - (k % int64(interval))
+					e := ranges[start]
+					if e == nil {
+						e = &DigestEntry{Key: key, Start: start}
+						ranges[start] = e
+					}
+					e.N++
+					e.Checksum ^= digestValue(field, k, v)
+				}
+			}
+
+			starts := make([]int64, 0, len(ranges))
+			for start := range ranges {
+				starts = append(starts, start)
+			}
+			sort.Sort(int64Slice(starts))
+			for _, start := range starts {
+				entries = append(entries, *ranges[start])
+			}
+		}
+	}
+	return entries, nil
+}
+
+// SeriesPoints returns the points of a series with timestamps between
+// min and max, inclusive.
+func (s *Shard) SeriesPoints(key string, min, max int64) ([]models.Point, error) {
+	series := s.index.Series(key)
+	if series == nil {
+		return nil, nil
+	}
+	name, tags := series.measurement.Name, series.Tags
+
+	m := s.measurementFieldsByName(name)
+	if m == nil {
+		return nil, nil
+	}
+
+	tx, err := s.ReadOnlyTx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Merge the values of every field by timestamp.
+	values := make(map[int64]models.Fields)
+	for _, field := range m.fields {
+		cur := tx.Cursor(key, []string{field}, m.codec, true)
+		if cur == nil {
+			continue
+		}
+
+		for k, v := cur.SeekTo(min); k != EOF && k <= max; k, v = cur.Next() {
+			if v == nil {
+				continue
+			}
+			if values[k] == nil {
+				values[k] = make(models.Fields)
+			}
+			values[k][field] = v
+		}
+	}
+
+	timestamps := make([]int64, 0, len(values))
+	for k := range values {
+		timestamps = append(timestamps, k)
+	}
+	sort.Sort(int64Slice(timestamps))
+
+	points := make([]models.Point, 0, len(values))
+	for _, k := range timestamps {
+		pt, err := models.NewPoint(name, tags, values[k], time.Unix(0, k))
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, pt)
+	}
+	return points, nil
+}
+
+// digestMeasurement holds the series and fields of a measurement in a shard.
+type digestMeasurement struct {
+	keys   []string
+	fields []string
+	codec  *FieldCodec
+}
+
+// digestMeasurements returns the series keys and field names of every
+// measurement with fields in the shard, sorted by measurement name.
+func (s *Shard) digestMeasurements() []*digestMeasurement {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.measurementFields))
+	for name := range s.measurementFields {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	var a []*digestMeasurement
+	for _, name := range names {
+		mm := s.index.Measurement(name)
+		if mm == nil {
+			continue
+		}
+
+		m := s.measurementFieldsByName(name)
+		if m == nil {
+			continue
+		}
+		m.keys = mm.SeriesKeys()
+		sort.Strings(m.keys)
+		a = append(a, m)
+	}
+	return a
+}
+
+// measurementFieldsByName returns the sorted field names and codec of a measurement.
+func (s *Shard) measurementFieldsByName(name string) *digestMeasurement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mf := s.measurementFields[name]
+	if mf == nil {
+		return nil
+	}
+
+	m := &digestMeasurement{codec: mf.Codec}
+	for field := range mf.Fields {
+		m.fields = append(m.fields, field)
+	}
+	sort.Strings(m.fields)
+	return m
+}
+
+// digestValue returns the checksum of a single field value. Checksums of
+// the values in a range are combined with XOR so the order that they are
+// read in does not matter.
+func digestValue(field string, timestamp int64, v interface{}) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(field))
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(timestamp))
+	h.Write(buf[:])
+
+	switch v := v.(type) {
+	case float64:
+		binary.BigEndian.PutUint64(buf[:], math.Float64bits(v))
+		h.Write([]byte{'f'})
+		h.Write(buf[:])
+	case int64:
+		binary.BigEndian.PutUint64(buf[:], uint64(v))
+		h.Write([]byte{'i'})
+		h.Write(buf[:])
+	case bool:
+		if v {
+			h.Write([]byte{'t'})
+		} else {
+			h.Write([]byte{'b'})
+		}
+	case string:
+		h.Write([]byte{'s'})
+		h.Write([]byte(v))
+	}
+	return h.Sum64()
+}
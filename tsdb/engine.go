@@ -16,6 +16,10 @@ import (
 var (
 	// ErrFormatNotFound is returned when no format can be determined from a path.
 	ErrFormatNotFound = errors.New("format not found")
+
+	// ErrWriteToNotSupported is returned when an engine cannot write its
+	// contents to a stream for copying to another node.
+	ErrWriteToNotSupported = errors.New("engine does not support streaming shard contents")
 )
 
 // Engine represents a swappable storage engine for the shard.
@@ -78,6 +78,7 @@ type WAL interface {
 	Open() error
 	Close() error
 	Flush() error
+	FlushMetadata() error
 }
 
 // NewEngine returns a new instance of Engine.
@@ -600,7 +601,14 @@ func (e *Engine) SeriesBucketStats(key string) (stats bolt.BucketStats, err erro
 }
 
 // WriteTo writes the length and contents of the engine to w.
+// The WAL is flushed first so that the contents include all written series and points.
 func (e *Engine) WriteTo(w io.Writer) (n int64, err error) {
+	if err := e.WAL.FlushMetadata(); err != nil {
+		return 0, err
+	} else if err := e.WAL.Flush(); err != nil {
+		return 0, err
+	}
+
 	tx, err := e.db.Begin(false)
 	if err != nil {
 		return 0, err
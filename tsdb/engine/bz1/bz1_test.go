@@ -444,6 +444,8 @@ func (w *EnginePointsWriter) Cursor(series string, fields []string, dec *tsdb.Fi
 
 func (w *EnginePointsWriter) Flush() error { return nil }
 
+func (w *EnginePointsWriter) FlushMetadata() error { return nil }
+
 // Cursor represents a mock that implements tsdb.Curosr.
 type Cursor struct {
 	ascending bool
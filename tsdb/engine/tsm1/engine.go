@@ -313,7 +313,8 @@ func (e *DevEngine) Begin(writable bool) (tsdb.Tx, error) {
 	return &devTx{engine: e}, nil
 }
 
-func (e *DevEngine) WriteTo(w io.Writer) (n int64, err error) { panic("not implemented") }
+// WriteTo is not yet supported by the tsm1 engine.
+func (e *DevEngine) WriteTo(w io.Writer) (n int64, err error) { return 0, tsdb.ErrWriteToNotSupported }
 
 // WriteSnapshot will snapshot the cache and write a new TSM file with its contents, releasing the snapshot when done.
 func (e *DevEngine) WriteSnapshot() error {
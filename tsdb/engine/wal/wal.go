@@ -87,6 +87,10 @@ const (
 	statMemorySize     = "memSize"
 )
 
+func init() {
+	influxdb.RegisterGauges("wal", statMemorySize)
+}
+
 // flushType indiciates why a flush and compaction are being run so the partition can
 // do the appropriate type of compaction
 type flushType int
@@ -344,7 +348,7 @@ func (l *Log) LoadMetadataIndex(index *tsdb.DatabaseIndex, measurementFields map
 // is meant to be called by bz1 BEFORE it updates its own index, since the metadata
 // is flushed here first.
 func (l *Log) DeleteSeries(keys []string) error {
-	if err := l.flushMetadata(); err != nil {
+	if err := l.FlushMetadata(); err != nil {
 		return err
 	}
 
@@ -613,17 +617,17 @@ func (l *Log) autoflusher(closing chan struct{}) {
 				l.logger.Println("flush error:", err)
 			}
 		case <-metaFlushTicker.C:
-			if err := l.flushMetadata(); err != nil {
+			if err := l.FlushMetadata(); err != nil {
 				l.logger.Println("metadata flush error:", err)
 			}
 		}
 	}
 }
 
-// flushMetadata will write start a new metafile for writes to go through and then flush all
+// FlushMetadata will write start a new metafile for writes to go through and then flush all
 // metadata from previous files to the index. After a sucessful write, the metadata files
 // will be removed. While the flush to index is happening we aren't blocked for new metadata writes.
-func (l *Log) flushMetadata() error {
+func (l *Log) FlushMetadata() error {
 	l.statMap.Add(statMetadataFlush, 1)
 
 	// make sure there's actually something in the metadata file to flush
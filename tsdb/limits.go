@@ -0,0 +1,202 @@
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQueryTimeout is returned when a query runs longer than its timeout.
+var ErrQueryTimeout = errors.New("query timeout exceeded")
+
+// ErrMaxSelectPointsLimitExceeded is returned when a query reads more points than allowed.
+func ErrMaxSelectPointsLimitExceeded(n, limit int) error {
+	return fmt.Errorf("max-select-point limit exceeded: (%d/%d)", n, limit)
+}
+
+// ErrMaxSelectSeriesLimitExceeded is returned when a query reads more series than allowed.
+func ErrMaxSelectSeriesLimitExceeded(n, limit int) error {
+	return fmt.Errorf("max-select-series limit exceeded: (%d/%d)", n, limit)
+}
+
+// ErrMaxSelectBucketsLimitExceeded is returned when a GROUP BY time query
+// would create more buckets than allowed.
+func ErrMaxSelectBucketsLimitExceeded(n, limit int) error {
+	return fmt.Errorf("max-select-buckets limit exceeded: (%d/%d)", n, limit)
+}
+
+// QueryLimits represents the resource limits for a query. Zero values are unlimited.
+type QueryLimits struct {
+	Timeout           time.Duration // Maximum duration of the query.
+	MaxSelectPointN   int           // Maximum number of points read by a SELECT statement.
+	MaxSelectSeriesN  int           // Maximum number of series read by a SELECT statement.
+	MaxSelectBucketsN int           // Maximum number of GROUP BY time buckets.
+}
+
+// Restrict returns the limits lowered to the limits in other. Limits in other
+// can only make the result stricter so a request cannot raise a server limit.
+func (l QueryLimits) Restrict(other QueryLimits) QueryLimits {
+	return QueryLimits{
+		Timeout:           time.Duration(restrictLimit(int64(l.Timeout), int64(other.Timeout))),
+		MaxSelectPointN:   int(restrictLimit(int64(l.MaxSelectPointN), int64(other.MaxSelectPointN))),
+		MaxSelectSeriesN:  int(restrictLimit(int64(l.MaxSelectSeriesN), int64(other.MaxSelectSeriesN))),
+		MaxSelectBucketsN: int(restrictLimit(int64(l.MaxSelectBucketsN), int64(other.MaxSelectBucketsN))),
+	}
+}
+
+// restrictLimit returns the lower of two limits where zero is unlimited.
+func restrictLimit(a, b int64) int64 {
+	if b > 0 && (a <= 0 || b < a) {
+		return b
+	}
+	return a
+}
+
+// SelectLimiter counts the points and series read by the mappers of a single
+// SELECT statement and returns an error once a limit is exceeded or the query
+// deadline has passed. It is safe for concurrent use. A nil SelectLimiter
+// allows everything.
+type SelectLimiter struct {
+	limits   QueryLimits
+	deadline time.Time
+	pointN   int64
+	seriesN  int64
+}
+
+// NewSelectLimiter returns a limiter for limits. The deadline is set from the
+// timeout at creation. Returns nil if limits has no point, series or timeout limit.
+func NewSelectLimiter(limits QueryLimits) *SelectLimiter {
+	if limits.MaxSelectPointN <= 0 && limits.MaxSelectSeriesN <= 0 && limits.Timeout <= 0 {
+		return nil
+	}
+
+	l := &SelectLimiter{limits: limits}
+	if limits.Timeout > 0 {
+		l.deadline = time.Now().Add(limits.Timeout)
+	}
+	return l
+}
+
+// Limits returns the limits being enforced.
+func (l *SelectLimiter) Limits() QueryLimits {
+	if l == nil {
+		return QueryLimits{}
+	}
+	return l.limits
+}
+
+// AddPoints records n points read and checks the point limit and deadline.
+func (l *SelectLimiter) AddPoints(n int) error {
+	if l == nil {
+		return nil
+	}
+
+	pointN := atomic.AddInt64(&l.pointN, int64(n))
+	if max := l.limits.MaxSelectPointN; max > 0 && pointN > int64(max) {
+		return ErrMaxSelectPointsLimitExceeded(int(pointN), max)
+	}
+	if !l.deadline.IsZero() && time.Now().After(l.deadline) {
+		return ErrQueryTimeout
+	}
+	return nil
+}
+
+// Counts returns the number of points read and series opened so far.
+func (l *SelectLimiter) Counts() (pointN, seriesN int) {
+	if l == nil {
+		return 0, 0
+	}
+	return int(atomic.LoadInt64(&l.pointN)), int(atomic.LoadInt64(&l.seriesN))
+}
+
+// AddSeries records n series opened and checks the series limit.
+func (l *SelectLimiter) AddSeries(n int) error {
+	if l == nil {
+		return nil
+	}
+
+	seriesN := atomic.AddInt64(&l.seriesN, int64(n))
+	if max := l.limits.MaxSelectSeriesN; max > 0 && seriesN > int64(max) {
+		return ErrMaxSelectSeriesLimitExceeded(int(seriesN), max)
+	}
+	return nil
+}
+
+// LimitedMapper is implemented by mappers that enforce the limits of a query.
+type LimitedMapper interface {
+	Mapper
+
+	// SetSelectLimiter sets the limiter shared by the mappers of a statement.
+	// It must be called before Open.
+	SetSelectLimiter(l *SelectLimiter)
+}
+
+// queryTimer aborts a query when its caller closes it or its timeout expires.
+type queryTimer struct {
+	closing  chan struct{} // closed when the query must stop
+	deadline time.Time
+	expired  int32
+	done     chan struct{}
+}
+
+// newQueryTimer returns a timer for a query closed by closing. If timeout is
+// not positive the timer only follows closing.
+func newQueryTimer(closing chan struct{}, timeout time.Duration) *queryTimer {
+	t := &queryTimer{closing: closing}
+	if timeout <= 0 {
+		return t
+	}
+
+	t.closing = make(chan struct{})
+	t.deadline = time.Now().Add(timeout)
+	t.done = make(chan struct{})
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			atomic.StoreInt32(&t.expired, 1)
+		case <-closing:
+		case <-t.done:
+			return
+		}
+		close(t.closing)
+	}()
+	return t
+}
+
+// Stop releases the timer once the query has completed.
+func (t *queryTimer) Stop() {
+	if t.done != nil {
+		close(t.done)
+	}
+}
+
+// Expired returns true if the query timeout has expired.
+func (t *queryTimer) Expired() bool {
+	return atomic.LoadInt32(&t.expired) == 1
+}
+
+// Err returns ErrQueryTimeout if the timeout has expired, otherwise err.
+// Errors from executors aborted by the timer are replaced this way.
+func (t *queryTimer) Err(err error) error {
+	if t.Expired() {
+		return ErrQueryTimeout
+	}
+	return err
+}
+
+// Limits returns limits with the timeout set to the time remaining in the query.
+func (t *queryTimer) Limits(limits QueryLimits) QueryLimits {
+	if t.deadline.IsZero() {
+		return limits
+	}
+
+	limits.Timeout = t.deadline.Sub(time.Now())
+	if limits.Timeout <= 0 {
+		limits.Timeout = time.Nanosecond
+	}
+	return limits
+}
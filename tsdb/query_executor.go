@@ -53,6 +53,9 @@ type QueryExecutor struct {
 	Logger          *log.Logger
 	QueryLogEnabled bool
 
+	// Resource limits applied to every query. Requests can only lower them.
+	Limits QueryLimits
+
 	// the local data store
 	Store *Store
 }
@@ -139,13 +142,30 @@ func (q *QueryExecutor) Authorize(u *meta.UserInfo, query *influxql.Query, datab
 // It sends results down the passed in chan and closes it when done. It will close the chan
 // on the first statement that throws an error.
 func (q *QueryExecutor) ExecuteQuery(query *influxql.Query, database string, chunkSize int, closing chan struct{}) (<-chan *influxql.Result, error) {
+	return q.ExecuteQueryWithLimits(query, database, chunkSize, QueryLimits{}, closing)
+}
+
+// ExecuteQueryWithLimits executes an InfluxQL query like ExecuteQuery. The
+// server limits are lowered to any limit set in limits for this query only.
+func (q *QueryExecutor) ExecuteQueryWithLimits(query *influxql.Query, database string, chunkSize int, limits QueryLimits, closing chan struct{}) (<-chan *influxql.Result, error) {
+	limits = q.Limits.Restrict(limits)
+
 	// Execute each statement. Keep the iterator external so we can
 	// track how many of the statements were executed
 	results := make(chan *influxql.Result)
 	go func() {
+		// Abort the query once the caller closes it or the timeout expires.
+		timer := newQueryTimer(closing, limits.Timeout)
+		defer timer.Stop()
+
 		var i int
 		var stmt influxql.Statement
 		for i, stmt = range query.Statements {
+			if timer.Expired() {
+				results <- &influxql.Result{Err: ErrQueryTimeout}
+				break
+			}
+
 			// If a default database wasn't passed in by the caller, check the statement.
 			// Some types of statements have an associated default database, even if it
 			// is not explicitly included.
@@ -170,8 +190,8 @@ func (q *QueryExecutor) ExecuteQuery(query *influxql.Query, database string, chu
 			var res *influxql.Result
 			switch stmt := stmt.(type) {
 			case *influxql.SelectStatement:
-				if err := q.executeStatement(i, stmt, database, results, chunkSize, closing); err != nil {
-					results <- &influxql.Result{Err: err}
+				if err := q.executeStatement(i, stmt, database, results, chunkSize, timer.Limits(limits), timer.closing); err != nil {
+					results <- &influxql.Result{Err: timer.Err(err)}
 					break
 				}
 			case *influxql.DropSeriesStatement:
@@ -183,13 +203,13 @@ func (q *QueryExecutor) ExecuteQuery(query *influxql.Query, database string, chu
 				// TODO: handle this in a cluster
 				res = q.executeDropMeasurementStatement(stmt, database)
 			case *influxql.ShowMeasurementsStatement:
-				if err := q.executeStatement(i, stmt, database, results, chunkSize, closing); err != nil {
-					results <- &influxql.Result{Err: err}
+				if err := q.executeStatement(i, stmt, database, results, chunkSize, timer.Limits(limits), timer.closing); err != nil {
+					results <- &influxql.Result{Err: timer.Err(err)}
 					break
 				}
 			case *influxql.ShowTagKeysStatement:
-				if err := q.executeStatement(i, stmt, database, results, chunkSize, closing); err != nil {
-					results <- &influxql.Result{Err: err}
+				if err := q.executeStatement(i, stmt, database, results, chunkSize, timer.Limits(limits), timer.closing); err != nil {
+					results <- &influxql.Result{Err: timer.Err(err)}
 					break
 				}
 			case *influxql.ShowTagValuesStatement:
@@ -234,6 +254,11 @@ func (q *QueryExecutor) ExecuteQuery(query *influxql.Query, database string, chu
 
 // Plan creates an execution plan for the given SelectStatement and returns an Executor.
 func (q *QueryExecutor) PlanSelect(stmt *influxql.SelectStatement, chunkSize int) (Executor, error) {
+	return q.planSelect(stmt, chunkSize, q.Limits)
+}
+
+// planSelect creates an execution plan for a SelectStatement enforcing limits.
+func (q *QueryExecutor) planSelect(stmt *influxql.SelectStatement, chunkSize int, limits QueryLimits) (Executor, error) {
 	var shardIDs []uint64
 	shards := map[uint64]meta.ShardInfo{} // Shards requiring mappers.
 
@@ -246,6 +271,23 @@ func (q *QueryExecutor) PlanSelect(stmt *influxql.SelectStatement, chunkSize int
 	if tmax.IsZero() {
 		tmax = now
 	}
+
+	// Check the number of GROUP BY time buckets before anything is allocated for them.
+	if max := limits.MaxSelectBucketsN; max > 0 && !tmin.IsZero() {
+		d, err := stmt.GroupByInterval()
+		if err != nil {
+			return nil, err
+		}
+		if d > 0 {
+			size := d.Nanoseconds()
+			top := tmax.UnixNano()/size*size + size
+			bottom := tmin.UnixNano() / size * size
+			if n := (top - bottom) / size; n > int64(max) {
+				return nil, ErrMaxSelectBucketsLimitExceeded(int(n), max)
+			}
+		}
+	}
+
 	if tmin.IsZero() {
 		tmin = time.Unix(0, 0)
 	}
@@ -275,7 +317,9 @@ func (q *QueryExecutor) PlanSelect(stmt *influxql.SelectStatement, chunkSize int
 	// Sort shard IDs to make testing deterministic.
 	sort.Sort(uint64Slice(shardIDs))
 
-	// Build the Mappers, one per shard.
+	// Build the Mappers, one per shard. The mappers share a limiter so the
+	// point and series limits apply to the whole statement.
+	limiter := NewSelectLimiter(limits)
 	mappers := []Mapper{}
 	for _, shardID := range shardIDs {
 		sh := shards[shardID]
@@ -288,6 +332,9 @@ func (q *QueryExecutor) PlanSelect(stmt *influxql.SelectStatement, chunkSize int
 			// No data for this shard, skip it.
 			continue
 		}
+		if lm, ok := m.(LimitedMapper); ok {
+			lm.SetSelectLimiter(limiter)
+		}
 		mappers = append(mappers, m)
 	}
 
@@ -611,10 +658,10 @@ func (q *QueryExecutor) filterShowSeriesResult(limit, offset int, rows models.Ro
 	return filteredSeries
 }
 
-func (q *QueryExecutor) planStatement(stmt influxql.Statement, database string, chunkSize int) (Executor, error) {
+func (q *QueryExecutor) planStatement(stmt influxql.Statement, database string, chunkSize int, limits QueryLimits) (Executor, error) {
 	switch stmt := stmt.(type) {
 	case *influxql.SelectStatement:
-		return q.PlanSelect(stmt, chunkSize)
+		return q.planSelect(stmt, chunkSize, limits)
 	case *influxql.ShowMeasurementsStatement:
 		return q.PlanShowMeasurements(stmt, database, chunkSize)
 	case *influxql.ShowTagKeysStatement:
@@ -696,9 +743,9 @@ func (q *QueryExecutor) PlanShowTagKeys(stmt *influxql.ShowTagKeysStatement, dat
 	return executor, nil
 }
 
-func (q *QueryExecutor) executeStatement(statementID int, stmt influxql.Statement, database string, results chan *influxql.Result, chunkSize int, closing chan struct{}) error {
+func (q *QueryExecutor) executeStatement(statementID int, stmt influxql.Statement, database string, results chan *influxql.Result, chunkSize int, limits QueryLimits, closing chan struct{}) error {
 	// Plan statement execution.
-	e, err := q.planStatement(stmt, database, chunkSize)
+	e, err := q.planStatement(stmt, database, chunkSize, limits)
 	if err != nil {
 		return err
 	}
@@ -407,6 +407,62 @@ func TestQueryNoData(t *testing.T) {
 	store.Close()
 }
 
+// Ensure that SELECT statements over the query limits return an error.
+func TestQueryLimits(t *testing.T) {
+	store, executor := testStoreAndExecutor("")
+	defer os.RemoveAll(store.Path())
+
+	for i, host := range []string{"serverA", "serverB"} {
+		if err := store.WriteToShard(shardID, []models.Point{
+			models.MustNewPoint("cpu", map[string]string{"host": host}, map[string]interface{}{"value": 1.0}, time.Unix(int64(i+1), 0)),
+			models.MustNewPoint("cpu", map[string]string{"host": host}, map[string]interface{}{"value": 2.0}, time.Unix(int64(i+3), 0)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, tt := range []struct {
+		limits tsdb.QueryLimits
+		query  string
+		exp    string
+	}{
+		{
+			limits: tsdb.QueryLimits{MaxSelectPointN: 2},
+			query:  "SELECT value FROM cpu",
+			exp:    `[{"error":"max-select-point limit exceeded: (4/2)"}]`,
+		},
+		{
+			limits: tsdb.QueryLimits{MaxSelectSeriesN: 1},
+			query:  "SELECT value FROM cpu GROUP BY host",
+			exp:    `[{"error":"max-select-series limit exceeded: (2/1)"}]`,
+		},
+		{
+			limits: tsdb.QueryLimits{MaxSelectBucketsN: 2},
+			query:  "SELECT count(value) FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time < '1970-01-01T00:00:05Z' GROUP BY time(1s)",
+			exp:    `[{"error":"max-select-buckets limit exceeded: (5/2)"}]`,
+		},
+		{
+			limits: tsdb.QueryLimits{MaxSelectPointN: 4, MaxSelectSeriesN: 2},
+			query:  "SELECT count(value) FROM cpu",
+			exp:    `[{"series":[{"name":"cpu","columns":["time","count"],"values":[["1970-01-01T00:00:00Z",4]]}]}]`,
+		},
+	} {
+		executor.Limits = tt.limits
+		if got := executeAndGetJSON(tt.query, executor); got != tt.exp {
+			t.Errorf("%d. %s\nexp: %s\ngot: %s", i, tt.query, tt.exp, got)
+		}
+	}
+}
+
+// Ensure that a request can only lower the limits of the executor.
+func TestQueryLimits_Restrict(t *testing.T) {
+	limits := tsdb.QueryLimits{Timeout: time.Minute, MaxSelectPointN: 100}
+	got := limits.Restrict(tsdb.QueryLimits{Timeout: time.Hour, MaxSelectPointN: 10, MaxSelectSeriesN: 5})
+	if exp := (tsdb.QueryLimits{Timeout: time.Minute, MaxSelectPointN: 10, MaxSelectSeriesN: 5}); got != exp {
+		t.Fatalf("unexpected limits: %+v", got)
+	}
+}
+
 // ensure that authenticate doesn't return an error if the user count is zero and they're attempting
 // to create a user.
 func TestAuthenticateIfUserCountZeroAndCreateUser(t *testing.T) {
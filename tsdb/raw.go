@@ -800,6 +800,8 @@ type RawMapper struct {
 	selectTags   []string
 	whereFields  []string
 
+	limiter *SelectLimiter
+
 	ChunkSize int
 }
 
@@ -895,6 +897,10 @@ func (m *RawMapper) openMeasurement(mm *Measurement) error {
 			cursors = append(cursors, cm)
 		}
 
+		if err := m.limiter.AddSeries(len(cursors)); err != nil {
+			return err
+		}
+
 		tsc := NewTagSetCursor(mm.Name, t.Tags, cursors, ascending)
 		tsc.SelectFields = m.selectFields
 		if ascending {
@@ -911,6 +917,9 @@ func (m *RawMapper) openMeasurement(mm *Measurement) error {
 	return nil
 }
 
+// SetSelectLimiter sets the limiter for the points and series read by the mapper.
+func (m *RawMapper) SetSelectLimiter(l *SelectLimiter) { m.limiter = l }
+
 // Close closes the mapper.
 func (m *RawMapper) Close() {
 	if m != nil && m.tx != nil {
@@ -943,7 +952,7 @@ func (m *RawMapper) NextChunk() (interface{}, error) {
 			m.cursorIndex++
 			if output != nil {
 				// There is data, so return it and continue when next called.
-				return output, nil
+				return m.limitChunk(output)
 			} else {
 				// Just go straight to the next cursor.
 				continue
@@ -966,7 +975,15 @@ func (m *RawMapper) NextChunk() (interface{}, error) {
 		})
 
 		if len(output.Values) == m.ChunkSize {
-			return output, nil
+			return m.limitChunk(output)
 		}
 	}
 }
+
+// limitChunk records the points in a chunk with the limiter and returns the chunk.
+func (m *RawMapper) limitChunk(output *MapperOutput) (interface{}, error) {
+	if err := m.limiter.AddPoints(len(output.Values)); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -20,12 +20,13 @@ import (
 )
 
 const (
-	statWriteReq        = "writeReq"
-	statSeriesCreate    = "seriesCreate"
-	statFieldsCreate    = "fieldsCreate"
-	statWritePointsFail = "writePointsFail"
-	statWritePointsOK   = "writePointsOk"
-	statWriteBytes      = "writeBytes"
+	statWriteReq           = "writeReq"
+	statSeriesCreate       = "seriesCreate"
+	statFieldsCreate       = "fieldsCreate"
+	statWritePointsFail    = "writePointsFail"
+	statWritePointsOK      = "writePointsOk"
+	statWritePointsDropped = "writePointsDropped"
+	statWriteBytes         = "writeBytes"
 )
 
 var (
@@ -199,9 +200,24 @@ type SeriesCreate struct {
 func (s *Shard) WritePoints(points []models.Point) error {
 	s.statMap.Add(statWriteReq, 1)
 
-	seriesToCreate, fieldsToCreate, seriesToAddShardTo, err := s.validateSeriesAndFields(points)
-	if err != nil {
-		return err
+	seriesToCreate, fieldsToCreate, seriesToAddShardTo, dropped := s.validateSeriesAndFields(points)
+	if len(dropped) > 0 {
+		s.statMap.Add(statWritePointsDropped, int64(len(dropped)))
+
+		// Only write the points that passed validation.
+		accepted := make([]models.Point, 0, len(points)-len(dropped))
+		for i, j := 0, 0; i < len(points); i++ {
+			if j < len(dropped) && dropped[j].Index == i {
+				j++
+				continue
+			}
+			accepted = append(accepted, points[i])
+		}
+		points = accepted
+
+		if len(points) == 0 {
+			return &influxdb.PartialWriteError{Errors: dropped}
+		}
 	}
 	s.statMap.Add(statSeriesCreate, int64(len(seriesToCreate)))
 	s.statMap.Add(statFieldsCreate, int64(len(fieldsToCreate)))
@@ -266,6 +282,9 @@ func (s *Shard) WritePoints(points []models.Point) error {
 	}
 	s.statMap.Add(statWritePointsOK, int64(len(points)))
 
+	if len(dropped) > 0 {
+		return &influxdb.PartialWriteError{Errors: dropped}
+	}
 	return nil
 }
 
@@ -382,10 +401,11 @@ func (s *Shard) createFieldsAndMeasurements(fieldsToCreate []*FieldCreate) (map[
 }
 
 // validateSeriesAndFields checks which series and fields are new and whose metadata should be saved and indexed
-func (s *Shard) validateSeriesAndFields(points []models.Point) ([]*SeriesCreate, []*FieldCreate, []string, error) {
+func (s *Shard) validateSeriesAndFields(points []models.Point) ([]*SeriesCreate, []*FieldCreate, []string, []influxdb.PointError) {
 	var seriesToCreate []*SeriesCreate
 	var fieldsToCreate []*FieldCreate
 	var seriesToAddShardTo []string
+	var dropped []influxdb.PointError
 
 	// get the mutex for the in memory index, which is shared across shards
 	s.index.mu.RLock()
@@ -395,10 +415,18 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]*SeriesCreate,
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for _, p := range points {
+	for i, p := range points {
+		// validate field types against the shard metadata
+		mf := s.measurementFields[p.Name()]
+		if err := validateFieldTypes(mf, p); err != nil {
+			dropped = append(dropped, influxdb.PointError{Index: i, Kind: influxdb.WriteErrorFieldTypeConflict, Err: err})
+			continue
+		}
+
 		// see if the series should be added to the index
-		if ss := s.index.series[string(p.Key())]; ss == nil {
-			series := NewSeries(string(p.Key()), p.Tags())
+		key := string(p.Key())
+		if ss := s.index.series[key]; ss == nil {
+			series := NewSeries(key, p.Tags())
 			seriesToCreate = append(seriesToCreate, &SeriesCreate{p.Name(), series})
 			seriesToAddShardTo = append(seriesToAddShardTo, series.Key)
 		} else if !ss.shardIDs[s.id] {
@@ -408,30 +436,31 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]*SeriesCreate,
 		}
 
 		// see if the field definitions need to be saved to the shard
-		mf := s.measurementFields[p.Name()]
-		if mf == nil {
-			for name, value := range p.Fields() {
-				fieldsToCreate = append(fieldsToCreate, &FieldCreate{p.Name(), &Field{Name: name, Type: influxql.InspectDataType(value)}})
-			}
-			continue // skip validation since all fields are new
-		}
-
-		// validate field types and encode data
 		for name, value := range p.Fields() {
-			if f := mf.Fields[name]; f != nil {
-				// Field present in shard metadata, make sure there is no type conflict.
-				if f.Type != influxql.InspectDataType(value) {
-					return nil, nil, nil, fmt.Errorf("field type conflict: input field \"%s\" on measurement \"%s\" is type %T, already exists as type %s", name, p.Name(), value, f.Type)
-				}
-
+			if mf != nil && mf.Fields[name] != nil {
 				continue // Field is present, and it's of the same type. Nothing more to do.
 			}
-
 			fieldsToCreate = append(fieldsToCreate, &FieldCreate{p.Name(), &Field{Name: name, Type: influxql.InspectDataType(value)}})
 		}
 	}
 
-	return seriesToCreate, fieldsToCreate, seriesToAddShardTo, nil
+	return seriesToCreate, fieldsToCreate, seriesToAddShardTo, dropped
+}
+
+// validateFieldTypes returns an error if any field of p has a different type
+// than the one already stored in mf.
+func validateFieldTypes(mf *MeasurementFields, p models.Point) error {
+	if mf == nil {
+		return nil // all fields are new
+	}
+
+	for name, value := range p.Fields() {
+		// Field present in shard metadata, make sure there is no type conflict.
+		if f := mf.Fields[name]; f != nil && f.Type != influxql.InspectDataType(value) {
+			return fmt.Errorf("field type conflict: input field \"%s\" on measurement \"%s\" is type %T, already exists as type %s", name, p.Name(), value, f.Type)
+		}
+	}
+	return nil
 }
 
 // SeriesCount returns the number of series buckets on the shard.
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/models"
 	"github.com/influxdb/influxdb/tsdb"
 	"github.com/influxdb/influxdb/tsdb/engine/b1"
@@ -140,6 +141,44 @@ func TestShardWriteAddNewField(t *testing.T) {
 
 }
 
+// Ensure points with conflicting field types are dropped without failing the whole write.
+func TestShard_WritePoints_FieldTypeConflict(t *testing.T) {
+	tmpDir, _ := ioutil.TempDir("", "shard_test")
+	defer os.RemoveAll(tmpDir)
+
+	index := tsdb.NewDatabaseIndex()
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = filepath.Join(tmpDir, "wal")
+
+	sh := tsdb.NewShard(1, index, path.Join(tmpDir, "shard"), path.Join(tmpDir, "wal"), opts)
+	if err := sh.Open(); err != nil {
+		t.Fatalf("error opening shard: %s", err.Error())
+	}
+	defer sh.Close()
+
+	if err := sh.WritePoints([]models.Point{
+		models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := sh.WritePoints([]models.Point{
+		models.MustNewPoint("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(2, 0)),
+		models.MustNewPoint("cpu", map[string]string{"host": "c"}, map[string]interface{}{"value": "x"}, time.Unix(3, 0)),
+	})
+	if perr, ok := err.(*influxdb.PartialWriteError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(perr.Errors) != 1 || perr.Errors[0].Index != 1 || perr.Errors[0].Kind != influxdb.WriteErrorFieldTypeConflict {
+		t.Fatalf("unexpected point errors: %+v", perr.Errors)
+	}
+
+	if index.Series("cpu,host=b") == nil {
+		t.Fatal("expected valid point to be written")
+	} else if index.Series("cpu,host=c") != nil {
+		t.Fatal("expected conflicting point to be dropped")
+	}
+}
+
 // Ensure the shard will automatically flush the WAL after a threshold has been reached.
 func TestShard_Autoflush(t *testing.T) {
 	path, _ := ioutil.TempDir("", "shard_test")
@@ -220,6 +259,67 @@ func TestShard_Autoflush_FlushInterval(t *testing.T) {
 	}
 }
 
+// Ensure replicas holding the same points have equal digests and that a
+// missing point is detected and can be read back from the other replica.
+func TestShard_Digest(t *testing.T) {
+	path, _ := ioutil.TempDir("", "shard_test")
+	defer os.RemoveAll(path)
+
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = filepath.Join(path, "wal")
+
+	sh0 := tsdb.NewShard(1, tsdb.NewDatabaseIndex(), filepath.Join(path, "shard0"), filepath.Join(path, "wal0"), opts)
+	sh1 := tsdb.NewShard(1, tsdb.NewDatabaseIndex(), filepath.Join(path, "shard1"), filepath.Join(path, "wal1"), opts)
+	for _, sh := range []*tsdb.Shard{sh0, sh1} {
+		if err := sh.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer sh.Close()
+	}
+
+	// Each shard gets its own points since writing caches the encoded
+	// fields of a point using the field codec of the shard.
+	newPoints := func() []models.Point {
+		return []models.Point{
+			models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0, "idle": int64(2)}, time.Unix(10, 0)),
+			models.MustNewPoint("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(7200, 0)),
+			models.MustNewPoint("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(20, 0)),
+		}
+	}
+	points := newPoints()
+	if err := sh0.WritePoints(points); err != nil {
+		t.Fatal(err)
+	} else if err := sh1.WritePoints(newPoints()[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	d0, err := sh0.Digest(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(d0) != 3 {
+		t.Fatalf("unexpected digest entry count: %d", len(d0))
+	} else if d0[0].Key != "cpu,host=a" || d0[0].Start != 0 || d0[0].N != 2 {
+		t.Fatalf("unexpected digest entry: %+v", d0[0])
+	} else if d0[1].Key != "cpu,host=a" || d0[1].Start != int64(2*time.Hour) || d0[1].N != 1 {
+		t.Fatalf("unexpected digest entry: %+v", d0[1])
+	}
+
+	d1, err := sh1.Digest(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(d0[:2], d1) {
+		t.Fatalf("unexpected digest:\nexp=%+v\ngot=%+v", d0[:2], d1)
+	}
+
+	// Read the missing series from the first replica.
+	a, err := sh0.SeriesPoints("cpu,host=b", 0, int64(time.Hour)-1)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(a) != 1 || a[0].String() != points[2].String() {
+		t.Fatalf("unexpected points: %v", a)
+	}
+}
+
 func BenchmarkWritePoints_NewSeries_1K(b *testing.B)   { benchmarkWritePoints(b, 38, 3, 3, 1) }
 func BenchmarkWritePoints_NewSeries_100K(b *testing.B) { benchmarkWritePoints(b, 32, 5, 5, 1) }
 func BenchmarkWritePoints_NewSeries_250K(b *testing.B) { benchmarkWritePoints(b, 80, 5, 5, 1) }
@@ -1,16 +1,18 @@
 package tsdb
 
 import (
+	"encoding/binary"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/influxdb/influxdb"
 	"github.com/influxdb/influxdb/influxql"
 	"github.com/influxdb/influxdb/models"
 )
@@ -77,7 +79,12 @@ func (s *Store) ShardN() int {
 func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.createShard(database, retentionPolicy, shardID)
+}
 
+// createShard opens a shard, creating it if it doesn't exist.
+// Must be called with the lock held.
+func (s *Store) createShard(database, retentionPolicy string, shardID uint64) error {
 	select {
 	case <-s.closing:
 		return ErrStoreClosed
@@ -122,7 +129,12 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64) er
 func (s *Store) DeleteShard(shardID uint64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.deleteShard(shardID)
+}
 
+// deleteShard closes and removes a shard from disk.
+// Must be called with the lock held.
+func (s *Store) deleteShard(shardID uint64) error {
 	// ensure shard exists
 	sh, ok := s.shards[shardID]
 	if !ok {
@@ -146,6 +158,53 @@ func (s *Store) DeleteShard(shardID uint64) error {
 	return nil
 }
 
+// RestoreShard replaces the contents of a shard with a stream written by
+// Shard.WriteTo on another node. Any existing copy of the shard is removed.
+func (s *Store) RestoreShard(database, retentionPolicy string, shardID uint64, r io.Reader) error {
+	// Read the size of the shard data.
+	var n uint64
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return fmt.Errorf("read shard size: %s", err)
+	}
+
+	// Write the shard data next to its path in the store. The store isn't
+	// locked while the data is read as that can take a long time.
+	if err := os.MkdirAll(filepath.Join(s.path, database, retentionPolicy), 0700); err != nil {
+		return err
+	}
+	shardPath := filepath.Join(s.path, database, retentionPolicy, strconv.FormatUint(shardID, 10))
+	tmpPath := shardPath + ".restore"
+	if err := func() error {
+		f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := io.CopyN(f, r, int64(n)); err != nil {
+			return fmt.Errorf("read shard data: %s", err)
+		}
+		return f.Sync()
+	}(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// Replace the existing copy and open the restored shard. The lock is held
+	// throughout so the shard can't be opened or written to in between.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.deleteShard(shardID); err != nil {
+		os.Remove(tmpPath)
+		return err
+	} else if err := os.Rename(tmpPath, shardPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return s.createShard(database, retentionPolicy, shardID)
+}
+
 // DeleteDatabase will close all shards associated with a database and remove the directory and files from disk.
 func (s *Store) DeleteDatabase(name string, shardIDs []uint64) error {
 	s.mu.Lock()
@@ -448,7 +507,8 @@ func IsRetryable(err error) bool {
 		return true
 	}
 
-	if strings.Contains(err.Error(), "field type conflict") {
+	switch influxdb.WriteErrorKindOf(err) {
+	case influxdb.WriteErrorFieldTypeConflict:
 		return false
 	}
 	return true
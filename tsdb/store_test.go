@@ -1,6 +1,7 @@
 package tsdb_test
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -277,6 +278,68 @@ func TestStoreEnsureSeriesPersistedInNewShards(t *testing.T) {
 	}
 }
 
+// Ensure a shard written by one store can be restored into another.
+func TestStoreRestoreShard(t *testing.T) {
+	src, dst := MustOpenStore(), MustOpenStore()
+	defer src.Close()
+	defer dst.Close()
+
+	if err := src.CreateShard("foo", "default", 1); err != nil {
+		t.Fatalf("error creating shard: %v", err)
+	}
+	p, _ := models.ParsePoints([]byte("cpu,host=a val=1 10\ncpu,host=a val=2 20"))
+	if err := src.WriteToShard(1, p); err != nil {
+		t.Fatalf("error writing to shard: %v", err)
+	}
+
+	// Stream the shard from the source store into the destination.
+	var buf bytes.Buffer
+	if _, err := src.Shard(1).WriteTo(&buf); err != nil {
+		t.Fatalf("error writing shard: %v", err)
+	} else if err := dst.RestoreShard("foo", "default", 1, &buf); err != nil {
+		t.Fatalf("error restoring shard: %v", err)
+	}
+
+	// Verify the restored shard has the same points.
+	sh := dst.Shard(1)
+	if sh == nil {
+		t.Fatal("expected restored shard")
+	}
+	if points, err := sh.SeriesPoints("cpu,host=a", 0, 100); err != nil {
+		t.Fatal(err)
+	} else if len(points) != 2 {
+		t.Fatalf("unexpected points: %v", points)
+	}
+}
+
+// Store is a test wrapper for tsdb.Store.
+type Store struct {
+	*tsdb.Store
+	dir string
+}
+
+// MustOpenStore returns a new, opened store in a temporary directory.
+func MustOpenStore() *Store {
+	dir, err := ioutil.TempDir("", "store_test")
+	if err != nil {
+		panic(err)
+	}
+
+	s := tsdb.NewStore(filepath.Join(dir, "data"))
+	s.EngineOptions.Config.WALDir = filepath.Join(dir, "wal")
+	if err := s.Open(); err != nil {
+		panic(err)
+	}
+	return &Store{Store: s, dir: dir}
+}
+
+// Close closes the store and removes its data.
+func (s *Store) Close() error {
+	err := s.Store.Close()
+	os.RemoveAll(s.dir)
+	return err
+}
+
 func BenchmarkStoreOpen_200KSeries_100Shards(b *testing.B) { benchmarkStoreOpen(b, 64, 5, 5, 1, 100) }
 
 func benchmarkStoreOpen(b *testing.B, mCnt, tkCnt, tvCnt, pntCnt, shardCnt int) {